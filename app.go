@@ -34,6 +34,7 @@ type App struct {
 	costCalculator          *cost.CostCalculator
 	taskScheduler           *redc.TaskScheduler
 	spotMonitor             *SpotMonitor
+	healthMonitor           *HealthMonitor
 	customDeploymentService *redc.CustomDeploymentService
 	templateManager         *redc.TemplateManager
 	configStore             *redc.ConfigStore
@@ -42,6 +43,8 @@ type App struct {
 	disableRightClick       bool
 	httpSrv                 *HTTPServer
 	wailsMode               bool // true when running inside Wails desktop
+	headless                bool // started via startupHeadless (server / daemon)
+	startedAt               time.Time
 	activeOps               atomic.Int32 // tracks in-flight async operations (apply/destroy/compose)
 }
 
@@ -82,13 +85,18 @@ func (a *App) shutdown(ctx context.Context) {
 		a.spotMonitor.Stop()
 		a.spotMonitor = nil
 	}
+	if a.healthMonitor != nil {
+		a.healthMonitor.Stop()
+		a.healthMonitor = nil
+	}
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
-	a.wailsMode = true
+	a.wailsMode = !a.headless
+	a.startedAt = time.Now()
 
 	// Set default values (same as CLI defaults)
 	if redc.Project == "" {
@@ -149,6 +157,7 @@ func (a *App) startup(ctx context.Context) {
 	// Load default project
 	if p, err := redc.ProjectParse(redc.Project, redc.U); err == nil {
 		a.project = p
+		a.setupProjectPluginHooks(p)
 		a.logMgr = gologger.NewLogManager(p.ProjectPath)
 		gologger.DefaultLogger.SetWriter(&guiWriter{out: a.createLogWriter("core")})
 		if redc.Debug {
@@ -168,37 +177,30 @@ func (a *App) startup(ctx context.Context) {
 	a.costCalculator = cost.NewCostCalculator()
 
 	// Set credential provider for cost estimation
-	// This function reads credentials from the active config file
-	credProvider := func(provider string) (accessKey, secretKey, region string, err error) {
-		conf, _, err := redc.ReadConfig(redc.ActiveConfigPath)
-		if err != nil {
-			return "", "", "", fmt.Errorf("failed to read config: %w", err)
-		}
-
-		switch provider {
-		case "alicloud":
-			return conf.Providers.Alicloud.AccessKey, conf.Providers.Alicloud.SecretKey, conf.Providers.Alicloud.Region, nil
-		case "tencentcloud":
-			return conf.Providers.Tencentcloud.SecretId, conf.Providers.Tencentcloud.SecretKey, conf.Providers.Tencentcloud.Region, nil
-		case "aws":
-			return conf.Providers.Aws.AccessKey, conf.Providers.Aws.SecretKey, conf.Providers.Aws.Region, nil
-		case "volcengine":
-			return conf.Providers.Volcengine.AccessKey, conf.Providers.Volcengine.SecretKey, conf.Providers.Volcengine.Region, nil
-		default:
-			return "", "", "", fmt.Errorf("unsupported provider: %s", provider)
-		}
+	// Credentials are read from the active config file
+	a.pricingService.SetCredentialProvider(redc.CostCredentialProvider)
+
+	// Prefer the newest offline pricing catalog when provider APIs are unavailable
+	if catalog, err := cost.InitializePricingCatalogs(a.pricingService, redc.PricingCatalogDir()); err != nil {
+		fmt.Printf("[WARN] %v\n", err)
+	} else if catalog != nil {
+		fmt.Printf("[INFO] %s\n", i18n.Tf("app_pricing_catalog_loaded", catalog.Version, catalog.EffectiveDate))
 	}
 
-	a.pricingService.SetCredentialProvider(credProvider)
-
 	// Also set global credential provider for data source resolution
-	cost.SetGlobalCredentialProvider(credProvider)
+	cost.SetGlobalCredentialProvider(redc.CostCredentialProvider)
 
 	// Start background cache cleanup (runs every hour)
 	a.pricingService.StartCacheCleanup(1 * time.Hour)
 
 	fmt.Printf("[INFO] %s\n", i18n.Tf("app_cost_init_success", pricingCacheDBPath))
 
+	// Record running intervals in the cost ledger, priced when each interval starts
+	pricingService, costCalculator := a.pricingService, a.costCalculator
+	redc.EnableCostLedger(func(c *redc.Case) (float64, string, error) {
+		return redc.CaseHourlyCost(c, pricingService, costCalculator)
+	})
+
 	// Initialize task scheduler
 	schedulerDBPath := filepath.Join(redc.RedcPath, "scheduler.db")
 	a.taskScheduler = redc.NewTaskScheduler(a.project, schedulerDBPath)
@@ -223,10 +225,19 @@ func (a *App) startup(ctx context.Context) {
 				a.emitRefresh()
 			}
 			return err
+		} else if action == redc.PolicyActionDestroy {
+			err := a.DestroyCase(caseID)
+			if err == nil {
+				a.emitRefresh()
+			}
+			return err
 		}
 		return fmt.Errorf(i18n.Tf("app_unknown_action", action))
 	})
 
+	// Hourly cost callback for TTL / budget policies
+	a.taskScheduler.SetCaseCostCallback(a.caseHourlyCost)
+
 	// SSH command callback for task center
 	a.taskScheduler.SetSSHCommandCallback(func(caseID string, command string) (string, error) {
 		c, err := a.project.GetCase(caseID)
@@ -237,6 +248,9 @@ func (a *App) startup(ctx context.Context) {
 		if err != nil {
 			return "", fmt.Errorf("SSH config error: %v", err)
 		}
+		if err := c.BeforeSSHExec(command); err != nil {
+			return "", err
+		}
 		result := a.execSSHCommand(sshConfig, command)
 		output := result.Stdout
 		if result.Stderr != "" {
@@ -291,11 +305,18 @@ func (a *App) startup(ctx context.Context) {
 		a.spotMonitor.Start()
 		fmt.Printf("[INFO] %s\n", i18n.T("app_spot_monitor_start_success"))
 	}
+
+	// Start health monitor for all running cases (if enabled in settings)
+	if settings, err := redc.LoadGUISettings(); err == nil && settings.HealthMonitorEnabled {
+		a.healthMonitor = NewHealthMonitor(a, healthIntervalFromSettings(settings))
+		a.healthMonitor.Start()
+		fmt.Printf("[INFO] %s\n", i18n.T("app_health_monitor_started"))
+	}
 }
 
 // startupHeadless initializes the app without Wails context
 func (a *App) startupHeadless() {
-	a.wailsMode = false
+	a.headless = true
 	a.startup(context.Background())
 }
 
@@ -430,6 +451,7 @@ type TemplateUpdateInfo struct {
 	LocalVersion string `json:"localVersion"`
 	LatestVersion string `json:"latestVersion"`
 	HasUpdate    bool   `json:"hasUpdate"`
+	Registry     string `json:"registry"` // registry the latest version comes from
 }
 
 // PluginUpdateInfo represents a plugin with its current version
@@ -523,34 +545,7 @@ type RegistryTemplate struct {
 	Tags          []string `json:"tags"`
 	Installed     bool     `json:"installed"`
 	LocalVer      string   `json:"localVersion"`
-}
-
-// remoteTemplateInfo matches a single template in the registry
-type remoteTemplateInfo struct {
-	ID       string `json:"id"`
-	Provider string `json:"provider"`
-	Slug     string `json:"slug"`
-	Latest   string `json:"latest"`
-	Versions map[string]struct {
-		URL       string `json:"url"`
-		SHA256    string `json:"sha256"`
-		UpdatedAt string `json:"updated_at"`
-	} `json:"versions"`
-	Metadata struct {
-		Name          string   `json:"name"`
-		Author        string   `json:"author"`
-		Description   string   `json:"description"`
-		DescriptionEN string   `json:"description_en"`
-		Readme        string   `json:"readme"`
-		Tags          []string `json:"tags,omitempty"`
-	} `json:"metadata"`
-}
-
-// remoteIndexResponse matches the index.json structure from the registry
-type remoteIndexResponse struct {
-	UpdatedAt string                        `json:"updated_at"`
-	RepoName  string                        `json:"repo_name"`
-	Templates map[string]remoteTemplateInfo `json:"templates"`
+	Registry      string   `json:"registry"` // name of the registry the template is listed from
 }
 
 type MCPStatus struct {
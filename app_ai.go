@@ -31,8 +31,7 @@ func (a *App) RecommendTemplates(query string) ([]TemplateRecommendation, error)
 	}
 
 	opts := redc.PullOptions{
-		RegistryURL: "https://redc.wgpsec.org",
-		Timeout:     30 * time.Second,
+		Timeout: 30 * time.Second,
 	}
 
 	results, err := redc.Search(context.Background(), query, opts)
@@ -237,7 +236,7 @@ func (a *App) AICostOptimization() error {
 	runningCount := 0
 
 	for _, c := range cases {
-		if c.State != redc.StateRunning {
+		if !redc.IsRunningState(c.State) {
 			continue
 		}
 		runningCount++
@@ -298,7 +297,7 @@ func (a *App) AICostOptimization() error {
 			continue
 		}
 
-		resources := extractResourcesFromState(state)
+		resources := redc.CostResourcesFromState(state)
 		if resources == nil || len(resources.Resources) == 0 {
 			if logMgr != nil {
 				if logger, logErr := logMgr.NewServiceLogger("cost-optimization"); logErr == nil {
@@ -395,6 +395,7 @@ func (a *App) runAgentLoop(conversationId string, messages []AIChatMessage, prom
 
 	// Build tool definitions from MCP server
 	mcpServer := mcp.NewMCPServer(project, a)
+	mcpServer.SetPluginManager(a.pluginMgr)
 	mcpTools := mcpServer.GetTools()
 	enableAskUser := aiConfig.EnableAskUser == nil || *aiConfig.EnableAskUser // default true
 	toolDefs := make([]ai.ToolDefinition, 0, len(mcpTools))
@@ -1026,7 +1027,7 @@ func (a *App) gatherRunningCasesInfo() (string, int) {
 	runningCount := 0
 
 	for _, c := range cases {
-		if c.State != redc.StateRunning {
+		if !redc.IsRunningState(c.State) {
 			continue
 		}
 		runningCount++
@@ -1062,7 +1063,7 @@ func (a *App) gatherRunningCasesInfo() (string, int) {
 			continue
 		}
 
-		resources := extractResourcesFromState(state)
+		resources := redc.CostResourcesFromState(state)
 		if resources == nil || len(resources.Resources) == 0 {
 			caseInfo := fmt.Sprintf(`- **%s**
   - 模板: %s
@@ -83,6 +83,7 @@ func (a *App) OrchestratorStream(conversationId string, config OrchestratorConfi
 
 	// Build MCP tools
 	mcpServer := mcp.NewMCPServer(project, a)
+	mcpServer.SetPluginManager(a.pluginMgr)
 	mcpTools := mcpServer.GetTools()
 	toolDefs := make([]ai.ToolDefinition, 0, len(mcpTools))
 	for _, t := range mcpTools {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -8,8 +9,6 @@ import (
 	"red-cloud/i18n"
 	redc "red-cloud/mod"
 	"red-cloud/mod/cost"
-
-	tfjson "github.com/hashicorp/terraform-json"
 )
 
 // GetCostEstimate calculates cost estimate for a template
@@ -97,6 +96,7 @@ func (a *App) GetCostEstimate(templateName string, variables map[string]string)
 		}
 		return nil, fmt.Errorf(i18n.Tf("app_cost_calculate_failed", err))
 	}
+	estimate = redc.NormalizeCostEstimate(estimate)
 
 	if logMgr != nil {
 		if logger, logErr := logMgr.NewServiceLogger("cost-estimation"); logErr == nil {
@@ -140,7 +140,7 @@ func (a *App) GetTotalRuntime() (string, error) {
 	now := time.Now()
 
 	for _, c := range cases {
-		if c.State == redc.StateRunning {
+		if redc.IsRunningState(c.State) {
 			var stateTime time.Time
 			var parseErr error
 
@@ -226,7 +226,7 @@ func (a *App) GetPredictedMonthlyCost() (string, error) {
 	}
 
 	for _, c := range cases {
-		if c.State != redc.StateRunning {
+		if !redc.IsRunningState(c.State) {
 			continue
 		}
 		runningCount++
@@ -269,7 +269,7 @@ func (a *App) GetPredictedMonthlyCost() (string, error) {
 			continue
 		}
 
-		resources := extractResourcesFromState(state)
+		resources := redc.CostResourcesFromState(state)
 		if len(resources.Resources) == 0 {
 			if logMgr != nil {
 				if logger, logErr := logMgr.NewServiceLogger("cost-prediction"); logErr == nil {
@@ -325,6 +325,7 @@ func (a *App) GetPredictedMonthlyCost() (string, error) {
 			}
 			continue
 		}
+		estimate = redc.NormalizeCostEstimate(estimate)
 
 		if logMgr != nil {
 			if logger, logErr := logMgr.NewServiceLogger("cost-prediction"); logErr == nil {
@@ -359,130 +360,94 @@ func (a *App) GetPredictedMonthlyCost() (string, error) {
 	return fmt.Sprintf("%s%.2f", symbol, totalMonthlyCost), nil
 }
 
-// extractResourcesFromState converts terraform state to cost.TemplateResources
-func extractResourcesFromState(state *tfjson.State) *cost.TemplateResources {
-	resources := &cost.TemplateResources{Resources: []cost.ResourceSpec{}}
-
-	if state.Values == nil || state.Values.RootModule == nil {
-		return resources
-	}
+// caseHourlyCost estimates the current hourly price of a case from its terraform state.
+// Used by the scheduler to accrue spend for TTL / budget policies.
+func (a *App) caseHourlyCost(projectID string, caseID string) (float64, string, error) {
+	a.mu.Lock()
+	pricingService := a.pricingService
+	costCalculator := a.costCalculator
+	a.mu.Unlock()
 
-	if len(state.Values.RootModule.Resources) > 0 {
-		firstResource := state.Values.RootModule.Resources[0]
-		if firstResource.ProviderName != "" {
-			providerName := extractShortProviderName(firstResource.ProviderName)
-			resources.Provider = providerName
-		}
+	if pricingService == nil || costCalculator == nil {
+		return 0, "", fmt.Errorf("%s", i18n.T("app_cost_estimate_not_init"))
 	}
 
-	extractModuleResources(state.Values.RootModule, resources)
-
-	for _, res := range resources.Resources {
-		if region, ok := res.Attributes["region"].(string); ok && region != "" {
-			resources.Region = region
-			break
-		} else if availabilityZone, ok := res.Attributes["availability_zone"].(string); ok && availabilityZone != "" {
-			if len(availabilityZone) > 2 {
-				lastDash := strings.LastIndex(availabilityZone, "-")
-				if lastDash > 0 {
-					resources.Region = availabilityZone[:lastDash]
-					break
-				}
-			}
-		} else if zone, ok := res.Attributes["zone"].(string); ok && zone != "" {
-			if len(zone) > 2 {
-				lastDash := strings.LastIndex(zone, "-")
-				if lastDash > 0 {
-					resources.Region = zone[:lastDash]
-					break
-				}
-			}
-		} else if zoneId, ok := res.Attributes["zone_id"].(string); ok && zoneId != "" {
-			if len(zoneId) > 2 {
-				lastDash := strings.LastIndex(zoneId, "-")
-				if lastDash > 0 {
-					resources.Region = zoneId[:lastDash]
-					break
-				}
-			}
-		}
+	c, err := redc.FindCaseBySearch(projectID, caseID)
+	if err != nil {
+		return 0, "", err
 	}
-
-	return resources
+	return redc.CaseHourlyCost(c, pricingService, costCalculator)
 }
 
-// extractShortProviderName extracts the short provider name from full registry path
-func extractShortProviderName(fullName string) string {
-	parts := strings.Split(fullName, "/")
-	if len(parts) >= 3 {
-		return parts[len(parts)-1]
+// GetCostReport returns the accrued spend recorded in the cost ledger between from and to
+// (2006-01-02, 2006-01 or RFC3339; empty means unbounded). The ledger keeps intervals of
+// destroyed cases, so past engagements can still be reported.
+func (a *App) GetCostReport(from string, to string, allProjects bool, currency string) (*redc.LedgerReport, error) {
+	fromTime, err := redc.ParseLedgerTime(from, false)
+	if err != nil {
+		return nil, err
 	}
-	return fullName
-}
-
-// extractModuleResources recursively extracts resources from a terraform module
-func extractModuleResources(module *tfjson.StateModule, resources *cost.TemplateResources) {
-	if module == nil {
-		return
+	toTime, err := redc.ParseLedgerTime(to, true)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, res := range module.Resources {
-		if res.Type == "" {
-			continue
+	projectID := ""
+	if !allProjects {
+		if a.project == nil {
+			return nil, fmt.Errorf("%s", i18n.T("app_project_not_loaded"))
 		}
-
-		providerName := extractShortProviderName(res.ProviderName)
-
-		costRes := cost.ResourceSpec{
-			Type:       res.Type,
-			Name:       res.Name,
-			Provider:   providerName,
-			Count:      1,
-			Attributes: make(map[string]interface{}),
+		projectID = a.project.ProjectName
+	}
+	if a.project != nil {
+		if err := redc.ReconcileCostLedger(a.project.ProjectName); err != nil {
+			return nil, err
 		}
+	}
 
-		if res.AttributeValues != nil {
-			for key, value := range res.AttributeValues {
-				costRes.Attributes[key] = value
-			}
-
-			if region, ok := res.AttributeValues["region"].(string); ok && region != "" {
-				costRes.Region = region
-			} else if availabilityZone, ok := res.AttributeValues["availability_zone"].(string); ok && availabilityZone != "" {
-				if len(availabilityZone) > 2 {
-					lastDash := strings.LastIndex(availabilityZone, "-")
-					if lastDash > 0 {
-						costRes.Region = availabilityZone[:lastDash]
-					}
-				}
-			} else if zone, ok := res.AttributeValues["zone"].(string); ok && zone != "" {
-				if len(zone) > 2 {
-					lastDash := strings.LastIndex(zone, "-")
-					if lastDash > 0 {
-						costRes.Region = zone[:lastDash]
-					}
-				}
-			} else if zoneId, ok := res.AttributeValues["zone_id"].(string); ok && zoneId != "" {
-				if len(zoneId) > 2 {
-					lastDash := strings.LastIndex(zoneId, "-")
-					if lastDash > 0 {
-						costRes.Region = zoneId[:lastDash]
-					}
-				}
-			}
+	ledger, err := redc.NewCostLedger()
+	if err != nil {
+		return nil, err
+	}
+	defer ledger.Close()
+	intervals, err := ledger.Intervals(projectID, fromTime, toTime)
+	if err != nil {
+		return nil, err
+	}
+	return redc.BuildLedgerReport(intervals, fromTime, toTime, time.Now(), currency), nil
+}
 
-			if res.Type == "alicloud_instance" || res.Type == "aws_instance" ||
-				res.Type == "tencentcloud_instance" || res.Type == "volcengine_ecs_instance" {
-				if instanceType, ok := res.AttributeValues["instance_type"].(string); ok && instanceType != "" {
-					costRes.Attributes["instance_type"] = instanceType
-				}
-			}
+// GetBillingReconciliation compares provider bill line items of a month (2006-01) with the
+// estimates in the cost ledger. Line items are attributed to cases by tag or resource ID;
+// the rest is reported as untracked spend.
+func (a *App) GetBillingReconciliation(month string, providers []string, currency string) (*redc.ReconcileReport, error) {
+	if month == "" {
+		month = time.Now().Format("2006-01")
+	}
+	projectID := ""
+	if a.project != nil {
+		projectID = a.project.ProjectName
+		if err := redc.ReconcileCostLedger(projectID); err != nil {
+			return nil, err
 		}
-
-		resources.Resources = append(resources.Resources, costRes)
 	}
+	return redc.BuildReconcileReport(context.Background(), projectID, month, providers, currency)
+}
 
-	for _, child := range module.ChildModules {
-		extractModuleResources(child, resources)
-	}
+// GetCurrencySettings returns the display currency and exchange rate source of the active profile
+func (a *App) GetCurrencySettings() redc.CurrencySettings {
+	return redc.ActiveCurrencySettings()
+}
+
+// UpdateCurrencySettings saves the display currency and exchange rate source of a profile.
+// Estimates, the cost ledger and billing reconciliation are converted into the display currency.
+func (a *App) UpdateCurrencySettings(profileID string, settings redc.CurrencySettings) error {
+	return redc.UpdateProfileCurrency(profileID, &settings)
+}
+
+// GetExchangeRates returns the rates from every supported currency to currency
+// (empty means the display currency) with their source and publication date
+func (a *App) GetExchangeRates(currency string) []cost.ExchangeRate {
+	_, rates := redc.ExchangeRatesTo(currency)
+	return rates
 }
@@ -91,7 +91,7 @@ func (a *App) GetF8xStatus(caseID string) F8xStatus {
 	checkCmd := fmt.Sprintf(
 		`%ssh -c 'for p in $(which f8x 2>/dev/null) /usr/local/bin/f8x /tmp/f8x; do [ -f "$p" ] && head -c 500 "$p" 2>/dev/null | grep -o "F8x_Version=\"[^\"]*\"" && exit 0; done; [ -d /ffffffff0x ] && echo "DEPLOYED" || echo "NOT_FOUND"'`,
 		sudo)
-	result := a.execSSHCommand(sshConfig, checkCmd)
+	result := a.execCaseCommand(caseID, sshConfig, checkCmd)
 
 	output := strings.TrimSpace(result.Stdout)
 	if output == "" || strings.Contains(output, "NOT_FOUND") {
@@ -116,28 +116,28 @@ func (a *App) EnsureF8x(caseID string) ExecCommandResult {
 	}
 
 	// Check if already present (both deploy and install locations)
-	checkResult := a.execSSHCommand(sshConfig, "test -f /tmp/f8x -o -f /usr/local/bin/f8x && echo 'EXISTS' || echo 'MISSING'")
+	checkResult := a.execCaseCommand(caseID, sshConfig, "test -f /tmp/f8x -o -f /usr/local/bin/f8x && echo 'EXISTS' || echo 'MISSING'")
 	if checkResult.Success && strings.Contains(checkResult.Stdout, "EXISTS") {
 		return ExecCommandResult{Success: true, Stdout: "f8x already deployed"}
 	}
 
 	// Download f8x from CDN
 	cmd := fmt.Sprintf("wget -q -O /tmp/f8x %s && chmod +x /tmp/f8x && echo 'OK'", redc.F8xDefaultURL)
-	result := a.execSSHCommand(sshConfig, cmd)
+	result := a.execCaseCommand(caseID, sshConfig, cmd)
 	if result.Success && strings.Contains(result.Stdout, "OK") {
 		return ExecCommandResult{Success: true, Stdout: "f8x deployed successfully"}
 	}
 
 	// Fallback to GitHub raw
 	cmd = fmt.Sprintf("wget -q -O /tmp/f8x %s && chmod +x /tmp/f8x && echo 'OK'", redc.F8xFallbackURL)
-	result = a.execSSHCommand(sshConfig, cmd)
+	result = a.execCaseCommand(caseID, sshConfig, cmd)
 	if result.Success && strings.Contains(result.Stdout, "OK") {
 		return ExecCommandResult{Success: true, Stdout: "f8x deployed (fallback)"}
 	}
 
 	// Try curl as last resort
 	cmd = fmt.Sprintf("curl -sL -o /tmp/f8x %s && chmod +x /tmp/f8x && echo 'OK'", redc.F8xDefaultURL)
-	result = a.execSSHCommand(sshConfig, cmd)
+	result = a.execCaseCommand(caseID, sshConfig, cmd)
 	if result.Success && strings.Contains(result.Stdout, "OK") {
 		return ExecCommandResult{Success: true, Stdout: "f8x deployed (curl)"}
 	}
@@ -243,6 +243,16 @@ func (a *App) runF8xInstallAsync(taskID, caseID string, flags []string, record *
 		"text": fmt.Sprintf("Running: %s", cmd),
 	})
 
+	if err := a.beforeSSHExec(caseID, cmd); err != nil {
+		record.Status = "failed"
+		record.Output = err.Error()
+		record.FinishedAt = time.Now().Format(time.RFC3339)
+		a.emitEvent("f8x:done", map[string]interface{}{
+			"taskID": taskID, "status": "failed",
+		})
+		return
+	}
+
 	client, err := sshutil.NewClient(sshConfig)
 	if err != nil {
 		record.Status = "failed"
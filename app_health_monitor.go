@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+)
+
+// defaultHealthInterval is used when gui_settings.json has no healthMonitorInterval.
+const defaultHealthInterval = 5 * time.Minute
+
+// driftCheckInterval limits drift checks per case; a refresh-only plan queries every
+// resource from the cloud API, which is too expensive for every health scan.
+const driftCheckInterval = time.Hour
+
+// HealthMonitor periodically probes every running case (spot or not), keeps
+// uptime history in the case directory and flips cases between running and
+// degraded. Transitions are reported through notifications and webhooks.
+// Running cases are also checked for drift, which runs the on-drift hooks.
+type HealthMonitor struct {
+	app      *App
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+	interval time.Duration
+	// scanMu serializes scheduled scans with on-demand checks
+	scanMu sync.Mutex
+	// drift holds the last drift check of each case, guarded by scanMu
+	drift map[string]driftCheck
+}
+
+// driftCheck is the result of the last drift check of a case
+type driftCheck struct {
+	at      time.Time
+	drifted bool
+}
+
+// NewHealthMonitor creates a new HealthMonitor.
+func NewHealthMonitor(app *App, interval time.Duration) *HealthMonitor {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	return &HealthMonitor{
+		app:      app,
+		stopCh:   make(chan struct{}),
+		interval: interval,
+		drift:    make(map[string]driftCheck),
+	}
+}
+
+// healthIntervalFromSettings returns the configured scan interval.
+func healthIntervalFromSettings(settings *redc.GUISettings) time.Duration {
+	if settings != nil && settings.HealthMonitorInterval > 0 {
+		return time.Duration(settings.HealthMonitorInterval) * time.Second
+	}
+	return defaultHealthInterval
+}
+
+// Start begins the background monitoring loop.
+func (m *HealthMonitor) Start() {
+	m.wg.Add(1)
+	go m.loop()
+}
+
+// Stop signals the monitor to stop and waits for it to finish.
+func (m *HealthMonitor) Stop() {
+	close(m.stopCh)
+	m.wg.Wait()
+}
+
+func (m *HealthMonitor) loop() {
+	defer m.wg.Done()
+
+	// Same startup delay as the spot monitor
+	select {
+	case <-time.After(60 * time.Second):
+	case <-m.stopCh:
+		return
+	}
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.scan()
+	for {
+		select {
+		case <-ticker.C:
+			m.scan()
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+// stopContext returns a context that is cancelled when the monitor stops.
+func (m *HealthMonitor) stopContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-m.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+func (m *HealthMonitor) scan() {
+	if m.app.project == nil {
+		return
+	}
+	cases, err := redc.LoadProjectCases(m.app.project.ProjectName)
+	if err != nil {
+		return
+	}
+
+	m.scanMu.Lock()
+	defer m.scanMu.Unlock()
+	ctx, cancel := m.stopContext()
+	defer cancel()
+
+	for _, c := range cases {
+		if !redc.IsRunningState(c.State) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		m.check(ctx, c)
+		if c.State == redc.StateRunning || c.State == redc.StateDegraded {
+			m.checkDrift(c)
+		}
+	}
+}
+
+// checkDrift runs a refresh-only plan at most once per driftCheckInterval and
+// runs the on-drift hooks when a case starts drifting. The hooks are not repeated
+// while the drift persists.
+func (m *HealthMonitor) checkDrift(c *redc.Case) {
+	last := m.drift[c.Id]
+	if time.Since(last.at) < driftCheckInterval {
+		return
+	}
+	drifted, err := redc.TfDriftCheck(c.Path, c.Parameter...)
+	if err != nil {
+		gologger.Debug().Msgf("drift check %s: %v", c.Name, err)
+		return
+	}
+	m.drift[c.Id] = driftCheck{at: time.Now(), drifted: drifted}
+	if !drifted || last.drifted {
+		return
+	}
+	if m.app.pluginMgr != nil {
+		m.app.setupPluginHooks(c)
+	}
+	if err := c.ReportDrift(); err != nil {
+		m.app.emitLog(fmt.Sprintf("⚠️ %v", err))
+	}
+	m.app.emitLog(fmt.Sprintf("⚠️ %s", i18n.Tf("case_drift_detected", c.Name, c.GetId())))
+}
+
+// check probes one case, records the sample and reports transitions.
+func (m *HealthMonitor) check(ctx context.Context, c *redc.Case) *redc.HealthRecord {
+	rec, _, err := redc.CheckCaseHealth(ctx, c)
+	if err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("app_health_check_failed", c.Name, err))
+	}
+	m.notify(c, rec)
+	return rec
+}
+
+// notify reports status changes that have not been notified yet. Tracking the
+// notified status in the record also covers transitions made by `redc health --check`.
+func (m *HealthMonitor) notify(c *redc.Case, rec *redc.HealthRecord) {
+	if rec == nil || rec.Status == redc.HealthUnknown || rec.Status == rec.Notified {
+		return
+	}
+	prev := rec.Notified
+	rec.Notified = rec.Status
+	if err := redc.SaveHealthRecord(c.Path, rec); err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("app_health_check_failed", c.Name, err))
+	}
+
+	app := m.app
+	switch rec.Status {
+	case redc.HealthDegraded:
+		app.emitLog(fmt.Sprintf("⚠️ %s", i18n.Tf("app_health_degraded", c.Name, rec.LastError)))
+		app.emitEvent("case-degraded", map[string]interface{}{
+			"caseId":   c.Id,
+			"caseName": c.Name,
+			"error":    rec.LastError,
+			"failures": rec.ConsecutiveFailures,
+		})
+		if app.notificationMgr != nil {
+			app.notificationMgr.SendCaseDegraded(c.Name, rec.LastError)
+		}
+	case redc.HealthHealthy:
+		// The first healthy sample of a case is not news
+		if prev != redc.HealthDegraded {
+			return
+		}
+		app.emitLog(fmt.Sprintf("✅ %s", i18n.Tf("app_health_recovered", c.Name)))
+		app.emitEvent("case-healthy", map[string]interface{}{
+			"caseId":   c.Id,
+			"caseName": c.Name,
+		})
+		if app.notificationMgr != nil {
+			app.notificationMgr.SendCaseHealthy(c.Name)
+		}
+	}
+	app.emitRefresh()
+}
+
+// GetHealthReport returns the health table of the current project.
+// When all is false only running/degraded cases are included.
+func (a *App) GetHealthReport(all bool) ([]redc.CaseHealth, error) {
+	if a.project == nil {
+		return nil, fmt.Errorf("%s", i18n.T("app_project_not_loaded"))
+	}
+	cases, err := redc.LoadProjectCases(a.project.ProjectName)
+	if err != nil {
+		return nil, err
+	}
+	return redc.HealthReport(cases, all), nil
+}
+
+// CheckCaseHealth probes a case right away and returns its updated health row.
+func (a *App) CheckCaseHealth(caseID string) (*redc.CaseHealth, error) {
+	if a.project == nil {
+		return nil, fmt.Errorf("%s", i18n.T("app_project_not_loaded"))
+	}
+	c, err := a.project.GetCase(caseID)
+	if err != nil {
+		return nil, err
+	}
+	if !redc.IsRunningState(c.State) {
+		return nil, fmt.Errorf("%s", i18n.Tf("app_health_not_running", c.Name, c.State))
+	}
+
+	a.mu.Lock()
+	m := a.healthMonitor
+	a.mu.Unlock()
+	if m != nil {
+		m.scanMu.Lock()
+		m.check(context.Background(), c)
+		m.scanMu.Unlock()
+	} else if _, _, err := redc.CheckCaseHealth(context.Background(), c); err != nil {
+		return nil, err
+	}
+
+	report := redc.HealthReport([]*redc.Case{c}, true)
+	return &report[0], nil
+}
+
+func (a *App) SetHealthMonitorEnabled(enabled bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	settings, err := redc.LoadGUISettings()
+	if err != nil {
+		return err
+	}
+	settings.HealthMonitorEnabled = enabled
+	if err := redc.SaveGUISettings(settings); err != nil {
+		return err
+	}
+
+	if enabled {
+		if a.healthMonitor != nil {
+			return nil
+		}
+		a.healthMonitor = NewHealthMonitor(a, healthIntervalFromSettings(settings))
+		a.healthMonitor.Start()
+		a.emitLog(i18n.T("app_health_monitor_started"))
+	} else {
+		if a.healthMonitor != nil {
+			a.healthMonitor.Stop()
+			a.healthMonitor = nil
+		}
+		a.emitLog(i18n.T("app_health_monitor_stopped"))
+	}
+	return nil
+}
+
+func (a *App) GetHealthMonitorEnabled() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	settings, err := redc.LoadGUISettings()
+	if err != nil {
+		return false
+	}
+	return settings.HealthMonitorEnabled
+}
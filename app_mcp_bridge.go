@@ -38,6 +38,11 @@ func (a *App) MCPGetCostEstimate(templateName string, variables map[string]strin
 	return a.GetCostEstimate(templateName, variables)
 }
 
+// MCPGetPlanCostDiff implements AppBridge
+func (a *App) MCPGetPlanCostDiff(caseID string) (interface{}, error) {
+	return a.GetCasePlanCostDiff(caseID)
+}
+
 // MCPGetBalances implements AppBridge
 func (a *App) MCPGetBalances(providers []string) (interface{}, error) {
 	return a.GetBalances(providers)
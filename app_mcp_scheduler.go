@@ -37,6 +37,7 @@ func (a *App) StartMCPServer(mode string, address string) error {
 	if a.mcpManager == nil {
 		a.mcpManager = mcp.NewMCPServerManager(a.project, a)
 		a.mcpManager.SetLogCallback(a.emitLog)
+		a.mcpManager.GetMCPServer().SetPluginManager(a.pluginMgr)
 	}
 
 	// Convert mode string to TransportMode
@@ -166,3 +167,64 @@ func (a *App) ListAllScheduledTasks() []*redc.ScheduledTask {
 
 	return scheduler.ListAllTasksFromDB()
 }
+
+// SetCasePolicy attaches a TTL / budget policy to a case (replaces any existing one)
+func (a *App) SetCasePolicy(caseID string, ttl string, budget string, action string) (*redc.LifecyclePolicy, error) {
+	a.mu.Lock()
+	scheduler := a.taskScheduler
+	project := a.project
+	a.mu.Unlock()
+
+	if scheduler == nil {
+		return nil, fmt.Errorf("%s", i18n.T("app_scheduler_not_init"))
+	}
+	if project == nil {
+		return nil, fmt.Errorf("%s", i18n.T("app_project_not_loaded"))
+	}
+
+	c, err := project.GetCase(caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := redc.NewLifecyclePolicy(redc.PolicyKindCase, c.Id, c.Name, project.ProjectName, []string{c.Id}, ttl, budget, action)
+	if err != nil {
+		return nil, err
+	}
+	if err := scheduler.SetPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	a.emitLog(i18n.Tf("policy_saved", c.Name, ttl, budget, policy.Action))
+	return policy, nil
+}
+
+// ListLifecyclePolicies returns all TTL / budget policies
+func (a *App) ListLifecyclePolicies() []*redc.LifecyclePolicy {
+	a.mu.Lock()
+	scheduler := a.taskScheduler
+	a.mu.Unlock()
+
+	if scheduler == nil {
+		return []*redc.LifecyclePolicy{}
+	}
+
+	policies, err := scheduler.ListPolicies()
+	if err != nil || policies == nil {
+		return []*redc.LifecyclePolicy{}
+	}
+	return policies
+}
+
+// RemoveLifecyclePolicy deletes a TTL / budget policy
+func (a *App) RemoveLifecyclePolicy(id string) error {
+	a.mu.Lock()
+	scheduler := a.taskScheduler
+	a.mu.Unlock()
+
+	if scheduler == nil {
+		return fmt.Errorf("%s", i18n.T("app_scheduler_not_init"))
+	}
+
+	return scheduler.RemovePolicy(id)
+}
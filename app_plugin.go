@@ -11,18 +11,23 @@ import (
 
 // PluginInfo is a serializable view of a plugin for the frontend
 type PluginInfo struct {
-	Name          string                       `json:"name"`
-	Version       string                       `json:"version"`
-	Description   string                       `json:"description"`
-	DescriptionEN string                       `json:"description_en"`
-	Author        string                       `json:"author"`
-	Homepage      string                       `json:"homepage"`
-	Category      string                       `json:"category"`
-	Tags          []string                     `json:"tags"`
-	Enabled       bool                         `json:"enabled"`
-	Dir           string                       `json:"dir"`
-	ConfigSchema  map[string]plugin.ConfigField `json:"config_schema,omitempty"`
-	Config        map[string]interface{}       `json:"config,omitempty"`
+	Name            string                        `json:"name"`
+	Version         string                        `json:"version"`
+	Description     string                        `json:"description"`
+	DescriptionEN   string                        `json:"description_en"`
+	Author          string                        `json:"author"`
+	Homepage        string                        `json:"homepage"`
+	Category        string                        `json:"category"`
+	Tags            []string                      `json:"tags"`
+	Enabled         bool                          `json:"enabled"`
+	Dir             string                        `json:"dir"`
+	ConfigSchema    map[string]plugin.ConfigField `json:"config_schema,omitempty"`
+	Config          map[string]interface{}        `json:"config,omitempty"`
+	IntegrityError  string                        `json:"integrity_error,omitempty"`
+	DependencyError string                        `json:"dependency_error,omitempty"`
+	Dependencies    map[string]string             `json:"dependencies,omitempty"`
+	Credentials     []string                      `json:"credentials,omitempty"`
+	Pending         []string                      `json:"pending_credentials,omitempty"` // requested but not approved
 }
 
 // ListPlugins returns all installed plugins
@@ -37,7 +42,9 @@ func (a *App) ListPlugins() ([]PluginInfo, error) {
 	plugins := a.pluginMgr.List()
 	result := make([]PluginInfo, 0, len(plugins))
 	for _, p := range plugins {
-		result = append(result, pluginToInfo(p))
+		info := pluginToInfo(p)
+		info.Pending = a.pluginMgr.PendingCredentials(p.Manifest.Name)
+		result = append(result, info)
 	}
 	return result, nil
 }
@@ -84,23 +91,55 @@ func (a *App) UpdatePlugin(name string) error {
 	return err
 }
 
-// GetPluginConfig returns plugin config as JSON string
+// VerifyPlugins re-checks installed plugins against the lockfile and returns the problems by plugin name
+func (a *App) VerifyPlugins() (map[string]string, error) {
+	if a.pluginMgr == nil {
+		return nil, fmt.Errorf("plugin manager not initialized")
+	}
+	return a.pluginMgr.Verify(), nil
+}
+
+// GetPluginDependencyTree returns installed plugins as dependency trees
+func (a *App) GetPluginDependencyTree() ([]*plugin.DependencyNode, error) {
+	if a.pluginMgr == nil {
+		return nil, fmt.Errorf("plugin manager not initialized")
+	}
+	return a.pluginMgr.DependencyTree(), nil
+}
+
+// ApprovePluginCredentials lets the plugin's hook scripts read the credentials it requests
+func (a *App) ApprovePluginCredentials(name string) error {
+	if a.pluginMgr == nil {
+		return fmt.Errorf("plugin manager not initialized")
+	}
+	return a.pluginMgr.ApproveCredentials(name)
+}
+
+// RevokePluginCredentials withdraws the credentials approved for a plugin
+func (a *App) RevokePluginCredentials(name string) error {
+	if a.pluginMgr == nil {
+		return fmt.Errorf("plugin manager not initialized")
+	}
+	return a.pluginMgr.RevokeCredentials(name)
+}
+
+// GetPluginConfig returns plugin config as JSON string; stored secrets are shown as plugin.SecretPlaceholder
 func (a *App) GetPluginConfig(name string) (string, error) {
 	if a.pluginMgr == nil {
 		return "", fmt.Errorf("plugin manager not initialized")
 	}
-	p, ok := a.pluginMgr.Get(name)
-	if !ok {
-		return "", fmt.Errorf("plugin %s not found", name)
+	config, err := a.pluginMgr.ConfigView(name)
+	if err != nil {
+		return "", err
 	}
-	data, err := json.MarshalIndent(p.Config, "", "  ")
+	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return "", err
 	}
 	return string(data), nil
 }
 
-// SavePluginConfig saves plugin config from JSON string
+// SavePluginConfig validates and saves plugin config from JSON string; secret fields go to the credential store
 func (a *App) SavePluginConfig(name string, configJSON string) error {
 	if a.pluginMgr == nil {
 		return fmt.Errorf("plugin manager not initialized")
@@ -114,52 +153,79 @@ func (a *App) SavePluginConfig(name string, configJSON string) error {
 
 // setupPluginHooks wires plugin hooks into a Case instance
 func (a *App) setupPluginHooks(c *redc.Case) {
-	c.SetPluginHookRunner(func(hookPoint string, cc *redc.Case) error {
-		outputJSON := ""
-		if outputs, err := cc.TfOutput(); err == nil {
-			data, _ := json.Marshal(outputs)
-			outputJSON = string(data)
-		}
+	c.SetPluginHookRunner(a.runPluginHook)
+}
+
+// setupProjectPluginHooks wires plugin hooks into a project so cases it creates or
+// looks up, and project-level compose hooks, run plugins
+func (a *App) setupProjectPluginHooks(p *redc.RedcProject) {
+	p.SetPluginHookRunner(a.runPluginHook)
+}
 
-		// Parse allowed plugins from case
-		var allowed []string
-		if cc.Plugins != "" {
-			for _, p := range strings.Split(cc.Plugins, ",") {
-				if name := strings.TrimSpace(p); name != "" {
-					allowed = append(allowed, name)
-				}
+// runPluginHook builds the hook context from a case (or a project-level event when cc is nil)
+func (a *App) runPluginHook(hookPoint string, cc *redc.Case, ev redc.PluginHookEvent) error {
+	if a.pluginMgr == nil {
+		return nil
+	}
+	ctx := &plugin.HookContext{
+		Project:        ev.Project,
+		Operator:       redc.U,
+		Error:          ev.Error,
+		Command:        ev.Command,
+		AllowedPlugins: ev.Plugins,
+	}
+	if cc == nil {
+		_, err := a.pluginMgr.RunHooks(hookPoint, ctx)
+		return err
+	}
+
+	outputJSON := ""
+	if outputs, err := cc.TfOutput(); err == nil {
+		data, _ := json.Marshal(outputs)
+		outputJSON = string(data)
+	}
+
+	// Parse allowed plugins from case
+	var allowed []string
+	if cc.Plugins != "" {
+		for _, p := range strings.Split(cc.Plugins, ",") {
+			if name := strings.TrimSpace(p); name != "" {
+				allowed = append(allowed, name)
 			}
 		}
+	}
 
-		// Parse case -var parameters into JSON
-		caseVars := ""
-		if len(cc.Parameter) > 0 {
-			varsMap := make(map[string]string)
-			for _, p := range cc.Parameter {
-				if strings.HasPrefix(p, "-var") {
-					continue
-				}
-				if idx := strings.Index(p, "="); idx > 0 {
-					varsMap[p[:idx]] = p[idx+1:]
-				}
+	// Parse case -var parameters into JSON
+	caseVars := ""
+	if len(cc.Parameter) > 0 {
+		varsMap := make(map[string]string)
+		for _, p := range cc.Parameter {
+			if strings.HasPrefix(p, "-var") {
+				continue
 			}
-			if len(varsMap) > 0 {
-				data, _ := json.Marshal(varsMap)
-				caseVars = string(data)
+			if idx := strings.Index(p, "="); idx > 0 {
+				varsMap[p[:idx]] = p[idx+1:]
 			}
 		}
-
-		ctx := &plugin.HookContext{
-			CaseName:       cc.Name,
-			CasePath:       cc.Path,
-			CaseTemplate:   cc.Type,
-			CaseState:      cc.State,
-			OutputJSON:     outputJSON,
-			CaseVars:       caseVars,
-			AllowedPlugins: allowed,
+		if len(varsMap) > 0 {
+			data, _ := json.Marshal(varsMap)
+			caseVars = string(data)
 		}
-		return a.pluginMgr.RunHooks(hookPoint, ctx)
-	})
+	}
+
+	ctx.CaseID = cc.Id
+	ctx.CaseName = cc.Name
+	ctx.CasePath = cc.Path
+	ctx.CaseTemplate = cc.Type
+	ctx.CaseState = cc.State
+	if cc.Operator != "" {
+		ctx.Operator = cc.Operator
+	}
+	ctx.OutputJSON = outputJSON
+	ctx.CaseVars = caseVars
+	ctx.AllowedPlugins = allowed
+	_, err := a.pluginMgr.RunHooks(hookPoint, ctx)
+	return err
 }
 
 // GetPluginsDir returns the base plugins installation directory
@@ -194,17 +260,21 @@ func (a *App) FetchPluginRegistry() ([]plugin.RegistryPlugin, error) {
 
 func pluginToInfo(p *plugin.Plugin) PluginInfo {
 	return PluginInfo{
-		Name:          p.Manifest.Name,
-		Version:       p.Manifest.Version,
-		Description:   p.Manifest.Description,
-		DescriptionEN: p.Manifest.DescriptionEN,
-		Author:        p.Manifest.Author,
-		Homepage:      p.Manifest.Homepage,
-		Category:      p.Manifest.Category,
-		Tags:          p.Manifest.Tags,
-		Enabled:       p.Enabled,
-		Dir:           p.Dir,
-		ConfigSchema:  p.Manifest.ConfigSchema,
-		Config:        p.Config,
+		Name:            p.Manifest.Name,
+		Version:         p.Manifest.Version,
+		Description:     p.Manifest.Description,
+		DescriptionEN:   p.Manifest.DescriptionEN,
+		Author:          p.Manifest.Author,
+		Homepage:        p.Manifest.Homepage,
+		Category:        p.Manifest.Category,
+		Tags:            p.Manifest.Tags,
+		Enabled:         p.Enabled,
+		Dir:             p.Dir,
+		ConfigSchema:    p.Manifest.ConfigSchema,
+		Config:          p.Config,
+		IntegrityError:  p.IntegrityError,
+		DependencyError: p.DependencyError,
+		Dependencies:    p.Manifest.Dependencies,
+		Credentials:     p.Manifest.Capabilities.Credentials,
 	}
 }
@@ -426,6 +426,7 @@ func (a *App) SwitchProject(projectName string) error {
 
 	// Update project reference
 	a.project = p
+	a.setupProjectPluginHooks(p)
 
 	// Update log manager to use new project path
 	a.logMgr = gologger.NewLogManager(p.ProjectPath)
@@ -504,7 +505,7 @@ func (a *App) GetResourceSummary() ([]ResourceSummary, error) {
 
 	counts := make(map[string]int)
 	for _, c := range cases {
-		if c.State != redc.StateRunning {
+		if !redc.IsRunningState(c.State) {
 			continue
 		}
 		if c.Path == "" {
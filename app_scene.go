@@ -10,6 +10,7 @@ import (
 
 	"red-cloud/i18n"
 	redc "red-cloud/mod"
+	"red-cloud/mod/cost"
 	"red-cloud/mod/plugin"
 )
 
@@ -189,6 +190,57 @@ func (a *App) RemoveCase(caseID string) error {
 	return nil
 }
 
+// DestroyCase stops a case and removes it afterwards (used by TTL / budget policies)
+func (a *App) DestroyCase(caseID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.project == nil {
+		return fmt.Errorf("%s", i18n.T("app_project_not_loaded"))
+	}
+
+	c, err := a.project.GetCase(caseID)
+	if err != nil {
+		return err
+	}
+
+	// Wire plugin hooks
+	if a.pluginMgr != nil {
+		a.setupPluginHooks(c)
+	}
+
+	go func() {
+		a.activeOps.Add(1)
+		defer a.activeOps.Add(-1)
+		defer func() {
+			if r := recover(); r != nil {
+				a.emitLog(i18n.Tf("app_scene_delete_error", r))
+			}
+			a.emitRefresh()
+		}()
+
+		if c.State != redc.StateStopped && c.State != redc.StateCreated {
+			a.emitLog(i18n.Tf("app_stopping_scene", c.Name))
+			if err := c.Stop(); err != nil {
+				a.emitLog(i18n.Tf("app_scene_stop_failed", err))
+				if a.notificationMgr != nil {
+					a.notificationMgr.SendSceneFailed(c.Name, "停止")
+				}
+				return
+			}
+		}
+
+		a.emitLog(i18n.Tf("app_deleting_scene", c.Name))
+		if err := c.Remove(); err != nil {
+			a.emitLog(i18n.Tf("app_scene_delete_failed", err))
+			return
+		}
+		a.emitLog(i18n.Tf("app_scene_delete_success", c.Name))
+	}()
+
+	return nil
+}
+
 // CreateCase creates a new case from a template (async)
 func (a *App) CreateCase(templateName string, name string, vars map[string]string) error {
 	a.mu.Lock()
@@ -318,6 +370,7 @@ type PlanPreview struct {
 	Resources      []PlanResourceChange `json:"resources"`
 	Edges          []PlanEdge           `json:"edges"`
 	TypeSummary    []PlanTypeSummary    `json:"typeSummary"`
+	CostDiff       *cost.CostDiff       `json:"costDiff,omitempty"`
 }
 
 // GetCasePlanPreview returns structured plan preview data for a case
@@ -338,7 +391,76 @@ func (a *App) GetCasePlanPreview(caseID string) (*PlanPreview, error) {
 		return nil, fmt.Errorf("case not found or path is empty")
 	}
 
-	return buildPlanPreview(c.Path)
+	preview, err := buildPlanPreview(c.Path)
+	if err != nil {
+		return nil, err
+	}
+	preview.CostDiff = a.planCostDiff(c.Path)
+	return preview, nil
+}
+
+// GetCasePlanCostDiff returns the cost before and after the pending plan of a case
+func (a *App) GetCasePlanCostDiff(caseID string) (*cost.CostDiff, error) {
+	a.mu.Lock()
+	if a.project == nil {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("%s", i18n.T("app_project_not_loaded"))
+	}
+	project := a.project
+	pricingService := a.pricingService
+	costCalculator := a.costCalculator
+	a.mu.Unlock()
+
+	if pricingService == nil || costCalculator == nil {
+		return nil, fmt.Errorf("%s", i18n.T("app_cost_estimate_not_init"))
+	}
+	c, err := project.GetCase(caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get case: %w", err)
+	}
+	return redc.PlanCostDiff(c.Path, pricingService, costCalculator)
+}
+
+// DetectCaseDrift checks whether a case's cloud resources were changed outside redc.
+// Drift runs the on-drift plugin hooks of the case.
+func (a *App) DetectCaseDrift(caseID string) (bool, error) {
+	a.mu.Lock()
+	if a.project == nil {
+		a.mu.Unlock()
+		return false, fmt.Errorf("%s", i18n.T("app_project_not_loaded"))
+	}
+	project := a.project
+	a.mu.Unlock()
+
+	c, err := project.GetCase(caseID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get case: %w", err)
+	}
+	if a.pluginMgr != nil {
+		a.setupPluginHooks(c)
+	}
+	drifted, err := c.DetectDrift()
+	if drifted {
+		a.emitLog(i18n.Tf("case_drift_detected", c.Name, c.GetId()))
+	}
+	return drifted, err
+}
+
+// planCostDiff estimates the cost impact of the plan in workDir; cost is best effort for previews
+func (a *App) planCostDiff(workDir string) *cost.CostDiff {
+	a.mu.Lock()
+	pricingService := a.pricingService
+	costCalculator := a.costCalculator
+	a.mu.Unlock()
+
+	if pricingService == nil || costCalculator == nil {
+		return nil
+	}
+	diff, err := redc.PlanCostDiff(workDir, pricingService, costCalculator)
+	if err != nil {
+		return nil
+	}
+	return diff
 }
 
 // GetDeploymentPlanPreview returns structured plan preview data for a custom deployment
@@ -356,7 +478,12 @@ func (a *App) GetDeploymentPlanPreview(deploymentID string) (*PlanPreview, error
 		return nil, fmt.Errorf("deployment path not found")
 	}
 
-	return buildPlanPreview(deploymentPath)
+	preview, err := buildPlanPreview(deploymentPath)
+	if err != nil {
+		return nil, err
+	}
+	preview.CostDiff = a.planCostDiff(deploymentPath)
+	return preview, nil
 }
 
 // buildPlanPreview builds plan preview data from a terraform working directory
@@ -419,7 +546,8 @@ func buildPlanPreview(workDir string) (*PlanPreview, error) {
 
 		// Detect spot instance from plan values
 		if !preview.IsSpotInstance {
-			preview.IsSpotInstance = detectSpotInstance(rc.Change.After)
+			after, _ := rc.Change.After.(map[string]interface{})
+			preview.IsSpotInstance = cost.IsSpotInstance(rc.Type, after)
 		}
 
 		if len(actions) == 1 {
@@ -528,39 +656,6 @@ func extractResourceDetail(resType string, after interface{}) map[string]string
 	return detail
 }
 
-// detectSpotInstance checks plan after-values for spot/preemptible instance indicators
-// Supports: Alibaba Cloud (spot_strategy), AWS (market_type), Volcengine (spot_strategy), etc.
-func detectSpotInstance(after interface{}) bool {
-	m, ok := after.(map[string]interface{})
-	if !ok || m == nil {
-		return false
-	}
-	// Alibaba Cloud / Volcengine: spot_strategy != "" && != "NoSpot"
-	if v, ok := m["spot_strategy"]; ok && v != nil {
-		s := fmt.Sprintf("%v", v)
-		if s != "" && s != "NoSpot" {
-			return true
-		}
-	}
-	// AWS: instance_market_options.market_type = "spot"
-	if v, ok := m["instance_market_options"]; ok && v != nil {
-		if opts, ok := v.([]interface{}); ok {
-			for _, opt := range opts {
-				if om, ok := opt.(map[string]interface{}); ok {
-					if mt, ok := om["market_type"]; ok && fmt.Sprintf("%v", mt) == "spot" {
-						return true
-					}
-				}
-			}
-		} else if om, ok := v.(map[string]interface{}); ok {
-			if mt, ok := om["market_type"]; ok && fmt.Sprintf("%v", mt) == "spot" {
-				return true
-			}
-		}
-	}
-	return false
-}
-
 // detectSpotFromTfFiles scans .tf files in the case directory for spot instance indicators
 // Covers: Alibaba Cloud (spot_strategy), AWS (market_type = "spot"), Volcengine (is_spot_instance)
 func detectSpotFromTfFiles(casePath string) bool {
@@ -720,7 +815,7 @@ func (a *App) GetCaseOutputs(caseID string) (map[string]string, error) {
 		return nil, err
 	}
 
-	if c.State != "running" {
+	if !redc.IsRunningState(c.State) {
 		return nil, nil
 	}
 
@@ -1,19 +1,21 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"net"
 	"strings"
 	"sync"
 	"time"
 
 	"red-cloud/i18n"
 	redc "red-cloud/mod"
+	"red-cloud/mod/compose"
+	"red-cloud/mod/gologger"
 )
 
 // SpotMonitor periodically checks running spot instances for termination
-// by probing their SSH port (TCP :22).
+// using the liveness probe configured in the template (SSH on :22 by default).
 type SpotMonitor struct {
 	app      *App
 	stopCh   chan struct{}
@@ -21,7 +23,9 @@ type SpotMonitor struct {
 	interval time.Duration
 	// alerted tracks IPs that have already been reported as terminated (key: "caseID:ip")
 	alerted map[string]bool
-	mu      sync.Mutex
+	// failures counts consecutive failed scans per IP (key: "caseID:ip")
+	failures map[string]int
+	mu       sync.Mutex
 }
 
 // NewSpotMonitor creates a new SpotMonitor.
@@ -31,6 +35,7 @@ func NewSpotMonitor(app *App, interval time.Duration) *SpotMonitor {
 		stopCh:   make(chan struct{}),
 		interval: interval,
 		alerted:  make(map[string]bool),
+		failures: make(map[string]int),
 	}
 }
 
@@ -82,7 +87,7 @@ func (m *SpotMonitor) scan() {
 	}
 
 	for _, c := range cases {
-		if c.State != redc.StateRunning {
+		if !redc.IsRunningState(c.State) {
 			continue
 		}
 		if !detectSpotFromTfFiles(c.Path) {
@@ -90,14 +95,26 @@ func (m *SpotMonitor) scan() {
 		}
 
 		// Extract all public IPs from terraform outputs
-		ips := m.getCasePublicIPs(c)
+		ips := redc.CasePublicIPs(c)
 		if len(ips) == 0 {
 			continue
 		}
 
+		// Liveness probe from case.json (defaults to SSH on :22)
+		cfg, err := redc.LoadProbeConfig(c.Path)
+		if err != nil {
+			gologger.Warning().Msgf("%s", i18n.Tf("app_spot_probe_config_invalid", c.Name, err))
+		}
+		prober, err := redc.NewProber(cfg)
+		if err != nil {
+			gologger.Warning().Msgf("%s", i18n.Tf("app_spot_probe_config_invalid", c.Name, err))
+			continue
+		}
+
 		// Check each IP individually
 		var downIPs []string
-		for _, ip := range ips {
+		for _, target := range redc.CaseProbeTargets(c, ips, cfg) {
+			ip := target.IP
 			alertKey := c.Id + ":" + ip
 			m.mu.Lock()
 			alreadyAlerted := m.alerted[alertKey]
@@ -106,9 +123,10 @@ func (m *SpotMonitor) scan() {
 				continue
 			}
 
-			if !m.probeSSH(ip) {
+			if m.checkIP(c, cfg, prober, target) {
 				m.mu.Lock()
 				m.alerted[alertKey] = true
+				delete(m.failures, alertKey)
 				m.mu.Unlock()
 				downIPs = append(downIPs, ip)
 			}
@@ -120,84 +138,35 @@ func (m *SpotMonitor) scan() {
 	}
 }
 
-// getCasePublicIPs extracts all public IPs from terraform outputs.
-// Handles various output key patterns (public_ip, ecs_ip, instance_ip, vps_ip, etc.)
-// and both single-value and list-value outputs.
-func (m *SpotMonitor) getCasePublicIPs(c *redc.Case) []string {
-	outputs, err := c.TfOutput()
-	if err != nil {
-		return nil
-	}
-
-	var ips []string
-	for name, meta := range outputs {
-		lower := strings.ToLower(name)
-		// Match output keys containing "ip" but skip private IPs
-		if !strings.Contains(lower, "ip") || strings.Contains(lower, "private") {
-			continue
-		}
-
-		raw := string(meta.Value)
-		extracted := extractIPs(raw)
-		ips = append(ips, extracted...)
-	}
-	return ips
-}
-
-// extractIPs parses terraform output value into individual IP strings.
-// Handles: "1.2.3.4", ["1.2.3.4","5.6.7.8"], or bare 1.2.3.4
-func extractIPs(raw string) []string {
-	raw = strings.TrimSpace(raw)
-	if raw == "" || raw == "null" || raw == "\"\"" || raw == "[]" {
-		return nil
-	}
-
-	// Try JSON array: ["ip1", "ip2"]
-	if strings.HasPrefix(raw, "[") {
-		var arr []string
-		if err := json.Unmarshal([]byte(raw), &arr); err == nil {
-			var result []string
-			for _, v := range arr {
-				v = strings.TrimSpace(v)
-				if v != "" && net.ParseIP(v) != nil {
-					result = append(result, v)
-				}
-			}
-			return result
+// checkIP runs the liveness probe for one IP and returns true once it has failed
+// FailureThreshold scans in a row. Inconclusive results leave the counter unchanged.
+func (m *SpotMonitor) checkIP(c *redc.Case, cfg redc.ProbeConfig, prober redc.Prober, target redc.ProbeTarget) bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-m.stopCh:
+			cancel()
+		case <-ctx.Done():
 		}
-	}
+	}()
 
-	// Single quoted string: "1.2.3.4"
-	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
-		raw = raw[1 : len(raw)-1]
-	}
-
-	if ip := net.ParseIP(raw); ip != nil {
-		return []string{raw}
-	}
-	return nil
-}
-
-// probeSSH tries to TCP-connect to ip:22 up to 3 times.
-// Returns true if at least one attempt succeeds.
-func (m *SpotMonitor) probeSSH(ip string) bool {
-	addr := net.JoinHostPort(ip, "22")
-	for attempt := 0; attempt < 3; attempt++ {
-		conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
-		if err == nil {
-			conn.Close()
-			return true
-		}
-		// Wait 15s between retries (except after the last attempt)
-		if attempt < 2 {
-			select {
-			case <-time.After(15 * time.Second):
-			case <-m.stopCh:
-				return true // stopping, don't report false positive
-			}
-		}
+	alertKey := c.Id + ":" + target.IP
+	err := redc.RunProbe(ctx, prober, cfg, target)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case err == nil:
+		delete(m.failures, alertKey)
+		return false
+	case errors.Is(err, redc.ErrProbeUnknown):
+		gologger.Debug().Msgf("spot probe %s %s: %v", c.Name, target.IP, err)
+		return false
 	}
-	return false
+	m.failures[alertKey]++
+	count := m.failures[alertKey]
+	gologger.Warning().Msgf("%s", i18n.Tf("app_spot_probe_failed", c.Name, target.IP, cfg.Type, count, cfg.FailureThreshold, err))
+	return count >= cfg.FailureThreshold
 }
 
 // handleTerminated reports terminated IPs and optionally recovers them.
@@ -226,6 +195,15 @@ func (m *SpotMonitor) handleTerminated(c *redc.Case, downIPs []string, totalIPs
 	msg := fmt.Sprintf("⚠️ %s", i18n.Tf("app_spot_terminated", detail))
 	m.app.emitLog(msg)
 
+	// on-spot-terminated hook
+	if m.app.pluginMgr != nil {
+		m.app.setupPluginHooks(c)
+		ev := redc.PluginHookEvent{Error: fmt.Sprintf("spot instances terminated: %s", ipList)}
+		if err := c.RunPluginHookEvent("on-spot-terminated", ev); err != nil {
+			m.app.emitLog(fmt.Sprintf("⚠️ %v", err))
+		}
+	}
+
 	// Send system notification
 	if m.app.notificationMgr != nil {
 		m.app.notificationMgr.SendSpotTerminated(c.Name, ipList)
@@ -250,67 +228,158 @@ func (m *SpotMonitor) isAutoRecoverEnabled() bool {
 }
 
 // attemptRecover runs terraform plan+apply to replenish terminated spot instances.
-// Uses low-level TfPlan/TfApply directly to bypass the state==running check in Case.TfApply().
+// Follows the case's recovery policy (redc-recovery.json): the first attempt reuses the
+// original parameters, later attempts walk the fallback list (region / instance type /
+// on-demand) with a cooldown in between. Every attempt is recorded in the case history.
 func (m *SpotMonitor) attemptRecover(c *redc.Case, downIPs []string) {
 	m.app.emitLog(fmt.Sprintf("🔄 %s", i18n.Tf("app_spot_recovering", c.Name)))
 
 	// Emit recovering event
-	m.app.emitEvent( "spot-recovering", map[string]interface{}{
+	m.app.emitEvent("spot-recovering", map[string]interface{}{
 		"caseId":   c.Id,
 		"caseName": c.Name,
 	})
 
-	// Run terraform plan + apply directly (bypass Case.TfApply which rejects running state)
-	if err := redc.TfPlan(c.Path, c.Parameter...); err != nil {
-		m.app.emitLog(fmt.Sprintf("❌ %s", i18n.Tf("app_spot_recover_failed", c.Name, err)))
-		if m.app.notificationMgr != nil {
-			m.app.notificationMgr.SendSpotRecoverFailed(c.Name)
+	rec, err := redc.LoadSpotRecovery(c.Path)
+	if err != nil {
+		m.app.emitLog(fmt.Sprintf("⚠️ %s", i18n.Tf("app_spot_recover_policy_invalid", c.Name, err)))
+		rec = &redc.SpotRecoveryRecord{}
+		rec.Policy.Normalize()
+	}
+	policy := rec.Policy
+	declared := redc.DeclaredTfVars(c.Path)
+	oldIPs := redc.CasePublicIPs(c)
+
+	var lastErr error
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 && policy.CooldownSeconds > 0 {
+			m.app.emitLog(fmt.Sprintf("⏳ %s", i18n.Tf("app_spot_recover_cooldown", c.Name, policy.CooldownDuration())))
+			select {
+			case <-time.After(policy.CooldownDuration()):
+			case <-m.stopCh:
+				return
+			}
+		}
+
+		step := policy.StepFor(attempt)
+		params, skipped := policy.ApplyStep(c.Parameter, step, declared)
+		if len(skipped) > 0 {
+			m.app.emitLog(fmt.Sprintf("⚠️ %s", i18n.Tf("app_spot_recover_vars_skipped", c.Name, strings.Join(skipped, ", "))))
+		}
+		replace := step > 0 && strings.Join(params, "\x00") != strings.Join(c.Parameter, "\x00")
+		m.app.emitLog(fmt.Sprintf("🔁 %s", i18n.Tf("app_spot_recover_attempt", c.Name, attempt+1, policy.MaxAttempts, describeRecoveryStep(policy, step))))
+
+		started := time.Now()
+		lastErr = m.recoverOnce(c, params, replace)
+		record := redc.RecoveryAttempt{
+			At:       started,
+			Attempt:  attempt + 1,
+			Step:     step,
+			Params:   params,
+			Success:  lastErr == nil,
+			OldIPs:   oldIPs,
+			Duration: time.Since(started).Round(time.Second).String(),
 		}
-		m.app.emitEvent( "spot-recover-failed", map[string]interface{}{
-			"caseId":   c.Id,
-			"caseName": c.Name,
-			"error":    err.Error(),
-		})
+		if lastErr != nil {
+			record.Error = lastErr.Error()
+			m.app.emitLog(fmt.Sprintf("❌ %s", i18n.Tf("app_spot_recover_failed", c.Name, lastErr)))
+			m.recordRecovery(c, record)
+			continue
+		}
+
+		// Fallback parameters become the case's parameters from now on
+		if replace {
+			c.Parameter = params
+		}
+		c.StatusChange(redc.StateRunning)
+		record.NewIPs = redc.CasePublicIPs(c)
+		m.recordRecovery(c, record)
+		m.onRecovered(c, policy, oldIPs, record.NewIPs)
 		return
 	}
 
-	if err := redc.TfApply(c.Path, c.Parameter...); err != nil {
-		m.app.emitLog(fmt.Sprintf("❌ %s", i18n.Tf("app_spot_recover_failed", c.Name, err)))
+	m.app.emitLog(fmt.Sprintf("❌ %s", i18n.Tf("app_spot_recover_exhausted", c.Name, policy.MaxAttempts)))
+	if m.app.notificationMgr != nil {
+		m.app.notificationMgr.SendSpotRecoverFailed(c.Name)
+	}
+	errMsg := ""
+	if lastErr != nil {
+		errMsg = lastErr.Error()
+	}
+	m.app.emitEvent("spot-recover-failed", map[string]interface{}{
+		"caseId":   c.Id,
+		"caseName": c.Name,
+		"error":    errMsg,
+	})
+	m.app.emitRefresh()
+}
+
+// recoverOnce runs one plan+apply with the given parameters.
+// Uses low-level TfPlan/TfApply directly to bypass the state==running check in Case.TfApply().
+// When replace is set (fallback changes region/type), the old resources are destroyed first
+// so nothing is left orphaned in the previous region.
+func (m *SpotMonitor) recoverOnce(c *redc.Case, params []string, replace bool) error {
+	// A pending template upgrade has unconfirmed files in the case directory
+	if err := c.CheckPendingUpgrade(); err != nil {
+		return err
+	}
+	if replace {
+		if err := redc.TfDestroy(c.Path, c.Parameter); err != nil {
+			return fmt.Errorf("destroy before fallback: %v", err)
+		}
+		c.StatusChange(redc.StateStopped)
+	}
+
+	if err := redc.TfPlan(c.Path, params...); err != nil {
+		return err
+	}
+
+	if err := redc.TfApply(c.Path, params...); err != nil {
 		// Rollback: destroy partially created resources to avoid orphaned instances
 		m.app.emitLog(fmt.Sprintf("🧹 %s", i18n.Tf("app_spot_recover_rollback", c.Name)))
-		if destroyErr := redc.TfDestroy(c.Path, c.Parameter); destroyErr != nil {
+		if destroyErr := redc.TfDestroy(c.Path, params); destroyErr != nil {
 			m.app.emitLog(fmt.Sprintf("❌ %s", i18n.Tf("app_spot_recover_rollback_failed", c.Name, destroyErr)))
 		} else {
 			c.StatusChange(redc.StateStopped)
 			m.app.emitLog(fmt.Sprintf("✅ %s", i18n.Tf("app_spot_recover_rollback_done", c.Name)))
 		}
-		if m.app.notificationMgr != nil {
-			m.app.notificationMgr.SendSpotRecoverFailed(c.Name)
-		}
-		m.app.emitEvent( "spot-recover-failed", map[string]interface{}{
-			"caseId":   c.Id,
-			"caseName": c.Name,
-			"error":    err.Error(),
-		})
 		m.app.emitRefresh()
-		return
-	}
-
-	// Ensure state is running
-	if c.State == redc.StateTerminated {
-		c.StatusChange(redc.StateRunning)
+		return err
 	}
+	return nil
+}
 
+// onRecovered propagates the new IPs (plugins, compose dependents) and notifies.
+func (m *SpotMonitor) onRecovered(c *redc.Case, policy redc.SpotRecoveryPolicy, oldIPs, newIPs []string) {
 	// Refresh terraform outputs so Case has new IPs
 	if _, err := c.TfOutput(); err != nil {
 		m.app.emitLog(fmt.Sprintf("⚠️ %s", i18n.Tf("app_spot_recover_output_failed", c.Name, err)))
 	}
+	if len(newIPs) > 0 {
+		m.app.emitLog(fmt.Sprintf("🌐 %s", i18n.Tf("app_spot_recover_ips", c.Name, strings.Join(oldIPs, ", "), strings.Join(newIPs, ", "))))
+	}
 
-	// Re-run plugin hooks so plugins (e.g. clash-config, upload-r2) pick up new IPs
+	// Re-run plugin hooks so plugins (e.g. clash-config, upload-r2, DNS) pick up new IPs
 	if m.app.pluginMgr != nil {
 		m.app.setupPluginHooks(c)
 		m.app.emitLog(fmt.Sprintf("🔌 %s", i18n.Tf("app_spot_recover_plugins", c.Name)))
-		c.RunPluginHookPublic("post-apply")
+		if err := c.RunPluginHookPublic("post-apply"); err != nil {
+			m.app.emitLog(fmt.Sprintf("⚠️ %v", err))
+		}
+	}
+
+	// Re-apply compose services that reference this one
+	if policy.ComposeFile != "" && m.app.project != nil {
+		updated, err := compose.RefreshDependents(compose.ComposeOptions{
+			File:        policy.ComposeFile,
+			Project:     m.app.project,
+			LogCallback: m.app.emitLog,
+		}, c.Name)
+		if err != nil {
+			m.app.emitLog(fmt.Sprintf("❌ %s", i18n.Tf("app_spot_recover_compose_failed", c.Name, err)))
+		} else if len(updated) > 0 {
+			m.app.emitLog(fmt.Sprintf("🔗 %s", i18n.Tf("app_spot_recover_compose", c.Name, strings.Join(updated, ", "))))
+		}
 	}
 
 	// Success — clear alerts for this case so new IPs can be monitored
@@ -321,14 +390,74 @@ func (m *SpotMonitor) attemptRecover(c *redc.Case, downIPs []string) {
 		m.app.notificationMgr.SendSpotRecovered(c.Name)
 	}
 
-	m.app.emitEvent( "spot-recovered", map[string]interface{}{
+	m.app.emitEvent("spot-recovered", map[string]interface{}{
 		"caseId":   c.Id,
 		"caseName": c.Name,
+		"oldIPs":   oldIPs,
+		"newIPs":   newIPs,
 	})
 
 	m.app.emitRefresh()
 }
 
+// recordRecovery appends an attempt to the case's recovery history and the redc log.
+func (m *SpotMonitor) recordRecovery(c *redc.Case, attempt redc.RecoveryAttempt) {
+	status := "success"
+	if !attempt.Success {
+		status = "failed: " + attempt.Error
+	}
+	redc.RedcLog(fmt.Sprintf("Spot recovery %s attempt %d (step %d): %s", c.Name, attempt.Attempt, attempt.Step, status))
+	if err := redc.AppendRecoveryAttempt(c.Path, attempt); err != nil {
+		m.app.emitLog(fmt.Sprintf("⚠️ %s", i18n.Tf("app_spot_recover_policy_invalid", c.Name, err)))
+	}
+}
+
+// describeRecoveryStep returns a short label for log messages.
+func describeRecoveryStep(p redc.SpotRecoveryPolicy, step int) string {
+	if step <= 0 || step > len(p.Fallbacks) {
+		return "original"
+	}
+	s := p.Fallbacks[step-1]
+	var parts []string
+	if s.Region != "" {
+		parts = append(parts, "region="+s.Region)
+	}
+	if s.InstanceType != "" {
+		parts = append(parts, "instance_type="+s.InstanceType)
+	}
+	if s.OnDemand {
+		parts = append(parts, "on-demand")
+	}
+	for k, v := range s.Vars {
+		parts = append(parts, k+"="+v)
+	}
+	return fmt.Sprintf("fallback #%d (%s)", step, strings.Join(parts, ", "))
+}
+
+// GetSpotRecoveryPolicy returns the recovery policy and attempt history of a case.
+func (a *App) GetSpotRecoveryPolicy(caseID string) (*redc.SpotRecoveryRecord, error) {
+	if a.project == nil {
+		return nil, fmt.Errorf("%s", i18n.T("app_project_not_loaded"))
+	}
+	c, err := a.project.GetCase(caseID)
+	if err != nil {
+		return nil, err
+	}
+	return redc.LoadSpotRecovery(c.Path)
+}
+
+// SetSpotRecoveryPolicy sets the fallback recovery policy of a case.
+func (a *App) SetSpotRecoveryPolicy(caseID string, policy redc.SpotRecoveryPolicy) error {
+	if a.project == nil {
+		return fmt.Errorf("%s", i18n.T("app_project_not_loaded"))
+	}
+	c, err := a.project.GetCase(caseID)
+	if err != nil {
+		return err
+	}
+	return redc.SetSpotRecoveryPolicy(c.Path, policy)
+}
+
 // ResetAlert removes all alerts for a case (e.g. when restarted).
 func (m *SpotMonitor) ResetAlert(caseID string) {
 	m.mu.Lock()
@@ -337,5 +466,10 @@ func (m *SpotMonitor) ResetAlert(caseID string) {
 			delete(m.alerted, key)
 		}
 	}
+	for key := range m.failures {
+		if strings.HasPrefix(key, caseID+":") {
+			delete(m.failures, key)
+		}
+	}
 	m.mu.Unlock()
 }
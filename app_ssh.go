@@ -82,6 +82,11 @@ func (a *App) ExecCommand(caseID string, command string) ExecCommandResult {
 			result.Success = false
 			return result
 		}
+		if err := c.BeforeSSHExec(command); err != nil {
+			result.Error = err.Error()
+			result.Success = false
+			return result
+		}
 
 		return a.execSSHCommand(sshConfig, command)
 	}
@@ -101,6 +106,29 @@ func (a *App) ExecCommand(caseID string, command string) ExecCommandResult {
 	return result
 }
 
+// execCaseCommand 运行场景的 pre-ssh-exec 钩子后执行命令，自定义部署没有钩子
+func (a *App) execCaseCommand(caseID string, sshConfig *sshutil.SSHConfig, command string) ExecCommandResult {
+	if err := a.beforeSSHExec(caseID, command); err != nil {
+		return ExecCommandResult{Error: err.Error(), Success: false}
+	}
+	return a.execSSHCommand(sshConfig, command)
+}
+
+// beforeSSHExec 运行场景的 pre-ssh-exec 钩子，策略插件可以阻止执行
+func (a *App) beforeSSHExec(caseID string, command string) error {
+	a.mu.Lock()
+	project := a.project
+	a.mu.Unlock()
+	if project == nil {
+		return nil
+	}
+	c, err := project.GetCase(caseID)
+	if err != nil {
+		return nil
+	}
+	return c.BeforeSSHExec(command)
+}
+
 // execSSHCommand 执行 SSH 命令的通用方法
 func (a *App) execSSHCommand(sshConfig *sshutil.SSHConfig, command string) ExecCommandResult {
 	result := ExecCommandResult{}
@@ -171,6 +199,11 @@ func (a *App) ExecUserdata(caseID string, script string) ExecCommandResult {
 			result.Success = false
 			return result
 		}
+		if err := c.BeforeSSHExec(script); err != nil {
+			result.Error = err.Error()
+			result.Success = false
+			return result
+		}
 	} else {
 		if service != nil {
 			var err error
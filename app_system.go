@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/projectdiscovery/gologger/levels"
@@ -150,39 +151,35 @@ func (a *App) checkTemplateUpdates() []TemplateUpdateInfo {
 		localMap[t.Name] = t.Version
 	}
 
-	// Fetch remote registry index
-	registryURL := "https://redc.wgpsec.org"
-	client := redc.NewProxyHTTPClient(15 * time.Second)
-	resp, err := client.Get(fmt.Sprintf("%s/index.json?t=%d", registryURL, time.Now().Unix()))
+	// Fetch the configured registries' indexes (with credentials), first match wins like redc pull
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	indexes, err := redc.RegistryIndexes(ctx, redc.PullOptions{})
 	if err != nil {
 		gologger.Warning().Msgf("checkTemplateUpdates: failed to fetch registry: %v", err)
 		return nil
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil
-	}
-
-	var idx remoteIndexResponse
-	if err := json.NewDecoder(resp.Body).Decode(&idx); err != nil {
-		return nil
-	}
 
 	var updates []TemplateUpdateInfo
-	for name, remote := range idx.Templates {
-		localVer, installed := localMap[name]
-		if !installed {
-			continue
+	seen := make(map[string]bool)
+	for _, ri := range indexes {
+		for name, remote := range ri.Index.Templates {
+			localVer, installed := localMap[name]
+			if !installed || seen[name] {
+				continue
+			}
+			seen[name] = true
+			latest := strings.TrimPrefix(remote.Latest, "v")
+			local := strings.TrimPrefix(localVer, "v")
+			hasUpdate := local != "" && latest != "" && compareVersions(local, latest) < 0
+			updates = append(updates, TemplateUpdateInfo{
+				Name:          name,
+				LocalVersion:  localVer,
+				LatestVersion: remote.Latest,
+				HasUpdate:     hasUpdate,
+				Registry:      ri.Registry.Name,
+			})
 		}
-		latest := strings.TrimPrefix(remote.Latest, "v")
-		local := strings.TrimPrefix(localVer, "v")
-		hasUpdate := local != "" && latest != "" && compareVersions(local, latest) < 0
-		updates = append(updates, TemplateUpdateInfo{
-			Name:          name,
-			LocalVersion:  localVer,
-			LatestVersion: remote.Latest,
-			HasUpdate:     hasUpdate,
-		})
 	}
 	return updates
 }
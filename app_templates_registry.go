@@ -185,91 +185,109 @@ func parseVariablesTf(filePath string) ([]*TemplateVariable, error) {
 	return variables, scanner.Err()
 }
 
+// FetchRegistryTemplates lists templates from the configured registries (with their
+// credentials) in lookup order, or only from registryURL / a registry name when given.
+// A template found in several registries is reported from the first one, which is
+// the one redc pull would use.
 func (a *App) FetchRegistryTemplates(registryURL string) ([]RegistryTemplate, error) {
-	if registryURL == "" {
-		registryURL = "https://redc.wgpsec.org"
+	opts := redc.PullOptions{}
+	opts.SetRegistry(registryURL)
+	a.emitLog(i18n.Tf("app_connecting_registry", registrySource(registryURL)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	indexes, err := redc.RegistryIndexes(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s", i18n.Tf("app_registry_connect_failed", err))
 	}
 
-	a.emitLog(i18n.Tf("app_connecting_registry", registryURL))
+	// Build result list
+	var result []RegistryTemplate
+	seen := make(map[string]bool)
+	for _, ri := range indexes {
+		for templateID, t := range ri.Index.Templates {
+			// Use templateID (e.g. "aliyun/ecs") as the name
+			name := templateID
+			if name == "" {
+				name = t.ID
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			result = append(result, registryTemplate(name, t, ri.Registry.Name))
+		}
+	}
 
-	// Fetch index.json
-	indexURL := fmt.Sprintf("%s/index.json?t=%d", registryURL, time.Now().Unix())
+	a.emitLog(i18n.Tf("app_fetched_templates", len(result)))
+	return result, nil
+}
 
-	client := redc.NewProxyHTTPClient(30 * time.Second)
-	resp, err := client.Get(indexURL)
+// registrySource describes where FetchRegistryTemplates looks, for the log
+func registrySource(registry string) string {
+	if registry != "" {
+		return registry
+	}
+	regs, err := redc.LoadRegistries()
 	if err != nil {
-		return nil, fmt.Errorf(i18n.Tf("app_registry_connect_failed", err))
+		return redc.DefaultRegistryURL
+	}
+	names := make([]string, 0, len(regs))
+	for _, r := range regs {
+		names = append(names, r.String())
 	}
-	defer resp.Body.Close()
+	return strings.Join(names, ", ")
+}
+
+// registryTemplate converts a registry index entry for the template list
+func registryTemplate(name string, t redc.TemplateItem, registry string) RegistryTemplate {
+	// Check if installed locally
+	installed, localVer, _ := redc.CheckLocalImage(name)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(i18n.Tf("app_registry_status_error", resp.Status))
+	// Get version list
+	versions := make([]string, 0, len(t.Versions))
+	var updatedAt string
+	for v, info := range t.Versions {
+		versions = append(versions, v)
+		if v == t.Latest && info.UpdatedAt != "" {
+			updatedAt = info.UpdatedAt
+		}
 	}
 
-	var idx remoteIndexResponse
-	if err := json.NewDecoder(resp.Body).Decode(&idx); err != nil {
-		return nil, fmt.Errorf(i18n.Tf("app_registry_parse_failed", err))
+	// Extract tags: prefer remote metadata tags, fallback to local case.json
+	var tags []string
+	if len(t.Metadata.Tags) > 0 {
+		tags = t.Metadata.Tags
+	} else if t.Provider != "" {
+		tags = []string{t.Provider}
 	}
 
-	// Build result list
-	result := make([]RegistryTemplate, 0, len(idx.Templates))
-	for templateID, t := range idx.Templates {
-		// Use templateID (e.g. "aliyun/ecs") as the name
-		name := templateID
-		if name == "" {
-			name = t.ID
-		}
-
-		// Check if installed locally
-		installed, localVer, _ := redc.CheckLocalImage(name)
-
-		// Get version list
-		versions := make([]string, 0, len(t.Versions))
-		var updatedAt string
-		for v, info := range t.Versions {
-			versions = append(versions, v)
-			if v == t.Latest && info.UpdatedAt != "" {
-				updatedAt = info.UpdatedAt
+	// For installed templates, read tags from local case.json
+	if installed {
+		localCasePath := filepath.Join(redc.TemplateDir, name, "case.json")
+		if data, err := os.ReadFile(localCasePath); err == nil {
+			var localCase struct {
+				Tags []string `json:"tags"`
 			}
-		}
-
-		// Extract tags: prefer remote metadata tags, fallback to local case.json
-		var tags []string
-		if len(t.Metadata.Tags) > 0 {
-			tags = t.Metadata.Tags
-		} else if t.Provider != "" {
-			tags = []string{t.Provider}
-		}
-
-		// For installed templates, read tags from local case.json
-		if installed {
-			localCasePath := filepath.Join(redc.TemplateDir, name, "case.json")
-			if data, err := os.ReadFile(localCasePath); err == nil {
-				var localCase struct {
-					Tags []string `json:"tags"`
-				}
-				if json.Unmarshal(data, &localCase) == nil && len(localCase.Tags) > 0 {
-					tags = localCase.Tags
-				}
+			if json.Unmarshal(data, &localCase) == nil && len(localCase.Tags) > 0 {
+				tags = localCase.Tags
 			}
 		}
-
-		result = append(result, RegistryTemplate{
-			Name:          name,
-			Description:   t.Metadata.Description,
-			DescriptionEN: t.Metadata.DescriptionEN,
-			Author:        t.Metadata.Author,
-			Latest:      t.Latest,
-			Versions:    versions,
-			UpdatedAt:   updatedAt,
-			Tags:        tags,
-			Installed:   installed,
-			LocalVer:    localVer,
-		})
 	}
 
-	a.emitLog(i18n.Tf("app_fetched_templates", len(result)))
-	return result, nil
+	return RegistryTemplate{
+		Name:          name,
+		Description:   t.Metadata.Description,
+		DescriptionEN: t.Metadata.DescriptionEN,
+		Author:        t.Metadata.Author,
+		Latest:        t.Latest,
+		Versions:      versions,
+		UpdatedAt:     updatedAt,
+		Tags:          tags,
+		Installed:     installed,
+		LocalVer:      localVer,
+		Registry:      registry,
+	}
 }
 
 func (a *App) FetchTemplateReadme(templateName string, lang string) (string, error) {
@@ -319,6 +337,10 @@ func (a *App) RemoveTemplate(templateName string) error {
 
 func (a *App) PullTemplate(templateName string, force bool) error {
 	a.emitLog(i18n.Tf("app_pulling_template", templateName))
+	lockFile := ""
+	if a.project != nil {
+		lockFile = a.project.TemplateLockPath()
+	}
 
 	go func() {
 		defer func() {
@@ -329,9 +351,9 @@ func (a *App) PullTemplate(templateName string, force bool) error {
 		}()
 
 		opts := redc.PullOptions{
-			RegistryURL: "https://redc.wgpsec.org",
-			Force:       force,
-			Timeout:     120 * time.Second,
+			Force:    force,
+			Timeout:  120 * time.Second,
+			LockFile: lockFile,
 		}
 
 		if err := redc.Pull(context.Background(), templateName, opts); err != nil {
@@ -127,13 +127,16 @@ func runGlobalStatus() {
 		if state == "" {
 			state = "unknown"
 		}
+		// degraded 的场景资源仍在运行，计入 running
+		if state == redc.StateDegraded {
+			state = redc.StateRunning
+		}
 		counts[state]++
 	}
 
 	// Scheduled tasks
 	pendingTasks := 0
-	schedulerDB := filepath.Join(redc.RedcPath, "scheduler.db")
-	if scheduler := redc.NewTaskScheduler(redcProject, schedulerDB); scheduler != nil {
+	if scheduler, err := openScheduler(); err == nil {
 		pendingTasks = len(scheduler.ListTasks())
 		scheduler.Stop()
 	}
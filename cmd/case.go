@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	caseUpgradeTo       string
+	caseUpgradeVars     map[string]string
+	caseUpgradeRegistry string
+	caseUpgradeTimeout  time.Duration
+	caseUpgradeYes      bool
+	caseUpgradeResume   bool
+)
+
+var caseCmd = &cobra.Command{
+	Use:   "case",
+	Short: i18n.T("case_short"),
+}
+
+var caseUpgradeCmd = &cobra.Command{
+	Use:   "upgrade <id>",
+	Short: i18n.T("case_upgrade_short"),
+	Long:  i18n.T("case_upgrade_long"),
+	Example: `  redc case upgrade 8a3f2c1b9e4d
+  redc case upgrade 8a3f2c1b9e4d --to 1.2.0 -e region=ap-east-1
+  redc case upgrade 8a3f2c1b9e4d --to 1.2.0 --yes -o json
+  redc case upgrade 8a3f2c1b9e4d --resume`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCaseSwap(args[0], func(c *redc.Case) (*redc.CaseUpgrade, error) {
+			if caseUpgradeResume {
+				return c.ResumeUpgrade()
+			}
+			pullOpts := redc.PullOptions{Timeout: caseUpgradeTimeout}
+			pullOpts.SetRegistry(caseUpgradeRegistry)
+			return c.PlanUpgrade(cmd.Context(), redc.CaseUpgradeOptions{
+				Version:     caseUpgradeTo,
+				Vars:        caseUpgradeVars,
+				PullOptions: pullOpts,
+			})
+		})
+	},
+}
+
+var caseRollbackCmd = &cobra.Command{
+	Use:   "rollback <id> [snapshot]",
+	Short: i18n.T("case_rollback_short"),
+	Long:  i18n.T("case_rollback_long"),
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshot := ""
+		if len(args) > 1 {
+			snapshot = args[1]
+		}
+		// 有待应用的升级时回滚即取消该升级
+		c, err := redcProject.GetCase(args[0])
+		if err != nil {
+			MustJSON(fmt.Errorf("%s", i18n.Tf("action_case_not_found", args[0], err)))
+			return
+		}
+		if cancelled, err := c.CancelPendingUpgrade(); err != nil {
+			MustJSON(fmt.Errorf("%s", i18n.Tf("case_upgrade_restore_failed", err)))
+			return
+		} else if cancelled {
+			gologger.Info().Msg(i18n.T("case_upgrade_cancelled"))
+			return
+		}
+		runCaseSwap(args[0], func(c *redc.Case) (*redc.CaseUpgrade, error) {
+			return c.PlanRollback(snapshot)
+		})
+	},
+}
+
+// runCaseSwap 生成升级或回滚的 plan，确认后应用，拒绝时恢复原文件
+func runCaseSwap(caseID string, plan func(*redc.Case) (*redc.CaseUpgrade, error)) {
+	if !caseUpgradeYes && IsJSON() {
+		MustJSON(fmt.Errorf("%s", i18n.T("case_upgrade_yes_required")))
+		return
+	}
+	c, err := redcProject.GetCase(caseID)
+	if err != nil {
+		MustJSON(fmt.Errorf("%s", i18n.Tf("action_case_not_found", caseID, err)))
+		return
+	}
+	u, err := plan(c)
+	if err != nil {
+		MustJSON(err)
+		return
+	}
+	if !IsJSON() {
+		printCaseUpgrade(u)
+	}
+
+	if !caseUpgradeYes {
+		fmt.Print(i18n.Tf("case_upgrade_confirm", c.Name, c.GetId()))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			if err := u.Cancel(); err != nil {
+				gologger.Error().Msgf("%s", i18n.Tf("case_upgrade_restore_failed", err))
+				return
+			}
+			gologger.Info().Msg(i18n.T("case_upgrade_cancelled"))
+			return
+		}
+	}
+
+	redc.RedcLog(fmt.Sprintf("Upgrade %s %s -> %s", c.GetId(), u.FromVersion, u.ToVersion))
+	if err := u.Apply(); err != nil {
+		if IsJSON() {
+			PrintJSONError(err)
+			return
+		}
+		gologger.Error().Msgf("%s", i18n.Tf("case_upgrade_failed", err, c.GetId(), c.GetId()))
+		return
+	}
+	if IsJSON() {
+		PrintJSON(u)
+		return
+	}
+	gologger.Info().Msgf("%s", i18n.Tf("case_upgrade_success", c.Name, c.GetId(), c.Type, orUnknown(u.ToVersion)))
+}
+
+// printCaseUpgrade 打印文件、变量与资源变更摘要
+func printCaseUpgrade(u *redc.CaseUpgrade) {
+	fmt.Println(i18n.Tf("case_upgrade_summary", u.Case.Type, orUnknown(u.FromVersion), orUnknown(u.ToVersion)))
+	for _, group := range []struct {
+		mark  string
+		items []string
+	}{
+		{"+", u.Added}, {"~", u.Changed}, {"-", u.Removed},
+	} {
+		for _, f := range group.items {
+			fmt.Printf("  %s %s\n", group.mark, f)
+		}
+	}
+	if len(u.DroppedVars) > 0 {
+		fmt.Println(i18n.Tf("case_upgrade_vars_dropped", strings.Join(u.DroppedVars, ", ")))
+	}
+	if len(u.NewVars) > 0 {
+		fmt.Println(i18n.Tf("case_upgrade_vars_new", strings.Join(u.NewVars, ", ")))
+	}
+	if len(u.Changes) == 0 {
+		fmt.Println(i18n.T("terraform_no_changes"))
+	}
+	for _, ch := range u.Changes {
+		fmt.Printf("  %-8s %s\n", ch.Action, ch.Address)
+	}
+}
+
+func orUnknown(v string) string {
+	if v == "" {
+		return "unknown"
+	}
+	return v
+}
+
+func init() {
+	caseUpgradeCmd.Flags().StringVar(&caseUpgradeTo, "to", "", i18n.T("flag_case_upgrade_to"))
+	caseUpgradeCmd.Flags().StringToStringVarP(&caseUpgradeVars, "env", "e", nil, i18n.T("flag_case_upgrade_env"))
+	caseUpgradeCmd.Flags().StringVarP(&caseUpgradeRegistry, "registry", "r", "", i18n.T("flag_registry"))
+	caseUpgradeCmd.Flags().DurationVar(&caseUpgradeTimeout, "timeout", 60*time.Second, "Download timeout")
+	caseUpgradeCmd.Flags().BoolVar(&caseUpgradeResume, "resume", false, i18n.T("flag_case_upgrade_resume"))
+	for _, c := range []*cobra.Command{caseUpgradeCmd, caseRollbackCmd} {
+		c.Flags().BoolVarP(&caseUpgradeYes, "yes", "y", false, i18n.T("flag_case_upgrade_yes"))
+		caseCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(caseCmd)
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"path/filepath"
 	"red-cloud/i18n"
+	redc "red-cloud/mod"
 	"red-cloud/mod/compose"
 	"red-cloud/mod/gologger"
 
@@ -28,7 +30,21 @@ var upCmd = &cobra.Command{
 			Project:  redcProject,
 		}
 
-		if err := compose.RunComposeUp(opts); err != nil {
+		absFile, _ := filepath.Abs(composeFile)
+		policy, err := policyFromFlags(redc.PolicyKindCompose, redc.ComposePolicyID(absFile), filepath.Base(composeFile), nil)
+		if err != nil {
+			if IsJSON() {
+				PrintJSONError(err)
+				return
+			}
+			gologger.Fatal().Msgf("%s", i18n.Tf("policy_save_failed", err))
+		}
+		if policy != nil {
+			opts.ExpiresAt = policy.ExpiresAt
+		}
+
+		result, err := compose.RunComposeUpWithResult(opts)
+		if err != nil {
 			if IsJSON() {
 				PrintJSONError(err)
 				return
@@ -36,6 +52,20 @@ var upCmd = &cobra.Command{
 			gologger.Fatal().Msgf(i18n.Tf("compose_up_failed", err))
 		}
 
+		// 预算按整个 compose 项目的所有服务合计
+		var caseIDs []string
+		for _, svc := range result.Services {
+			if svc.CaseID != "" {
+				caseIDs = append(caseIDs, svc.CaseID)
+			}
+		}
+		if policy != nil {
+			policy.CaseIDs = caseIDs
+		}
+		if err := savePolicy(policy); err != nil {
+			gologger.Error().Msgf("%s", i18n.Tf("policy_save_failed", err))
+		}
+
 		if IsJSON() {
 			PrintJSONMessage("compose up completed")
 			return
@@ -94,6 +124,7 @@ var configCmd = &cobra.Command{
 func init() {
 	upCmd.Flags().StringVarP(&composeFile, "file", "f", "redc-compose.yaml", i18n.T("flag_compose_file"))
 	upCmd.Flags().StringSliceVarP(&profiles, "profile", "p", []string{}, i18n.T("flag_compose_profile"))
+	upCmd.Flags().AddFlagSet(policyFlagSet())
 
 	downCmd.Flags().StringVarP(&composeFile, "file", "f", "redc-compose.yaml", i18n.T("flag_compose_file"))
 	downCmd.Flags().StringSliceVarP(&profiles, "profile", "p", []string{}, i18n.T("flag_compose_profile"))
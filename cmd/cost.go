@@ -0,0 +1,641 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"red-cloud/mod/cost"
+	"red-cloud/mod/gologger"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	costMonth       string
+	costProviders   string
+	costFrom        string
+	costTo          string
+	costFormat      string
+	costFile        string
+	costCurrency    string
+	costAllProjects bool
+	catalogVersion  string
+)
+
+var costCmd = &cobra.Command{
+	Use:   "cost",
+	Short: i18n.T("cost_short"),
+}
+
+var costReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: i18n.T("cost_report_short"),
+	Long:  i18n.T("cost_report_long"),
+	Example: `  redc cost report --from 2026-03
+  redc cost report --project engagement-x --from 2026-03-01 --to 2026-03-31 --format csv --file march.csv
+  redc cost report --all-projects --currency USD -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := redc.ParseLedgerTime(costFrom, false)
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		to, err := redc.ParseLedgerTime(costTo, true)
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		format := strings.ToLower(costFormat)
+		if format != "table" && format != "csv" && format != "json" {
+			MustJSON(fmt.Errorf("%s", i18n.Tf("cost_format_invalid", costFormat)))
+			return
+		}
+
+		// 先用当前状态校正台账，使运行中的场景计到此刻
+		if err := redc.ReconcileCostLedger(redc.Project); err != nil {
+			MustJSON(err)
+			return
+		}
+		projectID := redc.Project
+		if costAllProjects {
+			projectID = ""
+		}
+		ledger, err := redc.NewCostLedger()
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		defer ledger.Close()
+		intervals, err := ledger.Intervals(projectID, from, to)
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		report := redc.BuildLedgerReport(intervals, from, to, time.Now(), costCurrency)
+
+		if IsJSON() {
+			PrintJSON(report)
+			return
+		}
+
+		var out io.Writer = os.Stdout
+		if costFile != "" {
+			f, err := os.Create(costFile)
+			if err != nil {
+				MustJSON(err)
+				return
+			}
+			defer f.Close()
+			out = f
+		}
+		switch format {
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(report)
+		case "csv":
+			err = writeCostReportCSV(out, report)
+		default:
+			writeCostReportTable(out, report)
+		}
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		if costFile != "" {
+			fmt.Println(i18n.Tf("cost_report_saved", costFile))
+		}
+	},
+}
+
+var costReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: i18n.T("cost_reconcile_short"),
+	Long:  i18n.T("cost_reconcile_long"),
+	Example: `  redc cost reconcile --month 2026-03
+  redc cost reconcile --month 2026-03 --providers aliyun,aws --currency USD --format csv --file recon.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := strings.ToLower(costFormat)
+		if format != "table" && format != "csv" && format != "json" {
+			MustJSON(fmt.Errorf("%s", i18n.Tf("cost_format_invalid", costFormat)))
+			return
+		}
+		month := costMonth
+		if month == "" {
+			month = time.Now().Format("2006-01")
+		}
+		var providers []string
+		for _, p := range strings.Split(costProviders, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				providers = append(providers, p)
+			}
+		}
+
+		if err := redc.ReconcileCostLedger(redc.Project); err != nil {
+			MustJSON(err)
+			return
+		}
+		report, err := redc.BuildReconcileReport(context.Background(), redc.Project, month, providers, costCurrency)
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+
+		if IsJSON() {
+			PrintJSON(report)
+			return
+		}
+		var out io.Writer = os.Stdout
+		if costFile != "" {
+			f, err := os.Create(costFile)
+			if err != nil {
+				MustJSON(err)
+				return
+			}
+			defer f.Close()
+			out = f
+		}
+		switch format {
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(report)
+		case "csv":
+			err = writeReconcileCSV(out, report)
+		default:
+			writeReconcileTable(out, report)
+		}
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		if costFile != "" {
+			fmt.Println(i18n.Tf("cost_report_saved", costFile))
+		}
+	},
+}
+
+func writeReconcileCSV(out io.Writer, report *redc.ReconcileReport) error {
+	w := csv.NewWriter(out)
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	w.Write([]string{"kind", "project", "case_id", "case_name", "provider", "resource_id", "product", "estimated", "billed", "diff", "currency"})
+	for _, c := range report.Cases {
+		w.Write([]string{"case", c.ProjectID, c.CaseID, c.CaseName, "", "", "", money(c.Estimated), money(c.Billed), money(c.Diff), report.Currency})
+	}
+	for _, item := range report.Untracked {
+		w.Write([]string{"untracked", "", "", item.Name, item.Provider, item.ResourceID, item.Product, "", money(item.Amount), "", report.Currency})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeReconcileTable(out io.Writer, report *redc.ReconcileReport) {
+	fmt.Fprintln(out, i18n.Tf("cost_reconcile_title", report.Month, report.Currency))
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tITEMS\tBILLED\tSTATUS")
+	var unsupported []string
+	for _, p := range report.Providers {
+		status := "ok"
+		if p.Unsupported {
+			unsupported = append(unsupported, p.Provider)
+			status = "unsupported"
+		} else if p.Error != "" {
+			status = p.Error
+			if len(status) > 80 {
+				status = status[:80] + "..."
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%s\n", p.Provider, p.Items, p.Billed, status)
+	}
+	w.Flush()
+	if len(unsupported) > 0 {
+		fmt.Fprintln(out, i18n.Tf("cost_reconcile_unsupported", strings.Join(unsupported, ", ")))
+	}
+
+	if len(report.Cases) > 0 {
+		fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "PROJECT\tCASE\tESTIMATED\tBILLED\tDIFF")
+		for _, c := range report.Cases {
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%+.2f\n", c.ProjectID, c.CaseName, c.Estimated, c.Billed, c.Diff)
+		}
+		w.Flush()
+	}
+
+	if len(report.Untracked) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, i18n.Tf("cost_reconcile_untracked", report.UntrackedTotal, report.Currency, len(report.Untracked)))
+		w = tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tRESOURCE\tNAME\tPRODUCT\tREGION\tBILLED")
+		for _, item := range report.Untracked {
+			id := item.ResourceID
+			if id == "" {
+				id = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.2f\n", item.Provider, id, item.Name, item.Product, item.Region, item.Amount)
+		}
+		w.Flush()
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, i18n.Tf("cost_reconcile_summary", report.BilledTotal, report.TrackedTotal, report.UntrackedTotal, report.EstimatedTotal, report.Currency))
+	writeMissingRates(out, report.MissingRates, report.Currency)
+}
+
+// writeMissingRates notes currencies left out of the totals for lack of an exchange rate
+func writeMissingRates(out io.Writer, missing []string, currency string) {
+	if len(missing) > 0 {
+		fmt.Fprintln(out, i18n.Tf("cost_missing_rates", strings.Join(missing, ", "), currency))
+	}
+}
+
+func writeCostReportCSV(out io.Writer, report *redc.LedgerReport) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"project", "case_id", "case_name", "template", "intervals", "hours", "unpriced_hours", "cost", "currency", "running"})
+	for _, c := range report.Cases {
+		w.Write([]string{
+			c.ProjectID, c.CaseID, c.CaseName, c.Template,
+			strconv.Itoa(c.Intervals),
+			strconv.FormatFloat(c.Hours, 'f', 2, 64),
+			strconv.FormatFloat(c.UnpricedHours, 'f', 2, 64),
+			strconv.FormatFloat(c.Cost, 'f', 2, 64),
+			report.Currency,
+			strconv.FormatBool(c.Running),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeCostReportTable(out io.Writer, report *redc.LedgerReport) {
+	if len(report.Cases) == 0 {
+		fmt.Fprintln(out, i18n.T("cost_report_empty"))
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tCASE\tTEMPLATE\tINTERVALS\tHOURS\tCOST")
+	for _, c := range report.Cases {
+		hours := fmt.Sprintf("%.1f", c.Hours)
+		if c.UnpricedHours > 0 {
+			hours += fmt.Sprintf(" (%.1f unpriced)", c.UnpricedHours)
+		}
+		name := c.CaseName
+		if c.Running {
+			name += " *"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%.2f %s\n", c.ProjectID, name, c.Template, c.Intervals, hours, c.Cost, report.Currency)
+	}
+	fmt.Fprintf(w, "\t%s\t\t\t%.1f\t%.2f %s\n", i18n.T("cost_report_total"), report.TotalHours, report.TotalCost, report.Currency)
+	w.Flush()
+	if report.UnpricedHours > 0 {
+		fmt.Fprintln(out, i18n.Tf("cost_report_unpriced", report.UnpricedHours))
+	}
+	writeMissingRates(out, report.MissingRates, report.Currency)
+}
+
+var costCatalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: i18n.T("cost_catalog_short"),
+	Long:  i18n.T("cost_catalog_long"),
+}
+
+var costCatalogImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: i18n.T("cost_catalog_import_short"),
+	Example: `  redc cost catalog import pricing-2026.10.json
+  redc cost catalog import mod/cost/providers/pricing_fallback.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		info, err := cost.ImportPricingCatalog(redc.PricingCatalogDir(), args[0])
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		if IsJSON() {
+			PrintJSON(info)
+			return
+		}
+		fmt.Println(i18n.Tf("cost_catalog_imported", info.Name, info.Entries, info.EffectiveDate))
+	},
+}
+
+var costCatalogExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: i18n.T("cost_catalog_export_short"),
+	Long:  i18n.T("cost_catalog_export_long"),
+	Example: `  redc cost catalog export pricing-2026.10.json --version 2026.10
+  redc cost catalog export > pricing.json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pricingService := cost.NewPricingService(filepath.Join(redc.RedcPath, "pricing_cache.db"))
+		defer pricingService.Close()
+		catalog, err := cost.ExportPricingCatalog(pricingService, redc.PricingCatalogDir(), catalogVersion)
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		if len(args) == 0 {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			MustJSON(enc.Encode(catalog))
+			return
+		}
+		if err := cost.WritePricingCatalog(catalog, args[0]); err != nil {
+			MustJSON(err)
+			return
+		}
+		if IsJSON() {
+			PrintJSONMessage(i18n.Tf("cost_catalog_exported", catalog.Entries(), args[0]))
+			return
+		}
+		fmt.Println(i18n.Tf("cost_catalog_exported", catalog.Entries(), args[0]))
+	},
+}
+
+var costCatalogListCmd = &cobra.Command{
+	Use:   "list",
+	Short: i18n.T("cost_catalog_list_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		infos, err := cost.ListPricingCatalogs(redc.PricingCatalogDir())
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		if IsJSON() {
+			PrintJSON(infos)
+			return
+		}
+		if len(infos) == 0 {
+			fmt.Println(i18n.T("cost_catalog_none"))
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tEFFECTIVE\tENTRIES\tSOURCE")
+		for _, info := range infos {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", info.Name, info.Version, info.EffectiveDate, info.Entries, info.Source)
+		}
+		w.Flush()
+	},
+}
+
+var costCatalogDiffCmd = &cobra.Command{
+	Use:   "diff [old] [new]",
+	Short: i18n.T("cost_catalog_diff_short"),
+	Long:  i18n.T("cost_catalog_diff_long"),
+	Example: `  redc cost catalog diff
+  redc cost catalog diff 2026-07-01_2026.07
+  redc cost catalog diff old.json new.json`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := redc.PricingCatalogDir()
+		refs := args
+		if len(refs) < 2 {
+			infos, err := cost.ListPricingCatalogs(dir)
+			if err != nil {
+				MustJSON(err)
+				return
+			}
+			// 缺省时与最新安装的目录比较；不带参数时比较最新的两个目录
+			switch {
+			case len(refs) == 1 && len(infos) >= 1:
+				refs = []string{refs[0], infos[0].Path}
+			case len(refs) == 0 && len(infos) >= 2:
+				refs = []string{infos[1].Path, infos[0].Path}
+			default:
+				MustJSON(fmt.Errorf("%s", i18n.T("cost_catalog_diff_need_two")))
+				return
+			}
+		}
+		oldCatalog, err := cost.ResolvePricingCatalog(dir, refs[0])
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		newCatalog, err := cost.ResolvePricingCatalog(dir, refs[1])
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		diff := cost.DiffPricingCatalogs(oldCatalog, newCatalog)
+		if IsJSON() {
+			PrintJSON(diff)
+			return
+		}
+		fmt.Println(i18n.Tf("cost_catalog_diff_title", oldCatalog.Version, oldCatalog.EffectiveDate, newCatalog.Version, newCatalog.EffectiveDate))
+		if len(diff) == 0 {
+			fmt.Println(i18n.T("cost_catalog_diff_none"))
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "CHANGE\tPROVIDER\tREGION\tTYPE\tOLD/HR\tNEW/HR\tSPOT OLD/HR\tSPOT NEW/HR\tCURRENCY")
+		price := func(v float64) string {
+			if v == 0 {
+				return "-"
+			}
+			return strconv.FormatFloat(v, 'f', 4, 64)
+		}
+		for _, d := range diff {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.Change, d.Provider, d.Region, d.ResourceType,
+				price(d.OldHourly), price(d.NewHourly), price(d.OldSpot), price(d.NewSpot), d.Currency)
+		}
+		w.Flush()
+	},
+}
+
+var costRatesCmd = &cobra.Command{
+	Use:   "rates [currency]",
+	Short: i18n.T("cost_rates_short"),
+	Long:  i18n.T("cost_rates_long"),
+	Example: `  redc cost rates
+  redc cost rates CNY -o json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := ""
+		if len(args) > 0 {
+			target = args[0]
+		}
+		settings := redc.ActiveCurrencySettings()
+		target, rates := redc.ExchangeRatesTo(target)
+		if IsJSON() {
+			PrintJSON(map[string]interface{}{"settings": settings, "currency": target, "rates": rates})
+			return
+		}
+		display, source := settings.DisplayCurrency, settings.RateSource
+		if display == "" {
+			display = "-"
+		}
+		if source == "" {
+			source = cost.RateSourceDefault
+		}
+		fmt.Println(i18n.Tf("cost_rates_title", display, source))
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "FROM\tTO\tRATE\tSOURCE\tDATE")
+		for _, r := range rates {
+			date := "-"
+			if !r.Timestamp.IsZero() {
+				date = r.Timestamp.Format("2006-01-02")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.6g\t%s\t%s\n", r.From, r.To, r.Rate, r.Source, date)
+		}
+		w.Flush()
+	},
+}
+
+var (
+	ratesDisplay        string
+	ratesSource         string
+	ratesFile           string
+	ratesURL            string
+	ratesCacheHours     int
+	ratesOverrides      []string
+	ratesClearOverrides bool
+)
+
+var costRatesSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: i18n.T("cost_rates_set_short"),
+	Example: `  redc cost rates set --display CNY --source ecb
+  redc cost rates set --source static --rate-file rates.json
+  redc cost rates set --override USD_CNY=7.1 --override EUR_CNY=7.8`,
+	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := redc.GetActiveProfile()
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		settings := redc.CurrencySettings{}
+		if profile.Currency != nil {
+			settings = *profile.Currency
+		}
+		flags := cmd.Flags()
+		if flags.Changed("display") {
+			settings.DisplayCurrency = ratesDisplay
+		}
+		if flags.Changed("source") {
+			settings.RateSource = ratesSource
+		}
+		if flags.Changed("rate-file") {
+			settings.RateFile = ratesFile
+		}
+		if flags.Changed("rate-url") {
+			settings.RateURL = ratesURL
+		}
+		if flags.Changed("cache-hours") {
+			settings.CacheHours = ratesCacheHours
+		}
+		if ratesClearOverrides {
+			settings.Overrides = nil
+		}
+		for _, o := range ratesOverrides {
+			pair, value, ok := strings.Cut(o, "=")
+			rate, err := strconv.ParseFloat(value, 64)
+			if !ok || err != nil {
+				MustJSON(fmt.Errorf("%s", i18n.Tf("cost_rates_override_invalid", o)))
+				return
+			}
+			if settings.Overrides == nil {
+				settings.Overrides = make(map[string]float64)
+			}
+			settings.Overrides[strings.ToUpper(pair)] = rate
+		}
+		if err := redc.UpdateProfileCurrency(profile.ID, &settings); err != nil {
+			MustJSON(err)
+			return
+		}
+		if IsJSON() {
+			PrintJSONMessage(i18n.Tf("cost_rates_saved", profile.Name))
+			return
+		}
+		fmt.Println(i18n.Tf("cost_rates_saved", profile.Name))
+	},
+}
+
+var (
+	costPricingOnce    sync.Once
+	costPricingService *cost.PricingService
+	costCalculator     *cost.CostCalculator
+)
+
+// costPricing 返回命令行共用的价格服务与费用计算器，第一次使用时才初始化
+func costPricing() (*cost.PricingService, *cost.CostCalculator) {
+	costPricingOnce.Do(func() {
+		costPricingService = cost.NewPricingService(filepath.Join(redc.RedcPath, "pricing_cache.db"))
+		costPricingService.SetCredentialProvider(redc.CostCredentialProvider)
+		if _, err := cost.InitializePricingCatalogs(costPricingService, redc.PricingCatalogDir()); err != nil {
+			gologger.Warning().Msgf("%v", err)
+		}
+		cost.SetGlobalCredentialProvider(redc.CostCredentialProvider)
+		costCalculator = cost.NewCostCalculator()
+	})
+	return costPricingService, costCalculator
+}
+
+// casePricer 返回费用台账使用的价格查询，价格服务在第一次使用时才初始化
+func casePricer() redc.LedgerPricer {
+	return func(c *redc.Case) (float64, string, error) {
+		pricingService, calculator := costPricing()
+		return redc.CaseHourlyCost(c, pricingService, calculator)
+	}
+}
+
+// printCostDiff 打印 plan 的费用变化
+func printCostDiff(diff *cost.CostDiff) {
+	fmt.Println(i18n.Tf("plan_cost_diff_title", diff.Currency, diff.BeforeMonthlyCost, diff.AfterMonthlyCost, signedCost(diff.DeltaMonthlyCost)))
+	if len(diff.Changes) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tADDRESS\tBEFORE/MO\tAFTER/MO\tDELTA/MO")
+	for _, c := range diff.Changes {
+		if !c.Available {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t%s\n", c.Action, c.Address, i18n.T("plan_cost_unavailable"))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%s\n", c.Action, c.Address, c.BeforeMonthly, c.AfterMonthly, signedCost(c.DeltaMonthly))
+	}
+	w.Flush()
+}
+
+// signedCost 格式化带符号的费用差额
+func signedCost(v float64) string {
+	if v > 0 {
+		return fmt.Sprintf("+%.2f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func init() {
+	costReportCmd.Flags().StringVar(&costFrom, "from", "", i18n.T("flag_cost_from"))
+	costReportCmd.Flags().StringVar(&costTo, "to", "", i18n.T("flag_cost_to"))
+	costReportCmd.Flags().StringVar(&costFormat, "format", "table", i18n.T("flag_cost_format"))
+	costReportCmd.Flags().StringVar(&costFile, "file", "", i18n.T("flag_cost_file"))
+	costReportCmd.Flags().StringVar(&costCurrency, "currency", "", i18n.T("flag_cost_currency"))
+	costReportCmd.Flags().BoolVar(&costAllProjects, "all-projects", false, i18n.T("flag_cost_all_projects"))
+	costReconcileCmd.Flags().StringVar(&costMonth, "month", "", i18n.T("flag_cost_month"))
+	costReconcileCmd.Flags().StringVar(&costProviders, "providers", "", i18n.Tf("flag_cost_providers", strings.Join(redc.BillingProviders(), ",")))
+	costReconcileCmd.Flags().StringVar(&costFormat, "format", "table", i18n.T("flag_cost_format"))
+	costReconcileCmd.Flags().StringVar(&costFile, "file", "", i18n.T("flag_cost_file"))
+	costReconcileCmd.Flags().StringVar(&costCurrency, "currency", "", i18n.T("flag_cost_currency"))
+	costCatalogExportCmd.Flags().StringVar(&catalogVersion, "version", "", i18n.T("flag_cost_catalog_version"))
+	costCatalogCmd.AddCommand(costCatalogImportCmd, costCatalogExportCmd, costCatalogListCmd, costCatalogDiffCmd)
+	costRatesSetCmd.Flags().StringVar(&ratesDisplay, "display", "", i18n.T("flag_cost_rates_display"))
+	costRatesSetCmd.Flags().StringVar(&ratesSource, "source", "", i18n.T("flag_cost_rates_source"))
+	costRatesSetCmd.Flags().StringVar(&ratesFile, "rate-file", "", i18n.T("flag_cost_rates_file"))
+	costRatesSetCmd.Flags().StringVar(&ratesURL, "rate-url", "", i18n.T("flag_cost_rates_url"))
+	costRatesSetCmd.Flags().IntVar(&ratesCacheHours, "cache-hours", 0, i18n.T("flag_cost_rates_cache_hours"))
+	costRatesSetCmd.Flags().StringArrayVar(&ratesOverrides, "override", nil, i18n.T("flag_cost_rates_override"))
+	costRatesSetCmd.Flags().BoolVar(&ratesClearOverrides, "clear-overrides", false, i18n.T("flag_cost_rates_clear_overrides"))
+	costRatesCmd.AddCommand(costRatesSetCmd)
+	costCmd.AddCommand(costReportCmd, costReconcileCmd, costCatalogCmd, costRatesCmd)
+	rootCmd.AddCommand(costCmd)
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var daemonPIDFile string
+
+// The daemon itself lives in the GUI/server binary (it needs the App, spot monitor
+// and HTTP server). `redc daemon` runs `redc-gui daemon` in the foreground and
+// forwards its flags; status and stop inspect and stop it.
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: i18n.T("daemon_short"),
+	Long:  i18n.T("daemon_long"),
+	Example: `  redc daemon --host=127.0.0.1 --port=8899 --token=<token>
+  redc daemon status
+  redc daemon stop`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		bin, err := daemonBinary()
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		daemonArgs := []string{"daemon"}
+		for _, name := range []string{"host", "port", "token", "no-http", "pid-file", "spot-interval", "health-interval"} {
+			if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+				daemonArgs = append(daemonArgs, fmt.Sprintf("--%s=%s", name, f.Value.String()))
+			}
+		}
+		child := exec.Command(bin, daemonArgs...)
+		child.Stdin, child.Stdout, child.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := child.Start(); err != nil {
+			MustJSON(err)
+			return
+		}
+		// Forward stop signals so the daemon can finish in-flight operations
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			for sig := range sigs {
+				child.Process.Signal(sig)
+			}
+		}()
+		err = child.Wait()
+		signal.Stop(sigs)
+		if err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				os.Exit(exitErr.ExitCode())
+			}
+			MustJSON(err)
+		}
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: i18n.T("daemon_status_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		pid, running := daemonPID()
+		result := map[string]interface{}{"running": running}
+		if running {
+			result["pid"] = pid
+			// The state file is shared, only trust it when it belongs to this PID
+			if state, err := redc.LoadDaemonState(); err == nil && state.PID == pid {
+				result["startedAt"] = state.StartedAt
+				result["uptime"] = time.Since(state.StartedAt).Round(time.Second).String()
+				result["project"] = state.Project
+				result["version"] = state.Version
+				if state.HealthURL != "" {
+					result["health"] = fetchDaemonHealth(state.HealthURL)
+				}
+			}
+		}
+
+		if IsJSON() {
+			PrintJSON(result)
+			return
+		}
+		if !running {
+			fmt.Println(i18n.T("daemon_not_running"))
+			return
+		}
+		fmt.Println(i18n.Tf("daemon_running", pid))
+		if v, ok := result["project"]; ok {
+			fmt.Printf("  project:   %v\n", v)
+			fmt.Printf("  version:   %v\n", result["version"])
+			fmt.Printf("  started:   %v\n", result["startedAt"].(time.Time).Format("2006-01-02 15:04:05"))
+			fmt.Printf("  uptime:    %v\n", result["uptime"])
+		}
+		if h, ok := result["health"].(map[string]interface{}); ok {
+			fmt.Printf("  health:    %v\n", h["status"])
+		}
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: i18n.T("daemon_stop_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		pid, running := daemonPID()
+		if !running {
+			MustJSON(fmt.Errorf("%s", i18n.T("daemon_not_running")))
+			return
+		}
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		// SIGTERM triggers graceful shutdown; Windows has no signals, fall back to kill
+		if err := p.Signal(syscall.SIGTERM); err != nil {
+			if err := p.Kill(); err != nil {
+				MustJSON(err)
+				return
+			}
+		}
+		if IsJSON() {
+			PrintJSON(map[string]interface{}{"pid": pid, "stopping": true})
+			return
+		}
+		fmt.Println(i18n.Tf("daemon_stop_sent", pid))
+	},
+}
+
+// daemonPID returns the PID recorded in the daemon lock file (--pid-file or the default) and whether it is alive
+func daemonPID() (int, bool) {
+	path := daemonPIDFile
+	if path == "" {
+		path = redc.DaemonPIDPath()
+	}
+	pid, err := redc.ReadDaemonPID(path)
+	if err != nil {
+		return 0, false
+	}
+	return pid, redc.ProcessAlive(pid)
+}
+
+// daemonBinary finds the GUI/server binary next to redc, then in PATH
+func daemonBinary() (string, error) {
+	name := "redc-gui"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if exe, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(exe), name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("%s", i18n.Tf("daemon_binary_not_found", name))
+}
+
+func fetchDaemonHealth(url string) interface{} {
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return map[string]interface{}{"status": "unreachable", "error": err.Error()}
+	}
+	defer resp.Body.Close()
+	var health map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return map[string]interface{}{"status": "unknown", "error": err.Error()}
+	}
+	return health
+}
+
+func init() {
+	daemonCmd.PersistentFlags().StringVar(&daemonPIDFile, "pid-file", "", i18n.T("flag_daemon_pid_file"))
+	daemonCmd.Flags().String("host", "127.0.0.1", i18n.T("flag_daemon_host"))
+	daemonCmd.Flags().Int("port", 8899, i18n.T("flag_daemon_port"))
+	daemonCmd.Flags().String("token", "", i18n.T("flag_daemon_token"))
+	daemonCmd.Flags().Bool("no-http", false, i18n.T("flag_daemon_no_http"))
+	daemonCmd.Flags().Duration("spot-interval", 120*time.Second, i18n.T("flag_daemon_spot_interval"))
+	daemonCmd.Flags().Duration("health-interval", 0, i18n.T("flag_daemon_health_interval"))
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonStopCmd)
+}
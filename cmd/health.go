@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	healthCheck bool
+	healthAll   bool
+)
+
+var healthCmd = &cobra.Command{
+	Use:   "health [case]",
+	Short: i18n.T("health_short"),
+	Long:  i18n.T("health_long"),
+	Example: `  redc health
+  redc health --check
+  redc health web --check -o json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var cases []*redc.Case
+		all := healthAll
+		if len(args) == 1 {
+			c, err := redcProject.GetCase(args[0])
+			if err != nil {
+				MustJSON(fmt.Errorf("%s", i18n.Tf("action_case_not_found", args[0], err)))
+				return
+			}
+			cases = []*redc.Case{c}
+			all = true
+		} else {
+			list, err := redc.LoadProjectCases(redc.Project)
+			if err != nil {
+				MustJSON(err)
+				return
+			}
+			cases = list
+		}
+
+		if healthCheck {
+			for _, c := range cases {
+				if !redc.IsRunningState(c.State) {
+					continue
+				}
+				if !IsJSON() {
+					gologger.Info().Msgf("%s", i18n.Tf("health_checking", c.Name))
+				}
+				if _, _, err := redc.CheckCaseHealth(context.Background(), c); err != nil {
+					gologger.Warning().Msgf("%s: %v", c.Name, err)
+				}
+			}
+		}
+
+		report := redc.HealthReport(cases, all)
+		if IsJSON() {
+			if report == nil {
+				report = []redc.CaseHealth{}
+			}
+			PrintJSON(report)
+			return
+		}
+		if len(report) == 0 {
+			fmt.Println(i18n.T("health_none"))
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSTATE\tHEALTH\tUPTIME 24H\tUPTIME 7D\tFAILURES\tLAST CHECK\tLAST ERROR")
+		for _, h := range report {
+			id := h.CaseID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			lastCheck := "-"
+			if !h.LastCheck.IsZero() {
+				lastCheck = h.LastCheck.Format("2006-01-02 15:04:05")
+			}
+			lastErr := h.LastError
+			if len(lastErr) > 60 {
+				lastErr = lastErr[:60] + "..."
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n", id, h.Name, h.State, h.Status,
+				uptimeLabel(h.Uptime24h), uptimeLabel(h.Uptime7d), h.ConsecutiveFailures, lastCheck, lastErr)
+		}
+		w.Flush()
+	},
+}
+
+func uptimeLabel(v float64) string {
+	if v < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", v)
+}
+
+func init() {
+	healthCmd.Flags().BoolVar(&healthCheck, "check", false, i18n.T("flag_health_check"))
+	healthCmd.Flags().BoolVarP(&healthAll, "all", "a", false, i18n.T("flag_health_all"))
+	rootCmd.AddCommand(healthCmd)
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"red-cloud/mod/gologger"
 	"red-cloud/mod/mcp"
+	"red-cloud/mod/plugin"
 
 	"github.com/spf13/cobra"
 )
@@ -25,6 +27,7 @@ The server reads JSON-RPC requests from stdin and writes responses to stdout.
 This mode is suitable for integration with AI assistants and tools.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		manager := mcp.NewMCPServerManager(redcProject, nil)
+		manager.GetMCPServer().SetPluginManager(loadedPluginManager())
 		if err := manager.Start(mcp.TransportSTDIO, ""); err != nil {
 			return
 		}
@@ -50,6 +53,7 @@ Example:
 		}
 
 		manager := mcp.NewMCPServerManager(redcProject, nil)
+		manager.GetMCPServer().SetPluginManager(loadedPluginManager())
 		if err := manager.Start(mcp.TransportSSE, addr); err != nil {
 			return
 		}
@@ -58,6 +62,15 @@ Example:
 	},
 }
 
+// loadedPluginManager returns the default plugin manager with installed plugins loaded
+func loadedPluginManager() *plugin.PluginManager {
+	pm := plugin.NewPluginManager("")
+	if err := pm.LoadAll(); err != nil {
+		gologger.Warning().Msgf("plugin: %v", err)
+	}
+	return pm
+}
+
 func init() {
 	rootCmd.AddCommand(mcpCmd)
 	mcpCmd.AddCommand(mcpStdioCmd)
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	orphansProviders string
+	orphansRegions   string
+	orphansCleanup   bool
+	orphansYes       bool
+)
+
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: i18n.T("orphans_short"),
+}
+
+var orphansScanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: i18n.T("orphans_scan_short"),
+	Long:  i18n.T("orphans_scan_long"),
+	Example: `  redc orphans scan
+  redc orphans scan --providers aws --regions us-east-1,ap-east-1
+  redc orphans scan --cleanup
+  redc orphans scan --cleanup --yes -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if orphansCleanup && !orphansYes && IsJSON() {
+			MustJSON(fmt.Errorf("%s", i18n.T("orphans_yes_required")))
+			return
+		}
+		providers := splitList(orphansProviders)
+		if len(providers) == 0 {
+			providers = redc.OrphanProviders()
+		}
+		regions := splitList(orphansRegions)
+
+		var inventories []redc.CloudInventory
+		var failed []redc.OrphanProvider
+		for _, p := range providers {
+			inv, err := redc.NewCloudInventory(p, regions)
+			if err != nil {
+				failed = append(failed, redc.OrphanProvider{Provider: p, Error: err.Error()})
+				continue
+			}
+			inventories = append(inventories, inv)
+		}
+		known, err := redc.LoadKnownCases()
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+
+		ctx := context.Background()
+		report := redc.ScanOrphans(ctx, inventories, known)
+		report.Providers = append(report.Providers, failed...)
+		if !orphansCleanup {
+			if IsJSON() {
+				PrintJSON(report)
+				return
+			}
+			writeOrphansTable(report)
+			return
+		}
+
+		if !IsJSON() {
+			writeOrphansTable(report)
+			if len(report.Orphans) == 0 {
+				return
+			}
+		}
+		var confirm func(redc.OrphanResource) bool
+		if !orphansYes {
+			reader := bufio.NewReader(os.Stdin)
+			confirm = func(o redc.OrphanResource) bool {
+				if o.Deletable {
+					fmt.Print(i18n.Tf("orphans_confirm", o.Provider, o.Type, o.ID, o.Name, o.Region))
+				} else {
+					fmt.Print(i18n.Tf("orphans_confirm_foreign", o.Provider, o.Type, o.ID, o.Name, o.Region))
+				}
+				answer, _ := reader.ReadString('\n')
+				answer = strings.ToLower(strings.TrimSpace(answer))
+				return answer == "y" || answer == "yes"
+			}
+		}
+		results := redc.CleanupOrphans(ctx, inventories, report.Orphans, confirm)
+		if IsJSON() {
+			PrintJSON(results)
+			return
+		}
+		deleted := 0
+		for _, r := range results {
+			switch {
+			case r.Deleted:
+				deleted++
+				gologger.Info().Msgf("%s", i18n.Tf("orphans_deleted", r.Resource.Provider, r.Resource.ID))
+			case r.Error != "":
+				gologger.Error().Msgf("%s", i18n.Tf("orphans_delete_failed", r.Resource.Provider, r.Resource.ID, r.Error))
+			case r.Skipped && orphansYes:
+				gologger.Warning().Msgf("%s", i18n.Tf("orphans_skipped_foreign", r.Resource.Provider, r.Resource.ID))
+			}
+		}
+		fmt.Println(i18n.Tf("orphans_cleanup_summary", deleted, len(results)))
+	},
+}
+
+func writeOrphansTable(report *redc.OrphanReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tSCANNED\tORPHANS\tSTATUS")
+	for _, p := range report.Providers {
+		status := "ok"
+		if p.Error != "" {
+			status = p.Error
+			if len(status) > 80 {
+				status = status[:80] + "..."
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", p.Provider, p.Scanned, p.Orphans, status)
+	}
+	w.Flush()
+
+	fmt.Println()
+	if len(report.Orphans) == 0 {
+		fmt.Println(i18n.T("orphans_none"))
+		return
+	}
+	w = tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tREGION\tID\tNAME\tSTATE\tCREATED\tREASON\tCASE")
+	for _, o := range report.Orphans {
+		caseID := o.CaseID
+		if len(caseID) > 12 {
+			caseID = caseID[:12]
+		}
+		if caseID == "" {
+			caseID = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", o.Provider, o.Region, o.ID, o.Name, o.State, o.CreatedAt, o.Reason, caseID)
+	}
+	w.Flush()
+	fmt.Println(i18n.Tf("orphans_found", len(report.Orphans)))
+	unverified := 0
+	for _, o := range report.Orphans {
+		if !o.Deletable {
+			unverified++
+		}
+	}
+	if unverified > 0 {
+		fmt.Println(i18n.Tf("orphans_unverified", unverified))
+	}
+}
+
+// splitList 解析逗号分隔的参数
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func init() {
+	orphansScanCmd.Flags().StringVar(&orphansProviders, "providers", "", i18n.Tf("flag_orphans_providers", strings.Join(redc.OrphanProviders(), ",")))
+	orphansScanCmd.Flags().StringVar(&orphansRegions, "regions", "", i18n.T("flag_orphans_regions"))
+	orphansScanCmd.Flags().BoolVar(&orphansCleanup, "cleanup", false, i18n.T("flag_orphans_cleanup"))
+	orphansScanCmd.Flags().BoolVarP(&orphansYes, "yes", "y", false, i18n.T("flag_orphans_yes"))
+	orphansCmd.AddCommand(orphansScanCmd)
+	rootCmd.AddCommand(orphansCmd)
+}
@@ -24,7 +24,20 @@ var runCmd = &cobra.Command{
 	Args:    cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		templateName := args[0]
+		if err := validatePolicyFlags(); err != nil {
+			if IsJSON() {
+				PrintJSONError(err)
+				return
+			}
+			gologger.Error().Msgf("%s", i18n.Tf("policy_save_failed", err))
+			return
+		}
 		if c, err := planLogic(templateName); err == nil {
+			// 先构建策略，首次 apply 的资源标签即带有 TTL 到期时间；策略在 apply 成功后保存
+			policy, _ := policyFromFlags(redc.PolicyKindCase, c.Id, c.Name, []string{c.Id})
+			if policy != nil {
+				c.SetExpiresAt(policy.ExpiresAt)
+			}
 			if err := c.TfApply(); err != nil {
 				if IsJSON() {
 					PrintJSONError(err)
@@ -33,6 +46,9 @@ var runCmd = &cobra.Command{
 				gologger.Error().Msgf(i18n.Tf("scene_start_failed", err.Error()))
 				return
 			}
+			if err := savePolicy(policy); err != nil {
+				gologger.Error().Msgf("%s", i18n.Tf("policy_save_failed", err))
+			}
 			if IsJSON() {
 				PrintJSON(map[string]interface{}{
 					"action": "run",
@@ -58,15 +74,28 @@ var planCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		templateName := args[0]
 		if c, err := planLogic(templateName); err == nil {
+			// 费用变化仅供参考，估算失败不影响 plan 结果
+			pricingService, calculator := costPricing()
+			diff, diffErr := redc.PlanCostDiff(c.Path, pricingService, calculator)
+			if diffErr != nil {
+				gologger.Debug().Msgf("%s", i18n.Tf("plan_cost_diff_failed", diffErr))
+			}
 			if IsJSON() {
-				PrintJSON(map[string]interface{}{
+				result := map[string]interface{}{
 					"action": "plan",
 					"name":   c.Name,
 					"id":     c.Id,
-				})
+				}
+				if diff != nil {
+					result["cost_diff"] = diff
+				}
+				PrintJSON(result)
 				return
 			}
 			gologger.Info().Msgf(i18n.Tf("scene_plan_done", c.Name, c.Id))
+			if diff != nil {
+				printCostDiff(diff)
+			}
 		}
 	},
 }
@@ -103,4 +132,5 @@ func init() {
 	CRCommonFlagSet.StringToStringVarP(&envVars, "env", "e", nil, i18n.T("flag_plan_env"))
 	planCmd.Flags().AddFlagSet(CRCommonFlagSet)
 	runCmd.Flags().AddFlagSet(CRCommonFlagSet)
+	runCmd.Flags().AddFlagSet(policyFlagSet())
 }
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"os"
 	"red-cloud/i18n"
+	"red-cloud/mod/gologger"
 	"red-cloud/mod/plugin"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -36,7 +41,11 @@ var pluginListCmd = &cobra.Command{
 		}
 		for _, p := range plugins {
 			status := "enabled"
-			if !p.Enabled {
+			if p.IntegrityError != "" {
+				status = "tampered"
+			} else if p.DependencyError != "" {
+				status = "unmet dependencies"
+			} else if !p.Enabled {
 				status = "disabled"
 			}
 			fmt.Printf("  %s  v%s  [%s]  %s\n", p.Manifest.Name, p.Manifest.Version, status, p.Manifest.Description)
@@ -50,21 +59,56 @@ var pluginInstallCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		pm := plugin.NewPluginManager("")
+		_ = pm.LoadAll()
 		name, err := pm.Install(args[0])
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
 		fmt.Printf("Plugin %s installed successfully.\n", name)
+		promptPluginCredentials(pm, name)
 	},
 }
 
+// promptPluginCredentials asks whether hook scripts of a newly installed, updated or re-pinned
+// plugin may read the credentials it requests and records the answer. Approvals are bound to
+// the plugin content, so changed code has to be approved again.
+func promptPluginCredentials(pm *plugin.PluginManager, name string) {
+	pending := pm.PendingCredentials(name)
+	if len(pending) == 0 {
+		return
+	}
+	if IsJSON() || pluginInstallYes {
+		if pluginInstallYes {
+			approvePluginCredentials(pm, name)
+		}
+		return
+	}
+	fmt.Print(i18n.Tf("plugin_credentials_confirm", name, strings.Join(pending, ", ")))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "y" || answer == "yes" {
+		approvePluginCredentials(pm, name)
+		return
+	}
+	fmt.Println(i18n.Tf("plugin_credentials_withheld", name))
+}
+
+func approvePluginCredentials(pm *plugin.PluginManager, name string) {
+	if err := pm.ApproveCredentials(name); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("Plugin %s may read: %s\n", name, strings.Join(pm.Approvals()[name].Credentials, ", "))
+}
+
 var pluginUninstallCmd = &cobra.Command{
 	Use:   "uninstall <name>",
 	Short: i18n.T("plugin_uninstall_short"),
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		pm := plugin.NewPluginManager("")
+		_ = pm.LoadAll()
 		if err := pm.Uninstall(args[0]); err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
@@ -79,6 +123,7 @@ var pluginEnableCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		pm := plugin.NewPluginManager("")
+		_ = pm.LoadAll()
 		if err := pm.Enable(args[0]); err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
@@ -93,6 +138,7 @@ var pluginDisableCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		pm := plugin.NewPluginManager("")
+		_ = pm.LoadAll()
 		if err := pm.Disable(args[0]); err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
@@ -107,12 +153,14 @@ var pluginUpdateCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		pm := plugin.NewPluginManager("")
+		_ = pm.LoadAll()
 		msg, err := pm.Update(args[0])
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
 		fmt.Printf("Plugin %s updated: %s\n", args[0], msg)
+		promptPluginCredentials(pm, args[0])
 	},
 }
 
@@ -152,6 +200,328 @@ var pluginInfoCmd = &cobra.Command{
 		if len(p.Manifest.Tags) > 0 {
 			fmt.Printf("Tags:        %v\n", p.Manifest.Tags)
 		}
+		if entry, ok := pm.Lock().Plugins[p.Manifest.Name]; ok {
+			fmt.Printf("SHA256:      %s\n", entry.SHA256)
+			if entry.SignedBy != "" {
+				fmt.Printf("Signed by:   %s\n", entry.SignedBy)
+			}
+		}
+		if p.IntegrityError != "" {
+			fmt.Printf("Integrity:   %s\n", p.IntegrityError)
+		}
+		if p.Manifest.RedcVersion != "" {
+			fmt.Printf("Requires:    redc %s\n", p.Manifest.RedcVersion)
+		}
+		if len(p.Manifest.Dependencies) > 0 {
+			deps := make([]string, 0, len(p.Manifest.Dependencies))
+			for dep, constraint := range p.Manifest.Dependencies {
+				deps = append(deps, strings.TrimSpace(dep+" "+constraint))
+			}
+			sort.Strings(deps)
+			fmt.Printf("Depends on:  %s\n", strings.Join(deps, ", "))
+		}
+		if p.DependencyError != "" {
+			fmt.Printf("Unmet:       %s\n", p.DependencyError)
+		}
+		if creds := p.Manifest.Capabilities.Credentials; len(creds) > 0 {
+			fmt.Printf("Credentials: %s\n", strings.Join(creds, ", "))
+			if pending := pm.PendingCredentials(p.Manifest.Name); len(pending) > 0 {
+				fmt.Printf("Not approved: %s\n", strings.Join(pending, ", "))
+			}
+		}
+	},
+}
+
+var pluginConfigCmd = &cobra.Command{
+	Use:   "config <name> [set k=v... | unset k...]",
+	Short: i18n.T("plugin_config_short"),
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pm := plugin.NewPluginManager("")
+		_ = pm.LoadAll()
+		name := args[0]
+		config, err := pm.ConfigView(name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if len(args) == 1 {
+			printPluginConfig(pm, name, config)
+			return
+		}
+		switch args[1] {
+		case "set":
+			if len(args) < 3 {
+				fmt.Println("Error: expected k=v")
+				return
+			}
+			for _, kv := range args[2:] {
+				k, v, ok := strings.Cut(kv, "=")
+				if !ok || k == "" {
+					fmt.Printf("Error: invalid assignment %q, expected k=v\n", kv)
+					return
+				}
+				config[k] = v
+			}
+		case "unset":
+			if len(args) < 3 {
+				fmt.Println("Error: expected field names")
+				return
+			}
+			for _, k := range args[2:] {
+				config[k] = ""
+			}
+		default:
+			fmt.Printf("Error: unknown action %q, expected set or unset\n", args[1])
+			return
+		}
+		if err := pm.SaveConfig(name, config); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		config, _ = pm.ConfigView(name)
+		printPluginConfig(pm, name, config)
+	},
+}
+
+// printPluginConfig prints the schema fields of a plugin with their current values
+func printPluginConfig(pm *plugin.PluginManager, name string, config map[string]interface{}) {
+	if IsJSON() {
+		PrintJSON(config)
+		return
+	}
+	p, _ := pm.Get(name)
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	for k := range p.Manifest.ConfigSchema {
+		if _, ok := config[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		fmt.Printf("Plugin %s has no config.\n", name)
+		return
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		field := p.Manifest.ConfigSchema[k]
+		value, ok := config[k]
+		switch {
+		case ok:
+			fmt.Printf("  %s = %v", k, value)
+		case field.Default != "":
+			fmt.Printf("  %s = %s (default)", k, field.Default)
+		default:
+			fmt.Printf("  %s =", k)
+		}
+		if field.Required {
+			fmt.Print("  [required]")
+		}
+		if field.Secret {
+			fmt.Print("  [secret]")
+		}
+		if field.Description != "" {
+			fmt.Printf("  # %s", field.Description)
+		}
+		fmt.Println()
+	}
+}
+
+var pluginApproveCmd = &cobra.Command{
+	Use:   "approve <name>",
+	Short: i18n.T("plugin_approve_short"),
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pm := plugin.NewPluginManager("")
+		_ = pm.LoadAll()
+		approvePluginCredentials(pm, args[0])
+	},
+}
+
+var pluginRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: i18n.T("plugin_revoke_short"),
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pm := plugin.NewPluginManager("")
+		if err := pm.RevokeCredentials(args[0]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("Credentials of plugin %s revoked.\n", args[0])
+	},
+}
+
+var pluginVerifyAccept bool
+var pluginInstallYes bool
+
+var pluginVerifyCmd = &cobra.Command{
+	Use:   "verify [name...]",
+	Short: i18n.T("plugin_verify_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		pm := plugin.NewPluginManager("")
+		if err := pm.LoadAll(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if pluginVerifyAccept {
+			if len(args) == 0 {
+				fmt.Println("Error: --accept requires plugin names")
+				return
+			}
+			for _, name := range args {
+				if err := pm.Repin(name); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				fmt.Printf("Plugin %s re-pinned.\n", name)
+				promptPluginCredentials(pm, name)
+			}
+			return
+		}
+		problems := pm.Verify(args...)
+		if IsJSON() {
+			PrintJSON(problems)
+			return
+		}
+		if len(problems) == 0 {
+			fmt.Println("All plugins match the lockfile.")
+			return
+		}
+		for name, reason := range problems {
+			fmt.Printf("  %s: %s\n", name, reason)
+		}
+	},
+}
+
+var pluginTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: i18n.T("plugin_tree_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		pm := plugin.NewPluginManager("")
+		if err := pm.LoadAll(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		roots := pm.DependencyTree()
+		if IsJSON() {
+			PrintJSON(roots)
+			return
+		}
+		if len(roots) == 0 {
+			fmt.Println("No plugins installed.")
+			return
+		}
+		for _, root := range roots {
+			fmt.Println(dependencyLabel(root))
+			printDependencyChildren(root.Children, "")
+		}
+	},
+}
+
+func printDependencyChildren(nodes []*plugin.DependencyNode, indent string) {
+	for i, node := range nodes {
+		branch, next := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Println(indent + branch + dependencyLabel(node))
+		printDependencyChildren(node.Children, indent+next)
+	}
+}
+
+func dependencyLabel(node *plugin.DependencyNode) string {
+	label := node.Name
+	if node.Version != "" {
+		label += " v" + node.Version
+	}
+	if node.Constraint != "" {
+		label += " (" + node.Constraint + ")"
+	}
+	if node.Status != "ok" {
+		label += " [" + node.Status + "]"
+	}
+	return label
+}
+
+var pluginTrustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: i18n.T("plugin_trust_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var pluginTrustListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   i18n.T("plugin_trust_ls_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := plugin.NewPluginManager("").TrustConfig()
+		if IsJSON() {
+			PrintJSON(cfg)
+			return
+		}
+		fmt.Printf("Require signature: %v\n", cfg.RequireSignature)
+		if len(cfg.TrustedKeys) == 0 {
+			fmt.Println("No trusted keys.")
+			return
+		}
+		for _, k := range cfg.TrustedKeys {
+			fmt.Printf("  %s  %s\n", k.Name, k.Key)
+		}
+	},
+}
+
+var pluginTrustAddCmd = &cobra.Command{
+	Use:   "add <name> <public-key>",
+	Short: i18n.T("plugin_trust_add_short"),
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		pm := plugin.NewPluginManager("")
+		if err := pm.AddTrustedKey(plugin.TrustedKey{Name: args[0], Key: args[1]}); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("Key %s trusted.\n", args[0])
+	},
+}
+
+var pluginTrustRemoveCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   i18n.T("plugin_trust_rm_short"),
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pm := plugin.NewPluginManager("")
+		if err := pm.RemoveTrustedKey(args[0]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("Key %s removed.\n", args[0])
+	},
+}
+
+var pluginTrustRequireCmd = &cobra.Command{
+	Use:       "require <on|off>",
+	Short:     i18n.T("plugin_trust_require_short"),
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"on", "off"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if args[0] != "on" && args[0] != "off" {
+			fmt.Println("Error: expected on or off")
+			return
+		}
+		pm := plugin.NewPluginManager("")
+		cfg := pm.TrustConfig()
+		cfg.RequireSignature = args[0] == "on"
+		if err := pm.SaveTrustConfig(cfg); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("Require signature: %v\n", cfg.RequireSignature)
 	},
 }
 
@@ -159,9 +529,69 @@ func init() {
 	rootCmd.AddCommand(pluginCmd)
 	pluginCmd.AddCommand(pluginListCmd)
 	pluginCmd.AddCommand(pluginInstallCmd)
+	pluginInstallCmd.Flags().BoolVarP(&pluginInstallYes, "yes", "y", false, i18n.T("plugin_install_yes"))
 	pluginCmd.AddCommand(pluginUninstallCmd)
 	pluginCmd.AddCommand(pluginEnableCmd)
 	pluginCmd.AddCommand(pluginDisableCmd)
 	pluginCmd.AddCommand(pluginUpdateCmd)
 	pluginCmd.AddCommand(pluginInfoCmd)
+	pluginCmd.AddCommand(pluginConfigCmd)
+	pluginCmd.AddCommand(pluginApproveCmd)
+	pluginCmd.AddCommand(pluginRevokeCmd)
+	pluginCmd.AddCommand(pluginVerifyCmd)
+	pluginCmd.AddCommand(pluginTreeCmd)
+	pluginVerifyCmd.Flags().BoolVar(&pluginVerifyAccept, "accept", false, i18n.T("plugin_verify_accept"))
+	pluginCmd.AddCommand(pluginTrustCmd)
+	pluginTrustCmd.AddCommand(pluginTrustListCmd)
+	pluginTrustCmd.AddCommand(pluginTrustAddCmd)
+	pluginTrustCmd.AddCommand(pluginTrustRemoveCmd)
+	pluginTrustCmd.AddCommand(pluginTrustRequireCmd)
+}
+
+// registerPluginCommands adds `redc <plugin> <command>` for enabled plugins that declare CLI
+// commands. Plugins are only loaded when args do not name a built-in command, and a plugin
+// never shadows one.
+func registerPluginCommands(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return
+	}
+	if c, _, err := rootCmd.Find(args); err == nil && c != rootCmd {
+		return
+	}
+	pm := plugin.NewPluginManager("")
+	if err := pm.LoadAll(); err != nil {
+		return
+	}
+	for name, specs := range pm.PluginCommands() {
+		if c, _, err := rootCmd.Find([]string{name}); err == nil && c != rootCmd {
+			gologger.Warning().Msgf("plugin: %s conflicts with a built-in command, its commands are not added", name)
+			continue
+		}
+		pluginName := name
+		parent := &cobra.Command{
+			Use:   pluginName,
+			Short: i18n.Tf("plugin_commands_short", pluginName),
+			Run: func(cmd *cobra.Command, args []string) {
+				cmd.Help()
+			},
+		}
+		for _, spec := range specs {
+			commandName := spec.Name
+			parent.AddCommand(&cobra.Command{
+				Use:                commandName,
+				Short:              spec.Description,
+				DisableFlagParsing: true,
+				Run: func(cmd *cobra.Command, args []string) {
+					code, err := pm.RunCommand(pluginName, commandName, args, os.Stdin, os.Stdout, os.Stderr)
+					if err != nil {
+						fmt.Printf("Error: %v\n", err)
+					}
+					if code != 0 {
+						os.Exit(code)
+					}
+				},
+			})
+		}
+		rootCmd.AddCommand(parent)
+	}
 }
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"path/filepath"
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	policyTTL     string
+	policyBudget  string
+	policyOnLimit string
+)
+
+// policyFlagSet TTL / 预算相关参数，run 与 compose up 共用
+func policyFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("policy", pflag.ExitOnError)
+	fs.StringVar(&policyTTL, "ttl", "", i18n.T("flag_policy_ttl"))
+	fs.StringVar(&policyBudget, "budget", "", i18n.T("flag_policy_budget"))
+	fs.StringVar(&policyOnLimit, "on-limit", redc.PolicyActionStop, i18n.T("flag_policy_on_limit"))
+	return fs
+}
+
+// openScheduler 打开与 GUI 共用的调度器数据库（不启动调度循环）
+func openScheduler() (*redc.TaskScheduler, error) {
+	s := redc.NewTaskScheduler(redcProject, filepath.Join(redc.RedcPath, "scheduler.db"))
+	if err := s.InitDB(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// validatePolicyFlags 在创建资源前校验 --ttl / --budget / --on-limit
+func validatePolicyFlags() error {
+	if policyTTL == "" && policyBudget == "" {
+		return nil
+	}
+	_, err := redc.NewLifecyclePolicy(redc.PolicyKindCase, "-", "-", "", nil, policyTTL, policyBudget, policyOnLimit)
+	return err
+}
+
+// policyFromFlags 根据 --ttl / --budget 构建生命周期策略，未设置时返回 nil。
+// 在 apply 之前构建，使首次 apply 的资源标签就带有到期时间
+func policyFromFlags(kind, id, name string, caseIDs []string) (*redc.LifecyclePolicy, error) {
+	if policyTTL == "" && policyBudget == "" {
+		return nil, nil
+	}
+	return redc.NewLifecyclePolicy(kind, id, name, redcProject.ProjectName, caseIDs, policyTTL, policyBudget, policyOnLimit)
+}
+
+// savePolicy 在 apply 成功后写入生命周期策略，由 GUI / daemon 调度器执行
+func savePolicy(p *redc.LifecyclePolicy) error {
+	if p == nil {
+		return nil
+	}
+	s, err := openScheduler()
+	if err != nil {
+		return err
+	}
+	defer s.Stop()
+	if err := s.SetPolicy(p); err != nil {
+		return err
+	}
+	if !IsJSON() {
+		gologger.Info().Msgf("%s", i18n.Tf("policy_saved", p.Name, orDash(policyTTL), orDash(policyBudget), p.Action))
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
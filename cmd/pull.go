@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"red-cloud/i18n"
 	"red-cloud/mod"
 	"red-cloud/mod/gologger"
 	"strings"
@@ -18,22 +19,40 @@ var opts struct {
 	// Dir 字段已移除，改为直接绑定 mod.TemplateDir
 	Force   bool
 	Timeout time.Duration
+	Locked  bool
+}
+
+// pullOptions 根据 --registry 构造拉取选项，取值为仓库名称或地址，为空时使用所有配置的仓库
+func pullOptions() mod.PullOptions {
+	o := mod.PullOptions{Force: opts.Force, Timeout: opts.Timeout}
+	o.SetRegistry(opts.Registry)
+	return o
 }
 
 var pullCmd = &cobra.Command{
-	Use:   "pull <image>[:tag]",
+	Use:   "pull <image>[:tag][@registry]",
 	Short: "Pull a template from registry",
-	Args:  cobra.ExactArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if opts.Locked {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		pullOpts := mod.PullOptions{
-			RegistryURL: opts.Registry,
-			Force:       opts.Force,
-			Timeout:     opts.Timeout,
+		pullOpts := pullOptions()
+		pullOpts.LockFile = redcProject.TemplateLockPath()
+		pullOpts.Locked = opts.Locked
+
+		var err error
+		target := "all"
+		if len(args) == 0 {
+			err = mod.PullLocked(cmd.Context(), pullOpts)
+		} else {
+			target = args[0]
+			err = mod.Pull(cmd.Context(), args[0], pullOpts)
 		}
 
-		err := mod.Pull(cmd.Context(), args[0], pullOpts)
-
 		if err != nil {
 			if strings.Contains(err.Error(), "context canceled") {
 				if IsJSON() {
@@ -51,7 +70,7 @@ var pullCmd = &cobra.Command{
 		}
 
 		if IsJSON() {
-			PrintJSON(map[string]string{"template": args[0], "status": "pulled"})
+			PrintJSON(map[string]string{"template": target, "status": "pulled"})
 		}
 		return nil
 	},
@@ -62,12 +81,7 @@ var searchCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		pullOpts := mod.PullOptions{
-			RegistryURL: opts.Registry,
-			Force:       opts.Force,
-			Timeout:     opts.Timeout,
-		}
-		res, err := mod.Search(cmd.Context(), args[0], pullOpts)
+		res, err := mod.Search(cmd.Context(), args[0], pullOptions())
 
 		if err != nil {
 			if strings.Contains(err.Error(), "context canceled") {
@@ -91,13 +105,14 @@ var searchCmd = &cobra.Command{
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 8, 4, ' ', 0)
-		fmt.Fprintln(w, "NAME\tVERSION\tAUTHOR\tDESCRIPTION")
+		fmt.Fprintln(w, "NAME\tVERSION\tREGISTRY\tAUTHOR\tDESCRIPTION")
 
 		for _, item := range res {
 			desc := cleanDescription(item.Description)
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 				item.Key,
 				item.Version,
+				item.Registry,
 				item.Author,
 				desc,
 			)
@@ -127,10 +142,11 @@ func cleanDescription(desc string) string {
 
 func init() {
 	// 绑定 Registry 参数
-	pullCmd.Flags().StringVarP(&opts.Registry, "registry", "r", "https://redc.wgpsec.org", "Registry URL")
+	pullCmd.Flags().StringVarP(&opts.Registry, "registry", "r", "", i18n.T("flag_registry"))
 	pullCmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Force pull (overwrite)")
 	pullCmd.Flags().DurationVar(&opts.Timeout, "timeout", 60*time.Second, "Download timeout")
-	searchCmd.Flags().StringVarP(&opts.Registry, "registry", "r", "https://redc.wgpsec.org", "Registry URL")
+	pullCmd.Flags().BoolVar(&opts.Locked, "locked", false, i18n.T("flag_pull_locked"))
+	searchCmd.Flags().StringVarP(&opts.Registry, "registry", "r", "", i18n.T("flag_registry"))
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(searchCmd)
 }
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	recoveryFallbacks       []string
+	recoveryMaxAttempts     int
+	recoveryCooldown        time.Duration
+	recoveryComposeFile     string
+	recoveryRegionVar       string
+	recoveryInstanceTypeVar string
+)
+
+var recoveryCmd = &cobra.Command{
+	Use:   "recovery",
+	Short: i18n.T("recovery_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var recoverySetCmd = &cobra.Command{
+	Use:   "set [case]",
+	Short: i18n.T("recovery_set_short"),
+	Example: `  redc recovery set web --fallback region=ap-southeast-1 --fallback instance_type=t3.medium --fallback on-demand --max-attempts 3 --cooldown 5m
+  redc recovery set c2 --compose-file ./redc-compose.yaml --max-attempts 2`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := redcProject.GetCase(args[0])
+		if err != nil {
+			MustJSON(fmt.Errorf("%s", i18n.Tf("action_case_not_found", args[0], err)))
+			return
+		}
+
+		policy := redc.SpotRecoveryPolicy{
+			MaxAttempts:     recoveryMaxAttempts,
+			CooldownSeconds: int(recoveryCooldown / time.Second),
+			RegionVar:       recoveryRegionVar,
+			InstanceTypeVar: recoveryInstanceTypeVar,
+		}
+		for _, f := range recoveryFallbacks {
+			step, err := redc.ParseRecoveryStep(f)
+			if err != nil {
+				MustJSON(err)
+				return
+			}
+			policy.Fallbacks = append(policy.Fallbacks, step)
+		}
+		if recoveryComposeFile != "" {
+			abs, err := filepath.Abs(recoveryComposeFile)
+			if err != nil {
+				MustJSON(err)
+				return
+			}
+			policy.ComposeFile = abs
+		}
+		// 默认最多尝试 原参数 + 每个回退方案 各一次
+		if policy.MaxAttempts == 0 {
+			policy.MaxAttempts = len(policy.Fallbacks) + 1
+		}
+
+		if err := redc.SetSpotRecoveryPolicy(c.Path, policy); err != nil {
+			MustJSON(err)
+			return
+		}
+		if IsJSON() {
+			rec, _ := redc.LoadSpotRecovery(c.Path)
+			PrintJSON(rec)
+			return
+		}
+		gologger.Info().Msgf("%s", i18n.Tf("recovery_saved", c.Name, len(policy.Fallbacks), policy.MaxAttempts))
+	},
+}
+
+var recoveryShowCmd = &cobra.Command{
+	Use:   "show [case]",
+	Short: i18n.T("recovery_show_short"),
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := redcProject.GetCase(args[0])
+		if err != nil {
+			MustJSON(fmt.Errorf("%s", i18n.Tf("action_case_not_found", args[0], err)))
+			return
+		}
+		rec, err := redc.LoadSpotRecovery(c.Path)
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		if IsJSON() {
+			PrintJSON(rec)
+			return
+		}
+
+		p := rec.Policy
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(w, "Case:\t%s\n", c.Name)
+		fmt.Fprintf(w, "Max attempts:\t%d\n", p.MaxAttempts)
+		fmt.Fprintf(w, "Cooldown:\t%s\n", p.CooldownDuration())
+		fmt.Fprintf(w, "Variables:\tregion=%s instance_type=%s\n", p.RegionVar, p.InstanceTypeVar)
+		if p.ComposeFile != "" {
+			fmt.Fprintf(w, "Compose file:\t%s\n", p.ComposeFile)
+		}
+		fmt.Fprintf(w, "Fallbacks:\t%d\n", len(p.Fallbacks))
+		for i, s := range p.Fallbacks {
+			var parts []string
+			if s.Region != "" {
+				parts = append(parts, "region="+s.Region)
+			}
+			if s.InstanceType != "" {
+				parts = append(parts, "instance_type="+s.InstanceType)
+			}
+			if s.OnDemand {
+				parts = append(parts, "on-demand")
+			}
+			for k, v := range s.Vars {
+				parts = append(parts, k+"="+v)
+			}
+			fmt.Fprintf(w, "  #%d\t%s\n", i+1, strings.Join(parts, ", "))
+		}
+		w.Flush()
+
+		if len(rec.History) == 0 {
+			return
+		}
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tATTEMPT\tSTEP\tRESULT\tNEW IPS")
+		for _, h := range rec.History {
+			result := "ok"
+			if !h.Success {
+				result = "failed: " + h.Error
+				if len(result) > 60 {
+					result = result[:60] + "..."
+				}
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", h.At.Format("2006-01-02 15:04:05"), h.Attempt, h.Step, result, strings.Join(h.NewIPs, ","))
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	recoverySetCmd.Flags().StringArrayVar(&recoveryFallbacks, "fallback", nil, i18n.T("flag_recovery_fallback"))
+	recoverySetCmd.Flags().IntVar(&recoveryMaxAttempts, "max-attempts", 0, i18n.T("flag_recovery_max_attempts"))
+	recoverySetCmd.Flags().DurationVar(&recoveryCooldown, "cooldown", 0, i18n.T("flag_recovery_cooldown"))
+	recoverySetCmd.Flags().StringVar(&recoveryComposeFile, "compose-file", "", i18n.T("flag_recovery_compose_file"))
+	recoverySetCmd.Flags().StringVar(&recoveryRegionVar, "region-var", "region", i18n.T("flag_recovery_region_var"))
+	recoverySetCmd.Flags().StringVar(&recoveryInstanceTypeVar, "instance-type-var", "instance_type", i18n.T("flag_recovery_instance_type_var"))
+
+	rootCmd.AddCommand(recoveryCmd)
+	recoveryCmd.AddCommand(recoverySetCmd)
+	recoveryCmd.AddCommand(recoveryShowCmd)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var registryAdd struct {
+	Token    string
+	Username string
+	CAFile   string
+	First    bool
+}
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: i18n.T("registry_short"),
+}
+
+var registryAddCmd = &cobra.Command{
+	Use:   "add <name> <url>",
+	Short: i18n.T("registry_add_short"),
+	Example: `  redc registry add team https://templates.example.com --token $TEAM_TOKEN
+  redc registry add corp https://git.corp/redc --username ci --token $PASS --ca-file corp-ca.pem --first
+  redc registry add local ./my-registry`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		r := redc.Registry{
+			Name:     args[0],
+			URL:      args[1],
+			Token:    registryAdd.Token,
+			Username: registryAdd.Username,
+			CAFile:   registryAdd.CAFile,
+		}
+		MustJSON(redc.AddRegistry(r, registryAdd.First))
+		if IsJSON() {
+			PrintJSON(map[string]string{"registry": r.Name, "status": "added"})
+			return
+		}
+		gologger.Info().Msgf("%s", i18n.Tf("registry_added", r.Name))
+	},
+}
+
+var registryListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   i18n.T("registry_ls_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		regs, err := redc.LoadRegistries()
+		MustJSON(err)
+		if IsJSON() {
+			PrintJSON(regs)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tURL\tAUTH\tCA")
+		for _, r := range regs {
+			auth := "-"
+			switch {
+			case r.Username != "":
+				auth = "basic (" + r.Username + ")"
+			case r.Token != "":
+				auth = "bearer"
+			}
+			ca := r.CAFile
+			if ca == "" {
+				ca = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.URL, auth, ca)
+		}
+		w.Flush()
+	},
+}
+
+var registryRemoveCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   i18n.T("registry_rm_short"),
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		MustJSON(redc.RemoveRegistry(args[0]))
+		if IsJSON() {
+			PrintJSON(map[string]string{"registry": args[0], "status": "removed"})
+			return
+		}
+		gologger.Info().Msgf("%s", i18n.Tf("registry_removed", args[0]))
+	},
+}
+
+func init() {
+	registryAddCmd.Flags().StringVar(&registryAdd.Token, "token", "", i18n.T("flag_registry_token"))
+	registryAddCmd.Flags().StringVar(&registryAdd.Username, "username", "", i18n.T("flag_registry_username"))
+	registryAddCmd.Flags().StringVar(&registryAdd.CAFile, "ca-file", "", i18n.T("flag_registry_ca_file"))
+	registryAddCmd.Flags().BoolVar(&registryAdd.First, "first", false, i18n.T("flag_registry_first"))
+	registryCmd.AddCommand(registryAddCmd, registryListCmd, registryRemoveCmd)
+	rootCmd.AddCommand(registryCmd)
+}
@@ -50,6 +50,8 @@ var rootCmd = &cobra.Command{
 		} else {
 			gologger.Fatal().Msgf(i18n.Tf("project_load_failed", err))
 		}
+		// 场景启停时记录费用台账
+		redc.EnableCostLedger(casePricer())
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if showVer {
@@ -67,6 +69,7 @@ var rootCmd = &cobra.Command{
 
 // Execute 是 main.go 调用的入口
 func Execute() {
+	registerPluginCommands(os.Args[1:])
 	if err := rootCmd.Execute(); err != nil {
 		gologger.Error().Msgf(err.Error())
 		os.Exit(1)
@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"red-cloud/i18n"
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+	"red-cloud/utils/sshutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	taskAction   string
+	taskAt       string
+	taskIn       string
+	taskRepeat   string
+	taskInterval int
+	taskCommand  string
+	taskNotify   bool
+	taskAll      bool
+	taskCase     string
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: i18n.T("task_short"),
+	Long:  i18n.T("task_long"),
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var taskAddCmd = &cobra.Command{
+	Use:   "add [case]",
+	Short: i18n.T("task_add_short"),
+	Example: `  redc task add web --action stop --in 8h
+  redc task add web --action start --at "2026-01-02 09:00" --repeat daily
+  redc task add web --action ssh_command --command "df -h" --repeat interval --interval 30 --notify`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := redcProject.GetCase(args[0])
+		if err != nil {
+			MustJSON(fmt.Errorf("%s", i18n.Tf("action_case_not_found", args[0], err)))
+			return
+		}
+		scheduledAt, err := parseTaskTime(taskAt, taskIn)
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+
+		s, err := openScheduler()
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		defer s.Stop()
+
+		task, err := s.AddTaskFull(c.Id, c.Name, taskAction, scheduledAt, taskRepeat, taskInterval, taskCommand, taskNotify)
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		if IsJSON() {
+			PrintJSON(task)
+			return
+		}
+		gologger.Info().Msgf("%s", i18n.Tf("task_added", task.ID, c.Name, task.Action, task.ScheduledAt.Format("2006-01-02 15:04:05")))
+	},
+}
+
+var taskListCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   i18n.T("task_ls_short"),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := openScheduler()
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		defer s.Stop()
+
+		var tasks []*redc.ScheduledTask
+		if taskAll {
+			tasks = s.ListAllTasksFromDB()
+		} else {
+			tasks = s.ListTasks()
+		}
+		if taskCase != "" {
+			filtered := tasks[:0]
+			for _, t := range tasks {
+				if t.CaseID == taskCase || t.CaseName == taskCase || strings.HasPrefix(t.CaseID, taskCase) {
+					filtered = append(filtered, t)
+				}
+			}
+			tasks = filtered
+		}
+		sort.Slice(tasks, func(i, j int) bool { return tasks[i].ScheduledAt.Before(tasks[j].ScheduledAt) })
+
+		if IsJSON() {
+			if tasks == nil {
+				tasks = []*redc.ScheduledTask{}
+			}
+			PrintJSON(tasks)
+			return
+		}
+		if len(tasks) == 0 {
+			fmt.Println(i18n.T("task_none"))
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tCASE\tACTION\tSCHEDULED\tREPEAT\tSTATUS")
+		for _, t := range tasks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.CaseName, t.Action,
+				t.ScheduledAt.Format("2006-01-02 15:04:05"), repeatLabel(t), t.Status)
+		}
+		w.Flush()
+	},
+}
+
+var taskShowCmd = &cobra.Command{
+	Use:   "show [id]",
+	Short: i18n.T("task_show_short"),
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := openScheduler()
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		defer s.Stop()
+
+		task, err := s.GetTask(args[0])
+		if err != nil {
+			MustJSON(fmt.Errorf("%s: %s", err, args[0]))
+			return
+		}
+		if IsJSON() {
+			PrintJSON(task)
+			return
+		}
+		printTask(task)
+	},
+}
+
+var taskRmCmd = &cobra.Command{
+	Use:   "rm [id]",
+	Short: i18n.T("task_rm_short"),
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := openScheduler()
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		defer s.Stop()
+
+		if err := s.RemoveTask(args[0]); err != nil {
+			MustJSON(fmt.Errorf("%s: %s", err, args[0]))
+			return
+		}
+		if IsJSON() {
+			PrintJSON(map[string]string{"id": args[0], "removed": "true"})
+			return
+		}
+		gologger.Info().Msgf("%s", i18n.Tf("task_removed", args[0]))
+	},
+}
+
+var taskRunNowCmd = &cobra.Command{
+	Use:   "run-now [id]",
+	Short: i18n.T("task_run_now_short"),
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := openScheduler()
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		defer s.Stop()
+		setCLITaskCallbacks(s)
+
+		task, err := s.RunTaskNow(args[0])
+		if err != nil {
+			MustJSON(err)
+			return
+		}
+		if IsJSON() {
+			PrintJSON(task)
+			return
+		}
+		printTask(task)
+		if task.Status == "failed" {
+			os.Exit(1)
+		}
+	},
+}
+
+// setCLITaskCallbacks 让 CLI 进程直接执行任务（不依赖 GUI / daemon）
+func setCLITaskCallbacks(s *redc.TaskScheduler) {
+	s.SetExecuteCallback(func(caseID string, action string) error {
+		c, err := redcProject.GetCase(caseID)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case "start":
+			return c.TfApply()
+		case "stop":
+			return c.Stop()
+		case "kill":
+			return c.Kill()
+		case redc.PolicyActionDestroy:
+			if c.State != redc.StateStopped && c.State != redc.StateCreated {
+				if err := c.Stop(); err != nil {
+					return err
+				}
+			}
+			return c.Remove()
+		}
+		return fmt.Errorf("%s", i18n.Tf("app_unknown_action", action))
+	})
+	s.SetSSHCommandCallback(func(caseID string, command string) (string, error) {
+		c, err := redcProject.GetCase(caseID)
+		if err != nil {
+			return "", err
+		}
+		conf, err := c.GetSSHConfig()
+		if err != nil {
+			return "", err
+		}
+		return runSSHCapture(conf, command)
+	})
+	s.SetNotifyCallback(func(title string, message string) {
+		// CLI 无系统通知 / webhook 管理器，直接输出
+		if !IsJSON() {
+			gologger.Info().Msgf("[%s] %s", title, message)
+		}
+	})
+}
+
+// runSSHCapture 执行 SSH 命令并返回合并后的输出
+func runSSHCapture(conf *sshutil.SSHConfig, command string) (string, error) {
+	client, err := sshutil.NewClient(conf)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	var stdout, stderr strings.Builder
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	err = session.Run(command)
+	output := stdout.String()
+	if stderr.Len() > 0 {
+		output += "\n[stderr] " + stderr.String()
+	}
+	if exitErr, ok := err.(*ssh.ExitError); ok {
+		return output, fmt.Errorf("exit code %d", exitErr.ExitStatus())
+	}
+	return output, err
+}
+
+// parseTaskTime 解析 --at / --in，--at 支持 RFC3339、"2006-01-02 15:04[:05]" 和当天 "15:04"
+func parseTaskTime(at, in string) (time.Time, error) {
+	if in != "" {
+		d, err := redc.ParseTTL(in)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Now().Add(d), nil
+	}
+	if at == "" {
+		return time.Time{}, fmt.Errorf("%s", i18n.T("task_time_required"))
+	}
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, at, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("15:04", at, time.Local); err == nil {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+		if next.Before(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		return next, nil
+	}
+	return time.Time{}, fmt.Errorf("%s", i18n.Tf("task_time_invalid", at))
+}
+
+func repeatLabel(t *redc.ScheduledTask) string {
+	if t.RepeatType == "interval" {
+		return fmt.Sprintf("every %dm", t.RepeatInterval)
+	}
+	if t.RepeatType == "" {
+		return "once"
+	}
+	return t.RepeatType
+}
+
+func printTask(t *redc.ScheduledTask) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", t.ID)
+	fmt.Fprintf(w, "Case:\t%s (%s)\n", t.CaseName, t.CaseID)
+	fmt.Fprintf(w, "Action:\t%s\n", t.Action)
+	if t.SSHCommand != "" {
+		fmt.Fprintf(w, "Command:\t%s\n", t.SSHCommand)
+	}
+	fmt.Fprintf(w, "Scheduled:\t%s\n", t.ScheduledAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "Repeat:\t%s\n", repeatLabel(t))
+	fmt.Fprintf(w, "Notify:\t%v\n", t.NotifyEnabled)
+	fmt.Fprintf(w, "Status:\t%s\n", t.Status)
+	if !t.CompletedAt.IsZero() {
+		fmt.Fprintf(w, "Completed:\t%s\n", t.CompletedAt.Format("2006-01-02 15:04:05"))
+	}
+	if t.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", t.Error)
+	}
+	w.Flush()
+	if t.TaskResult != "" {
+		fmt.Printf("\n%s\n", strings.TrimRight(t.TaskResult, "\n"))
+	}
+}
+
+func init() {
+	taskAddCmd.Flags().StringVar(&taskAction, "action", "", i18n.T("flag_task_action"))
+	taskAddCmd.Flags().StringVar(&taskAt, "at", "", i18n.T("flag_task_at"))
+	taskAddCmd.Flags().StringVar(&taskIn, "in", "", i18n.T("flag_task_in"))
+	taskAddCmd.Flags().StringVar(&taskRepeat, "repeat", "once", i18n.T("flag_task_repeat"))
+	taskAddCmd.Flags().IntVar(&taskInterval, "interval", 0, i18n.T("flag_task_interval"))
+	taskAddCmd.Flags().StringVar(&taskCommand, "command", "", i18n.T("flag_task_command"))
+	taskAddCmd.Flags().BoolVar(&taskNotify, "notify", false, i18n.T("flag_task_notify"))
+	taskAddCmd.MarkFlagRequired("action")
+
+	taskListCmd.Flags().BoolVarP(&taskAll, "all", "a", false, i18n.T("flag_task_all"))
+	taskListCmd.Flags().StringVar(&taskCase, "case", "", i18n.T("flag_task_case"))
+
+	rootCmd.AddCommand(taskCmd)
+	taskCmd.AddCommand(taskAddCmd)
+	taskCmd.AddCommand(taskListCmd)
+	taskCmd.AddCommand(taskShowCmd)
+	taskCmd.AddCommand(taskRmCmd)
+	taskCmd.AddCommand(taskRunNowCmd)
+}
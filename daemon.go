@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+
+	"github.com/projectdiscovery/gologger/formatter"
+	"github.com/projectdiscovery/gologger/levels"
+)
+
+// HealthStatus is returned by GET /api/health
+type HealthStatus struct {
+	Status        string `json:"status"` // "ok" | "degraded"
+	Version       string `json:"version"`
+	PID           int    `json:"pid"`
+	Project       string `json:"project"`
+	Uptime        string `json:"uptime"`
+	Headless      bool   `json:"headless"`
+	Scheduler     bool   `json:"scheduler"`
+	SpotMonitor   bool   `json:"spotMonitor"`
+	HealthMonitor bool   `json:"healthMonitor"`
+	ActiveOps     int32  `json:"activeOps"`
+	Error         string `json:"error,omitempty"`
+}
+
+// healthStatus reports whether the background subsystems are up
+func (a *App) healthStatus() HealthStatus {
+	h := HealthStatus{
+		Status:        "ok",
+		Version:       redc.Version,
+		PID:           os.Getpid(),
+		Headless:      a.headless,
+		Scheduler:     a.taskScheduler != nil,
+		SpotMonitor:   a.spotMonitor != nil,
+		HealthMonitor: a.healthMonitor != nil,
+		ActiveOps:     a.activeOps.Load(),
+	}
+	if !a.startedAt.IsZero() {
+		h.Uptime = time.Since(a.startedAt).Round(time.Second).String()
+	}
+	if a.project != nil {
+		h.Project = a.project.ProjectName
+	}
+	if a.initError != "" || a.taskScheduler == nil {
+		h.Status = "degraded"
+		h.Error = a.initError
+	}
+	return h
+}
+
+// runDaemonMode boots the scheduler, spot monitor and (optionally) the HTTP server
+// without a GUI, so a VPS can own an engagement's automation.
+//
+//	redc-gui daemon [--host=127.0.0.1] [--port=8899] [--token=xxx] [--no-http] [--pid-file=path]
+func runDaemonMode() {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	host := fs.String("host", "127.0.0.1", "HTTP listen host")
+	port := fs.Int("port", 8899, "HTTP listen port")
+	token := fs.String("token", "", "HTTP access token (generated when empty)")
+	noHTTP := fs.Bool("no-http", false, "do not start the HTTP server / health endpoint")
+	pidFile := fs.String("pid-file", "", "PID/lock file (default <redc path>/daemon.pid)")
+	spotInterval := fs.Duration("spot-interval", 120*time.Second, "spot monitor scan interval")
+	healthInterval := fs.Duration("health-interval", 0, "health monitor scan interval (default from settings, 5m)")
+	fs.Parse(os.Args[2:])
+
+	// Take the lock before starting anything, so a second daemon never runs
+	// the scheduler or lifecycle policies alongside the first one
+	if *pidFile == "" {
+		*pidFile = redc.DaemonPIDPath()
+	}
+	if err := redc.AcquireDaemonLock(*pidFile); err != nil {
+		gologger.Error().Msgf("%v", err)
+		os.Exit(1)
+	}
+	defer redc.ReleaseDaemonLock(*pidFile)
+
+	app := NewApp()
+	app.startupHeadless()
+
+	// systemd-friendly logging: the core log writer already echoes to stdout,
+	// drop ANSI colours and only add timestamps when journald isn't doing it
+	gologger.DefaultLogger.SetFormatter(formatter.NewCLI(true))
+	if os.Getenv("INVOCATION_ID") == "" {
+		gologger.DefaultLogger.SetTimestamp(true, levels.LevelFatal)
+	}
+
+	// Unlike the GUI, the daemon always watches spot instances and case health
+	if app.spotMonitor == nil {
+		app.spotMonitor = NewSpotMonitor(app, *spotInterval)
+		app.spotMonitor.Start()
+	}
+	if app.healthMonitor == nil {
+		interval := *healthInterval
+		if interval <= 0 {
+			settings, _ := redc.LoadGUISettings()
+			interval = healthIntervalFromSettings(settings)
+		}
+		app.healthMonitor = NewHealthMonitor(app, interval)
+		app.healthMonitor.Start()
+	}
+
+	state := &redc.DaemonState{
+		PID:       os.Getpid(),
+		PIDFile:   *pidFile,
+		StartedAt: app.startedAt,
+		Version:   redc.Version,
+	}
+	if app.project != nil {
+		state.Project = app.project.ProjectName
+	}
+
+	var httpSrv *HTTPServer
+	if !*noHTTP {
+		var users []redc.HTTPUser
+		if settings, err := redc.LoadGUISettings(); err == nil && settings != nil {
+			users = settings.HTTPServerUsers
+		}
+		if *token == "" {
+			*token = GenerateToken()
+		}
+		httpSrv = NewHTTPServer(app, *host, *port, *token, users)
+		app.httpSrv = httpSrv
+		if err := httpSrv.Start(assets); err != nil {
+			gologger.Error().Msgf("failed to start HTTP server: %v", err)
+			app.daemonShutdown(httpSrv)
+			redc.ReleaseDaemonLock(*pidFile)
+			os.Exit(1)
+		}
+		state.HealthURL = fmt.Sprintf("http://%s:%d/api/health", *host, *port)
+		gologger.Info().Msgf("HTTP server listening on http://%s:%d (token: %s)", *host, *port, *token)
+	}
+	if err := redc.SaveDaemonState(state); err != nil {
+		gologger.Warning().Msgf("write daemon state: %v", err)
+	}
+	defer os.Remove(redc.DaemonStatePath())
+
+	gologger.Info().Msgf("redc daemon started (pid %d, project %s)", state.PID, state.Project)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	sig := <-quit
+	gologger.Info().Msgf("received %s, shutting down", sig)
+	app.daemonShutdown(httpSrv)
+	gologger.Info().Msg("redc daemon stopped")
+}
+
+// daemonShutdown stops the subsystems in reverse order, waiting for in-flight operations
+func (a *App) daemonShutdown(httpSrv *HTTPServer) {
+	if httpSrv != nil {
+		httpSrv.Stop()
+	}
+	// Give running apply/destroy operations a chance to finish before exiting
+	deadline := time.Now().Add(5 * time.Minute)
+	for a.activeOps.Load() > 0 && time.Now().Before(deadline) {
+		gologger.Info().Msgf("waiting for %d active operation(s) to finish", a.activeOps.Load())
+		time.Sleep(5 * time.Second)
+	}
+	a.shutdown(context.Background())
+	if a.taskScheduler != nil {
+		a.taskScheduler.Stop()
+	}
+}
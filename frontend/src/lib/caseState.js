@@ -0,0 +1,5 @@
+// Case state helpers shared by the case list, dashboard and SSH manager
+
+// isUp mirrors IsRunningState in the backend: a degraded case's instances are
+// still running (and billed), only its health probe fails.
+export const isUp = (state) => state === 'running' || state === 'degraded';
@@ -195,6 +195,7 @@ export const i18n = {
     estimatedMonthlyCost: '预估月度成本', predictedMonthlyCost: '预测本月费用',
     runtime: '运行时长',
     costBreakdown: '成本明细', pricingUnavailable: '价格不可用',
+    onDemandMonthlyCost: '按量月度成本', spotSavings: '抢占式节省', potentialSpotSavings: '改用抢占式可节省', spotPrice: '抢占式', onDemandPrice: '按量',
     calculating: '计算中...', costEstimateError: '成本估算错误',
     costEstimateErrorHint: '无法估算成本。您仍可以创建场景。',
     loadAllTemplateCosts: '加载所有模板成本', loadingAllTemplateCosts: '加载中...',
@@ -253,7 +254,7 @@ export const i18n = {
     scheduleStart: '定时启动', scheduleStop: '定时停止',
     planPreview: '资源拓扑预览', planPreviewBtn: '预览', planToCreate: '创建', planToUpdate: '更新', planToDelete: '删除', planToRecreate: '重建',
     planNoChanges: '没有资源变更', planNoPlanFile: 'Plan 文件不存在，请重新创建场景', planStartScene: '启动场景',
-    planResources: '个资源', planDependencies: '条依赖', planLoading: '正在解析 Plan 文件...',
+    planResources: '个资源', planDependencies: '条依赖', planCostImpact: '费用变化', planCostBefore: '变更前', planCostAfter: '变更后', planLoading: '正在解析 Plan 文件...',
     count: '数量', keyInfo: '关键信息',
     noScheduledTasks: '暂无待执行的定时任务', remaining: '剩余',
     hour: '小时', minute: '分钟', second: '秒', minutesLater: '分钟后执行',
@@ -311,6 +312,7 @@ export const i18n = {
     spotTerminated: '已回收',
     spotTerminatedTitle: '抢占式实例已被云厂商回收',
     terminated: '已回收',
+    degraded: '异常',
     noOutput: '部署未运行，无输出信息',
     deploymentFailed: '部署失败',
     checkConfigRetry: '请检查配置后重试。错误详情：',
@@ -667,6 +669,7 @@ export const i18n = {
     estimatedMonthlyCost: 'Estimated Monthly Cost', predictedMonthlyCost: 'Predicted Monthly Cost',
     runtime: 'Runtime',
     costBreakdown: 'Cost Breakdown', pricingUnavailable: 'Pricing Unavailable',
+    onDemandMonthlyCost: 'On-demand Monthly Cost', spotSavings: 'Spot Savings', potentialSpotSavings: 'Potential Spot Savings', spotPrice: 'Spot', onDemandPrice: 'On-demand',
     calculating: 'Calculating...', costEstimateError: 'Cost Estimation Error',
     costEstimateErrorHint: 'Failed to estimate costs. You can still create the scene.',
     loadAllTemplateCosts: 'Load All Template Costs', loadingAllTemplateCosts: 'Loading...',
@@ -727,7 +730,7 @@ export const i18n = {
     scheduleStart: 'Schedule Start', scheduleStop: 'Schedule Stop',
     planPreview: 'Resource Topology Preview', planPreviewBtn: 'Preview', planToCreate: 'Create', planToUpdate: 'Update', planToDelete: 'Delete', planToRecreate: 'Recreate',
     planNoChanges: 'No resource changes', planNoPlanFile: 'Plan file not found, please recreate the case', planStartScene: 'Start Scene',
-    planResources: 'resources', planDependencies: 'dependencies', planLoading: 'Parsing plan file...',
+    planResources: 'resources', planDependencies: 'dependencies', planCostImpact: 'Cost impact', planCostBefore: 'Before', planCostAfter: 'After', planLoading: 'Parsing plan file...',
     resourceType: 'Resource Type', count: 'Count', keyInfo: 'Key Info',
     noScheduledTasks: 'No pending scheduled tasks', remaining: 'Remaining',
     hour: 'h', minute: 'm', second: 's', minutesLater: 'minutes later',
@@ -790,6 +793,7 @@ export const i18n = {
     spotTerminated: 'Reclaimed',
     spotTerminatedTitle: 'Spot instance reclaimed by provider',
     terminated: 'Terminated',
+    degraded: 'Degraded',
     noOutput: 'Deployment not running, no output',
     experimentalFeatureDesc: 'Custom deployment is currently in experimental stage and may be unstable. Use with caution in production environments.',
     all: 'All', baseTemplates: 'Base Templates', predefinedTemplates: 'Predefined Templates',
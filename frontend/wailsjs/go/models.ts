@@ -406,6 +406,7 @@ export namespace main {
 	    tags: string[];
 	    installed: boolean;
 	    localVersion: string;
+	    registry: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new RegistryTemplate(source);
@@ -422,6 +423,7 @@ export namespace main {
 	        this.tags = source["tags"];
 	        this.installed = source["installed"];
 	        this.localVersion = source["localVersion"];
+	        this.registry = source["registry"];
 	    }
 	}
 	export class ResourceSummary {
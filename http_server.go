@@ -111,11 +111,12 @@ var methodMinRole = map[string]string{
 	"GetLanguage": "viewer", "GetShowWelcomeDialog": "viewer",
 	"GetNotificationEnabled": "viewer", "GetDisableRightClick": "viewer",
 	"GetSpotMonitorEnabled": "viewer", "GetSpotAutoRecoverEnabled": "viewer",
+	"GetHealthMonitorEnabled": "viewer", "GetHealthReport": "viewer",
 	"GetWebhookConfig": "viewer", "GetAllCaseTags": "viewer", "GetAllTagNames": "viewer",
 	"GetHTTPServerStatus": "viewer",
 	"ListCases": "viewer", "GetCaseOutputs": "viewer", "GetCasePlanPreview": "viewer",
 	"GetResourceSummary": "viewer", "GetBalances": "viewer", "GetBills": "viewer",
-	"GetTotalRuntime": "viewer", "GetPredictedMonthlyCost": "viewer",
+	"GetTotalRuntime": "viewer", "GetPredictedMonthlyCost": "viewer", "GetCostReport": "viewer", "GetBillingReconciliation": "viewer",
 	"ListProfiles": "viewer", "GetActiveProfile": "viewer",
 	"GetProvidersConfig": "viewer", "GetCurrentProject": "viewer", "ListProjects": "viewer",
 	"ListTemplates": "viewer", "ListAllTemplates": "viewer", "GetTemplateVariables": "viewer",
@@ -129,6 +130,7 @@ var methodMinRole = map[string]string{
 	"GetMCPStatus": "viewer",
 	"ListScheduledTasks": "viewer", "ListCaseScheduledTasks": "viewer",
 	"ListAllScheduledTasks": "viewer", "GetScheduledTask": "viewer",
+	"GetSpotRecoveryPolicy": "viewer", "ListLifecyclePolicies": "viewer",
 	"GetAgentMemories": "viewer",
 	"GetF8xCatalog": "viewer", "GetF8xCategories": "viewer", "GetF8xPresets": "viewer",
 	"GetF8xStatus": "viewer", "GetF8xInstallHistory": "viewer", "GetF8xRunningTasks": "viewer",
@@ -183,6 +185,8 @@ var methodMinRole = map[string]string{
 	"ListConfigTemplates": "operator",
 	"ScheduleTask": "operator", "ScheduleTaskWithRepeat": "operator",
 	"ScheduleTaskFull": "operator", "CancelScheduledTask": "operator",
+	"SetCasePolicy": "operator", "RemoveLifecyclePolicy": "operator",
+	"SetSpotRecoveryPolicy": "operator", "CheckCaseHealth": "operator",
 	"SetCaseTags": "operator",
 	"SetActiveProfile": "operator", "SwitchProject": "operator",
 	"InstallPlugin": "operator", "EnablePlugin": "operator",
@@ -410,6 +414,21 @@ func (s *HTTPServer) Start(staticFS fs.FS) error {
 		}
 	})
 
+	// GET /api/health — liveness probe for daemon / systemd / load balancers.
+	// Anonymous callers only get the status; project, PID and subsystem details need auth
+	mux.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		health := s.app.healthStatus()
+		if health.Status != "ok" {
+			w.WriteHeader(503)
+		}
+		if _, _, ok := checkAuth(r); !ok {
+			json.NewEncoder(w).Encode(map[string]string{"status": health.Status})
+			return
+		}
+		json.NewEncoder(w).Encode(health)
+	})
+
 	// File upload endpoint for browser mode
 	mux.HandleFunc("/api/upload", func(w http.ResponseWriter, r *http.Request) {
 		_, role, ok := checkAuth(r)
@@ -159,6 +159,9 @@ var enMessages = map[string]string{
 	"case_save_state_failed":            "Failed to save state to config file: %s \n",
 	"case_destroying":                   "Destroying scene \"%s(%s)\"...",
 	"case_starting":                     "Starting scene: %s(%s)",
+	"case_hook_blocked":                 "Plugin %s hook blocked the operation: %v",
+	"case_hook_post_failed":             "Plugin %s hook failed after the operation completed: %v",
+	"case_drift_detected":               "Scene \"%s(%s)\" has drifted: cloud resources were changed outside redc",
 	"case_creating":                     "Creating scene \"%s\"",
 	"case_copying_template":             "Copying template %s",
 	"case_get_output_failed":            "Failed to get Output info: %v",
@@ -404,7 +407,8 @@ var enMessages = map[string]string{
 	"app_compose_down_done": "compose down completed",
 
 	// 成本估算
-	"app_cost_init_success": "Cost estimate service initialized - cache path: %s",
+	"app_cost_init_success":      "Cost estimate service initialized - cache path: %s",
+	"app_pricing_catalog_loaded": "Offline pricing catalog %s (effective %s) loaded",
 
 	// 配置模板相关
 	"app_config_store_not_init":    "Config store not initialized",
@@ -567,6 +571,21 @@ var enMessages = map[string]string{
 	"plugin_disable_short":   "Disable a plugin",
 	"plugin_update_short":    "Update a plugin (git pull)",
 	"plugin_info_short":      "Show plugin details",
+	"plugin_tree_short":      "List installed plugins as a dependency tree",
+	"plugin_config_short":    "Show or change plugin config",
+	"plugin_commands_short":  "Commands provided by plugin %s",
+	"plugin_approve_short":   "Approve the credentials a plugin requests",
+	"plugin_revoke_short":    "Revoke the credentials approved for a plugin",
+	"plugin_install_yes":     "Approve requested credentials without prompting",
+	"plugin_credentials_confirm": "Allow plugin %s hooks to read %s? [y/N] ",
+	"plugin_credentials_withheld": "Credentials withheld; run redc plugin approve %s to grant them later",
+	"plugin_verify_short":    "Verify installed plugins against the lockfile",
+	"plugin_verify_accept":   "Accept the current content and re-pin the plugin",
+	"plugin_trust_short":     "Manage keys trusted for plugin signatures",
+	"plugin_trust_ls_short":  "List trusted keys",
+	"plugin_trust_add_short": "Trust an ed25519 or minisign public key",
+	"plugin_trust_rm_short":  "Remove a trusted key",
+	"plugin_trust_require_short": "Require trusted signatures for remote plugins (on|off)",
 
 	// Graceful quit
 	"app_quit_confirm_title":   "Confirm Exit",
@@ -575,4 +594,228 @@ var enMessages = map[string]string{
 	"app_quit_btn_cancel":      "Cancel",
 
 	"f8x_deploy_failed": "Failed to deploy f8x, please check the target host network connection",
+
+	// Lifecycle policies (TTL / budget)
+	"flag_policy_ttl":      "Auto stop after this duration (e.g. 8h, 30m, 2d)",
+	"flag_policy_budget":   "Auto stop when accrued spend exceeds this budget (e.g. 20USD)",
+	"flag_policy_on_limit": "Action when TTL or budget is exceeded: stop or destroy",
+	"policy_saved":         "Lifecycle policy saved for %s (TTL: %s, budget: %s, action: %s)",
+	"policy_save_failed":   "Failed to save lifecycle policy: %v",
+
+	// Daemon
+	"daemon_short":                "Run, inspect or stop the headless daemon",
+	"daemon_long":                 "The daemon runs the task scheduler, lifecycle policies, spot monitor and HTTP server without a GUI.\nredc daemon starts it in the foreground through the GUI/server binary (redc-gui, next to redc or in PATH), e.g. under systemd:\n\n  redc daemon --host=127.0.0.1 --port=8899 --token=<token>\n\nHealth endpoint: GET /api/health returns only the status without auth, details need the token",
+	"daemon_status_short":         "Show daemon status and health",
+	"daemon_stop_short":           "Gracefully stop the daemon",
+	"daemon_not_running":          "redc daemon is not running",
+	"daemon_running":              "redc daemon is running (pid %d)",
+	"daemon_stop_sent":            "Stop signal sent to daemon (pid %d)",
+	"daemon_binary_not_found":     "%s not found next to redc or in PATH, the daemon needs the GUI/server binary",
+	"flag_daemon_pid_file":        "PID/lock file (default <redc path>/daemon.pid)",
+	"flag_daemon_host":            "HTTP listen host",
+	"flag_daemon_port":            "HTTP listen port",
+	"flag_daemon_token":           "HTTP access token (generated when empty)",
+	"flag_daemon_no_http":         "Do not start the HTTP server / health endpoint",
+	"flag_daemon_spot_interval":   "Spot monitor scan interval",
+	"flag_daemon_health_interval": "Health monitor scan interval (default from settings, 5m)",
+
+	// Task scheduler CLI
+	"task_short":         "Manage scheduled tasks",
+	"task_long":          "Manage scheduled tasks. Tasks are stored in <redc path>/scheduler.db and shared with the GUI / daemon scheduler, which executes them when due.",
+	"task_add_short":     "Schedule a task for a case",
+	"task_ls_short":      "List scheduled tasks",
+	"task_show_short":    "Show task details and last result",
+	"task_rm_short":      "Remove a scheduled task",
+	"task_run_now_short": "Execute a pending task immediately in this process",
+	"task_added":         "Task %s scheduled: %s %s at %s",
+	"task_removed":       "Task %s removed",
+	"task_none":          "No scheduled tasks",
+	"task_time_required": "either --at or --in is required",
+	"task_time_invalid":  "invalid time %q (examples: 2026-01-02 15:04, 15:04, RFC3339)",
+	"flag_task_action":   "Action: start, stop, kill, ssh_command, auto_stop",
+	"flag_task_at":       "Execution time (\"2006-01-02 15:04\", \"15:04\" or RFC3339)",
+	"flag_task_in":       "Execute after a delay, e.g. 30m, 8h, 1d",
+	"flag_task_repeat":   "Repeat type: once, daily, weekly, interval",
+	"flag_task_interval": "Repeat interval in minutes (for --repeat interval)",
+	"flag_task_command":  "SSH command (for --action ssh_command)",
+	"flag_task_notify":   "Send a notification when the task finishes",
+	"flag_task_all":      "Include finished tasks from the last 7 days",
+	"flag_task_case":     "Only show tasks for this case",
+
+	// Spot recovery policy
+	"app_spot_recover_attempt":        "Spot recovery %s: attempt %d/%d using %s",
+	"app_spot_recover_cooldown":       "Spot recovery %s: waiting %s before next attempt",
+	"app_spot_recover_vars_skipped":   "Spot recovery %s: template does not declare %s, skipped",
+	"app_spot_recover_exhausted":      "Spot recovery %s gave up after %d attempt(s)",
+	"app_spot_recover_policy_invalid": "Spot recovery policy of %s unusable: %v",
+	"app_spot_recover_ips":            "Spot recovery %s: IPs changed %s -> %s",
+	"app_spot_recover_compose":        "Spot recovery %s: re-applied compose dependents %s",
+	"app_spot_recover_compose_failed": "Spot recovery %s: failed to update compose dependents: %v",
+
+	// Spot recovery CLI
+	"recovery_short":                  "Manage spot instance recovery policies",
+	"recovery_set_short":              "Set the recovery policy of a case (fallback regions / instance types / on-demand)",
+	"recovery_show_short":             "Show the recovery policy and attempt history of a case",
+	"recovery_saved":                  "Recovery policy saved for %s: %d fallback(s), max %d attempt(s)",
+	"flag_recovery_fallback":          "Fallback, tried in order (repeatable), e.g. region=ap-southeast-1,instance_type=t3.small or on-demand",
+	"flag_recovery_max_attempts":      "Maximum recovery attempts (default: 1 + number of fallbacks)",
+	"flag_recovery_cooldown":          "Wait between attempts, e.g. 5m",
+	"flag_recovery_compose_file":      "Compose file the case belongs to; dependents are re-applied with the new IPs",
+	"flag_recovery_region_var":        "Template variable holding the region",
+	"flag_recovery_instance_type_var": "Template variable holding the instance type",
+
+	// Spot liveness probes
+	"app_spot_probe_config_invalid": "Liveness probe config of %s invalid, using default: %v",
+	"app_spot_probe_failed":         "Spot probe %s %s (%s) failed %d/%d: %v",
+
+	// Case health monitor
+	"app_health_monitor_started": "Health monitor started",
+	"app_health_monitor_stopped": "Health monitor stopped",
+	"app_health_check_failed":    "Health check of %s: %v",
+	"app_health_degraded":        "Case degraded: %s (%s)",
+	"app_health_recovered":       "Case healthy again: %s",
+	"app_health_not_running":     "Case %s is not running (state: %s)",
+	"notify_case_degraded":       "Scene Degraded",
+	"notify_case_degraded_msg":   "Health probes of scene \"%s\" are failing: %s",
+	"notify_case_healthy":        "Scene Healthy",
+	"notify_case_healthy_msg":    "Scene \"%s\" passes health probes again",
+
+	// Health command
+	"health_short":      "Show health and uptime of running cases",
+	"health_long":       "Shows the result of the liveness probes recorded by the health monitor (GUI setting or daemon).\nThe probe is configured per template via liveness_probe in case.json (SSH on :22 by default).\nUse --check to probe now; cases failing failure_threshold checks in a row become degraded.",
+	"health_checking":   "Probing %s ...",
+	"health_none":       "No running cases",
+	"flag_health_check": "Run the liveness probes now and record the result",
+	"flag_health_all":   "Include cases that are not running",
+
+	// Cost ledger
+	"cost_short":             "Cost tracking commands",
+	"cost_report_short":      "Report actual accrued spend from the cost ledger",
+	"cost_report_long":       "Every start/stop interval of a case is recorded in the cost ledger together with the hourly price at that time.\nThe report sums the intervals that overlap the --from/--to window per case, including cases that have since been destroyed.\n--from/--to accept 2006-01-02, 2006-01 or RFC3339; a date or month in --to is inclusive. Cases marked * are still running.",
+	"cost_format_invalid":    "Invalid format %s, expected table, csv or json",
+	"cost_report_saved":      "Report saved to %s",
+	"cost_report_empty":      "No recorded spend in this period",
+	"cost_report_total":      "TOTAL",
+	"cost_report_unpriced":   "Note: %.1f hours have no recorded price and are not included in the cost",
+	"cost_missing_rates":     "Note: amounts in %s have no exchange rate to %s and are not included; totals are partial",
+	"flag_cost_from":         "Start of the report window (2006-01-02, 2006-01 or RFC3339)",
+	"flag_cost_to":           "End of the report window, inclusive for dates and months",
+	"flag_cost_format":       "Report format: table, csv or json",
+	"flag_cost_file":         "Write the report to a file instead of stdout",
+	"flag_cost_currency":     "Report currency (default: the profile display currency, else the currency of the first priced interval)",
+	"flag_cost_all_projects": "Report spend of all projects",
+
+	// Billing reconciliation
+	"cost_reconcile_short":       "Reconcile estimated cost with provider billing",
+	"cost_reconcile_long":        "Fetches resource-level bill line items for a billing month and attributes them to cases by the redc_case_id tag or by resource IDs recorded from Terraform state (kept after cases are destroyed).\nEach case's billed amount is compared with the estimate accrued in the cost ledger, and billed resources that belong to no case are listed as untracked spend.\nSupported providers: aliyun (instance bill), tencentcloud (resource summary), huaweicloud (resource records), aws (Cost Explorer grouped by the redc_case_id cost allocation tag), volcengine (bill details with tags), ucloud (bill details, matched by resource ID).\nVultr and GCP have no resource-level billing API; when their credentials are configured they are listed as unsupported and their spend is not included.",
+	"cost_reconcile_title":       "Billing reconciliation %s (%s)",
+	"cost_reconcile_untracked":   "Untracked spend: %.2f %s across %d billed items that belong to no case",
+	"cost_reconcile_summary":     "Billed %.2f = tracked %.2f + untracked %.2f, estimated %.2f (%s)",
+	"cost_reconcile_unsupported": "Note: %s cannot be reconciled (no resource-level billing API); their spend is not included",
+	"flag_cost_month":            "Billing month (2006-01, default: current month)",
+	"flag_cost_providers":        "Comma separated providers (default: %s)",
+
+	// Offline pricing catalogs
+	"cost_catalog_short":         "Manage offline pricing catalogs",
+	"cost_catalog_long":          "A pricing catalog is a versioned JSON bundle of prices keyed by provider, region and resource type with an effective date.\nThe newest installed catalog whose effective date has passed is used whenever provider pricing APIs are unavailable or no credentials are configured,\nso estimates are reproducible on air-gapped machines. The bundled pricing_fallback.json can be imported as a catalog.",
+	"cost_catalog_import_short":  "Validate and install a pricing catalog",
+	"cost_catalog_imported":      "Installed pricing catalog %s (%d entries, effective %s)",
+	"cost_catalog_export_short":  "Export cached and catalog prices as a new catalog",
+	"cost_catalog_export_long":   "Builds a catalog effective today from the newest installed catalog overlaid with every price in the local pricing cache.\nRun estimates on a connected machine to fill the cache, export, then import the file on the offline machine.",
+	"cost_catalog_exported":      "Exported %d prices to %s",
+	"cost_catalog_list_short":    "List installed pricing catalogs",
+	"cost_catalog_none":          "No pricing catalogs installed",
+	"cost_catalog_diff_short":    "Compare prices between two catalogs",
+	"cost_catalog_diff_long":     "Shows added, removed and changed prices between two catalogs given as file paths or installed names.\nWith one argument it is compared to the newest installed catalog; without arguments the two newest installed catalogs are compared.",
+	"cost_catalog_diff_need_two": "At least two catalogs are needed to compare",
+	"cost_catalog_diff_title":    "Pricing catalog %s (%s) -> %s (%s)",
+	"cost_catalog_diff_none":     "No price differences",
+	"plan_cost_diff_title":       "💰 Cost impact (%s/month): %.2f → %.2f (%s)",
+	"plan_cost_unavailable":      "pricing unavailable",
+	"plan_cost_diff_failed":      "Cost diff unavailable: %v",
+	"flag_cost_catalog_version":  "Catalog version (default: today's date)",
+
+	// Exchange rates
+	"cost_rates_short":                "Show exchange rates used to normalise cost figures",
+	"cost_rates_long":                 "Lists the rates from every supported currency to the given currency (default: the profile display currency).\nRates come from the profile's rate source: the built-in defaults, a static JSON file or an ECB-style XML feed (cached on disk), with manual overrides on top.\nEstimates, the cost ledger and billing reconciliation are converted into the display currency and record the rate used.",
+	"cost_rates_title":                "Display currency: %s, rate source: %s",
+	"cost_rates_set_short":            "Set the display currency and exchange rate source of the active profile",
+	"cost_rates_saved":                "Currency settings saved to profile %s",
+	"cost_rates_override_invalid":     "Invalid override %s, expected FROM_TO=rate, e.g. USD_CNY=7.1",
+	"flag_cost_rates_display":         "Display currency for all cost figures (empty keeps provider currencies)",
+	"flag_cost_rates_source":          "Exchange rate source: default, static or ecb",
+	"flag_cost_rates_file":            "JSON rate table used by the static source",
+	"flag_cost_rates_url":             "ECB-style XML feed URL (default: ECB daily reference rates)",
+	"flag_cost_rates_cache_hours":     "Hours to cache fetched rates (default 24)",
+	"flag_cost_rates_override":        "Manual rate FROM_TO=rate, repeatable",
+	"flag_cost_rates_clear_overrides": "Remove all manual rates",
+
+	// Resource tagging
+	"case_tags_failed":      "Failed to generate resource tags, resources will be created without redc tags: %v",
+	"case_tags_unsupported": "%s resources cannot carry redc tags (%s); they will not be attributed by cost reconcile or orphan detection",
+
+	// Orphaned resources
+	"orphans_short":           "Find cloud instances that belong to no case",
+	"orphans_scan_short":      "Scan provider accounts for orphaned instances",
+	"orphans_scan_long":       "Lists compute instances in the configured provider accounts and reports those that belong to no case in redc.db. Other resource types (EIPs, disks, NAT gateways, security groups) are not scanned.\n  tag      the instance carries a redc_case_id tag of a local project, but the case no longer exists\n  foreign  the instance carries a redc_case_id tag, but its redc_project is not a local project (it may belong to another redc installation)\n  name     the instance name follows the generated case naming (e.g. red_pig_xxx) but no case has that name\nThe default region of each provider is scanned unless --regions is given.\nUse --cleanup to delete the orphans, confirming each one, or add --yes to delete without asking. --yes only deletes tag orphans; foreign and name orphans must be confirmed one by one.",
+	"orphans_none":            "No orphaned instances found",
+	"orphans_found":           "%d orphaned instances found, run with --cleanup to delete them",
+	"orphans_unverified":      "%d of them cannot be attributed to a local project (foreign/name) and are never deleted with --yes",
+	"orphans_confirm":         "Delete %s %s %s (%s, %s)? [y/N] ",
+	"orphans_confirm_foreign": "%s %s %s (%s, %s) cannot be attributed to a local project and may belong to another redc installation. Delete anyway? [y/N] ",
+	"orphans_deleted":         "Deleted %s %s",
+	"orphans_delete_failed":   "Failed to delete %s %s: %s",
+	"orphans_skipped_foreign": "Skipped %s %s: not attributed to a local project, confirm it without --yes to delete",
+	"orphans_cleanup_summary": "Deleted %d of %d orphaned instances",
+	"orphans_yes_required":    "--cleanup with JSON output cannot prompt, add --yes",
+	"flag_orphans_providers":  "Comma-separated providers to scan (default: all of %s)",
+	"flag_orphans_regions":    "Comma-separated regions to scan (default: the configured region of each provider)",
+	"flag_orphans_cleanup":    "Delete the orphaned instances after scanning",
+	"flag_orphans_yes":        "Delete tag orphans without confirmation (with --cleanup)",
+
+	// Template version pinning
+	"case_template_changed":       "Template %s has changed locally since case %s was created (created from v%s, local v%s); the case keeps using the files copied at creation",
+	"case_template_lock_mismatch": "Template %s is v%s locally but %s pins v%s, run redc pull --locked to install the pinned version",
+	"case_template_hash_failed":   "Failed to hash template %s: %v",
+	"template_lock_read_failed":   "Failed to read %s: %v",
+	"template_lock_write_failed":  "Failed to update %s: %v",
+	"flag_pull_locked":            "Install the template versions pinned in the project redc.lock (all of them without an argument)",
+
+	// Case template upgrade
+	"case_short":                  "Manage cases",
+	"case_upgrade_short":          "Upgrade a case to a newer template version",
+	"case_upgrade_long":           "Copy a new template version into the case directory, keep the variables the new version still declares and show the terraform plan. The change is applied after confirmation; the previous files are kept in a snapshot for redc case rollback. A running case is updated in place, other cases use the new files on next start.",
+	"case_rollback_short":         "Restore the template files of a case from a snapshot",
+	"case_rollback_long":          "Restore the template files and variables saved by redc case upgrade (the latest snapshot by default), show the plan and apply it after confirmation.",
+	"case_upgrade_yes_required":   "--yes is required with JSON output",
+	"case_upgrade_confirm":        "Apply these changes to %s (%s)? [y/N] ",
+	"case_upgrade_cancelled":      "Upgrade cancelled, original files restored",
+	"case_upgrade_failed":         "Upgrade failed: %v. Fix the issue and run redc case upgrade %s --resume, or run redc case rollback %s",
+	"case_upgrade_pending":        "Case %s has a pending upgrade to %s; run redc case upgrade %s --resume to apply it or redc case rollback %s to cancel it",
+	"case_upgrade_success":        "Case %s (%s) now uses %s:%s",
+	"case_upgrade_summary":        "Template %s: v%s -> v%s",
+	"case_upgrade_vars_dropped":   "Variables no longer declared (dropped): %s",
+	"case_upgrade_vars_new":       "New variables (default or -e value): %s",
+	"case_upgrade_snapshot":       "Saved current files to %s",
+	"case_upgrade_restore_failed": "Failed to restore the original files: %v",
+	"case_upgrade_reinit_failed":  "terraform init after restoring files failed: %v",
+	"case_snapshot_remove_failed": "Failed to remove snapshot %s: %v",
+	"flag_case_upgrade_to":        "Target template version (default: the locally installed template)",
+	"flag_case_upgrade_env":       "Set or override template variables (format: key=value)",
+	"flag_case_upgrade_yes":       "Apply without asking for confirmation",
+	"flag_case_upgrade_resume":    "Re-plan and apply the pending upgrade left by an interrupted or failed upgrade",
+
+	// Template registries
+	"registry_short":         "Manage template registries",
+	"registry_add_short":     "Add a template registry (searched in order, --first to search it first)",
+	"registry_ls_short":      "List template registries in search order",
+	"registry_rm_short":      "Remove a template registry",
+	"registry_added":         "Registry %s added",
+	"registry_removed":       "Registry %s removed",
+	"registry_unavailable":   "Registry %s is unavailable: %v",
+	"flag_registry":          "Registry name or URL (default: all configured registries in order)",
+	"flag_registry_token":    "Bearer token, or the password with --username",
+	"flag_registry_username": "Username for basic authentication",
+	"flag_registry_ca_file":  "CA bundle (PEM) used to verify the registry certificate",
+	"flag_registry_first":    "Search this registry before the existing ones",
 }
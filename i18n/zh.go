@@ -159,6 +159,9 @@ var zhMessages = map[string]string{
 	"case_save_state_failed":            "状态保存到配置文件失败: %s \n",
 	"case_destroying":                   "正在销毁场景「%s(%s)」...",
 	"case_starting":                     "正在启动场景：%s(%s)",
+	"case_hook_blocked":                 "插件 %s 钩子阻止了本次操作：%v",
+	"case_hook_post_failed":             "插件 %s 钩子在操作完成后执行失败：%v",
+	"case_drift_detected":               "场景 %s(%s) 发生漂移：云上资源被 redc 之外的操作修改",
 	"case_creating":                     "正在创建场景 「%s」",
 	"case_copying_template":             "复制模版中 %s",
 	"case_get_output_failed":            "获取 Output 信息失败: %v",
@@ -404,7 +407,8 @@ var zhMessages = map[string]string{
 	"app_compose_down_done": "compose down 完成",
 
 	// 成本估算
-	"app_cost_init_success": "成本估算服务初始化成功 - 缓存路径: %s",
+	"app_cost_init_success":      "成本估算服务初始化成功 - 缓存路径: %s",
+	"app_pricing_catalog_loaded": "已加载离线价格目录 %s（生效日期 %s）",
 
 	// 配置模板相关
 	"app_config_store_not_init":    "配置存储未初始化",
@@ -567,6 +571,21 @@ var zhMessages = map[string]string{
 	"plugin_disable_short":   "禁用插件",
 	"plugin_update_short":    "更新插件 (git pull)",
 	"plugin_info_short":      "查看插件详情",
+	"plugin_tree_short":      "以依赖树形式列出已安装插件",
+	"plugin_config_short":    "查看或修改插件配置",
+	"plugin_commands_short":  "插件 %s 提供的命令",
+	"plugin_approve_short":   "批准插件请求的凭据",
+	"plugin_revoke_short":    "撤销插件已批准的凭据",
+	"plugin_install_yes":     "不询问直接批准插件请求的凭据",
+	"plugin_credentials_confirm": "允许插件 %s 的钩子读取 %s？[y/N] ",
+	"plugin_credentials_withheld": "未授予凭据，之后可执行 redc plugin approve %s 授权",
+	"plugin_verify_short":    "按锁文件校验已安装插件",
+	"plugin_verify_accept":   "接受插件当前内容并重新锁定",
+	"plugin_trust_short":     "管理插件签名的受信公钥",
+	"plugin_trust_ls_short":  "列出受信公钥",
+	"plugin_trust_add_short": "信任 ed25519 或 minisign 公钥",
+	"plugin_trust_rm_short":  "移除受信公钥",
+	"plugin_trust_require_short": "远程插件必须带有受信签名 (on|off)",
 
 	// Graceful quit
 	"app_quit_confirm_title":   "确认退出",
@@ -575,4 +594,228 @@ var zhMessages = map[string]string{
 	"app_quit_btn_cancel":      "取消",
 
 	"f8x_deploy_failed": "f8x 部署失败，请检查目标主机网络连接",
+
+	// 生命周期策略 (TTL / 预算)
+	"flag_policy_ttl":      "运行超过该时长后自动处理 (例如 8h, 30m, 2d)",
+	"flag_policy_budget":   "累计花费超出预算后自动处理 (例如 20USD)",
+	"flag_policy_on_limit": "超出 TTL 或预算后执行的操作: stop 或 destroy",
+	"policy_saved":         "已为 %s 设置生命周期策略 (TTL: %s, 预算: %s, 操作: %s)",
+	"policy_save_failed":   "保存生命周期策略失败: %v",
+
+	// 后台守护进程
+	"daemon_short":                "运行、查看或停止守护进程",
+	"daemon_long":                 "守护进程在无 GUI 的情况下运行定时任务、生命周期策略、Spot 监控和 HTTP 服务。\nredc daemon 通过 GUI/服务端二进制（redc-gui，与 redc 同目录或在 PATH 中）在前台启动，例如在 systemd 中：\n\n  redc daemon --host=127.0.0.1 --port=8899 --token=<token>\n\n健康检查: GET /api/health 未认证时只返回状态，详细信息需要 token",
+	"daemon_status_short":         "显示守护进程状态与健康检查结果",
+	"daemon_stop_short":           "优雅停止守护进程",
+	"daemon_not_running":          "redc 守护进程未运行",
+	"daemon_running":              "redc 守护进程运行中 (pid %d)",
+	"daemon_stop_sent":            "已向守护进程发送停止信号 (pid %d)",
+	"daemon_binary_not_found":     "未在 redc 同目录或 PATH 中找到 %s，守护进程需要 GUI/服务端二进制",
+	"flag_daemon_pid_file":        "PID/锁文件（默认 <redc 目录>/daemon.pid）",
+	"flag_daemon_host":            "HTTP 监听地址",
+	"flag_daemon_port":            "HTTP 监听端口",
+	"flag_daemon_token":           "HTTP 访问 token（为空时自动生成）",
+	"flag_daemon_no_http":         "不启动 HTTP 服务和健康检查接口",
+	"flag_daemon_spot_interval":   "Spot 监控扫描间隔",
+	"flag_daemon_health_interval": "健康检查扫描间隔（默认读取设置，5m）",
+
+	// 定时任务 CLI
+	"task_short":         "管理定时任务",
+	"task_long":          "管理定时任务。任务保存在 <redc path>/scheduler.db，与 GUI / daemon 调度器共享，到期后由其执行。",
+	"task_add_short":     "为场景添加定时任务",
+	"task_ls_short":      "列出定时任务",
+	"task_show_short":    "显示任务详情与执行结果",
+	"task_rm_short":      "删除定时任务",
+	"task_run_now_short": "在当前进程中立即执行待执行任务",
+	"task_added":         "已添加任务 %s: %s %s，计划时间 %s",
+	"task_removed":       "任务 %s 已删除",
+	"task_none":          "暂无定时任务",
+	"task_time_required": "必须指定 --at 或 --in",
+	"task_time_invalid":  "无效的时间 %q (示例: 2026-01-02 15:04, 15:04, RFC3339)",
+	"flag_task_action":   "操作: start, stop, kill, ssh_command, auto_stop",
+	"flag_task_at":       "执行时间（\"2006-01-02 15:04\"、\"15:04\" 或 RFC3339）",
+	"flag_task_in":       "延迟执行，例如 30m, 8h, 1d",
+	"flag_task_repeat":   "重复类型: once, daily, weekly, interval",
+	"flag_task_interval": "重复间隔（分钟，用于 --repeat interval）",
+	"flag_task_command":  "SSH 命令（用于 --action ssh_command）",
+	"flag_task_notify":   "任务完成后发送通知",
+	"flag_task_all":      "包含最近 7 天已结束的任务",
+	"flag_task_case":     "仅显示该场景的任务",
+
+	// 抢占式实例恢复策略
+	"app_spot_recover_attempt":        "抢占式实例恢复 %s: 第 %d/%d 次尝试，方案 %s",
+	"app_spot_recover_cooldown":       "抢占式实例恢复 %s: 等待 %s 后重试",
+	"app_spot_recover_vars_skipped":   "抢占式实例恢复 %s: 模板未声明变量 %s，已跳过",
+	"app_spot_recover_exhausted":      "抢占式实例恢复 %s 失败，已尝试 %d 次",
+	"app_spot_recover_policy_invalid": "抢占式实例恢复策略不可用 %s: %v",
+	"app_spot_recover_ips":            "抢占式实例恢复 %s: IP 已变更 %s -> %s",
+	"app_spot_recover_compose":        "抢占式实例恢复 %s: 已重新部署依赖的 compose 服务 %s",
+	"app_spot_recover_compose_failed": "抢占式实例恢复 %s: 更新 compose 依赖服务失败: %v",
+
+	// 抢占式实例恢复 CLI
+	"recovery_short":                  "管理抢占式实例恢复策略",
+	"recovery_set_short":              "设置场景的恢复策略（回退地域 / 实例类型 / 按量）",
+	"recovery_show_short":             "显示场景的恢复策略与恢复记录",
+	"recovery_saved":                  "已保存 %s 的恢复策略: %d 个回退方案，最多尝试 %d 次",
+	"flag_recovery_fallback":          "回退方案，按顺序尝试（可重复），例如 region=ap-southeast-1,instance_type=t3.small 或 on-demand",
+	"flag_recovery_max_attempts":      "最大尝试次数（默认: 1 + 回退方案数）",
+	"flag_recovery_cooldown":          "两次尝试之间的等待时间，例如 5m",
+	"flag_recovery_compose_file":      "场景所属的 compose 文件，恢复后使用新 IP 重新部署依赖服务",
+	"flag_recovery_region_var":        "模板中表示地域的变量名",
+	"flag_recovery_instance_type_var": "模板中表示实例类型的变量名",
+
+	// Spot 存活探测
+	"app_spot_probe_config_invalid": "%s 的存活探测配置无效，使用默认探测: %v",
+	"app_spot_probe_failed":         "抢占式实例探测失败 %s %s (%s) %d/%d: %v",
+
+	// 场景健康监控
+	"app_health_monitor_started": "健康监控已启动",
+	"app_health_monitor_stopped": "健康监控已停止",
+	"app_health_check_failed":    "%s 健康检查: %v",
+	"app_health_degraded":        "场景异常: %s (%s)",
+	"app_health_recovered":       "场景已恢复正常: %s",
+	"app_health_not_running":     "场景 %s 未运行 (状态: %s)",
+	"notify_case_degraded":       "场景异常",
+	"notify_case_degraded_msg":   "场景 \"%s\" 健康探测失败: %s",
+	"notify_case_healthy":        "场景恢复正常",
+	"notify_case_healthy_msg":    "场景 \"%s\" 健康探测已恢复",
+
+	// health 命令
+	"health_short":      "查看运行中场景的健康状态与在线率",
+	"health_long":       "展示健康监控（GUI 设置或 daemon）记录的存活探测结果。\n探测方式由模板 case.json 中的 liveness_probe 配置（默认探测 SSH 22 端口）。\n使用 --check 立即探测；连续失败达到 failure_threshold 次的场景会进入 degraded 状态。",
+	"health_checking":   "正在探测 %s ...",
+	"health_none":       "没有运行中的场景",
+	"flag_health_check": "立即执行存活探测并记录结果",
+	"flag_health_all":   "包含未运行的场景",
+
+	// 费用台账
+	"cost_short":             "费用统计相关命令",
+	"cost_report_short":      "根据费用台账统计实际花费",
+	"cost_report_long":       "场景每一次启动 / 停止的运行区间都会连同当时的小时价格记录到费用台账中。\n报表按场景汇总与 --from/--to 时间窗口重叠的区间，已销毁的场景同样计入。\n--from/--to 支持 2006-01-02、2006-01 或 RFC3339 格式，--to 中的日期 / 月份包含当天 / 当月。带 * 的场景仍在运行。",
+	"cost_format_invalid":    "无效的格式 %s，可选 table、csv 或 json",
+	"cost_report_saved":      "报表已保存到 %s",
+	"cost_report_empty":      "该时间段内没有费用记录",
+	"cost_report_total":      "合计",
+	"cost_report_unpriced":   "注意：%.1f 小时的运行时长没有价格记录，未计入费用",
+	"cost_missing_rates":     "注意：%s 的金额没有到 %s 的汇率，未计入，合计为部分金额",
+	"flag_cost_from":         "统计开始时间（2006-01-02、2006-01 或 RFC3339）",
+	"flag_cost_to":           "统计结束时间，日期 / 月份包含当天 / 当月",
+	"flag_cost_format":       "报表格式：table、csv 或 json",
+	"flag_cost_file":         "将报表写入文件而不是标准输出",
+	"flag_cost_currency":     "报表币种（默认使用 profile 的显示币种，未设置时使用第一个已定价区间的币种）",
+	"flag_cost_all_projects": "统计所有项目的花费",
+
+	// 账单对账
+	"cost_reconcile_short":       "将估算费用与云厂商账单对账",
+	"cost_reconcile_long":        "读取账期内资源级账单明细，按 redc_case_id 标签或从 Terraform state 记录的资源 ID（场景销毁后仍保留）归属到场景。\n每个场景的账单金额会与费用台账中累计的估算费用对比，不属于任何场景的计费资源列为未跟踪花费。\n支持的云厂商：aliyun（实例账单）、tencentcloud（资源汇总账单）、huaweicloud（资源消费记录）、aws（Cost Explorer 按 redc_case_id 成本分配标签分组）、volcengine（带标签的账单明细）、ucloud（账单明细，按资源 ID 归属）。\nVultr 与 GCP 没有资源级账单 API，配置了凭据时在报表中标记为不支持，其费用不计入报表。",
+	"cost_reconcile_title":       "账单对账 %s（%s）",
+	"cost_reconcile_untracked":   "未跟踪花费：%.2f %s，共 %d 条账单不属于任何场景",
+	"cost_reconcile_summary":     "账单 %.2f = 已归属 %.2f + 未跟踪 %.2f，估算 %.2f（%s）",
+	"cost_reconcile_unsupported": "注意：%s 无法对账（没有资源级账单 API），其费用未计入报表",
+	"flag_cost_month":            "账期（2006-01，默认当月）",
+	"flag_cost_providers":        "逗号分隔的云厂商（默认：%s）",
+
+	// 离线价格目录
+	"cost_catalog_short":         "管理离线价格目录",
+	"cost_catalog_long":          "价格目录是按云厂商、地域、资源类型组织并带有生效日期的版本化 JSON 价格包。\n云厂商价格 API 不可用或未配置凭据时，使用已安装且已生效的最新目录，\n使离线环境中的估算结果可复现。内置的 pricing_fallback.json 也可以作为目录导入。",
+	"cost_catalog_import_short":  "校验并安装价格目录",
+	"cost_catalog_imported":      "已安装价格目录 %s（%d 条价格，生效日期 %s）",
+	"cost_catalog_export_short":  "将缓存和目录中的价格导出为新目录",
+	"cost_catalog_export_long":   "以最新安装的目录为基础，叠加本地价格缓存中的全部价格，生成今天生效的目录。\n在联网机器上执行估算填充缓存后导出，再在离线机器上导入该文件。",
+	"cost_catalog_exported":      "已导出 %d 条价格到 %s",
+	"cost_catalog_list_short":    "列出已安装的价格目录",
+	"cost_catalog_none":          "没有安装价格目录",
+	"cost_catalog_diff_short":    "比较两个价格目录的差异",
+	"cost_catalog_diff_long":     "显示两个目录（文件路径或已安装名称）之间新增、删除和变化的价格。\n只给一个参数时与最新安装的目录比较；不带参数时比较最新安装的两个目录。",
+	"cost_catalog_diff_need_two": "至少需要两个价格目录才能比较",
+	"cost_catalog_diff_title":    "价格目录 %s（%s）-> %s（%s）",
+	"cost_catalog_diff_none":     "价格没有差异",
+	"plan_cost_diff_title":       "💰 费用变化（%s/月）：%.2f → %.2f（%s）",
+	"plan_cost_unavailable":      "价格不可用",
+	"plan_cost_diff_failed":      "无法估算费用变化: %v",
+	"flag_cost_catalog_version":  "目录版本（默认：当天日期）",
+
+	// 汇率
+	"cost_rates_short":                "查看费用换算使用的汇率",
+	"cost_rates_long":                 "列出各支持币种到指定币种（默认为 profile 的显示币种）的汇率。\n汇率来自 profile 配置的汇率来源：内置汇率、静态 JSON 文件或 ECB 格式的 XML 汇率源（缓存在本地），手动汇率优先。\n费用估算、费用台账与账单对账都会换算到显示币种，并记录使用的汇率。",
+	"cost_rates_title":                "显示币种: %s，汇率来源: %s",
+	"cost_rates_set_short":            "设置当前 profile 的显示币种与汇率来源",
+	"cost_rates_saved":                "币种设置已保存到 profile %s",
+	"cost_rates_override_invalid":     "无效的手动汇率 %s，格式为 FROM_TO=汇率，例如 USD_CNY=7.1",
+	"flag_cost_rates_display":         "所有费用数据的显示币种（为空时保留云厂商计价币种）",
+	"flag_cost_rates_source":          "汇率来源：default、static 或 ecb",
+	"flag_cost_rates_file":            "static 来源使用的 JSON 汇率表",
+	"flag_cost_rates_url":             "ECB 格式的 XML 汇率地址（默认：ECB 每日参考汇率）",
+	"flag_cost_rates_cache_hours":     "汇率缓存小时数（默认 24）",
+	"flag_cost_rates_override":        "手动汇率 FROM_TO=汇率，可重复",
+	"flag_cost_rates_clear_overrides": "清除所有手动汇率",
+
+	// 资源标签
+	"case_tags_failed":      "生成资源标签失败，资源将不带 redc 标签创建: %v",
+	"case_tags_unsupported": "%s 的资源无法注入 redc 标签（%s），对账与孤儿资源检测无法归属这些资源",
+
+	// 孤儿资源
+	"orphans_short":           "查找不属于任何场景的云主机",
+	"orphans_scan_short":      "扫描云账号中的孤儿云主机",
+	"orphans_scan_long":       "列出已配置云账号中的云主机实例，报告不属于 redc.db 中任何场景的实例。不扫描其他资源类型（EIP、云盘、NAT 网关、安全组）。\n  tag      实例带有本地项目的 redc_case_id 标签，但对应的场景已不存在\n  foreign  实例带有 redc_case_id 标签，但 redc_project 不是本地项目（可能属于其他机器上的 redc）\n  name     实例名称符合场景自动命名规则（如 red_pig_xxx），但没有同名场景\n未指定 --regions 时扫描各云厂商配置的默认地域。\n使用 --cleanup 逐个确认后删除孤儿实例，加上 --yes 不再确认直接删除。--yes 只删除 tag 类孤儿实例，foreign 与 name 类必须逐个确认。",
+	"orphans_none":            "没有发现孤儿云主机",
+	"orphans_found":           "发现 %d 个孤儿云主机，使用 --cleanup 删除",
+	"orphans_unverified":      "其中 %d 个无法归属到本地项目（foreign/name），--yes 不会删除",
+	"orphans_confirm":         "删除 %s %s %s（%s，%s）？[y/N] ",
+	"orphans_confirm_foreign": "%s %s %s（%s，%s）无法归属到本地项目，可能属于其他机器上的 redc，仍然删除？[y/N] ",
+	"orphans_deleted":         "已删除 %s %s",
+	"orphans_delete_failed":   "删除 %s %s 失败: %s",
+	"orphans_skipped_foreign": "已跳过 %s %s：无法归属到本地项目，需要不带 --yes 逐个确认删除",
+	"orphans_cleanup_summary": "已删除 %d / %d 个孤儿云主机",
+	"orphans_yes_required":    "JSON 输出无法交互确认，--cleanup 需要同时指定 --yes",
+	"flag_orphans_providers":  "要扫描的云厂商，逗号分隔（默认全部：%s）",
+	"flag_orphans_regions":    "要扫描的地域，逗号分隔（默认使用各云厂商配置的地域）",
+	"flag_orphans_cleanup":    "扫描后删除孤儿云主机",
+	"flag_orphans_yes":        "删除 tag 类孤儿实例前不再确认（配合 --cleanup）",
+
+	// Template version pinning
+	"case_template_changed":       "模板 %s 在场景 %s 创建后已在本地修改（创建时 v%s，当前 v%s），场景仍使用创建时复制的文件",
+	"case_template_lock_mismatch": "本地模板 %s 为 v%s，但 %s 锁定为 v%s，可运行 redc pull --locked 安装锁定版本",
+	"case_template_hash_failed":   "计算模板 %s 摘要失败: %v",
+	"template_lock_read_failed":   "读取 %s 失败: %v",
+	"template_lock_write_failed":  "更新 %s 失败: %v",
+	"flag_pull_locked":            "安装项目 redc.lock 中锁定的模板版本（不带参数时安装全部）",
+
+	// Case template upgrade
+	"case_short":                  "管理场景",
+	"case_upgrade_short":          "将场景升级到新版本模板",
+	"case_upgrade_long":           "将新版本模板复制到场景目录，保留新版本仍声明的变量并展示 terraform plan，确认后应用；原文件保存为快照，可用 redc case rollback 回滚。运行中的场景直接更新，其他场景在下次启动时使用新文件。",
+	"case_rollback_short":         "从快照恢复场景的模板文件",
+	"case_rollback_long":          "恢复 redc case upgrade 保存的模板文件和变量（默认最近的快照），展示 plan 并在确认后应用。",
+	"case_upgrade_yes_required":   "JSON 输出时必须指定 --yes",
+	"case_upgrade_confirm":        "确认对 %s (%s) 应用以上变更? [y/N] ",
+	"case_upgrade_cancelled":      "已取消升级，原文件已恢复",
+	"case_upgrade_failed":         "升级失败: %v。修复后运行 redc case upgrade %s --resume 重试，或运行 redc case rollback %s",
+	"case_upgrade_pending":        "场景 %s 有待应用的升级 (%s)，运行 redc case upgrade %s --resume 应用或 redc case rollback %s 取消",
+	"case_upgrade_success":        "场景 %s (%s) 已使用 %s:%s",
+	"case_upgrade_summary":        "模板 %s: v%s -> v%s",
+	"case_upgrade_vars_dropped":   "新版本不再声明的变量（已丢弃）: %s",
+	"case_upgrade_vars_new":       "新增变量（使用默认值或 -e 指定）: %s",
+	"case_upgrade_snapshot":       "当前文件已保存到 %s",
+	"case_upgrade_restore_failed": "恢复原文件失败: %v",
+	"case_upgrade_reinit_failed":  "恢复文件后 terraform init 失败: %v",
+	"case_snapshot_remove_failed": "删除快照 %s 失败: %v",
+	"flag_case_upgrade_to":        "目标模板版本（默认使用本地安装的模板）",
+	"flag_case_upgrade_env":       "设置或覆盖模板变量 (格式: key=value)",
+	"flag_case_upgrade_yes":       "不询问直接应用",
+	"flag_case_upgrade_resume":    "重新生成并应用中断或失败后待应用的升级",
+
+	// Template registries
+	"registry_short":         "管理模板仓库",
+	"registry_add_short":     "添加模板仓库（按顺序查找，--first 优先查找）",
+	"registry_ls_short":      "按查找顺序列出模板仓库",
+	"registry_rm_short":      "删除模板仓库",
+	"registry_added":         "已添加仓库 %s",
+	"registry_removed":       "已删除仓库 %s",
+	"registry_unavailable":   "仓库 %s 不可用: %v",
+	"flag_registry":          "仓库名称或地址（默认按顺序使用所有配置的仓库）",
+	"flag_registry_token":    "Bearer token，配合 --username 时作为密码",
+	"flag_registry_username": "Basic 认证用户名",
+	"flag_registry_ca_file":  "校验仓库证书的 CA bundle (PEM)",
+	"flag_registry_first":    "优先于已有仓库查找",
 }
@@ -27,6 +27,10 @@ func main() {
 		runServerMode()
 		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == "daemon" {
+		runDaemonMode()
+		return
+	}
 
 	// Create an instance of the app structure
 	app := NewApp()
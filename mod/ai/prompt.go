@@ -35,6 +35,8 @@ const TemplateGenerationSystemPrompt = `你是一个 RedC 场景模板生成助
   "description_en": "英文描述",
   "template": "preset"
 }
+可选字段 liveness_probe：抢占式实例的存活探测，如 {"type": "http", "port": 80, "path": "/"}，type 可选 ssh / tcp / http / https / cloud，默认探测 SSH 22 端口
+可选字段 disable_resource_tags：设为 true 时 redc 不为资源注入 redc_case_id 等标准标签，仅在资源不支持标签时使用
 
 ## Terraform 最佳实践
 - 使用小型实例（t3.micro, t2.micro, ecs.t6-lite 等）适合渗透测试
@@ -0,0 +1,326 @@
+package mod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"red-cloud/mod/cost"
+)
+
+// ErrBillingUnsupported 云厂商不提供资源级账单明细
+var ErrBillingUnsupported = errors.New("provider does not expose resource-level billing")
+
+// BillLineItem 一条资源级账单明细
+type BillLineItem struct {
+	Provider   string            `json:"provider"`
+	ResourceID string            `json:"resourceId,omitempty"`
+	Name       string            `json:"name,omitempty"`
+	Product    string            `json:"product,omitempty"`
+	Region     string            `json:"region,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Amount     float64           `json:"amount"`
+	Currency   string            `json:"currency"`
+	// 换算到报表币种前的金额与使用的汇率，未换算时为空
+	OriginalAmount   float64            `json:"originalAmount,omitempty"`
+	OriginalCurrency string             `json:"originalCurrency,omitempty"`
+	ExchangeRate     *cost.ExchangeRate `json:"exchangeRate,omitempty"`
+}
+
+// BillingSource 按账期读取资源级账单明细，month 格式为 2006-01
+type BillingSource interface {
+	Provider() string
+	LineItems(ctx context.Context, month string) ([]BillLineItem, error)
+}
+
+// billingSourceFactories 各云厂商账单数据源的构造函数
+var billingSourceFactories = map[string]func(conf *Config) (BillingSource, error){
+	"aliyun":       newAliyunBillingSource,
+	"tencentcloud": newTencentBillingSource,
+	"aws":          newAWSBillingSource,
+	"huaweicloud":  newHuaweiBillingSource,
+	"volcengine":   newVolcengineBillingSource,
+	"ucloud":       newUCloudBillingSource,
+}
+
+// unsupportedBillingProviders 没有资源级账单 API 的云厂商及原因，配置了凭据时在对账报表中明确列出
+var unsupportedBillingProviders = map[string]string{
+	"vultr":  "Vultr only exposes invoice totals, not per-resource bill lines",
+	"google": "GCP resource-level billing is only available through the BigQuery billing export",
+}
+
+// billingCredentialsConfigured 判断配置中是否填写了不支持对账的云厂商的凭据
+func billingCredentialsConfigured(provider string, conf *Config) bool {
+	switch provider {
+	case "vultr":
+		return conf.Providers.Vultr.ApiKey != ""
+	case "google":
+		return conf.Providers.Google.Credentials != "" || conf.Providers.Google.Project != ""
+	}
+	return false
+}
+
+// BillingProviders 支持账单对账的云厂商
+func BillingProviders() []string {
+	providers := make([]string, 0, len(billingSourceFactories))
+	for p := range billingSourceFactories {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// NewBillingSource 使用配置中的凭据创建账单数据源
+func NewBillingSource(provider string, conf *Config) (BillingSource, error) {
+	factory, ok := billingSourceFactories[provider]
+	if !ok {
+		if reason, known := unsupportedBillingProviders[provider]; known {
+			return nil, fmt.Errorf("%s: %w: %s", provider, ErrBillingUnsupported, reason)
+		}
+		return nil, fmt.Errorf("%s: %w", provider, ErrBillingUnsupported)
+	}
+	return factory(conf)
+}
+
+// ReconcileCase 单个场景的估算费用与账单金额
+type ReconcileCase struct {
+	ProjectID string  `json:"project,omitempty"`
+	CaseID    string  `json:"caseId"`
+	CaseName  string  `json:"caseName"`
+	Estimated float64 `json:"estimated"` // 费用台账按估算价格累计的金额
+	Billed    float64 `json:"billed"`    // 归属到该场景的账单金额
+	Diff      float64 `json:"diff"`      // Billed - Estimated
+	Items     int     `json:"items"`
+}
+
+// ReconcileProvider 单个云厂商的账单读取结果
+type ReconcileProvider struct {
+	Provider    string  `json:"provider"`
+	Items       int     `json:"items"`
+	Billed      float64 `json:"billed"`
+	Error       string  `json:"error,omitempty"`
+	Unsupported bool    `json:"unsupported,omitempty"` // 云厂商不提供资源级账单，费用未计入报表
+}
+
+// ReconcileReport 账单对账报表
+type ReconcileReport struct {
+	Month          string              `json:"month"`
+	Currency       string              `json:"currency"`
+	EstimatedTotal float64             `json:"estimatedTotal"`
+	BilledTotal    float64             `json:"billedTotal"`
+	TrackedTotal   float64             `json:"trackedTotal"`
+	UntrackedTotal float64             `json:"untrackedTotal"`
+	Providers      []ReconcileProvider `json:"providers"`
+	Cases          []ReconcileCase     `json:"cases"`
+	Untracked      []BillLineItem      `json:"untracked"`              // 有账单但不属于任何场景的资源
+	Rates          []cost.ExchangeRate `json:"rates,omitempty"`        // 换算到 Currency 时使用的汇率
+	MissingRates   []string            `json:"missingRates,omitempty"` // 没有汇率、未计入合计的币种，非空时合计不完整
+}
+
+// ReconcileInput 对账输入
+type ReconcileInput struct {
+	Month     string
+	Currency  string
+	Sources   []BillingSource
+	Resources []LedgerResource // 资源 ID -> 场景的归属索引
+	Estimates *LedgerReport    // 同一账期的费用台账报表
+}
+
+// ReconcileBilling 读取各数据源的账单明细，按资源 ID / 标签归属到场景并与估算费用对比
+func ReconcileBilling(ctx context.Context, in ReconcileInput) *ReconcileReport {
+	currency := strings.ToUpper(in.Currency)
+	if currency == "" && in.Estimates != nil {
+		currency = in.Estimates.Currency
+	}
+	if currency == "" {
+		currency = DisplayCurrency()
+	}
+	if currency == "" {
+		currency = string(cost.CurrencyCNY)
+	}
+	report := &ReconcileReport{
+		Month:     in.Month,
+		Currency:  currency,
+		Providers: []ReconcileProvider{},
+		Cases:     []ReconcileCase{},
+		Untracked: []BillLineItem{},
+	}
+
+	byResource := make(map[string]LedgerResource, len(in.Resources))
+	byCase := make(map[string]LedgerResource)
+	for _, r := range in.Resources {
+		byResource[strings.ToLower(r.ResourceID)] = r
+		byCase[r.CaseID] = r
+	}
+	if in.Estimates != nil {
+		for _, c := range in.Estimates.Cases {
+			if _, ok := byCase[c.CaseID]; !ok {
+				byCase[c.CaseID] = LedgerResource{CaseID: c.CaseID, ProjectID: c.ProjectID, CaseName: c.CaseName}
+			}
+		}
+	}
+
+	index := make(map[string]int)
+	row := func(caseID, projectID, name string) *ReconcileCase {
+		i, ok := index[caseID]
+		if !ok {
+			report.Cases = append(report.Cases, ReconcileCase{ProjectID: projectID, CaseID: caseID, CaseName: name})
+			i = len(report.Cases) - 1
+			index[caseID] = i
+		}
+		return &report.Cases[i]
+	}
+	normalizer := newCurrencyNormalizer(currency)
+	if in.Estimates != nil {
+		for _, c := range in.Estimates.Cases {
+			r := row(c.CaseID, c.ProjectID, c.CaseName)
+			estimated, _, err := normalizer.convert(c.Cost, in.Estimates.Currency)
+			if err != nil {
+				continue
+			}
+			r.Estimated += estimated
+			report.EstimatedTotal += estimated
+		}
+	}
+
+	for _, src := range in.Sources {
+		status := ReconcileProvider{Provider: src.Provider()}
+		items, err := src.LineItems(ctx, in.Month)
+		if err != nil {
+			status.Error = err.Error()
+			report.Providers = append(report.Providers, status)
+			continue
+		}
+		for _, item := range items {
+			amount, rate, err := normalizer.convert(item.Amount, item.Currency)
+			if err != nil {
+				continue
+			}
+			status.Items++
+			status.Billed += amount
+			report.BilledTotal += amount
+
+			if owner, ok := attributeLineItem(item, byResource, byCase); ok {
+				r := row(owner.CaseID, owner.ProjectID, owner.CaseName)
+				r.Billed += amount
+				r.Items++
+				report.TrackedTotal += amount
+				continue
+			}
+			if rate != nil {
+				item.OriginalAmount, item.OriginalCurrency, item.ExchangeRate = item.Amount, item.Currency, rate
+			}
+			item.Amount, item.Currency = amount, currency
+			report.Untracked = append(report.Untracked, item)
+			report.UntrackedTotal += amount
+		}
+		report.Providers = append(report.Providers, status)
+	}
+
+	report.Rates, report.MissingRates = normalizer.rates, normalizer.missing
+	for i := range report.Cases {
+		report.Cases[i].Diff = report.Cases[i].Billed - report.Cases[i].Estimated
+	}
+	sort.SliceStable(report.Cases, func(i, j int) bool {
+		return report.Cases[i].Billed+report.Cases[i].Estimated > report.Cases[j].Billed+report.Cases[j].Estimated
+	})
+	sort.SliceStable(report.Untracked, func(i, j int) bool {
+		return report.Untracked[i].Amount > report.Untracked[j].Amount
+	})
+	return report
+}
+
+// attributeLineItem 先按 redc_case_id 标签、再按资源 ID 查找账单明细所属的场景
+// 标签指向台账中不存在的场景（已删除的记录或其他 redc 实例创建的场景）时不归属，计入未跟踪费用
+func attributeLineItem(item BillLineItem, byResource map[string]LedgerResource, byCase map[string]LedgerResource) (LedgerResource, bool) {
+	if id := item.Tags[TagCaseID]; id != "" {
+		owner, ok := byCase[id]
+		return owner, ok
+	}
+	if item.ResourceID == "" {
+		return LedgerResource{}, false
+	}
+	// 部分账单的实例 ID 带有附加信息，例如 "i-xxx;cn-hangzhou"
+	for _, part := range strings.FieldsFunc(item.ResourceID, func(r rune) bool { return r == ';' || r == ',' }) {
+		if owner, ok := byResource[strings.ToLower(strings.TrimSpace(part))]; ok {
+			return owner, true
+		}
+	}
+	return LedgerResource{}, false
+}
+
+// BuildReconcileReport 对指定账期执行对账：providers 为空时使用所有支持的云厂商
+// 先刷新运行中场景的资源记录，使当前项目新建的资源也能被归属
+func BuildReconcileReport(ctx context.Context, projectID string, month string, providers []string, currency string) (*ReconcileReport, error) {
+	// 按月份解析而不是比较时长，夏令时地区的月份不是整数天
+	from, err := time.ParseInLocation("2006-01", strings.TrimSpace(month), time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("账期必须是月份，例如 2026-03")
+	}
+	to := from.AddDate(0, 1, 0)
+	month = from.Format("2006-01")
+
+	conf, _, err := ReadConfig(ActiveConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(providers) == 0 {
+		providers = BillingProviders()
+		// 配置了凭据但无法对账的云厂商也列入报表，避免其费用被静默遗漏
+		for p := range unsupportedBillingProviders {
+			if billingCredentialsConfigured(p, conf) {
+				providers = append(providers, p)
+			}
+		}
+		sort.Strings(providers[len(BillingProviders()):])
+	}
+	var sources []BillingSource
+	var failed []ReconcileProvider
+	for _, p := range providers {
+		src, err := NewBillingSource(p, conf)
+		if err != nil {
+			failed = append(failed, ReconcileProvider{Provider: p, Error: err.Error(), Unsupported: errors.Is(err, ErrBillingUnsupported)})
+			continue
+		}
+		sources = append(sources, src)
+	}
+
+	ledger, err := NewCostLedger()
+	if err != nil {
+		return nil, err
+	}
+	defer ledger.Close()
+
+	if projectID != "" {
+		if cases, err := LoadProjectCases(projectID); err == nil {
+			now := time.Now()
+			for _, c := range cases {
+				if IsRunningState(c.State) {
+					ledger.recordResources(c, now)
+				}
+			}
+		}
+	}
+	resources, err := ledger.Resources()
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := ledger.Intervals("", from, to)
+	if err != nil {
+		return nil, err
+	}
+	estimates := BuildLedgerReport(intervals, from, to, time.Now(), currency)
+
+	report := ReconcileBilling(ctx, ReconcileInput{
+		Month:     month,
+		Currency:  currency,
+		Sources:   sources,
+		Resources: resources,
+		Estimates: estimates,
+	})
+	report.Providers = append(report.Providers, failed...)
+	return report, nil
+}
@@ -0,0 +1,176 @@
+package mod
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+type fakeBillingSource struct {
+	provider string
+	items    []BillLineItem
+	err      error
+}
+
+func (f *fakeBillingSource) Provider() string { return f.provider }
+
+func (f *fakeBillingSource) LineItems(context.Context, string) ([]BillLineItem, error) {
+	return f.items, f.err
+}
+
+func TestReconcileBilling(t *testing.T) {
+	resources := []LedgerResource{
+		{CaseID: "a", ProjectID: "x", CaseName: "web", ResourceID: "i-web"},
+		{CaseID: "b", ProjectID: "x", CaseName: "c2", ResourceID: "ins-c2"},
+	}
+	estimates := &LedgerReport{Currency: "CNY", Cases: []LedgerCaseCost{
+		{ProjectID: "x", CaseID: "a", CaseName: "web", Cost: 10},
+		{ProjectID: "x", CaseID: "d", CaseName: "idle", Cost: 3},
+	}}
+	sources := []BillingSource{
+		&fakeBillingSource{provider: "aliyun", items: []BillLineItem{
+			{Provider: "aliyun", ResourceID: "i-web;cn-hangzhou", Amount: 12, Currency: "CNY"},
+			{Provider: "aliyun", ResourceID: "i-forgotten", Product: "ECS", Amount: 5, Currency: "CNY"},
+		}},
+		&fakeBillingSource{provider: "tencentcloud", items: []BillLineItem{
+			{Provider: "tencentcloud", ResourceID: "INS-C2", Amount: 2, Currency: "CNY"},
+		}},
+		&fakeBillingSource{provider: "aws", items: []BillLineItem{
+			// Tagged with a case ID: attributed without a resource match
+			{Provider: "aws", Product: "EC2", Tags: map[string]string{TagCaseID: "a"}, Amount: 1, Currency: "USD"},
+			{Provider: "aws", Product: "Tax", Amount: 0.5, Currency: "USD"},
+			// Tagged with a case redc does not know: unattributed spend
+			{Provider: "aws", Product: "S3", Tags: map[string]string{TagCaseID: "gone"}, Amount: 0.25, Currency: "USD"},
+		}},
+		&fakeBillingSource{provider: "gcp", err: errors.New("denied")},
+	}
+
+	report := ReconcileBilling(context.Background(), ReconcileInput{
+		Month: "2026-03", Sources: sources, Resources: resources, Estimates: estimates,
+	})
+
+	if report.Currency != "CNY" || len(report.Providers) != 4 || report.Providers[3].Error != "denied" {
+		t.Fatalf("report = %+v", report)
+	}
+	byCase := map[string]ReconcileCase{}
+	for _, c := range report.Cases {
+		byCase[c.CaseID] = c
+	}
+	if c := byCase["a"]; !approx(c.Billed, 12+7.2) || c.Items != 2 || !approx(c.Diff, 12+7.2-10) {
+		t.Errorf("case a = %+v", c)
+	}
+	if c := byCase["b"]; !approx(c.Billed, 2) || c.Estimated != 0 {
+		t.Errorf("case b = %+v", c)
+	}
+	if c := byCase["d"]; c.Billed != 0 || !approx(c.Diff, -3) {
+		t.Errorf("case d = %+v", c)
+	}
+	if _, ok := byCase["gone"]; ok {
+		t.Error("unknown case ID from a tag must not be attributed")
+	}
+	if len(report.Untracked) != 3 || report.Untracked[0].ResourceID != "i-forgotten" || !approx(report.Untracked[1].Amount, 3.6) ||
+		report.Untracked[2].Tags[TagCaseID] != "gone" {
+		t.Errorf("untracked = %+v", report.Untracked)
+	}
+	if !approx(report.UntrackedTotal, 10.4) || !approx(report.TrackedTotal, 21.2) ||
+		!approx(report.BilledTotal, 31.6) || !approx(report.EstimatedTotal, 13) {
+		t.Errorf("totals = %+v", report)
+	}
+}
+
+func TestReconcileBilling_SeveralEstimatesPerCase(t *testing.T) {
+	estimates := &LedgerReport{Currency: "CNY", Cases: []LedgerCaseCost{
+		{ProjectID: "x", CaseID: "a", CaseName: "web", Cost: 4},
+		{ProjectID: "x", CaseID: "a", CaseName: "web", Cost: 6},
+	}}
+	report := ReconcileBilling(context.Background(), ReconcileInput{Month: "2026-03", Estimates: estimates})
+
+	if len(report.Cases) != 1 || !approx(report.Cases[0].Estimated, 10) {
+		t.Errorf("cases = %+v, want one case estimated at 10", report.Cases)
+	}
+	if !approx(report.EstimatedTotal, 10) {
+		t.Errorf("EstimatedTotal = %v, want 10", report.EstimatedTotal)
+	}
+}
+
+func TestParseAliyunBillTags(t *testing.T) {
+	got := parseAliyunBillTags("key:redc_case_id value:abc; key:team value:red team")
+	want := map[string]string{TagCaseID: "abc", "team": "red team"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAliyunBillTags = %v, want %v", got, want)
+	}
+	if parseAliyunBillTags("") != nil {
+		t.Error("empty tag string should give nil")
+	}
+}
+
+func TestParseHuaweiBillTags(t *testing.T) {
+	got := parseHuaweiBillTags("redc_case_id:abc;team=red team")
+	want := map[string]string{TagCaseID: "abc", "team": "red team"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHuaweiBillTags = %v, want %v", got, want)
+	}
+}
+
+func TestParseVolcengineBillTags(t *testing.T) {
+	want := map[string]string{TagCaseID: "abc", "team": "red team"}
+	for _, raw := range []string{
+		`[{"Key":"redc_case_id","Value":"abc"},{"Key":"team","Value":"red team"}]`,
+		`{"redc_case_id":"abc","team":"red team"}`,
+		"redc_case_id:abc;team:red team",
+	} {
+		if got := parseVolcengineBillTags(raw); !reflect.DeepEqual(got, want) {
+			t.Errorf("parseVolcengineBillTags(%q) = %v, want %v", raw, got, want)
+		}
+	}
+	if parseVolcengineBillTags("") != nil {
+		t.Error("empty tag string should give nil")
+	}
+}
+
+func TestNewBillingSource_Unsupported(t *testing.T) {
+	conf := &Config{}
+	for _, p := range []string{"volcengine", "ucloud"} {
+		if _, err := NewBillingSource(p, conf); err == nil || errors.Is(err, ErrBillingUnsupported) {
+			t.Errorf("%s without credentials: err = %v, want a missing credentials error", p, err)
+		}
+	}
+	for _, p := range []string{"vultr", "google", "azurerm"} {
+		if _, err := NewBillingSource(p, conf); !errors.Is(err, ErrBillingUnsupported) {
+			t.Errorf("%s: err = %v, want ErrBillingUnsupported", p, err)
+		}
+	}
+	if billingCredentialsConfigured("vultr", conf) {
+		t.Error("vultr without an API key should not be listed")
+	}
+	conf.Providers.Vultr.ApiKey = "key"
+	if !billingCredentialsConfigured("vultr", conf) {
+		t.Error("vultr with an API key should be listed as unsupported")
+	}
+}
+
+func TestResourcesFromModule(t *testing.T) {
+	root := &tfjson.StateModule{
+		Resources: []*tfjson.StateResource{
+			{Type: "alicloud_instance", Mode: tfjson.ManagedResourceMode, ProviderName: "registry.terraform.io/aliyun/alicloud",
+				AttributeValues: map[string]interface{}{"id": "i-1", "instance_id": "i-1"}},
+			{Type: "alicloud_images", Mode: tfjson.DataResourceMode, AttributeValues: map[string]interface{}{"id": "data"}},
+		},
+		ChildModules: []*tfjson.StateModule{{Resources: []*tfjson.StateResource{
+			{Type: "aws_spot_instance_request", Mode: tfjson.ManagedResourceMode, ProviderName: "registry.terraform.io/hashicorp/aws",
+				AttributeValues: map[string]interface{}{"id": "sir-1", "spot_instance_id": "i-0aws"}},
+		}}},
+	}
+	got := resourcesFromModule(root, nil)
+	want := []CaseResource{
+		{ID: "i-1", Type: "alicloud_instance", Provider: "alicloud"},
+		{ID: "sir-1", Type: "aws_spot_instance_request", Provider: "aws"},
+		{ID: "i-0aws", Type: "aws_spot_instance_request", Provider: "aws"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resourcesFromModule = %+v", got)
+	}
+}
@@ -17,27 +17,30 @@ import (
 	"github.com/hashicorp/terraform-exec/tfexec"
 )
 
+// randomFirstNames / randomLastNames RandomName 使用的词表，孤儿资源扫描按同样的规则识别
+var randomFirstNames = []string{
+	"red", "blue", "yellow", "brown", "purple", "anger", "lazy", "shy", "huge", "rare",
+	"fast", "stupid", "sluggish", "boring", "rigid", "rigorous", "clever", "dexterity",
+	"white", "black", "dark", "idiot", "shiny", "friendly", "integrity", "happy", "sad",
+	"lively", "lonely", "ugly", "leisurely", "calm", "young", "tenacious", "admiring",
+	"agitated", "boring", "clever", "compassionate", "condescending", "cranky", "desperate",
+	"distracted", "ecstatic", "focused", "goofy", "hungry", "jolly", "modest", "naughty", "nostalgic",
+	"pensive", "recursing", "sleepy", "thirsty", "xenodochial", "zen", "niubi",
+}
+
+var randomLastNames = []string{
+	"pig", "cow", "sheep", "mouse", "dragon", "serpent", "tiger", "fox", "frog", "chicken",
+	"fish", "shrimp", "hippocampus", "helicopter", "crab", "dolphin", "whale", "chinchilla",
+	"bunny", "mole", "rabbit", "horse", "monkey", "dog", "shark", "panda", "bear", "lion",
+	"rhino", "leopard", "giraffe", "deer", "wolf", "parrot", "camel", "antelope", "turtle",
+	"zebra", "hacker",
+}
+
 func RandomName(s string) string {
-	var firstName = []string{
-		"red", "blue", "yellow", "brown", "purple", "anger", "lazy", "shy", "huge", "rare",
-		"fast", "stupid", "sluggish", "boring", "rigid", "rigorous", "clever", "dexterity",
-		"white", "black", "dark", "idiot", "shiny", "friendly", "integrity", "happy", "sad",
-		"lively", "lonely", "ugly", "leisurely", "calm", "young", "tenacious", "admiring",
-		"agitated", "boring", "clever", "compassionate", "condescending", "cranky", "desperate",
-		"distracted", "ecstatic", "focused", "goofy", "hungry", "jolly", "modest", "naughty", "nostalgic",
-		"pensive", "recursing", "sleepy", "thirsty", "xenodochial", "zen", "niubi",
-	}
-	var lastName = []string{
-		"pig", "cow", "sheep", "mouse", "dragon", "serpent", "tiger", "fox", "frog", "chicken",
-		"fish", "shrimp", "hippocampus", "helicopter", "crab", "dolphin", "whale", "chinchilla",
-		"bunny", "mole", "rabbit", "horse", "monkey", "dog", "shark", "panda", "bear", "lion",
-		"rhino", "leopard", "giraffe", "deer", "wolf", "parrot", "camel", "antelope", "turtle",
-		"zebra", "hacker",
-	}
 	rand.Seed(time.Now().UnixNano())
 begin:
-	first := firstName[rand.Intn(len(firstName)-1)]
-	last := lastName[rand.Intn(len(lastName)-1)]
+	first := randomFirstNames[rand.Intn(len(randomFirstNames)-1)]
+	last := randomLastNames[rand.Intn(len(randomLastNames)-1)]
 	// NO NO NO ~
 	if (first == "stupid" || first == "goofy") && last == "wolf" {
 		goto begin
@@ -308,9 +311,16 @@ func (p *RedcProject) CaseCreate(CaseName string, User string, Name string, vars
 	// 读取模板元数据
 	meta, _ := readTemplateMeta(tpPath)
 	pluginsStr := ""
+	tmplVersion := ""
 	if meta != nil {
 		pluginsStr = meta.RedcPlugins
+		tmplVersion = meta.Version
+	}
+	tmplHash, err := TemplateHash(tpPath)
+	if err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_template_hash_failed", CaseName, err))
 	}
+	p.checkTemplateLock(CaseName, tmplVersion, tmplHash)
 
 	// 初始化实例
 	c := &Case{
@@ -324,9 +334,18 @@ func (p *RedcProject) CaseCreate(CaseName string, User string, Name string, vars
 		Parameter:  par,
 		ProjectID:  p.ProjectName,
 		State:      StatePending,
+
+		TemplateVersion: tmplVersion,
+		TemplateSHA256:  tmplHash,
 	}
 	// 绑定 project 参数
 	c.bindHandlers()
+	c.pluginHookRunner = p.pluginHookRunner
+
+	// 注入标准资源标签，TTL 在启动前重新生成时写入
+	if err := WriteCaseTags(c, time.Time{}); err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_tags_failed", err))
+	}
 
 	// 构建场景
 	if err := c.TfPlan(); err != nil {
@@ -342,37 +361,61 @@ func (p *RedcProject) CaseCreate(CaseName string, User string, Name string, vars
 		return nil, err
 	}
 	RedcLog("创建成功 " + p.ProjectPath + "/" + uid + " " + CaseName)
+	// post-create hook，场景已保存，钩子失败只记录日志
+	c.runPostHook("post-create")
 	return c, nil
 }
 
 func (c *Case) TfApply() error {
 	var err error
 	gologger.Info().Msgf("%s", i18n.Tf("case_starting", c.Name, c.GetId()))
-	if c.State == StateRunning {
+	// degraded 的场景资源仍然存在，同样不能重复启动
+	if IsRunningState(c.State) {
 		return fmt.Errorf("%s", i18n.T("case_scene_running"))
 	}
+	// 升级写入的新文件尚未确认，不能按其启动
+	if err = c.CheckPendingUpgrade(); err != nil {
+		return err
+	}
 	
+	c.warnTemplateChanged()
+
 	// 设置为正在启动状态
+	prev := c.State
 	c.StatusChange(StateStarting)
 	
-	// pre-apply hook
-	c.runPluginHook("pre-apply")
+	// pre-apply hook，策略插件可以阻止启动
+	if err = c.runPluginHook("pre-apply"); err != nil {
+		c.StatusChange(prev)
+		return err
+	}
 	
+	// 刷新资源标签（生命周期策略可能在创建后才设置 TTL）
+	if err = WriteCaseTags(c, c.tagExpiresAt()); err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_tags_failed", err))
+	}
+
 	// 重新生成 plan 以确保与当前 state 一致
 	gologger.Info().Msg(i18n.T("case_plan_refreshing"))
 	if err = TfPlan(c.Path, c.Parameter...); err != nil {
-		c.StatusChange(StateError)
-		return fmt.Errorf("%s", i18n.Tf("case_plan_refresh_failed", err))
+		err = fmt.Errorf("%s", i18n.Tf("case_plan_refresh_failed", err))
+		c.markError(err)
+		return err
 	}
 	
 	if err = TfApply(c.Path, c.Parameter...); err != nil {
-		c.StatusChange(StateError)
+		c.markError(err)
 		// 启动失败立即销毁
 		if err := c.TfDestroy(); err != nil {
 			return err
 		}
 		return err
 	}
+	return c.finishApply()
+}
+
+// finishApply apply 成功后更新状态、输出与保存记录并运行 post-apply 钩子
+func (c *Case) finishApply() error {
 	c.StatusChange(StateRunning)
 	output, err := c.TfOutput()
 	if err != nil {
@@ -386,7 +429,7 @@ func (c *Case) TfApply() error {
 		c.saveHandler()
 	}
 	// post-apply hook
-	c.runPluginHook("post-apply")
+	c.runPostHook("post-apply")
 	return nil
 }
 func (c *Case) GetInstanceInfo(id string) (string, error) {
@@ -445,23 +488,57 @@ func (c *Case) GetInstanceInfoList(id string) ([]string, error) {
 }
 
 // SetPluginHookRunner sets the callback for running plugin hooks
-func (c *Case) SetPluginHookRunner(runner func(hookPoint string, c *Case) error) {
+func (c *Case) SetPluginHookRunner(runner PluginHookRunner) {
 	c.pluginHookRunner = runner
 }
 
-// runPluginHook runs plugin hooks for a given hook point
-func (c *Case) runPluginHook(hookPoint string) {
+// runPluginHook runs plugin hooks for a given hook point.
+// The runner only returns an error when a hook declared with on_failure: abort fails,
+// which stops the operation; post-* hooks go through runPostHook instead.
+func (c *Case) runPluginHook(hookPoint string) error {
+	return c.runPluginHookEvent(hookPoint, PluginHookEvent{})
+}
+
+// runPluginHookEvent runs plugin hooks with extra context such as the error text or SSH command
+func (c *Case) runPluginHookEvent(hookPoint string, ev PluginHookEvent) error {
+	if c.pluginHookRunner == nil {
+		return nil
+	}
+	if ev.Project == "" {
+		ev.Project = c.ProjectID
+	}
+	if err := c.pluginHookRunner(hookPoint, c, ev); err != nil {
+		gologger.Error().Msgf("%s", i18n.Tf("case_hook_blocked", hookPoint, err))
+		return fmt.Errorf("%s", i18n.Tf("case_hook_blocked", hookPoint, err))
+	}
+	return nil
+}
+
+// runPostHook runs post-* hooks. The operation has already completed, so a failing hook
+// (even with on_failure: abort) is only logged and never turns it into a failure.
+func (c *Case) runPostHook(hookPoint string) {
 	if c.pluginHookRunner == nil {
 		return
 	}
-	if err := c.pluginHookRunner(hookPoint, c); err != nil {
-		gologger.Warning().Msgf("plugin hook %s: %v", hookPoint, err)
+	if err := c.pluginHookRunner(hookPoint, c, PluginHookEvent{Project: c.ProjectID}); err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_hook_post_failed", hookPoint, err))
 	}
 }
 
 // RunPluginHookPublic is a public wrapper for runPluginHook, used by spot monitor recovery
-func (c *Case) RunPluginHookPublic(hookPoint string) {
-	c.runPluginHook(hookPoint)
+func (c *Case) RunPluginHookPublic(hookPoint string) error {
+	return c.runPluginHook(hookPoint)
+}
+
+// RunPluginHookEvent runs plugin hooks with extra context, used by spot monitor termination
+func (c *Case) RunPluginHookEvent(hookPoint string, ev PluginHookEvent) error {
+	return c.runPluginHookEvent(hookPoint, ev)
+}
+
+// markError 将场景置为 error 并运行 on-error 钩子，钩子失败只记录日志
+func (c *Case) markError(cause error) {
+	c.StatusChange(StateError)
+	_ = c.runPluginHookEvent("on-error", PluginHookEvent{Error: cause.Error()})
 }
 
 func (c *Case) TfOutput() (map[string]tfexec.OutputMeta, error) {
@@ -489,16 +566,19 @@ func (c *Case) bindHandlers() {
 func (c *Case) TfPlan() error {
 	gologger.Info().Msgf("%s", i18n.Tf("case_building", c.Name, c.GetId()))
 	c.Parameter = ensureProviderParams(c.Type, c.Parameter)
-	c.runPluginHook("pre-plan")
+	if err := c.runPluginHook("pre-plan"); err != nil {
+		return err
+	}
 	if err := TfPlan(c.Path, c.Parameter...); err != nil {
 		return err
 	}
 	c.StatusChange(StateCreated)
-	c.runPluginHook("post-plan")
+	c.runPostHook("post-plan")
 	return nil
 }
 
 func (c *Case) StatusChange(s string) {
+	prev := c.State
 	c.State = s
 	// Use RFC3339 format to include timezone information
 	c.StateTime = time.Now().Format(time.RFC3339)
@@ -507,30 +587,35 @@ func (c *Case) StatusChange(s string) {
 			gologger.Error().Msgf("%s", i18n.Tf("case_save_state_failed", err))
 		}
 	}
+	recordCostLedger(c, prev)
 }
 
 func (c *Case) TfDestroy() error {
 	gologger.Info().Msgf("%s", i18n.Tf("case_destroying", c.Name, c.GetId()))
 	
 	// 设置为正在停止状态
+	prev := c.State
 	c.StatusChange(StateStopping)
 	
-	// pre-destroy hook
-	c.runPluginHook("pre-destroy")
+	// pre-destroy hook，策略插件可以阻止销毁
+	if err := c.runPluginHook("pre-destroy"); err != nil {
+		c.StatusChange(prev)
+		return err
+	}
 	
 	err := TfDestroy(c.Path, c.Parameter)
 	if err != nil {
 		gologger.Error().Msgf("%s", i18n.Tf("case_destroy_failed", err.Error()))
-		c.StatusChange(StateError)
+		c.markError(err)
 		return err
 	}
 	c.StatusChange(StateStopped)
 	// post-destroy hook
-	c.runPluginHook("post-destroy")
+	c.runPostHook("post-destroy")
 	return nil
 }
 func (c *Case) Remove() error {
-	if c.State == StateRunning {
+	if IsRunningState(c.State) {
 		return fmt.Errorf("%s", i18n.T("case_delete_running"))
 	}
 	
@@ -539,12 +624,14 @@ func (c *Case) Remove() error {
 	
 	err := os.RemoveAll(c.Path)
 	if err != nil {
-		c.StatusChange(StateError)
-		return fmt.Errorf("%s", i18n.Tf("case_delete_file_failed", err.Error()))
+		err = fmt.Errorf("%s", i18n.Tf("case_delete_file_failed", err.Error()))
+		c.markError(err)
+		return err
 	}
 	if err = c.removeHandle(); err != nil {
-		c.StatusChange(StateError)
-		return fmt.Errorf("%s", i18n.Tf("case_delete_db_failed", err))
+		err = fmt.Errorf("%s", i18n.Tf("case_delete_db_failed", err))
+		c.markError(err)
+		return err
 	}
 	gologger.Info().Msg(i18n.T("case_delete_success"))
 	return nil
@@ -552,13 +639,18 @@ func (c *Case) Remove() error {
 
 // Stop 停止场景
 func (c *Case) Stop() error {
-	if c.State != StateRunning {
+	if !IsRunningState(c.State) {
 		gologger.Warning().Msg(i18n.T("case_destroy_warning"))
 	}
+	// pre-stop hook，策略插件可以阻止停止
+	if err := c.runPluginHook("pre-stop"); err != nil {
+		return err
+	}
 	err := c.TfDestroy()
 	if err != nil {
 		return err
 	}
+	c.runPostHook("post-stop")
 	return nil
 }
 
@@ -582,6 +674,9 @@ func (c *Case) Kill() error {
 
 // Change 重建场景
 func (c *Case) Change(cc ChangeCommand) error {
+	if err := c.CheckPendingUpgrade(); err != nil {
+		return err
+	}
 	if cc.IsRemove {
 		// 销毁场景，不删除项目
 		gologger.Info().Msgf("%s", i18n.Tf("case_change_destroying", c.Name, c.Id))
@@ -605,6 +700,22 @@ func (c *Case) Change(cc ChangeCommand) error {
 	return nil
 }
 
+// DetectDrift 检查场景资源是否被 redc 之外的操作修改，发现漂移时运行 on-drift 钩子
+func (c *Case) DetectDrift() (bool, error) {
+	drifted, err := TfDriftCheck(c.Path, c.Parameter...)
+	if err != nil || !drifted {
+		return false, err
+	}
+	return true, c.ReportDrift()
+}
+
+// ReportDrift 记录漂移并运行 on-drift 钩子，供定期检查在发现新的漂移时调用
+func (c *Case) ReportDrift() error {
+	msg := i18n.Tf("case_drift_detected", c.Name, c.GetId())
+	gologger.Warning().Msgf("%s", msg)
+	return c.runPluginHookEvent("on-drift", PluginHookEvent{Error: msg})
+}
+
 func (c *Case) Status() error {
 	gologger.Info().Msgf("%s", i18n.Tf("case_status_format", c.Name, c.State))
 	state, err := TfStatus(c.Path)
@@ -0,0 +1,332 @@
+package mod
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"red-cloud/mod/cost"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// CostCredentialProvider 从当前配置文件读取费用查询使用的云厂商凭据
+func CostCredentialProvider(provider string) (accessKey, secretKey, region string, err error) {
+	conf, _, err := ReadConfig(ActiveConfigPath)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to read config: %w", err)
+	}
+
+	switch provider {
+	case "alicloud":
+		return conf.Providers.Alicloud.AccessKey, conf.Providers.Alicloud.SecretKey, conf.Providers.Alicloud.Region, nil
+	case "tencentcloud":
+		return conf.Providers.Tencentcloud.SecretId, conf.Providers.Tencentcloud.SecretKey, conf.Providers.Tencentcloud.Region, nil
+	case "aws":
+		return conf.Providers.Aws.AccessKey, conf.Providers.Aws.SecretKey, conf.Providers.Aws.Region, nil
+	case "volcengine":
+		return conf.Providers.Volcengine.AccessKey, conf.Providers.Volcengine.SecretKey, conf.Providers.Volcengine.Region, nil
+	case "huaweicloud":
+		return conf.Providers.Huaweicloud.AccessKey, conf.Providers.Huaweicloud.SecretKey, conf.Providers.Huaweicloud.Region, nil
+	case "ucloud":
+		return conf.Providers.UCloud.PublicKey, conf.Providers.UCloud.PrivateKey, conf.Providers.UCloud.Region, nil
+	case "vultr":
+		// Vultr 价格接口无需密钥，有 API Key 时一并携带
+		return conf.Providers.Vultr.ApiKey, "", "", nil
+	case "google":
+		// GCP 使用服务账号 JSON 文件路径作为 accessKey
+		return conf.Providers.Google.Credentials, conf.Providers.Google.Project, conf.Providers.Google.Region, nil
+	default:
+		return "", "", "", fmt.Errorf("unsupported provider: %s", provider)
+	}
+}
+
+// PricingCatalogDir 离线价格目录（redc cost catalog import）的安装目录
+func PricingCatalogDir() string {
+	return filepath.Join(RedcPath, "pricing_catalogs")
+}
+
+// CaseHourlyCost 根据场景当前的 terraform state 估算小时费用，返回 (小时价格, 币种)
+func CaseHourlyCost(c *Case, pricingService *cost.PricingService, calculator *cost.CostCalculator) (float64, string, error) {
+	state, err := TfStatus(c.Path)
+	if err != nil {
+		return 0, "", err
+	}
+	if state == nil || state.Values == nil {
+		return 0, "", fmt.Errorf("case %s has no terraform state", c.Name)
+	}
+
+	estimate, err := calculator.CalculateCost(CostResourcesFromState(state), pricingService)
+	if err != nil {
+		return 0, "", err
+	}
+	return estimate.TotalHourlyCost, estimate.Currency, nil
+}
+
+// CostResourcesFromState 将 terraform state 转换为费用估算使用的资源列表
+func CostResourcesFromState(state *tfjson.State) *cost.TemplateResources {
+	resources := &cost.TemplateResources{Resources: []cost.ResourceSpec{}}
+
+	if state.Values == nil || state.Values.RootModule == nil {
+		return resources
+	}
+
+	if len(state.Values.RootModule.Resources) > 0 {
+		firstResource := state.Values.RootModule.Resources[0]
+		if firstResource.ProviderName != "" {
+			providerName := extractShortProviderName(firstResource.ProviderName)
+			resources.Provider = providerName
+		}
+	}
+
+	extractModuleResources(state.Values.RootModule, resources)
+
+	for _, res := range resources.Resources {
+		if region, ok := res.Attributes["region"].(string); ok && region != "" {
+			resources.Region = region
+			break
+		} else if availabilityZone, ok := res.Attributes["availability_zone"].(string); ok && availabilityZone != "" {
+			if len(availabilityZone) > 2 {
+				lastDash := strings.LastIndex(availabilityZone, "-")
+				if lastDash > 0 {
+					resources.Region = availabilityZone[:lastDash]
+					break
+				}
+			}
+		} else if zone, ok := res.Attributes["zone"].(string); ok && zone != "" {
+			if len(zone) > 2 {
+				lastDash := strings.LastIndex(zone, "-")
+				if lastDash > 0 {
+					resources.Region = zone[:lastDash]
+					break
+				}
+			}
+		} else if zoneId, ok := res.Attributes["zone_id"].(string); ok && zoneId != "" {
+			if len(zoneId) > 2 {
+				lastDash := strings.LastIndex(zoneId, "-")
+				if lastDash > 0 {
+					resources.Region = zoneId[:lastDash]
+					break
+				}
+			}
+		}
+	}
+
+	return resources
+}
+
+// extractShortProviderName 从完整的 registry 路径中提取 provider 短名称
+func extractShortProviderName(fullName string) string {
+	parts := strings.Split(fullName, "/")
+	if len(parts) >= 3 {
+		return parts[len(parts)-1]
+	}
+	return fullName
+}
+
+// extractModuleResources 递归提取 terraform 模块中的资源
+func extractModuleResources(module *tfjson.StateModule, resources *cost.TemplateResources) {
+	if module == nil {
+		return
+	}
+
+	for _, res := range module.Resources {
+		if res.Type == "" {
+			continue
+		}
+
+		costRes := costResourceSpec(res.Type, res.Name, res.ProviderName, res.AttributeValues)
+		resources.Resources = append(resources.Resources, costRes)
+	}
+
+	for _, child := range module.ChildModules {
+		extractModuleResources(child, resources)
+	}
+}
+
+// costResourceSpec 将 terraform 资源的属性值转换为费用估算使用的资源描述
+func costResourceSpec(resType, name, providerName string, values map[string]interface{}) cost.ResourceSpec {
+	costRes := cost.ResourceSpec{
+		Type:       resType,
+		Name:       name,
+		Provider:   extractShortProviderName(providerName),
+		Count:      1,
+		Attributes: make(map[string]interface{}),
+	}
+
+	if values == nil {
+		return costRes
+	}
+	for key, value := range values {
+		costRes.Attributes[key] = value
+	}
+
+	if region, ok := values["region"].(string); ok && region != "" {
+		costRes.Region = region
+	} else if availabilityZone, ok := values["availability_zone"].(string); ok && availabilityZone != "" {
+		if len(availabilityZone) > 2 {
+			lastDash := strings.LastIndex(availabilityZone, "-")
+			if lastDash > 0 {
+				costRes.Region = availabilityZone[:lastDash]
+			}
+		}
+	} else if zone, ok := values["zone"].(string); ok && zone != "" {
+		if len(zone) > 2 {
+			lastDash := strings.LastIndex(zone, "-")
+			if lastDash > 0 {
+				costRes.Region = zone[:lastDash]
+			}
+		}
+	} else if zoneId, ok := values["zone_id"].(string); ok && zoneId != "" {
+		if len(zoneId) > 2 {
+			lastDash := strings.LastIndex(zoneId, "-")
+			if lastDash > 0 {
+				costRes.Region = zoneId[:lastDash]
+			}
+		}
+	}
+
+	return costRes
+}
+
+// PlanCostResources 合并 terraform state 与 plan 中的资源变更，得到每个资源地址变更前后的资源描述
+// 变更前取 state 中的资源（state 中不存在时使用 plan 的 before 值），变更后取 plan 的 after 值
+func PlanCostResources(state *tfjson.State, changes []*tfjson.ResourceChange) []cost.PlannedResource {
+	current := make(map[string]*cost.ResourceSpec)
+	var order []string
+	if state != nil && state.Values != nil {
+		var walk func(module *tfjson.StateModule)
+		walk = func(module *tfjson.StateModule) {
+			if module == nil {
+				return
+			}
+			for _, res := range module.Resources {
+				if res.Type == "" || res.Mode == tfjson.DataResourceMode {
+					continue
+				}
+				spec := costResourceSpec(res.Type, res.Address, res.ProviderName, res.AttributeValues)
+				current[res.Address] = &spec
+				order = append(order, res.Address)
+			}
+			for _, child := range module.ChildModules {
+				walk(child)
+			}
+		}
+		walk(state.Values.RootModule)
+	}
+
+	var planned []cost.PlannedResource
+	seen := make(map[string]bool)
+	for _, rc := range changes {
+		if rc == nil || rc.Change == nil || rc.Mode == tfjson.DataResourceMode {
+			continue
+		}
+		seen[rc.Address] = true
+
+		item := cost.PlannedResource{Address: rc.Address, Before: current[rc.Address]}
+		actions := rc.Change.Actions
+		switch {
+		case actions.Create():
+			item.Action = "create"
+		case actions.Delete():
+			item.Action = "delete"
+		case actions.Replace():
+			item.Action = "replace"
+		case actions.Update():
+			item.Action = "update"
+		default:
+			item.Action = "no-op"
+		}
+
+		if item.Before == nil && item.Action != "create" {
+			if before, ok := rc.Change.Before.(map[string]interface{}); ok {
+				spec := costResourceSpec(rc.Type, rc.Address, rc.ProviderName, before)
+				item.Before = &spec
+			}
+		}
+		if item.Action != "delete" {
+			after, _ := rc.Change.After.(map[string]interface{})
+			spec := costResourceSpec(rc.Type, rc.Address, rc.ProviderName, after)
+			item.After = &spec
+		}
+		planned = append(planned, item)
+	}
+
+	// plan 中没有出现的 state 资源视为不变
+	for _, address := range order {
+		if !seen[address] {
+			planned = append(planned, cost.PlannedResource{Address: address, Action: "no-op", Before: current[address], After: current[address]})
+		}
+	}
+	return planned
+}
+
+// PlanCostDiff 对比 terraform 工作目录当前 state 与已生成的 plan，估算变更前后的费用
+func PlanCostDiff(workDir string, pricingService *cost.PricingService, calculator *cost.CostCalculator) (*cost.CostDiff, error) {
+	te, err := NewTerraformExecutor(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create terraform executor: %w", err)
+	}
+
+	ctx, cancel := createContextWithTimeout()
+	defer cancel()
+
+	changes, err := te.GetPlanResourceChanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 尚未部署的场景没有 state，变更前费用为 0
+	state, _ := te.Show(ctx)
+
+	diff, err := calculator.CalculateCostDiff(PlanCostResources(state, changes), pricingService)
+	if err != nil {
+		return nil, err
+	}
+	return NormalizeCostDiff(diff), nil
+}
+
+// CaseResource terraform state 中的一个托管资源
+type CaseResource struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+}
+
+// caseResourceIDAttrs 除 id 外可能出现在账单中的资源 ID 属性
+var caseResourceIDAttrs = []string{"id", "instance_id", "spot_instance_id"}
+
+// CaseResources 读取场景 terraform state 中所有托管资源的 ID
+func CaseResources(casePath string) ([]CaseResource, error) {
+	state, err := TfStatus(casePath)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.Values == nil {
+		return nil, nil
+	}
+	return resourcesFromModule(state.Values.RootModule, nil), nil
+}
+
+func resourcesFromModule(module *tfjson.StateModule, result []CaseResource) []CaseResource {
+	if module == nil {
+		return result
+	}
+	for _, res := range module.Resources {
+		if res.Mode != tfjson.ManagedResourceMode {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, attr := range caseResourceIDAttrs {
+			id, _ := res.AttributeValues[attr].(string)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			result = append(result, CaseResource{ID: id, Type: res.Type, Provider: extractShortProviderName(res.ProviderName)})
+		}
+	}
+	for _, child := range module.ChildModules {
+		result = resourcesFromModule(child, result)
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package mod
+
+import (
+	"fmt"
+	"testing"
+
+	"red-cloud/mod/cost"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestPlanCostDiff_ResourceChanges(t *testing.T) {
+	provider := "registry.terraform.io/aliyun/alicloud"
+	state := &tfjson.State{Values: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+		Resources: []*tfjson.StateResource{
+			{Address: "alicloud_instance.web", Mode: tfjson.ManagedResourceMode, Type: "alicloud_instance", Name: "web", ProviderName: provider,
+				AttributeValues: map[string]interface{}{"instance_type": "ecs.t5-lc1m1.small"}},
+			{Address: "alicloud_instance.old", Mode: tfjson.ManagedResourceMode, Type: "alicloud_instance", Name: "old", ProviderName: provider,
+				AttributeValues: map[string]interface{}{"instance_type": "ecs.t5-lc1m1.small"}},
+			{Address: "alicloud_vpc.main", Mode: tfjson.ManagedResourceMode, Type: "alicloud_vpc", Name: "main", ProviderName: provider},
+			{Address: "data.alicloud_zones.default", Mode: tfjson.DataResourceMode, Type: "alicloud_zones", Name: "default", ProviderName: provider},
+		},
+	}}}
+	changes := []*tfjson.ResourceChange{
+		{Address: "alicloud_instance.web", Mode: tfjson.ManagedResourceMode, Type: "alicloud_instance", ProviderName: provider, Change: &tfjson.Change{
+			Actions: tfjson.Actions{tfjson.ActionUpdate},
+			After:   map[string]interface{}{"instance_type": "ecs.g6.large"},
+		}},
+		{Address: "alicloud_instance.old", Mode: tfjson.ManagedResourceMode, Type: "alicloud_instance", ProviderName: provider, Change: &tfjson.Change{
+			Actions: tfjson.Actions{tfjson.ActionDelete},
+		}},
+		{Address: "alicloud_instance.new", Mode: tfjson.ManagedResourceMode, Type: "alicloud_instance", ProviderName: provider, Change: &tfjson.Change{
+			Actions: tfjson.Actions{tfjson.ActionCreate},
+			After:   map[string]interface{}{"instance_type": "ecs.g6.large"},
+		}},
+	}
+
+	planned := PlanCostResources(state, changes)
+	if len(planned) != 4 {
+		t.Fatalf("Expected 3 changes and 1 unchanged resource, got %+v", planned)
+	}
+	if planned[3].Address != "alicloud_vpc.main" || planned[3].Action != "no-op" {
+		t.Errorf("Expected unchanged VPC as no-op, got %+v", planned[3])
+	}
+
+	prices := map[string]float64{"ecs.t5-lc1m1.small": 0.1, "ecs.g6.large": 0.5}
+	pricingService := cost.NewPricingService(":memory:")
+	defer pricingService.Close()
+	pricingService.SetFallbackProvider(func(provider, region, resourceType string) (*cost.PricingData, error) {
+		price, ok := prices[resourceType]
+		if !ok {
+			return nil, fmt.Errorf("no price for %s", resourceType)
+		}
+		return &cost.PricingData{Provider: provider, Region: region, ResourceType: resourceType, Currency: "CNY", HourlyPrice: price}, nil
+	})
+
+	diff, err := cost.NewCostCalculator().CalculateCostDiff(planned, pricingService)
+	if err != nil {
+		t.Fatalf("CalculateCostDiff failed: %v", err)
+	}
+
+	if diff.BeforeHourlyCost != 0.2 || diff.AfterHourlyCost != 1.0 {
+		t.Errorf("Expected hourly cost 0.2 -> 1.0, got %f -> %f", diff.BeforeHourlyCost, diff.AfterHourlyCost)
+	}
+	if d := diff.DeltaMonthlyCost - 0.8*720; d > 1e-6 || d < -1e-6 {
+		t.Errorf("Expected monthly delta %f, got %f", 0.8*720, diff.DeltaMonthlyCost)
+	}
+	if len(diff.Changes) != 3 || diff.Currency != "CNY" {
+		t.Fatalf("Expected 3 changed resources in CNY, got %+v", diff)
+	}
+
+	byAddress := make(map[string]cost.ResourceCostChange)
+	for _, c := range diff.Changes {
+		byAddress[c.Address] = c
+	}
+	if c := byAddress["alicloud_instance.web"]; c.Action != "update" || c.DeltaHourly != 0.4 {
+		t.Errorf("Expected update with +0.4/h, got %+v", c)
+	}
+	if c := byAddress["alicloud_instance.old"]; c.Action != "delete" || c.AfterHourly != 0 || c.DeltaHourly != -0.1 {
+		t.Errorf("Expected delete with -0.1/h, got %+v", c)
+	}
+	if c := byAddress["alicloud_instance.new"]; c.Action != "create" || c.BeforeHourly != 0 || c.AfterHourly != 0.5 {
+		t.Errorf("Expected create with 0.5/h, got %+v", c)
+	}
+}
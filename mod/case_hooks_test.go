@@ -0,0 +1,56 @@
+package mod
+
+import (
+	"errors"
+	"testing"
+)
+
+type recordedHook struct {
+	point string
+	c     *Case
+	ev    PluginHookEvent
+}
+
+func TestCasePluginHookEvents(t *testing.T) {
+	var got []recordedHook
+	p := &RedcProject{ProjectName: "demo"}
+	p.SetPluginHookRunner(func(hookPoint string, c *Case, ev PluginHookEvent) error {
+		got = append(got, recordedHook{hookPoint, c, ev})
+		if hookPoint == "pre-ssh-exec" && ev.Command == "rm -rf /" {
+			return errors.New("command not allowed")
+		}
+		return nil
+	})
+
+	c := &Case{Id: "c1", ProjectID: "demo", State: StateRunning, pluginHookRunner: p.pluginHookRunner}
+	c.markError(errors.New("apply failed"))
+	if c.State != StateError {
+		t.Errorf("state = %s, want error", c.State)
+	}
+	if err := c.BeforeSSHExec("id"); err != nil {
+		t.Fatalf("BeforeSSHExec: %v", err)
+	}
+	if err := c.BeforeSSHExec("rm -rf /"); err == nil {
+		t.Fatal("BeforeSSHExec should be blocked")
+	}
+	if err := p.RunPluginHook("pre-up", PluginHookEvent{Plugins: []string{"notify"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []recordedHook{
+		{"on-error", c, PluginHookEvent{Project: "demo", Error: "apply failed"}},
+		{"pre-ssh-exec", c, PluginHookEvent{Project: "demo", Command: "id"}},
+		{"pre-ssh-exec", c, PluginHookEvent{Project: "demo", Command: "rm -rf /"}},
+		{"pre-up", nil, PluginHookEvent{Project: "demo", Plugins: []string{"notify"}}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("hooks = %+v", got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.point != w.point || g.c != w.c || g.ev.Project != w.ev.Project || g.ev.Error != w.ev.Error ||
+			g.ev.Command != w.ev.Command || len(g.ev.Plugins) != len(w.ev.Plugins) {
+			t.Errorf("hook %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
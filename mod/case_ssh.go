@@ -127,6 +127,12 @@ func (c *Case) GetSSHConfigs() ([]*sshutil.SSHConfig, error) {
 
 	return configs, nil
 }
+
+// BeforeSSHExec 在场景实例上执行命令前运行 pre-ssh-exec 钩子，策略插件可以阻止执行
+func (c *Case) BeforeSSHExec(command string) error {
+	return c.runPluginHookEvent("pre-ssh-exec", PluginHookEvent{Command: command})
+}
+
 func (c *Case) getSSHClient() (*sshutil.Client, error) {
 	info, err := c.GetSSHConfig()
 	if err != nil {
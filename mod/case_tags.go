@@ -0,0 +1,359 @@
+package mod
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"red-cloud/i18n"
+	"red-cloud/mod/gologger"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// 场景资源的标准标签键
+const (
+	TagCaseID   = "redc_case_id"
+	TagProject  = "redc_project"
+	TagOperator = "redc_operator"
+	TagExpires  = "redc_expires"
+)
+
+// CaseTagsFile 场景目录下生成的 terraform override 文件，为资源注入标准标签
+const CaseTagsFile = "redc_tags_override.tf"
+
+// tagsNever 没有 TTL 的场景 redc_expires 取值
+const tagsNever = "never"
+
+// defaultTagProviders 支持 default_tags 的 provider，在 provider 块上注入标签
+var defaultTagProviders = map[string]bool{
+	"aws": true,
+}
+
+// tagStyle 资源上标签属性的写法
+type tagStyle int
+
+const (
+	tagsMap    tagStyle = iota // tags = { key = "value" }
+	tagsBlocks                 // 每个标签一个 tags { key = "..." value = "..." } 嵌套块（volcengine）
+	labelsMap                  // labels = { ... }，值只能包含小写字母、数字、_ 和 -（google）
+)
+
+// taggableResources 不支持 default_tags 的云厂商中可以注入标签的资源类型
+var taggableResources = map[string]tagStyle{
+	"alicloud_instance":            tagsMap,
+	"alicloud_vpc":                 tagsMap,
+	"alicloud_vswitch":             tagsMap,
+	"alicloud_security_group":      tagsMap,
+	"alicloud_eip":                 tagsMap,
+	"alicloud_eip_address":         tagsMap,
+	"alicloud_disk":                tagsMap,
+	"alicloud_ecs_disk":            tagsMap,
+	"alicloud_slb_load_balancer":   tagsMap,
+	"alicloud_nat_gateway":         tagsMap,
+	"alicloud_ecs_key_pair":        tagsMap,
+	"tencentcloud_instance":        tagsMap,
+	"tencentcloud_vpc":             tagsMap,
+	"tencentcloud_subnet":          tagsMap,
+	"tencentcloud_security_group":  tagsMap,
+	"tencentcloud_eip":             tagsMap,
+	"tencentcloud_cbs_storage":     tagsMap,
+	"tencentcloud_clb_instance":    tagsMap,
+	"huaweicloud_compute_instance": tagsMap,
+	"huaweicloud_vpc":              tagsMap,
+	"huaweicloud_vpc_subnet":       tagsMap,
+	"huaweicloud_vpc_eip":          tagsMap,
+	"huaweicloud_evs_volume":       tagsMap,
+	"volcengine_ecs_instance":      tagsBlocks,
+	"volcengine_vpc":               tagsBlocks,
+	"volcengine_security_group":    tagsBlocks,
+	"volcengine_eip_address":       tagsBlocks,
+	"volcengine_volume":            tagsBlocks,
+	"volcengine_clb":               tagsBlocks,
+	"volcengine_nat_gateway":       tagsBlocks,
+	"google_compute_instance":      labelsMap,
+	"google_compute_disk":          labelsMap,
+}
+
+// untaggableProviders 资源无法携带 redc 标准标签的云厂商，生成标签时提示，
+// 这些场景的资源不会出现在对账与孤儿资源检测的标签归属中
+var untaggableProviders = map[string]string{
+	"ucloud":  "only a single business group tag string",
+	"vultr":   "tags are plain strings",
+	"ctyun":   "no tag support in the provider",
+	"azurerm": "not supported by redc yet",
+	"oci":     "not supported by redc yet",
+}
+
+// CaseTags 返回场景资源的标准标签；expires 为零值表示没有 TTL
+// 时间使用紧凑的 ISO 8601 格式，各云厂商的标签值字符集都能接受
+func CaseTags(c *Case, expires time.Time) map[string]string {
+	exp := tagsNever
+	if !expires.IsZero() {
+		exp = expires.UTC().Format("20060102T150405Z")
+	}
+	operator := c.Operator
+	if operator == "" {
+		operator = "system"
+	}
+	return map[string]string{
+		TagCaseID:   c.Id,
+		TagProject:  c.ProjectID,
+		TagOperator: operator,
+		TagExpires:  exp,
+	}
+}
+
+// WriteCaseTags 根据场景目录中的 terraform 配置生成标签 override 文件
+// 模板 case.json 设置 disable_resource_tags 时删除已有的 override 文件
+func WriteCaseTags(c *Case, expires time.Time) error {
+	path := filepath.Join(c.Path, CaseTagsFile)
+	if meta, err := readTemplateMeta(c.Path); err == nil && meta != nil && meta.DisableResourceTags {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	content, untagged, err := buildTagsOverride(c.Path, CaseTags(c, expires))
+	if err != nil {
+		return err
+	}
+	for _, provider := range untagged {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_tags_unsupported", provider, untaggableProviders[provider]))
+	}
+	if content == nil {
+		// 模板中没有可注入标签的资源
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// buildTagsOverride 解析目录下的 .tf 文件并生成 override 内容，没有可注入的块时返回 nil；
+// 同时返回模板中使用的、无法注入标签的云厂商
+func buildTagsOverride(dir string, tags map[string]string) ([]byte, []string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(files)
+	literal := tagsLiteral(tags)
+
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by redc: standard tags for cost attribution and orphan detection.\n")
+	buf.WriteString("# Regenerated before every apply; set \"disable_resource_tags\": true in case.json to opt out.\n")
+	blocks := 0
+	untagged := make(map[string]bool)
+	for _, file := range files {
+		base := filepath.Base(file)
+		if base == "override.tf" || strings.HasSuffix(base, "_override.tf") {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		f, diags := hclsyntax.ParseConfig(src, base, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			return nil, nil, fmt.Errorf("解析 %s 失败: %s", base, diags.Error())
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			switch {
+			case block.Type == "provider" && len(block.Labels) == 1 && defaultTagProviders[block.Labels[0]]:
+				writeProviderTags(&buf, src, block, literal)
+				blocks++
+			case block.Type == "resource" && len(block.Labels) == 2:
+				style, ok := taggableResources[block.Labels[0]]
+				if !ok {
+					provider, _, _ := strings.Cut(block.Labels[0], "_")
+					if _, unsupported := untaggableProviders[provider]; unsupported {
+						untagged[provider] = true
+					}
+					continue
+				}
+				if writeResourceTags(&buf, src, block, style, tags) {
+					blocks++
+				}
+			}
+		}
+	}
+	providers := make([]string, 0, len(untagged))
+	for p := range untagged {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+	if blocks == 0 {
+		return nil, providers, nil
+	}
+	return hclwrite.Format(buf.Bytes()), providers, nil
+}
+
+// writeProviderTags 为 provider 块生成 default_tags，保留模板已有的 default_tags
+func writeProviderTags(buf *bytes.Buffer, src []byte, block *hclsyntax.Block, literal string) {
+	fmt.Fprintf(buf, "\nprovider %q {\n", block.Labels[0])
+	if alias, ok := block.Body.Attributes["alias"]; ok {
+		fmt.Fprintf(buf, "alias = %s\n", exprSource(src, alias.Expr))
+	}
+	tags := literal
+	for _, nested := range block.Body.Blocks {
+		if nested.Type != "default_tags" {
+			continue
+		}
+		if existing, ok := nested.Body.Attributes["tags"]; ok {
+			tags = fmt.Sprintf("merge(%s, %s)", exprSource(src, existing.Expr), literal)
+		}
+	}
+	fmt.Fprintf(buf, "default_tags {\ntags = %s\n}\n}\n", tags)
+}
+
+// writeResourceTags 为资源块生成标签，保留模板已有的标签。
+// map 形式的资源以嵌套块定义标签时跳过；嵌套块形式的资源使用 dynamic 块时跳过
+func writeResourceTags(buf *bytes.Buffer, src []byte, block *hclsyntax.Block, style tagStyle, tags map[string]string) bool {
+	if style == tagsBlocks {
+		return writeResourceTagBlocks(buf, src, block, tags)
+	}
+	attr := "tags"
+	if style == labelsMap {
+		attr = "labels"
+		tags = gcpLabels(tags)
+	}
+	for _, nested := range block.Body.Blocks {
+		if nested.Type == attr {
+			return false
+		}
+	}
+	literal := tagsLiteral(tags)
+	if existing, ok := block.Body.Attributes[attr]; ok {
+		literal = fmt.Sprintf("merge(%s, %s)", exprSource(src, existing.Expr), literal)
+	}
+	fmt.Fprintf(buf, "\nresource %q %q {\n%s = %s\n}\n", block.Labels[0], block.Labels[1], attr, literal)
+	return true
+}
+
+// writeResourceTagBlocks 为 tags 嵌套块形式的资源生成标签块。override 中的同类嵌套块会替换原有的块，
+// 因此先复制模板已有的 tags 块
+func writeResourceTagBlocks(buf *bytes.Buffer, src []byte, block *hclsyntax.Block, tags map[string]string) bool {
+	var existing []string
+	for _, nested := range block.Body.Blocks {
+		switch {
+		case nested.Type == "dynamic" && len(nested.Labels) == 1 && nested.Labels[0] == "tags":
+			return false
+		case nested.Type == "tags":
+			if _, ok := tags[tagBlockKey(src, nested)]; !ok {
+				existing = append(existing, string(nested.Range().SliceBytes(src)))
+			}
+		}
+	}
+	fmt.Fprintf(buf, "\nresource %q %q {\n", block.Labels[0], block.Labels[1])
+	for _, b := range existing {
+		fmt.Fprintf(buf, "%s\n", b)
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, "tags {\nkey = %q\nvalue = %q\n}\n", k, tags[k])
+	}
+	buf.WriteString("}\n")
+	return true
+}
+
+// tagBlockKey 返回 tags 块中字面量 key 的值，不是字面量时返回空串
+func tagBlockKey(src []byte, block *hclsyntax.Block) string {
+	attr, ok := block.Body.Attributes["key"]
+	if !ok {
+		return ""
+	}
+	return strings.Trim(exprSource(src, attr.Expr), `"`)
+}
+
+// gcpLabels 将标签转换为 GCP label 允许的取值：小写字母、数字、_ 和 -，最长 63 个字符
+func gcpLabels(tags map[string]string) map[string]string {
+	labels := make(map[string]string, len(tags))
+	for k, v := range tags {
+		b := []byte(strings.ToLower(v))
+		for i, ch := range b {
+			if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-') {
+				b[i] = '_'
+			}
+		}
+		if len(b) > 63 {
+			b = b[:63]
+		}
+		labels[k] = string(b)
+	}
+	return labels
+}
+
+func exprSource(src []byte, expr hclsyntax.Expression) string {
+	return string(expr.Range().SliceBytes(src))
+}
+
+// tagsLiteral 生成按键排序的 HCL map 字面量
+func tagsLiteral(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s = %q", k, tags[k]))
+	}
+	return "{ " + strings.Join(parts, ", ") + " }"
+}
+
+// SetExpiresAt 设置尚未保存的生命周期策略的 TTL 到期时间，首次 apply 时写入资源标签
+// （redc run / compose up 的 --ttl 策略在 apply 成功后才保存）
+func (c *Case) SetExpiresAt(t time.Time) {
+	c.expiresAt = t
+}
+
+// tagExpiresAt 返回写入标签的到期时间：已保存的策略与 SetExpiresAt 设置的时间中较早者
+func (c *Case) tagExpiresAt() time.Time {
+	expires := caseExpiresAt(c.Id)
+	if !c.expiresAt.IsZero() && (expires.IsZero() || c.expiresAt.Before(expires)) {
+		expires = c.expiresAt
+	}
+	return expires
+}
+
+// caseExpiresAt 从生命周期策略中读取场景的 TTL 到期时间，没有 TTL 时返回零值
+func caseExpiresAt(caseID string) time.Time {
+	s := NewTaskScheduler(nil, filepath.Join(RedcPath, "scheduler.db"))
+	if err := s.InitDB(); err != nil {
+		return time.Time{}
+	}
+	defer s.db.Close()
+	policies, err := s.ListPolicies()
+	if err != nil {
+		return time.Time{}
+	}
+	var expires time.Time
+	for _, p := range policies {
+		if p.ExpiresAt.IsZero() || p.Triggered {
+			continue
+		}
+		for _, id := range p.CaseIDs {
+			if id == caseID && (expires.IsZero() || p.ExpiresAt.Before(expires)) {
+				expires = p.ExpiresAt
+			}
+		}
+	}
+	return expires
+}
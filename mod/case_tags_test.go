@@ -0,0 +1,181 @@
+package mod
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaseTags(t *testing.T) {
+	c := &Case{Id: "abc123", ProjectID: "default"}
+	tags := CaseTags(c, time.Time{})
+	if tags[TagCaseID] != "abc123" || tags[TagProject] != "default" || tags[TagOperator] != "system" || tags[TagExpires] != "never" {
+		t.Errorf("CaseTags = %v", tags)
+	}
+	c.Operator = "alice"
+	exp := time.Date(2026, 3, 1, 8, 30, 0, 0, time.FixedZone("CST", 8*3600))
+	tags = CaseTags(c, exp)
+	if tags[TagOperator] != "alice" || tags[TagExpires] != "20260301T003000Z" {
+		t.Errorf("CaseTags with expiry = %v", tags)
+	}
+}
+
+func TestBuildTagsOverride(t *testing.T) {
+	dir := t.TempDir()
+	main := `
+provider "aws" {
+  region = "us-east-1"
+}
+
+provider "aws" {
+  alias  = "east2"
+  region = "us-east-2"
+  default_tags {
+    tags = { Owner = "team" }
+  }
+}
+
+provider "volcengine" {
+  region = "cn-beijing"
+}
+
+resource "alicloud_instance" "web" {
+  instance_type = "ecs.t5"
+  tags = {
+    Name = "web"
+  }
+}
+
+resource "alicloud_vpc" "net" {
+  cidr_block = "10.0.0.0/8"
+}
+
+resource "volcengine_ecs_instance" "vm" {
+  instance_type = "ecs.g1"
+  tags {
+    key   = "Name"
+    value = "vm"
+  }
+}
+
+resource "volcengine_vpc" "net" {
+  dynamic "tags" {
+    for_each = var.tags
+    content {
+      key   = tags.key
+      value = tags.value
+    }
+  }
+}
+
+resource "google_compute_instance" "gce" {
+  labels = { team = "red" }
+}
+
+resource "ucloud_instance" "uhost" {
+  tag = "redc"
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 已有的 override 文件不参与解析
+	if err := os.WriteFile(filepath.Join(dir, "custom_override.tf"), []byte(`resource "alicloud_disk" "d" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, untagged, err := buildTagsOverride(dir, map[string]string{TagCaseID: "abc", TagProject: "Red Team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(untagged, ",") != "ucloud" {
+		t.Errorf("untagged providers = %v", untagged)
+	}
+	got := string(out)
+	for _, want := range []string{
+		`alias = "east2"`,
+		`merge({ Owner = "team" }, { redc_case_id = "abc", redc_project = "Red Team" })`,
+		`resource "alicloud_instance" "web"`,
+		`resource "alicloud_vpc" "net"`,
+		// volcengine 的标签为嵌套块，保留原有的块
+		"resource \"volcengine_ecs_instance\" \"vm\" {\n  tags {\n    key   = \"Name\"",
+		"key   = \"redc_case_id\"\n    value = \"abc\"",
+		// google 使用 labels，值转换为小写
+		`labels = merge({ team = "red" }, { redc_case_id = "abc", redc_project = "red_team" })`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("override missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, `provider "aws"`) != 2 {
+		t.Errorf("expected both aws provider blocks:\n%s", got)
+	}
+	for _, unwanted := range []string{`provider "volcengine"`, "volcengine_vpc", "ucloud", "alicloud_disk"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("override should not contain %q:\n%s", unwanted, got)
+		}
+	}
+	if !strings.Contains(got, "merge({\n    Name = \"web\"\n  }") {
+		t.Errorf("existing resource tags should be merged:\n%s", got)
+	}
+}
+
+func TestWriteCaseTags(t *testing.T) {
+	dir := t.TempDir()
+	tf := `resource "tencentcloud_instance" "vm" {
+  instance_name = "vm"
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(tf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := &Case{Id: "abc", ProjectID: "p", Path: dir}
+	path := filepath.Join(dir, CaseTagsFile)
+
+	if err := WriteCaseTags(c, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `redc_case_id = "abc"`) {
+		t.Fatalf("override = %s, %v", data, err)
+	}
+
+	// 模板通过 case.json 关闭标签注入后删除 override 文件
+	if err := os.WriteFile(filepath.Join(dir, TmplCaseFile), []byte(`{"name":"t","disable_resource_tags":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteCaseTags(c, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("override should be removed when tags are disabled, stat err = %v", err)
+	}
+}
+
+func TestWriteCaseTags_ExpiryBeforePolicySaved(t *testing.T) {
+	oldPath := RedcPath
+	RedcPath = t.TempDir()
+	t.Cleanup(func() { RedcPath = oldPath })
+
+	dir := t.TempDir()
+	tf := `resource "tencentcloud_instance" "vm" {
+  instance_name = "vm"
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(tf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// redc run --ttl 8h: the policy is only saved after the first apply succeeds
+	c := &Case{Id: "abc", ProjectID: "p", Path: dir}
+	c.SetExpiresAt(time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC))
+
+	if err := WriteCaseTags(c, c.tagExpiresAt()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, CaseTagsFile))
+	if err != nil || !strings.Contains(string(data), `redc_expires = "20260301T080000Z"`) {
+		t.Errorf("override = %s, %v; want the TTL expiry", data, err)
+	}
+}
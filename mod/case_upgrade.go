@@ -0,0 +1,701 @@
+package mod
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"red-cloud/i18n"
+	"red-cloud/mod/gologger"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// CaseSnapshotDir 场景目录下保存升级前模板文件的目录
+const CaseSnapshotDir = ".redc-snapshots"
+
+// CaseUpgradeFile 记录已写入场景目录、尚未应用或取消的升级。
+// 存在时场景不能启动，避免进程中断后按未确认的新文件 apply
+const CaseUpgradeFile = ".redc-upgrade.json"
+
+// caseSwapExcluded 升级时不属于模板的场景文件：terraform 状态与缓存、生成文件和快照本身
+var caseSwapExcluded = map[string]bool{
+	CaseSnapshotDir:            true,
+	CaseUpgradeFile:            true,
+	".git":                     true,
+	".terraform":               true,
+	"terraform.tfstate":        true,
+	"terraform.tfstate.backup": true,
+	RedcPlanPath:               true,
+	CaseTagsFile:               true,
+	HealthFile:                 true,
+	".DS_Store":                true,
+}
+
+// CaseUpgradeOptions 场景模板升级选项
+type CaseUpgradeOptions struct {
+	Version     string            // 目标版本，为空时使用本地模板
+	Vars        map[string]string // 覆盖或补充的变量
+	PullOptions PullOptions       // 本地模板不是目标版本时从 registry 下载
+}
+
+// CaseSnapshot 升级前保存的场景模板文件，用于回滚
+type CaseSnapshot struct {
+	ID              string   `json:"id"`
+	TemplateVersion string   `json:"template_version"`
+	TemplateSHA256  string   `json:"template_sha256"`
+	Parameter       []string `json:"parameter"`
+	CreatedAt       string   `json:"created_at"`
+}
+
+// PlanChange terraform plan 中的单个资源变更
+type PlanChange struct {
+	Address string `json:"address"`
+	Action  string `json:"action"` // create / update / delete / replace
+}
+
+// CaseUpgrade 已写入场景目录、等待确认的模板升级（或回滚）。
+// Apply 应用变更，Cancel 从快照恢复原文件
+type CaseUpgrade struct {
+	Case        *Case        `json:"-"`
+	FromVersion string       `json:"from_version"`
+	ToVersion   string       `json:"to_version"`
+	Snapshot    string       `json:"snapshot"`
+	Added       []string     `json:"files_added,omitempty"`
+	Changed     []string     `json:"files_changed,omitempty"`
+	Removed     []string     `json:"files_removed,omitempty"`
+	KeptVars    []string     `json:"vars_kept,omitempty"`
+	DroppedVars []string     `json:"vars_dropped,omitempty"`
+	NewVars     []string     `json:"vars_new,omitempty"`
+	Changes     []PlanChange `json:"changes"`
+
+	hash      string
+	params    []string
+	restoring string // 回滚时使用的快照，成功后删除
+}
+
+// pendingUpgrade CaseUpgradeFile 的内容
+type pendingUpgrade struct {
+	CaseUpgrade
+	Hash      string   `json:"hash"`
+	Params    []string `json:"params"`
+	Restoring string   `json:"restoring,omitempty"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// PlanUpgrade 将新版本模板写入场景目录并生成 plan，原文件先保存到快照。
+// 返回的升级需要调用 Apply 或 Cancel
+func (c *Case) PlanUpgrade(ctx context.Context, opts CaseUpgradeOptions) (*CaseUpgrade, error) {
+	if err := c.checkUpgradable(); err != nil {
+		return nil, err
+	}
+	src, version, cleanup, err := resolveUpgradeSource(ctx, c.Type, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	hash, err := TemplateHash(src)
+	if err != nil {
+		return nil, fmt.Errorf("hash template failed: %w", err)
+	}
+	if hash == c.TemplateSHA256 {
+		return nil, fmt.Errorf("case %s already uses %s:%s", c.GetId(), c.Type, version)
+	}
+	return c.prepareSwap(src, version, hash, c.Parameter, opts.Vars, "")
+}
+
+// PlanRollback 用快照中的模板文件和变量替换当前文件并生成 plan，id 为空时使用最近的快照
+func (c *Case) PlanRollback(id string) (*CaseUpgrade, error) {
+	if err := c.checkUpgradable(); err != nil {
+		return nil, err
+	}
+	snapshots, err := c.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("case %s has no snapshots", c.GetId())
+	}
+	snap := snapshots[0]
+	if id != "" {
+		found := false
+		for _, s := range snapshots {
+			if s.ID == id {
+				snap, found = s, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("snapshot %s not found", id)
+		}
+	}
+	src := filepath.Join(c.Path, CaseSnapshotDir, snap.ID)
+	return c.prepareSwap(src, snap.TemplateVersion, snap.TemplateSHA256, snap.Parameter, nil, snap.ID)
+}
+
+// Snapshots 返回场景的快照，最新的在前
+func (c *Case) Snapshots() ([]CaseSnapshot, error) {
+	dir := filepath.Join(c.Path, CaseSnapshotDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []CaseSnapshot
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var s CaseSnapshot
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("parse snapshot %s: %w", e.Name(), err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ID > snapshots[j].ID })
+	return snapshots, nil
+}
+
+// Apply 应用升级：运行中的场景按 pre-apply 钩子、apply 用户确认过的 plan 文件、post-apply 钩子的顺序执行，
+// 状态变化同样记入费用台账；其他状态只更新场景记录，下次启动时生效
+func (u *CaseUpgrade) Apply() error {
+	c := u.Case
+	running := IsRunningState(c.State)
+	if running {
+		if err := c.runPluginHook("pre-apply"); err != nil {
+			return err
+		}
+		c.StatusChange(StateStarting)
+		// 不重新生成 plan，state 在确认后发生变化时 terraform 会拒绝过期的 plan
+		if err := TfApplyPlan(c.Path); err != nil {
+			// 保留新文件、快照与待应用标记，可以修复后重试或回滚
+			c.markError(err)
+			return err
+		}
+	}
+	c.TemplateVersion = u.ToVersion
+	c.TemplateSHA256 = u.hash
+	c.Parameter = u.params
+	var err error
+	if running {
+		err = c.finishApply()
+	} else {
+		c.StatusChange(c.State)
+	}
+	if rerr := os.Remove(filepath.Join(c.Path, CaseUpgradeFile)); rerr != nil && !os.IsNotExist(rerr) {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_snapshot_remove_failed", CaseUpgradeFile, rerr))
+	}
+	if u.restoring != "" {
+		if rerr := removeSnapshot(c.Path, u.restoring); rerr != nil {
+			gologger.Warning().Msgf("%s", i18n.Tf("case_snapshot_remove_failed", u.restoring, rerr))
+		}
+	}
+	return err
+}
+
+// Cancel 放弃升级，从快照恢复原文件并删除快照
+func (u *CaseUpgrade) Cancel() error {
+	c := u.Case
+	src := filepath.Join(c.Path, CaseSnapshotDir, u.Snapshot)
+	if err := installCaseFiles(src, c.Path); err != nil {
+		return fmt.Errorf("restore snapshot %s: %w", u.Snapshot, err)
+	}
+	os.Remove(filepath.Join(c.Path, RedcPlanPath))
+	if err := WriteCaseTags(c, c.tagExpiresAt()); err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_tags_failed", err))
+	}
+	// 恢复原 provider 锁定版本
+	if err := TfInit(c.Path); err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_upgrade_reinit_failed", err))
+	}
+	if err := os.Remove(filepath.Join(c.Path, CaseUpgradeFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return removeSnapshot(c.Path, u.Snapshot)
+}
+
+// PendingUpgrade 返回已写入场景目录但尚未应用或取消的升级，没有时返回 nil
+func (c *Case) PendingUpgrade() (*CaseUpgrade, error) {
+	data, err := os.ReadFile(filepath.Join(c.Path, CaseUpgradeFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p pendingUpgrade
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", CaseUpgradeFile, err)
+	}
+	u := p.CaseUpgrade
+	u.Case = c
+	u.hash, u.params, u.restoring = p.Hash, p.Params, p.Restoring
+	return &u, nil
+}
+
+// CheckPendingUpgrade 场景有待应用的升级时返回错误，启动与重新 apply 前调用
+func (c *Case) CheckPendingUpgrade() error {
+	u, err := c.PendingUpgrade()
+	if err != nil {
+		return err
+	}
+	if u != nil {
+		return fmt.Errorf("%s", i18n.Tf("case_upgrade_pending", c.GetId(), orUnknownVersion(u.ToVersion), c.GetId(), c.GetId()))
+	}
+	return nil
+}
+
+// ResumeUpgrade 重新为待应用的升级生成 plan（文件已在场景目录中），之后可以 Apply 或 Cancel
+func (c *Case) ResumeUpgrade() (*CaseUpgrade, error) {
+	u, err := c.PendingUpgrade()
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, fmt.Errorf("case %s has no pending upgrade", c.GetId())
+	}
+	if err := u.refreshPlan(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// CancelPendingUpgrade 取消待应用的升级并恢复原文件，没有待应用的升级时返回 false
+func (c *Case) CancelPendingUpgrade() (bool, error) {
+	u, err := c.PendingUpgrade()
+	if err != nil || u == nil {
+		return false, err
+	}
+	return true, u.Cancel()
+}
+
+// savePending 记录待应用的升级，变量中可能包含凭据
+func (u *CaseUpgrade) savePending() error {
+	data, err := json.MarshalIndent(pendingUpgrade{
+		CaseUpgrade: *u,
+		Hash:        u.hash,
+		Params:      u.params,
+		Restoring:   u.restoring,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(u.Case.Path, CaseUpgradeFile), data, 0600)
+}
+
+// checkUpgradable 正在启动、停止或删除，或已有待应用升级的场景不能替换模板文件
+func (c *Case) checkUpgradable() error {
+	switch c.State {
+	case StateStarting, StateStopping, StateRemoving:
+		return fmt.Errorf("case %s is %s", c.GetId(), c.State)
+	}
+	return c.CheckPendingUpgrade()
+}
+
+func orUnknownVersion(v string) string {
+	if v == "" {
+		return "unknown"
+	}
+	return v
+}
+
+// resolveUpgradeSource 返回目标模板目录与版本。本地模板即目标版本时直接使用，
+// 否则下载到临时目录，不影响已安装的模板
+func resolveUpgradeSource(ctx context.Context, name string, opts CaseUpgradeOptions) (string, string, func(), error) {
+	noop := func() {}
+	exists, localVer, _ := CheckLocalImage(name)
+	if opts.Version == "" || (exists && localVer == opts.Version) {
+		if !exists {
+			return "", "", noop, fmt.Errorf("template '%s' not found locally", name)
+		}
+		path, err := GetTemplatePath(name)
+		return path, localVer, noop, err
+	}
+
+	if opts.PullOptions.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.PullOptions.Timeout)
+		defer cancel()
+	}
+	reg, tmpl, err := findTemplate(ctx, name, opts.PullOptions)
+	if err != nil {
+		return "", "", noop, err
+	}
+	verData, ok := tmpl.Versions[opts.Version]
+	if !ok {
+		return "", "", noop, fmt.Errorf("version '%s' not found", opts.Version)
+	}
+	stage, err := os.MkdirTemp("", "redc-upgrade-*")
+	if err != nil {
+		return "", "", noop, err
+	}
+	cleanup := func() { os.RemoveAll(stage) }
+	dest := filepath.Join(stage, "template")
+	if err := downloadAndInstall(ctx, reg, verData, dest); err != nil {
+		cleanup()
+		return "", "", noop, err
+	}
+	return dest, opts.Version, cleanup, nil
+}
+
+// prepareSwap 映射变量、保存快照、写入 src 的模板文件并生成 plan，失败时恢复原文件
+func (c *Case) prepareSwap(src, version, hash string, params []string, vars map[string]string, restoring string) (*CaseUpgrade, error) {
+	u := &CaseUpgrade{
+		Case:        c,
+		FromVersion: c.TemplateVersion,
+		ToVersion:   version,
+		hash:        hash,
+		restoring:   restoring,
+	}
+	if err := u.mapVariables(src, params, vars); err != nil {
+		return nil, err
+	}
+	if err := u.diffFiles(src); err != nil {
+		return nil, err
+	}
+
+	snap, err := c.saveSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("save snapshot: %w", err)
+	}
+	u.Snapshot = snap
+	gologger.Info().Msgf("%s", i18n.Tf("case_upgrade_snapshot", filepath.Join(CaseSnapshotDir, snap)))
+	// 先记录待应用的升级再写入新文件，进程中断后场景不会按未确认的文件启动
+	if err := u.savePending(); err != nil {
+		removeSnapshot(c.Path, snap)
+		return nil, fmt.Errorf("save pending upgrade: %w", err)
+	}
+
+	if err := u.plan(src); err != nil {
+		if cerr := u.Cancel(); cerr != nil {
+			gologger.Error().Msgf("%s", i18n.Tf("case_upgrade_restore_failed", cerr))
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// plan 写入模板文件、重新初始化并生成 plan
+func (u *CaseUpgrade) plan(src string) error {
+	c := u.Case
+	if err := installCaseFiles(src, c.Path); err != nil {
+		return err
+	}
+	// 标签文件依赖模板中的 provider 与资源，需要按新模板重新生成
+	if err := WriteCaseTags(c, c.tagExpiresAt()); err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_tags_failed", err))
+	}
+	return u.refreshPlan()
+}
+
+// refreshPlan 按场景目录中的文件重新初始化、生成 plan 并收集资源变更
+func (u *CaseUpgrade) refreshPlan() error {
+	c := u.Case
+	if err := TfInit(c.Path); err != nil {
+		return err
+	}
+	if err := TfPlan(c.Path, u.params...); err != nil {
+		return err
+	}
+
+	ctx, cancel := createContextWithTimeout()
+	defer cancel()
+	te, err := NewTerraformExecutor(c.Path)
+	if err != nil {
+		return err
+	}
+	changes, err := te.GetPlanResourceChanges(ctx)
+	if err != nil {
+		return err
+	}
+	u.Changes = []PlanChange{}
+	for _, rc := range changes {
+		if rc.Change == nil {
+			continue
+		}
+		if action := planAction(rc.Change.Actions); action != "" {
+			u.Changes = append(u.Changes, PlanChange{Address: rc.Address, Action: action})
+		}
+	}
+	return nil
+}
+
+// planAction 将 terraform 的动作列表归并为单个动作，no-op 与 read 返回空
+func planAction(actions tfjson.Actions) string {
+	switch {
+	case actions.Replace():
+		return "replace"
+	case actions.Create():
+		return "create"
+	case actions.Update():
+		return "update"
+	case actions.Delete():
+		return "delete"
+	}
+	return ""
+}
+
+// mapVariables 保留新模板仍然声明的变量，丢弃已删除的变量，vars 覆盖或补充变量值。
+// 新模板中没有默认值且未提供的变量会导致升级失败
+func (u *CaseUpgrade) mapVariables(src string, params []string, vars map[string]string) error {
+	declared, err := templateVariables(src)
+	if err != nil {
+		return err
+	}
+	old, _ := templateVariables(u.Case.Path)
+
+	values := map[string]string{}
+	var order []string
+	set := func(k, v string) {
+		if _, ok := values[k]; !ok {
+			order = append(order, k)
+		}
+		values[k] = v
+	}
+	for _, p := range params {
+		if k, v, ok := strings.Cut(p, "="); ok && k != "" {
+			set(k, v)
+		}
+	}
+	for _, k := range sortedKeys(vars) {
+		set(k, vars[k])
+	}
+
+	for _, k := range order {
+		// 新模板没有任何变量声明时无法判断，保留全部变量
+		if _, ok := declared[k]; ok || len(declared) == 0 {
+			u.KeptVars = append(u.KeptVars, k)
+			u.params = append(u.params, k+"="+values[k])
+		} else {
+			u.DroppedVars = append(u.DroppedVars, k)
+		}
+	}
+	var missing []string
+	for _, name := range sortedKeys(declared) {
+		if _, ok := old[name]; !ok {
+			u.NewVars = append(u.NewVars, name)
+		}
+		if _, ok := values[name]; !ok && declared[name].Required {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("template %s:%s requires variables %s (set them with -e name=value)", u.Case.Type, u.ToVersion, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// diffFiles 对比场景目录与 src 的模板文件
+func (u *CaseUpgrade) diffFiles(src string) error {
+	newFiles, err := caseFiles(src)
+	if err != nil {
+		return err
+	}
+	current, err := caseFiles(u.Case.Path)
+	if err != nil {
+		return err
+	}
+	for _, rel := range newFiles {
+		if !slices.Contains(current, rel) {
+			u.Added = append(u.Added, rel)
+		} else if !sameFile(filepath.Join(src, rel), filepath.Join(u.Case.Path, rel)) {
+			u.Changed = append(u.Changed, rel)
+		}
+	}
+	for _, rel := range current {
+		if !slices.Contains(newFiles, rel) && removableCaseFile(rel) {
+			u.Removed = append(u.Removed, rel)
+		}
+	}
+	return nil
+}
+
+// saveSnapshot 复制场景当前的模板文件到快照目录，并记录模板版本与变量
+func (c *Case) saveSnapshot() (string, error) {
+	root := filepath.Join(c.Path, CaseSnapshotDir)
+	id := time.Now().Format("20060102-150405")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(root, id)); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", time.Now().Format("20060102-150405"), i)
+	}
+	dir := filepath.Join(root, id)
+	files, err := caseFiles(c.Path)
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range files {
+		if err := copyCaseFile(filepath.Join(c.Path, rel), filepath.Join(dir, rel)); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(CaseSnapshot{
+		ID:              id,
+		TemplateVersion: c.TemplateVersion,
+		TemplateSHA256:  c.TemplateSHA256,
+		Parameter:       c.Parameter,
+		CreatedAt:       time.Now().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	// 变量中可能包含凭据
+	if err := os.WriteFile(filepath.Join(root, id+".json"), data, 0600); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return id, nil
+}
+
+func removeSnapshot(casePath, id string) error {
+	root := filepath.Join(casePath, CaseSnapshotDir)
+	if err := os.RemoveAll(filepath.Join(root, id)); err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(root, id+".json"))
+}
+
+// installCaseFiles 用 src 的模板文件覆盖场景目录，并删除 src 中没有的 terraform 文件。
+// 其他文件（密钥、插件输出等）保留
+func installCaseFiles(src, casePath string) error {
+	newFiles, err := caseFiles(src)
+	if err != nil {
+		return err
+	}
+	current, err := caseFiles(casePath)
+	if err != nil {
+		return err
+	}
+	for _, rel := range current {
+		if !slices.Contains(newFiles, rel) && removableCaseFile(rel) {
+			if err := os.Remove(filepath.Join(casePath, rel)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, rel := range newFiles {
+		if err := copyCaseFile(filepath.Join(src, rel), filepath.Join(casePath, rel)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// caseFiles 列出目录中参与升级的文件（相对路径），不包含 caseSwapExcluded
+func caseFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if caseSwapExcluded[info.Name()] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// removableCaseFile 新模板中不存在时需要删除的文件：terraform 配置与 provider 锁文件
+func removableCaseFile(rel string) bool {
+	return strings.HasSuffix(rel, ".tf") || strings.HasSuffix(rel, ".tf.json") || filepath.Base(rel) == ".terraform.lock.hcl"
+}
+
+func copyCaseFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func sameFile(a, b string) bool {
+	da, err := os.ReadFile(a)
+	if err != nil {
+		return false
+	}
+	db, err := os.ReadFile(b)
+	return err == nil && bytes.Equal(da, db)
+}
+
+// templateVariables 解析目录下所有 .tf 文件中声明的变量
+func templateVariables(dir string) (map[string]TemplateVariable, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	declared := map[string]TemplateVariable{}
+	for _, f := range files {
+		if filepath.Base(f) == CaseTagsFile {
+			continue
+		}
+		vars, err := parseVariablesFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(f), err)
+		}
+		for _, v := range vars {
+			declared[v.Name] = v
+		}
+	}
+	return declared, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
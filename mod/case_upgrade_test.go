@@ -0,0 +1,156 @@
+package mod
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCaseUpgrade_SwapFilesAndVariables(t *testing.T) {
+	casePath := t.TempDir()
+	writeFiles(t, casePath, map[string]string{
+		TmplCaseFile:        `{"version": "1.0.0"}`,
+		"main.tf":           "variable \"region\" {}\nvariable \"size\" {}\n",
+		"old.tf":            "# removed in 1.1.0\n",
+		"id_rsa":            "key",
+		"terraform.tfstate": "{}",
+		CaseTagsFile:        "# tags",
+	})
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		TmplCaseFile: `{"version": "1.1.0"}`,
+		"main.tf":    "variable \"region\" {}\nvariable \"disk\" {\n  default = 20\n}\n",
+		"extra.tf":   "# new in 1.1.0\n",
+	})
+	c := &Case{Id: "c1", Type: "aws/ec2", Path: casePath, TemplateVersion: "1.0.0", Parameter: []string{"region=us-east-1", "size=small"}}
+
+	u := &CaseUpgrade{Case: c, ToVersion: "1.1.0"}
+	if err := u.mapVariables(src, c.Parameter, map[string]string{"region": "ap-east-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(u.params, " ") != "region=ap-east-1" || strings.Join(u.DroppedVars, ",") != "size" || strings.Join(u.NewVars, ",") != "disk" {
+		t.Errorf("params=%v dropped=%v new=%v", u.params, u.DroppedVars, u.NewVars)
+	}
+	if err := u.diffFiles(src); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(u.Added, ",") != "extra.tf" || strings.Join(u.Changed, ",") != "case.json,main.tf" || strings.Join(u.Removed, ",") != "old.tf" {
+		t.Errorf("added=%v changed=%v removed=%v", u.Added, u.Changed, u.Removed)
+	}
+
+	snap, err := c.saveSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := installCaseFiles(src, casePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(casePath, "old.tf")); !os.IsNotExist(err) {
+		t.Error("old.tf was not removed")
+	}
+	for _, kept := range []string{"id_rsa", "terraform.tfstate", CaseTagsFile} {
+		if _, err := os.Stat(filepath.Join(casePath, kept)); err != nil {
+			t.Errorf("%s was not kept: %v", kept, err)
+		}
+	}
+
+	snapshots, err := c.Snapshots()
+	if err != nil || len(snapshots) != 1 || snapshots[0].ID != snap || snapshots[0].TemplateVersion != "1.0.0" {
+		t.Fatalf("Snapshots() = %+v, %v", snapshots, err)
+	}
+	if err := installCaseFiles(filepath.Join(casePath, CaseSnapshotDir, snap), casePath); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(casePath, "old.tf")); string(data) != "# removed in 1.1.0\n" {
+		t.Errorf("old.tf after restore = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(casePath, "extra.tf")); !os.IsNotExist(err) {
+		t.Error("extra.tf was not removed by the restore")
+	}
+
+	// 新模板中没有默认值的变量必须提供
+	writeFiles(t, src, map[string]string{"main.tf": "variable \"region\" {}\nvariable \"zone\" {}\n"})
+	u = &CaseUpgrade{Case: c, ToVersion: "1.1.0"}
+	if err := u.mapVariables(src, c.Parameter, nil); err == nil || !strings.Contains(err.Error(), "requires variables zone") {
+		t.Errorf("missing variable: err = %v", err)
+	}
+}
+
+func TestResolveUpgradeSource_DownloadsWithoutInstalling(t *testing.T) {
+	tmplDir := useTemplateDir(t)
+	srv, _ := templateRegistry(t)
+	ctx := context.Background()
+	if err := Pull(ctx, "aws/ec2:1.0.0", PullOptions{RegistryURL: srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := CaseUpgradeOptions{Version: "1.0.0"}
+	src, version, cleanup, err := resolveUpgradeSource(ctx, "aws/ec2", opts)
+	cleanup()
+	if err != nil || src != filepath.Join(tmplDir, "aws", "ec2") || version != "1.0.0" {
+		t.Fatalf("local version: %s %s %v", src, version, err)
+	}
+
+	opts = CaseUpgradeOptions{Version: "1.1.0", PullOptions: PullOptions{RegistryURL: srv.URL}}
+	src, version, cleanup, err = resolveUpgradeSource(ctx, "aws/ec2", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(src, "main.tf")); version != "1.1.0" || string(data) != "# v1.1.0\n" {
+		t.Errorf("staged %s = %q", version, data)
+	}
+	cleanup()
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("staging directory was not cleaned up")
+	}
+	if _, v, _ := CheckLocalImage("aws/ec2"); v != "1.0.0" {
+		t.Errorf("installed template changed to v%s", v)
+	}
+}
+
+func TestCaseUpgrade_PendingMarker(t *testing.T) {
+	casePath := t.TempDir()
+	c := &Case{Id: "c1", Type: "aws/ec2", Path: casePath, State: StateStopped, TemplateVersion: "1.0.0"}
+	if err := c.CheckPendingUpgrade(); err != nil {
+		t.Fatalf("no marker: %v", err)
+	}
+
+	u := &CaseUpgrade{Case: c, FromVersion: "1.0.0", ToVersion: "1.1.0", Snapshot: "s1", hash: "abc", params: []string{"region=ap-east-1"}}
+	if err := u.savePending(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.PendingUpgrade()
+	if err != nil || got == nil {
+		t.Fatalf("PendingUpgrade() = %v, %v", got, err)
+	}
+	if got.Case != c || got.ToVersion != "1.1.0" || got.Snapshot != "s1" || got.hash != "abc" || strings.Join(got.params, " ") != "region=ap-east-1" {
+		t.Errorf("PendingUpgrade() = %+v", got)
+	}
+	// 待应用时不能启动或开始新的升级
+	if err := c.CheckPendingUpgrade(); err == nil {
+		t.Error("CheckPendingUpgrade() = nil with a pending upgrade")
+	}
+	if err := c.checkUpgradable(); err == nil {
+		t.Error("checkUpgradable() = nil with a pending upgrade")
+	}
+	if err := c.TfApply(); err == nil || !strings.Contains(err.Error(), "1.1.0") {
+		t.Errorf("TfApply() = %v", err)
+	}
+	// 标记文件不随快照保存或被新模板覆盖
+	if !caseSwapExcluded[CaseUpgradeFile] {
+		t.Error("pending marker is swapped with template files")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"red-cloud/i18n"
+	"red-cloud/mod"
 	"red-cloud/mod/gologger"
 	"red-cloud/utils/sshutil"
 
@@ -47,6 +48,12 @@ func RunComposeUpWithResult(opts ComposeOptions) (*ComposeUpResult, error) {
 		return nil, err
 	}
 
+	// pre-up hook，策略插件可以阻止整个编排
+	hookEv := composeHookEvent(ctx)
+	if err := ctx.Project.RunPluginHook("pre-up", hookEv); err != nil {
+		return nil, err
+	}
+
 	ctx.emitLog(i18n.Tf("compose_deploy_total", len(ctx.RuntimeSvcs)))
 
 	// 2. 编排循环
@@ -110,9 +117,28 @@ func RunComposeUpWithResult(opts ComposeOptions) (*ComposeUpResult, error) {
 		result.Services = append(result.Services, s)
 	}
 
+	// post-up hook，服务已经部署，钩子失败只记录日志
+	if err := ctx.Project.RunPluginHook("post-up", hookEv); err != nil {
+		gologger.Warning().Msgf("%v", err)
+	}
 	return result, nil
 }
 
+// composeHookEvent 项目级钩子只运行各服务模板 redc_plugins 中声明的插件
+func composeHookEvent(ctx *ComposeContext) mod.PluginHookEvent {
+	var ev mod.PluginHookEvent
+	seen := make(map[string]bool)
+	for _, name := range ctx.SortedSvcKeys {
+		for _, p := range mod.TemplatePlugins(ctx.RuntimeSvcs[name].Spec.Image) {
+			if !seen[p] {
+				seen[p] = true
+				ev.Plugins = append(ev.Plugins, p)
+			}
+		}
+	}
+	return ev
+}
+
 // RunComposeDown 销毁入口
 func RunComposeDown(opts ComposeOptions) error {
 	ctx, err := NewComposeContext(opts)
@@ -179,6 +205,42 @@ func RunComposeDown(opts ComposeOptions) error {
 
 // processServiceUp 单个服务部署逻辑
 func processServiceUp(svc *RuntimeService, ctx *ComposeContext) error {
+	tfVars, err := buildServiceVars(svc, ctx)
+	if err != nil {
+		return err
+	}
+
+	// TF Apply
+	ctx.emitLog(fmt.Sprintf("[%s] Terraform Apply...", svc.Name))
+	p := ctx.Project
+	c, err := p.GetCase(svc.Name)
+	if err != nil {
+		c, err = p.CaseCreate(svc.Spec.Image, p.User, svc.Name, tfVars)
+		if err != nil {
+			return fmt.Errorf("CaseCreate fail: %v", err)
+		}
+	}
+	if !ctx.ExpiresAt.IsZero() {
+		c.SetExpiresAt(ctx.ExpiresAt)
+	}
+	if err := c.TfApply(); err != nil {
+		return fmt.Errorf("Terraform Apply fail: %v", err)
+	}
+	ctx.emitLog(fmt.Sprintf("[%s] Terraform Apply 完成", svc.Name))
+	svc.CaseRef = c
+
+	// Output Cache
+	rawOut, err := c.TfOutput()
+	if err == nil {
+		svc.Outputs = parseTfOutput(rawOut)
+	}
+
+	// SSH Actions
+	return runSSHActions(svc, ctx)
+}
+
+// buildServiceVars 解析服务的 configs / environment / provider，生成 terraform 变量
+func buildServiceVars(svc *RuntimeService, ctx *ComposeContext) (map[string]string, error) {
 	tfVars := make(map[string]string)
 
 	// Configs
@@ -201,7 +263,7 @@ func processServiceUp(svc *RuntimeService, ctx *ComposeContext) error {
 			key, rawVal := parts[0], parts[1]
 			vals, err := expandVariable(rawVal, ctx.RuntimeSvcs, svc)
 			if err != nil {
-				return fmt.Errorf("Environment parse error: %v", err)
+				return nil, fmt.Errorf("Environment parse error: %v", err)
 			}
 			tfVars[key] = strings.Join(vals, ",")
 		}
@@ -211,31 +273,7 @@ func processServiceUp(svc *RuntimeService, ctx *ComposeContext) error {
 	if pStr, ok := svc.Spec.Provider.(string); ok && pStr != "" && pStr != "default" {
 		tfVars["provider_alias"] = pStr
 	}
-
-	// TF Apply
-	ctx.emitLog(fmt.Sprintf("[%s] Terraform Apply...", svc.Name))
-	p := ctx.Project
-	c, err := p.GetCase(svc.Name)
-	if err != nil {
-		c, err = p.CaseCreate(svc.Spec.Image, p.User, svc.Name, tfVars)
-		if err != nil {
-			return fmt.Errorf("CaseCreate fail: %v", err)
-		}
-	}
-	if err := c.TfApply(); err != nil {
-		return fmt.Errorf("Terraform Apply fail: %v", err)
-	}
-	ctx.emitLog(fmt.Sprintf("[%s] Terraform Apply 完成", svc.Name))
-	svc.CaseRef = c
-
-	// Output Cache
-	rawOut, err := c.TfOutput()
-	if err == nil {
-		svc.Outputs = parseTfOutput(rawOut)
-	}
-
-	// SSH Actions
-	return runSSHActions(svc, ctx)
+	return tfVars, nil
 }
 
 func runSSHActions(svc *RuntimeService, ctx *ComposeContext) error {
@@ -290,6 +328,9 @@ func runSSHActions(svc *RuntimeService, ctx *ComposeContext) error {
 		msg := fmt.Sprintf("[%s] Running init command...", svc.Name)
 		gologger.Info().Msg(msg)
 		ctx.emitLog(msg)
+		if err := svc.CaseRef.BeforeSSHExec(svc.Spec.Command); err != nil {
+			return err
+		}
 		if err := client.RunCommandWithLogger(svc.Spec.Command, writer); err != nil {
 			gologger.Error().Msgf("[%s] Command failed: %v", svc.Name, err)
 		}
@@ -377,6 +418,9 @@ func runSetupTasks(tasks []SetupTask, svcs map[string]*RuntimeService, ctx *Comp
 					cmdMsg := fmt.Sprintf("[setup] Task: %s | Cmd: %s", task.Name, cmd)
 					gologger.Info().Msg(cmdMsg)
 					ctx.emitLog(cmdMsg)
+					if err := targetSvc.CaseRef.BeforeSSHExec(cmd); err != nil {
+						return err
+					}
 
 					// 1. 创建一个 Buffer 来捕获输出 (包括 stdout 和 stderr)
 					var outputBuf bytes.Buffer
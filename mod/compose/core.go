@@ -7,6 +7,7 @@ import (
 	"red-cloud/mod"
 	"sort"
 	"strings"
+	"time"
 
 	"red-cloud/mod/gologger"
 
@@ -84,6 +85,7 @@ type ComposeOptions struct {
 	Profiles    []string
 	Project     *mod.RedcProject
 	LogCallback func(message string) // optional callback for GUI log streaming
+	ExpiresAt   time.Time            // optional TTL expiry tagged on the services' resources
 }
 
 // ComposeContext 核心上下文，贯穿整个生命周期
@@ -95,6 +97,7 @@ type ComposeContext struct {
 	LogMgr        *gologger.LogManager       // 日志管理器
 	Project       *mod.RedcProject           // 项目引用
 	LogCallback   func(message string)       // optional GUI log callback
+	ExpiresAt     time.Time                  // TTL 到期时间，写入服务资源的标签
 }
 
 // emitLog sends a log message to the callback if set
@@ -168,6 +171,7 @@ func NewComposeContext(opts ComposeOptions) (*ComposeContext, error) {
 		LogMgr:        logMgr,
 		Project:       opts.Project,
 		LogCallback:   opts.LogCallback,
+		ExpiresAt:     opts.ExpiresAt,
 	}, nil
 }
 
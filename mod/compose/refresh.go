@@ -0,0 +1,141 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"red-cloud/mod"
+	"red-cloud/mod/gologger"
+)
+
+// RefreshDependents 在某个服务的 outputs 发生变化后（如抢占式实例恢复换了 IP），
+// 重新计算并 apply 依赖它的服务，返回被更新的服务名
+func RefreshDependents(opts ComposeOptions, changedService string) ([]string, error) {
+	ctx, err := NewComposeContext(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 状态回填
+	for _, name := range ctx.SortedSvcKeys {
+		svc := ctx.RuntimeSvcs[name]
+		c, err := ctx.Project.GetCase(svc.Name)
+		if err != nil {
+			continue
+		}
+		svc.CaseRef = c
+		svc.IsDeployed = true
+		if rawOut, err := c.TfOutput(); err == nil {
+			svc.Outputs = parseTfOutput(rawOut)
+		}
+	}
+
+	target, ok := ctx.RuntimeSvcs[changedService]
+	if !ok {
+		return nil, fmt.Errorf("服务 %s 不在 compose 文件 %s 中", changedService, opts.File)
+	}
+
+	changed := map[string]bool{target.Name: true}
+	var updated []string
+	// SortedSvcKeys 已按依赖排序，依次处理即可覆盖传递依赖
+	for _, name := range ctx.SortedSvcKeys {
+		svc := ctx.RuntimeSvcs[name]
+		if changed[svc.Name] || !svc.IsDeployed || !dependsOnAny(svc, changed, ctx.RuntimeSvcs) {
+			continue
+		}
+
+		tfVars, err := buildServiceVars(svc, ctx)
+		if err != nil {
+			return updated, fmt.Errorf("[%s] %v", svc.Name, err)
+		}
+		params, diff := mergeParams(svc.CaseRef.Parameter, tfVars)
+		if !diff {
+			continue
+		}
+
+		msg := fmt.Sprintf("[%s] 依赖的服务已变更，重新 apply", svc.Name)
+		gologger.Info().Msg(msg)
+		ctx.emitLog(msg)
+
+		c := svc.CaseRef
+		if err := c.CheckPendingUpgrade(); err != nil {
+			return updated, fmt.Errorf("[%s] %v", svc.Name, err)
+		}
+		// 直接调用底层 TfPlan/TfApply，绕过 Case.TfApply 对运行状态的检查
+		if err := mod.TfPlan(c.Path, params...); err != nil {
+			return updated, fmt.Errorf("[%s] Terraform Plan fail: %v", svc.Name, err)
+		}
+		if err := mod.TfApply(c.Path, params...); err != nil {
+			return updated, fmt.Errorf("[%s] Terraform Apply fail: %v", svc.Name, err)
+		}
+		c.Parameter = params
+		if err := c.DBSave(); err != nil {
+			gologger.Error().Msgf("[%s] save case: %v", svc.Name, err)
+		}
+		if err := c.RunPluginHookPublic("post-apply"); err != nil {
+			gologger.Warning().Msgf("[%s] %v", svc.Name, err)
+		}
+		if rawOut, err := c.TfOutput(); err == nil {
+			svc.Outputs = parseTfOutput(rawOut)
+		}
+
+		changed[svc.Name] = true
+		updated = append(updated, svc.Name)
+	}
+	return updated, nil
+}
+
+// dependsOnAny 判断服务是否通过 depends_on 或 ${svc.outputs.key} 引用了 changed 中的服务
+func dependsOnAny(svc *RuntimeService, changed map[string]bool, all map[string]*RuntimeService) bool {
+	for name := range changed {
+		other, ok := all[name]
+		if !ok {
+			continue
+		}
+		for _, dep := range svc.Spec.DependsOn {
+			if dep == other.RawName {
+				return true
+			}
+		}
+		for _, env := range svc.Spec.Environment {
+			if strings.Contains(env, "${"+other.Name+".outputs.") || strings.Contains(env, "${"+other.RawName+".outputs.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeParams 用新变量覆盖原参数，返回合并结果以及是否有变化
+func mergeParams(params []string, vars map[string]string) ([]string, bool) {
+	var merged []string
+	seen := map[string]bool{}
+	diff := false
+	for _, p := range params {
+		key, val, ok := strings.Cut(p, "=")
+		if ok {
+			if nv, exists := vars[key]; exists {
+				seen[key] = true
+				if nv != val {
+					diff = true
+				}
+				merged = append(merged, key+"="+nv)
+				continue
+			}
+		}
+		merged = append(merged, p)
+	}
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		merged = append(merged, k+"="+vars[k])
+		diff = true
+	}
+	return merged, diff
+}
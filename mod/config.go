@@ -33,6 +33,8 @@ type GUISettings struct {
 	NotificationEnabled    bool   `json:"notificationEnabled"`
 	SpotMonitorEnabled     bool   `json:"spotMonitorEnabled"`
 	SpotAutoRecoverEnabled bool   `json:"spotAutoRecoverEnabled"`
+	HealthMonitorEnabled   bool   `json:"healthMonitorEnabled"`
+	HealthMonitorInterval  int    `json:"healthMonitorInterval,omitempty"` // 健康检查间隔（秒），默认 300
 	DebugEnabled           bool   `json:"debugEnabled"`
 	HttpProxy           string `json:"httpProxy"`
 	HttpsProxy          string `json:"httpsProxy"`
@@ -129,6 +131,8 @@ type Config struct {
 		Email  string `yaml:"CF_EMAIL" env:"CF_EMAIL"`
 		APIKey string `yaml:"CF_API_KEY" env:"CF_API_KEY"`
 	} `yaml:"cloudflare"`
+	// Registries 模板仓库，按顺序查找，为空时使用官方仓库
+	Registries []Registry `yaml:"registries,omitempty"`
 }
 
 func LoadConfig(path string) error {
@@ -17,6 +17,16 @@ type CostEstimate struct {
 	Timestamp          time.Time                       `json:"timestamp"`
 	Disclaimer         string                          `json:"disclaimer"`
 	Warnings           []string                        `json:"warnings,omitempty"`
+
+	// OnDemandMonthlyCost is what the same resources would cost with every instance on-demand
+	OnDemandMonthlyCost float64 `json:"on_demand_monthly_cost"`
+	// SpotSavingsMonthly is saved by resources already configured as spot instances
+	SpotSavingsMonthly float64 `json:"spot_savings_monthly,omitempty"`
+	// PotentialSpotSavingsMonthly could be saved by switching on-demand instances to spot
+	PotentialSpotSavingsMonthly float64 `json:"potential_spot_savings_monthly,omitempty"`
+
+	// ExchangeRates lists the rates used when the estimate was converted to Currency
+	ExchangeRates []ExchangeRate `json:"exchange_rates,omitempty"`
 }
 
 // ProviderCostSummary represents aggregated costs for a single provider
@@ -40,6 +50,22 @@ type ResourceCostBreakdown struct {
 	TotalMonthly float64 `json:"total_monthly"`
 	Currency     string  `json:"currency"`
 	Available    bool    `json:"available"` // false if pricing unavailable
+
+	// Spot is true when the resource requests spot / preemptible capacity;
+	// UnitHourly then uses the spot price when one is known
+	Spot               bool    `json:"spot"`
+	OnDemandHourly     float64 `json:"on_demand_hourly,omitempty"`
+	SpotHourly         float64 `json:"spot_hourly,omitempty"`
+	SpotMinHourly      float64 `json:"spot_min_hourly,omitempty"`
+	SpotMaxHourly      float64 `json:"spot_max_hourly,omitempty"`
+	SpotSavingsPercent float64 `json:"spot_savings_percent,omitempty"`
+
+	// UsageNote describes the unit price of resources billed by usage (e.g. object storage),
+	// which are not included in the totals
+	UsageNote string `json:"usage_note,omitempty"`
+
+	// ExchangeRate is the rate used to convert the prices into Currency, nil when not converted
+	ExchangeRate *ExchangeRate `json:"exchange_rate,omitempty"`
 }
 
 // CostCalculator computes cost estimates from resource specifications
@@ -70,33 +96,15 @@ func (cc *CostCalculator) CalculateCost(resources *TemplateResources, pricingSer
 
 		// Add to totals if pricing is available
 		if breakdown.Available {
-			estimate.TotalHourlyCost += breakdown.TotalHourly
-			estimate.TotalMonthlyCost += breakdown.TotalMonthly
-
-			// Set currency from first available resource
-			if estimate.Currency == "" {
-				estimate.Currency = breakdown.Currency
-			}
-
-			// Aggregate by provider
-			provider := breakdown.Provider
-			if provider == "" {
-				provider = "unknown"
+			if breakdown.UsageNote != "" {
+				estimate.Warnings = append(estimate.Warnings,
+					fmt.Sprintf("%s (%s) is not included in the total: %s", breakdown.ResourceName, breakdown.ResourceType, breakdown.UsageNote))
 			}
-
-			if _, exists := estimate.ProviderBreakdown[provider]; !exists {
-				estimate.ProviderBreakdown[provider] = &ProviderCostSummary{
-					Provider:         provider,
-					TotalHourlyCost:  0,
-					TotalMonthlyCost: 0,
-					Currency:         breakdown.Currency,
-					ResourceCount:    0,
-				}
+			if breakdown.Spot && breakdown.SpotHourly <= 0 {
+				estimate.Warnings = append(estimate.Warnings,
+					fmt.Sprintf("Spot price unavailable for %s (%s), using on-demand price", breakdown.ResourceName, breakdown.ResourceType))
 			}
-
-			estimate.ProviderBreakdown[provider].TotalHourlyCost += breakdown.TotalHourly
-			estimate.ProviderBreakdown[provider].TotalMonthlyCost += breakdown.TotalMonthly
-			estimate.ProviderBreakdown[provider].ResourceCount++
+			estimate.addBreakdown(breakdown)
 		} else {
 			// Track unavailable pricing
 			estimate.UnavailableCount++
@@ -115,6 +123,70 @@ func (cc *CostCalculator) CalculateCost(resources *TemplateResources, pricingSer
 	return estimate, nil
 }
 
+// addBreakdown adds an available breakdown to the totals and its provider summary
+func (estimate *CostEstimate) addBreakdown(breakdown ResourceCostBreakdown) {
+	estimate.TotalHourlyCost += breakdown.TotalHourly
+	estimate.TotalMonthlyCost += breakdown.TotalMonthly
+	estimate.OnDemandMonthlyCost += breakdown.OnDemandHourly * 720 * float64(breakdown.Count)
+
+	// Compare on-demand against spot where a spot price is known
+	if breakdown.SpotHourly > 0 {
+		savings := (breakdown.OnDemandHourly - breakdown.SpotHourly) * 720 * float64(breakdown.Count)
+		if breakdown.Spot {
+			estimate.SpotSavingsMonthly += savings
+		} else {
+			estimate.PotentialSpotSavingsMonthly += savings
+		}
+	}
+
+	// Set currency from first available resource
+	if estimate.Currency == "" {
+		estimate.Currency = breakdown.Currency
+	}
+
+	// Aggregate by provider
+	provider := breakdown.Provider
+	if provider == "" {
+		provider = "unknown"
+	}
+
+	if _, exists := estimate.ProviderBreakdown[provider]; !exists {
+		estimate.ProviderBreakdown[provider] = &ProviderCostSummary{
+			Provider:         provider,
+			TotalHourlyCost:  0,
+			TotalMonthlyCost: 0,
+			Currency:         breakdown.Currency,
+			ResourceCount:    0,
+		}
+	}
+
+	estimate.ProviderBreakdown[provider].TotalHourlyCost += breakdown.TotalHourly
+	estimate.ProviderBreakdown[provider].TotalMonthlyCost += breakdown.TotalMonthly
+	estimate.ProviderBreakdown[provider].ResourceCount++
+}
+
+// computeInstanceTypeAttributes maps compute resource types to the attributes holding their instance type
+var computeInstanceTypeAttributes = map[string][]string{
+	"alicloud_instance":            {"instance_type"},
+	"aws_instance":                 {"instance_type"},
+	"aws_spot_instance_request":    {"instance_type"},
+	"tencentcloud_instance":        {"instance_type"},
+	"volcengine_ecs_instance":      {"instance_type"},
+	"huaweicloud_compute_instance": {"flavor_id", "flavor_name"},
+	"ucloud_instance":              {"instance_type"},
+	"vultr_instance":               {"plan"},
+	"google_compute_instance":      {"machine_type"},
+}
+
+// zoneAttributes lists, per provider, the resource attributes holding the zone or region used for pricing
+var zoneAttributes = map[string][]string{
+	"tencentcloud": {"availability_zone"},
+	"huaweicloud":  {"availability_zone", "region"},
+	"ucloud":       {"availability_zone", "region"},
+	"vultr":        {"region"},
+	"google":       {"zone"},
+}
+
 // calculateResourceCost calculates cost for a single resource
 func (cc *CostCalculator) calculateResourceCost(resource ResourceSpec, pricingService *PricingService) ResourceCostBreakdown {
 	breakdown := ResourceCostBreakdown{
@@ -123,6 +195,12 @@ func (cc *CostCalculator) calculateResourceCost(resource ResourceSpec, pricingSe
 		Provider:     resource.Provider,
 		Count:        resource.Count,
 		Available:    false,
+		Spot:         IsSpotInstance(resource.Type, resource.Attributes),
+	}
+
+	// Network, storage and DNS resources are priced per instance, GB or Mbps
+	if rule, ok := usageRules[resource.Type]; ok {
+		return cc.calculateUsageCost(resource, rule, pricingService)
 	}
 
 	// Determine the resource type to use for pricing lookup
@@ -130,18 +208,16 @@ func (cc *CostCalculator) calculateResourceCost(resource ResourceSpec, pricingSe
 	pricingResourceType := resource.Type
 	
 	// Extract instance type from attributes for compute resources
-	if resource.Type == "alicloud_instance" || resource.Type == "aws_instance" || resource.Type == "tencentcloud_instance" || resource.Type == "volcengine_ecs_instance" {
-		if instanceType, ok := resource.Attributes["instance_type"].(string); ok && instanceType != "" {
+	if attrNames, ok := computeInstanceTypeAttributes[resource.Type]; ok {
+		instanceType, _ := usageAttr(resource.Attributes, attrNames)
+		if s, ok := instanceType.(string); ok && s != "" {
 			// Check if instance_type contains unresolved expressions
 			// Unresolved expressions typically contain "${", "data.", or other Terraform syntax
-			if strings.Contains(instanceType, "${") || 
-			   strings.Contains(instanceType, "data.") ||
-			   strings.Contains(instanceType, "local.") ||
-			   strings.Contains(instanceType, "module.") {
+			if isUnresolvedValue(s) {
 				// Instance type contains unresolved expression - pricing unavailable
 				return breakdown
 			}
-			pricingResourceType = instanceType
+			pricingResourceType = s
 		} else {
 			// Instance type not found in attributes - pricing unavailable
 			return breakdown
@@ -158,14 +234,14 @@ func (cc *CostCalculator) calculateResourceCost(resource ResourceSpec, pricingSe
 			// AWS non-compute resources
 			"aws_security_group", "aws_security_group_rule",
 			"aws_vpc", "aws_subnet",
-			"aws_key_pair", "aws_eip",
+			"aws_key_pair",
 			"aws_availability_zones", "aws_ami",
 			// Tencent Cloud non-compute resources
 			"tencentcloud_security_group", "tencentcloud_security_group_rule",
 			"tencentcloud_vpc", "tencentcloud_subnet",
 			"tencentcloud_availability_zones", "tencentcloud_images",
 			// Volcengine non-compute resources
-			"volcengine_eip_address", "volcengine_eip_associate",
+			"volcengine_eip_associate",
 			"volcengine_security_group", "volcengine_security_group_rule",
 			"volcengine_vpc", "volcengine_subnet",
 			"volcengine_zones", "volcengine_images",
@@ -185,11 +261,13 @@ func (cc *CostCalculator) calculateResourceCost(resource ResourceSpec, pricingSe
 	}
 	
 	// Get pricing data for this resource
-	// For Tencent Cloud, use availability_zone if available
+	// Providers priced per zone read it from the resource (e.g. Tencent Cloud availability_zone)
 	regionOrZone := resource.Region
-	if resource.Provider == "tencentcloud" {
-		if zone, ok := resource.Attributes["availability_zone"].(string); ok && zone != "" {
-			regionOrZone = zone
+	if attrNames, ok := zoneAttributes[resource.Provider]; ok {
+		if zone, ok := usageAttr(resource.Attributes, attrNames); ok {
+			if s, ok := zone.(string); ok && s != "" && !isUnresolvedValue(s) {
+				regionOrZone = s
+			}
 		}
 	}
 	
@@ -207,24 +285,94 @@ func (cc *CostCalculator) calculateResourceCost(resource ResourceSpec, pricingSe
 	if len(pricing.PricingTiers) > 0 {
 		// Use tiered pricing calculation
 		breakdown.UnitHourly = cc.calculateTieredPrice(resource.Count, pricing.PricingTiers)
-		breakdown.UnitMonthly = breakdown.UnitHourly * 720 // 720 hours per month (30 days * 24 hours)
-		
-		// For tiered pricing, total is already calculated per unit based on quantity
-		breakdown.TotalHourly = breakdown.UnitHourly * float64(resource.Count)
-		breakdown.TotalMonthly = breakdown.UnitMonthly * float64(resource.Count)
 	} else {
 		// Use simple flat pricing
 		breakdown.UnitHourly = pricing.HourlyPrice
-		breakdown.UnitMonthly = pricing.HourlyPrice * 720 // 720 hours per month (30 days * 24 hours)
+	}
+	breakdown.OnDemandHourly = breakdown.UnitHourly
 
-		// Calculate total costs (multiply by count)
-		breakdown.TotalHourly = breakdown.UnitHourly * float64(resource.Count)
-		breakdown.TotalMonthly = breakdown.UnitMonthly * float64(resource.Count)
+	// Carry spot pricing for comparison; spot resources are billed at the spot price
+	if pricing.Spot != nil && pricing.Spot.HourlyPrice > 0 {
+		breakdown.SpotHourly = pricing.Spot.HourlyPrice
+		breakdown.SpotMinHourly = pricing.Spot.MinHourlyPrice
+		breakdown.SpotMaxHourly = pricing.Spot.MaxHourlyPrice
+		if breakdown.OnDemandHourly > 0 {
+			breakdown.SpotSavingsPercent = (1 - breakdown.SpotHourly/breakdown.OnDemandHourly) * 100
+		}
+		if breakdown.Spot {
+			breakdown.UnitHourly = breakdown.SpotHourly
+		}
 	}
 
+	breakdown.UnitMonthly = breakdown.UnitHourly * 720 // 720 hours per month (30 days * 24 hours)
+
+	// Calculate total costs (multiply by count)
+	breakdown.TotalHourly = breakdown.UnitHourly * float64(resource.Count)
+	breakdown.TotalMonthly = breakdown.UnitMonthly * float64(resource.Count)
+
 	return breakdown
 }
 
+// IsSpotInstance checks resource attributes (HCL, state or plan values) for spot /
+// preemptible instance indicators. It is the single detector used by the cost engine
+// and the GUI's plan preview.
+// Supports: Alibaba Cloud and Volcengine (spot_strategy), AWS (instance_market_options or
+// aws_spot_instance_request), Tencent Cloud (instance_charge_type = SPOTPAID),
+// Google Cloud (scheduling preemptible or provisioning_model = SPOT)
+func IsSpotInstance(resourceType string, attrs map[string]interface{}) bool {
+	if resourceType == "aws_spot_instance_request" {
+		return true
+	}
+	if v, ok := attrs["spot_strategy"].(string); ok && v != "" && v != "NoSpot" {
+		return true
+	}
+	if v, ok := attrs["instance_charge_type"].(string); ok && strings.EqualFold(v, "SPOTPAID") {
+		return true
+	}
+	if v, ok := attrs["is_spot_instance"].(bool); ok && v {
+		return true
+	}
+	isSpotMarket := func(opts interface{}) bool {
+		om, ok := opts.(map[string]interface{})
+		return ok && fmt.Sprintf("%v", om["market_type"]) == "spot"
+	}
+	isSpotScheduling := func(scheduling interface{}) bool {
+		sm, ok := scheduling.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if v, ok := sm["preemptible"].(bool); ok && v {
+			return true
+		}
+		v, ok := sm["provisioning_model"].(string)
+		return ok && strings.EqualFold(v, "SPOT")
+	}
+	// Nested blocks arrive as a list in terraform state / plan JSON, as a map from HCL
+	switch scheduling := attrs["scheduling"].(type) {
+	case []interface{}:
+		for _, block := range scheduling {
+			if isSpotScheduling(block) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		if isSpotScheduling(scheduling) {
+			return true
+		}
+	}
+	switch opts := attrs["instance_market_options"].(type) {
+	case []interface{}:
+		for _, opt := range opts {
+			if isSpotMarket(opt) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		return isSpotMarket(opts)
+	}
+	return false
+}
+
 // calculateTieredPrice calculates the effective price per unit based on tiered pricing
 // For a given quantity, it finds the appropriate tier and returns the price per unit for that tier
 func (cc *CostCalculator) calculateTieredPrice(quantity int, tiers []PricingTier) float64 {
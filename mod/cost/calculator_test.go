@@ -2,6 +2,7 @@ package cost
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -345,3 +346,252 @@ func TestCalculateCost_EmptyResources(t *testing.T) {
 	}
 }
 
+
+// TestCalculateCost_SpotInstance tests that spot instances use the spot price and report savings
+func TestCalculateCost_SpotInstance(t *testing.T) {
+	ps := NewPricingService(":memory:")
+	defer ps.Close()
+
+	ps.SetFallbackProvider(func(provider, region, resourceType string) (*PricingData, error) {
+		return &PricingData{
+			Provider:     provider,
+			Region:       region,
+			ResourceType: resourceType,
+			Currency:     "CNY",
+			HourlyPrice:  1.0,
+			Spot:         &SpotPricing{HourlyPrice: 0.2, MinHourlyPrice: 0.1, MaxHourlyPrice: 0.3},
+		}, nil
+	})
+
+	calc := NewCostCalculator()
+	resources := &TemplateResources{
+		Resources: []ResourceSpec{
+			{
+				Type:       "alicloud_instance",
+				Name:       "spot",
+				Count:      2,
+				Provider:   "alicloud",
+				Region:     "cn-hangzhou",
+				Attributes: map[string]interface{}{"instance_type": "ecs.g6.large", "spot_strategy": "SpotAsPriceGo"},
+			},
+			{
+				Type:       "alicloud_instance",
+				Name:       "ondemand",
+				Count:      1,
+				Provider:   "alicloud",
+				Region:     "cn-hangzhou",
+				Attributes: map[string]interface{}{"instance_type": "ecs.g6.large", "spot_strategy": "NoSpot"},
+			},
+		},
+	}
+
+	estimate, err := calc.CalculateCost(resources, ps)
+	if err != nil {
+		t.Fatalf("CalculateCost failed: %v", err)
+	}
+
+	spot := estimate.Breakdown[0]
+	if !spot.Spot {
+		t.Error("Expected spot_strategy resource to be detected as spot")
+	}
+	if spot.UnitHourly != 0.2 || spot.OnDemandHourly != 1.0 {
+		t.Errorf("Expected unit hourly 0.2 and on-demand 1.0, got %f and %f", spot.UnitHourly, spot.OnDemandHourly)
+	}
+	if spot.SpotMinHourly != 0.1 || spot.SpotMaxHourly != 0.3 {
+		t.Errorf("Expected spot range 0.1-0.3, got %f-%f", spot.SpotMinHourly, spot.SpotMaxHourly)
+	}
+	if fmt.Sprintf("%.1f", spot.SpotSavingsPercent) != "80.0" {
+		t.Errorf("Expected 80%% spot savings, got %f", spot.SpotSavingsPercent)
+	}
+
+	ondemand := estimate.Breakdown[1]
+	if ondemand.Spot || ondemand.UnitHourly != 1.0 {
+		t.Errorf("Expected on-demand resource billed at 1.0, got spot=%v unit=%f", ondemand.Spot, ondemand.UnitHourly)
+	}
+
+	if fmt.Sprintf("%.2f", estimate.TotalHourlyCost) != "1.40" {
+		t.Errorf("Expected total hourly 1.40, got %f", estimate.TotalHourlyCost)
+	}
+	if fmt.Sprintf("%.2f", estimate.OnDemandMonthlyCost) != "2160.00" {
+		t.Errorf("Expected on-demand monthly 2160, got %f", estimate.OnDemandMonthlyCost)
+	}
+	if fmt.Sprintf("%.2f", estimate.SpotSavingsMonthly) != "1152.00" {
+		t.Errorf("Expected spot savings 1152, got %f", estimate.SpotSavingsMonthly)
+	}
+	if fmt.Sprintf("%.2f", estimate.PotentialSpotSavingsMonthly) != "576.00" {
+		t.Errorf("Expected potential spot savings 576, got %f", estimate.PotentialSpotSavingsMonthly)
+	}
+}
+
+// TestCalculateCost_SpotWithoutSpotPrice tests the on-demand fallback for spot instances
+func TestCalculateCost_SpotWithoutSpotPrice(t *testing.T) {
+	ps := NewPricingService(":memory:")
+	defer ps.Close()
+
+	ps.SetFallbackProvider(func(provider, region, resourceType string) (*PricingData, error) {
+		return &PricingData{Provider: provider, Region: region, ResourceType: resourceType, Currency: "USD", HourlyPrice: 0.1}, nil
+	})
+
+	calc := NewCostCalculator()
+	resources := &TemplateResources{
+		Resources: []ResourceSpec{
+			{
+				Type:     "aws_instance",
+				Name:     "spot",
+				Count:    1,
+				Provider: "aws",
+				Region:   "us-east-1",
+				Attributes: map[string]interface{}{
+					"instance_type":           "t3.large",
+					"instance_market_options": map[string]interface{}{"market_type": "spot"},
+				},
+			},
+		},
+	}
+
+	estimate, err := calc.CalculateCost(resources, ps)
+	if err != nil {
+		t.Fatalf("CalculateCost failed: %v", err)
+	}
+	if !estimate.Breakdown[0].Spot || estimate.Breakdown[0].UnitHourly != 0.1 {
+		t.Errorf("Expected spot resource at on-demand price 0.1, got spot=%v unit=%f", estimate.Breakdown[0].Spot, estimate.Breakdown[0].UnitHourly)
+	}
+	if len(estimate.Warnings) != 1 {
+		t.Errorf("Expected 1 warning about missing spot price, got %v", estimate.Warnings)
+	}
+}
+
+// TestCalculateCost_UsageResources tests per-GB, per-Mbps and usage-billed resources
+func TestCalculateCost_UsageResources(t *testing.T) {
+	ps := NewPricingService(":memory:")
+	defer ps.Close()
+
+	// Usage resources are priced from the bundled table, never from the fallback
+	ps.SetFallbackProvider(func(provider, region, resourceType string) (*PricingData, error) {
+		return nil, fmt.Errorf("unexpected fallback lookup for %s", resourceType)
+	})
+
+	calc := NewCostCalculator()
+	resources := &TemplateResources{
+		Resources: []ResourceSpec{
+			{Type: "aws_ebs_volume", Name: "data", Count: 2, Provider: "aws", Attributes: map[string]interface{}{"size": 100, "type": "gp3"}},
+			{Type: "alicloud_eip_address", Name: "ip", Count: 1, Provider: "alicloud", Attributes: map[string]interface{}{"internet_charge_type": "PayByBandwidth", "bandwidth": "5"}},
+			{Type: "huaweicloud_vpc_eip", Name: "hw-ip", Count: 1, Provider: "huaweicloud", Attributes: map[string]interface{}{
+				"bandwidth": map[string]interface{}{"charge_mode": "traffic", "size": 10},
+			}},
+			{Type: "aws_s3_bucket", Name: "bucket", Count: 1, Provider: "aws"},
+			{Type: "aws_ebs_volume", Name: "unresolved", Count: 1, Provider: "aws", Attributes: map[string]interface{}{"size": "${var.size}"}},
+		},
+	}
+
+	estimate, err := calc.CalculateCost(resources, ps)
+	if err != nil {
+		t.Fatalf("CalculateCost failed: %v", err)
+	}
+
+	byName := make(map[string]ResourceCostBreakdown)
+	for _, b := range estimate.Breakdown {
+		byName[b.ResourceName] = b
+	}
+
+	// 2 x 100GB gp3 at 0.08 USD per GB-month
+	if got := byName["data"].TotalMonthly; got < 15.99 || got > 16.01 {
+		t.Errorf("Expected disk monthly cost 16, got %f", got)
+	}
+	// EIP instance fee plus 5 Mbps of bandwidth
+	if got, expected := byName["ip"].UnitHourly, 0.02+5*0.063; got < expected-1e-9 || got > expected+1e-9 {
+		t.Errorf("Expected EIP hourly %f, got %f", expected, got)
+	}
+	// Traffic-billed EIP pays no bandwidth fee
+	if !byName["hw-ip"].Available || byName["hw-ip"].UnitHourly != 0 {
+		t.Errorf("Expected traffic-billed Huawei Cloud EIP to be free, got %+v", byName["hw-ip"])
+	}
+	bucket := byName["bucket"]
+	if !bucket.Available || bucket.TotalMonthly != 0 || bucket.UsageNote == "" {
+		t.Errorf("Expected object storage with usage note and no fixed cost, got %+v", bucket)
+	}
+	if byName["unresolved"].Available {
+		t.Error("Expected unresolved disk size to be unavailable")
+	}
+	if estimate.UnavailableCount != 1 {
+		t.Errorf("Expected 1 unavailable resource, got %d", estimate.UnavailableCount)
+	}
+
+	foundNote := false
+	for _, w := range estimate.Warnings {
+		if strings.Contains(w, "bucket") {
+			foundNote = true
+		}
+	}
+	if !foundNote {
+		t.Errorf("Expected usage warning for object storage, got %v", estimate.Warnings)
+	}
+}
+
+// TestIsSpotInstance_GCPScheduling tests both shapes of the google_compute_instance scheduling block
+func TestIsSpotInstance_GCPScheduling(t *testing.T) {
+	tests := []struct {
+		name       string
+		scheduling interface{}
+		want       bool
+	}{
+		// terraform state / plan JSON: nested blocks are lists
+		{"state spot", []interface{}{map[string]interface{}{"preemptible": false, "provisioning_model": "SPOT"}}, true},
+		{"state preemptible", []interface{}{map[string]interface{}{"preemptible": true, "provisioning_model": "STANDARD"}}, true},
+		{"state standard", []interface{}{map[string]interface{}{"preemptible": false, "provisioning_model": "STANDARD"}}, false},
+		{"hcl map", map[string]interface{}{"preemptible": true}, true},
+		{"empty list", []interface{}{}, false},
+	}
+	for _, tt := range tests {
+		attrs := map[string]interface{}{"scheduling": tt.scheduling}
+		if got := IsSpotInstance("google_compute_instance", attrs); got != tt.want {
+			t.Errorf("%s: IsSpotInstance = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestCalculateCost_NewProviderInstances tests instance type extraction for the added providers
+func TestCalculateCost_NewProviderInstances(t *testing.T) {
+	ps := NewPricingService(":memory:")
+	defer ps.Close()
+
+	var lookups []string
+	ps.SetFallbackProvider(func(provider, region, resourceType string) (*PricingData, error) {
+		lookups = append(lookups, provider+"/"+region+"/"+resourceType)
+		return &PricingData{Provider: provider, Region: region, ResourceType: resourceType, Currency: "USD", HourlyPrice: 0.1}, nil
+	})
+
+	calc := NewCostCalculator()
+	resources := &TemplateResources{
+		Resources: []ResourceSpec{
+			{Type: "google_compute_instance", Name: "gce", Count: 1, Provider: "google", Attributes: map[string]interface{}{
+				"machine_type": "e2-standard-2", "zone": "us-central1-a",
+				"scheduling": map[string]interface{}{"provisioning_model": "SPOT"},
+			}},
+			{Type: "vultr_instance", Name: "vps", Count: 1, Provider: "vultr", Attributes: map[string]interface{}{"plan": "vc2-1c-1gb", "region": "nrt"}},
+			{Type: "huaweicloud_compute_instance", Name: "ecs", Count: 1, Provider: "huaweicloud", Attributes: map[string]interface{}{"flavor_id": "s6.small.1"}},
+			{Type: "ucloud_instance", Name: "uhost", Count: 1, Provider: "ucloud", Attributes: map[string]interface{}{"instance_type": "n-standard-2", "availability_zone": "cn-bj2-02"}},
+		},
+	}
+
+	estimate, err := calc.CalculateCost(resources, ps)
+	if err != nil {
+		t.Fatalf("CalculateCost failed: %v", err)
+	}
+	if estimate.UnavailableCount != 0 {
+		t.Errorf("Expected all instances priced, got %d unavailable", estimate.UnavailableCount)
+	}
+	if !estimate.Breakdown[0].Spot {
+		t.Error("Expected GCP SPOT provisioning model to be detected as spot")
+	}
+
+	expected := []string{
+		"google/us-central1-a/e2-standard-2",
+		"vultr/nrt/vc2-1c-1gb",
+		"huaweicloud//s6.small.1",
+		"ucloud/cn-bj2-02/n-standard-2",
+	}
+	if strings.Join(lookups, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected lookups %v, got %v", expected, lookups)
+	}
+}
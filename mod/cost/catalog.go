@@ -0,0 +1,355 @@
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// catalogDateLayout is the layout of a catalog effective date
+const catalogDateLayout = "2006-01-02"
+
+// PricingCatalog is a versioned offline pricing bundle.
+// Pricing is keyed by provider, region and resource type, the same layout as the
+// bundled fallback JSON, so a fallback file can be imported as a catalog.
+type PricingCatalog struct {
+	Version       string                                        `json:"version"`
+	EffectiveDate string                                        `json:"effective_date"` // date the prices apply from (2006-01-02)
+	LastUpdated   string                                        `json:"last_updated,omitempty"`
+	Source        string                                        `json:"source,omitempty"`
+	Pricing       map[string]map[string]map[string]*PricingData `json:"pricing"`
+}
+
+// CatalogInfo describes an installed catalog
+type CatalogInfo struct {
+	Name          string `json:"name"`
+	Path          string `json:"path"`
+	Version       string `json:"version"`
+	EffectiveDate string `json:"effective_date"`
+	Source        string `json:"source,omitempty"`
+	Entries       int    `json:"entries"`
+}
+
+// CatalogDiffEntry is a single price difference between two catalogs
+type CatalogDiffEntry struct {
+	Provider     string  `json:"provider"`
+	Region       string  `json:"region"`
+	ResourceType string  `json:"resource_type"`
+	Change       string  `json:"change"` // added, removed or changed
+	Currency     string  `json:"currency"`
+	OldHourly    float64 `json:"old_hourly"`
+	NewHourly    float64 `json:"new_hourly"`
+	OldSpot      float64 `json:"old_spot_hourly,omitempty"`
+	NewSpot      float64 `json:"new_spot_hourly,omitempty"`
+}
+
+// Entries returns the number of priced resource types in the catalog
+func (c *PricingCatalog) Entries() int {
+	n := 0
+	for _, regions := range c.Pricing {
+		for _, types := range regions {
+			n += len(types)
+		}
+	}
+	return n
+}
+
+// Validate checks the catalog and fills provider, region and resource type
+// of each entry from its keys. A fallback file without effective_date uses last_updated.
+func (c *PricingCatalog) Validate() error {
+	if c.EffectiveDate == "" {
+		c.EffectiveDate = c.LastUpdated
+	}
+	if _, err := time.Parse(catalogDateLayout, c.EffectiveDate); err != nil {
+		return fmt.Errorf("invalid effective_date %q, expected YYYY-MM-DD", c.EffectiveDate)
+	}
+	if c.Version == "" {
+		return fmt.Errorf("catalog version is required")
+	}
+	if c.Entries() == 0 {
+		return fmt.Errorf("catalog contains no pricing entries")
+	}
+	for provider, regions := range c.Pricing {
+		for region, types := range regions {
+			for resourceType, data := range types {
+				if data == nil {
+					return fmt.Errorf("empty pricing entry %s/%s/%s", provider, region, resourceType)
+				}
+				if data.HourlyPrice < 0 || data.MonthlyPrice < 0 {
+					return fmt.Errorf("negative price for %s/%s/%s", provider, region, resourceType)
+				}
+				if data.Currency == "" {
+					return fmt.Errorf("missing currency for %s/%s/%s", provider, region, resourceType)
+				}
+				data.Provider, data.Region, data.ResourceType = provider, region, resourceType
+			}
+		}
+	}
+	return nil
+}
+
+// Lookup returns the catalog price of a resource. A zone such as "ap-beijing-7"
+// falls back to its region when the catalog has no zone-level entry.
+func (c *PricingCatalog) Lookup(provider, region, resourceType string) (*PricingData, bool) {
+	regions := c.Pricing[provider]
+	if regions == nil {
+		return nil, false
+	}
+	if data, ok := regions[region][resourceType]; ok && data != nil {
+		return data, true
+	}
+	if parts := strings.Split(region, "-"); len(parts) >= 3 {
+		if data, ok := regions[strings.Join(parts[:len(parts)-1], "-")][resourceType]; ok && data != nil {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// Set adds or replaces the price of a resource
+func (c *PricingCatalog) Set(data *PricingData) {
+	if c.Pricing == nil {
+		c.Pricing = make(map[string]map[string]map[string]*PricingData)
+	}
+	if c.Pricing[data.Provider] == nil {
+		c.Pricing[data.Provider] = make(map[string]map[string]*PricingData)
+	}
+	if c.Pricing[data.Provider][data.Region] == nil {
+		c.Pricing[data.Provider][data.Region] = make(map[string]*PricingData)
+	}
+	c.Pricing[data.Provider][data.Region][data.ResourceType] = data
+}
+
+// LoadPricingCatalog reads and validates a catalog file
+func LoadPricingCatalog(path string) (*PricingCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing catalog: %w", err)
+	}
+	var catalog PricingCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing catalog %s: %w", path, err)
+	}
+	if err := catalog.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pricing catalog %s: %w", path, err)
+	}
+	return &catalog, nil
+}
+
+// WritePricingCatalog writes a catalog as indented JSON
+func WritePricingCatalog(catalog *PricingCatalog, path string) error {
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pricing catalog: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write pricing catalog: %w", err)
+	}
+	return nil
+}
+
+var catalogNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// catalogFileName is the installed file name, ordered by effective date then version
+func catalogFileName(catalog *PricingCatalog) string {
+	return catalog.EffectiveDate + "_" + catalogNameUnsafe.ReplaceAllString(catalog.Version, "-") + ".json"
+}
+
+// ImportPricingCatalog validates a catalog file and installs it into dir.
+// Importing the same effective date and version again replaces the installed copy.
+func ImportPricingCatalog(dir, path string) (*CatalogInfo, error) {
+	catalog, err := LoadPricingCatalog(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create catalog directory: %w", err)
+	}
+	dest := filepath.Join(dir, catalogFileName(catalog))
+	if err := WritePricingCatalog(catalog, dest); err != nil {
+		return nil, err
+	}
+	return catalogInfo(catalog, dest), nil
+}
+
+func catalogInfo(catalog *PricingCatalog, path string) *CatalogInfo {
+	return &CatalogInfo{
+		Name:          strings.TrimSuffix(filepath.Base(path), ".json"),
+		Path:          path,
+		Version:       catalog.Version,
+		EffectiveDate: catalog.EffectiveDate,
+		Source:        catalog.Source,
+		Entries:       catalog.Entries(),
+	}
+}
+
+// ListPricingCatalogs lists installed catalogs, newest effective date first.
+// Files that fail to load are skipped.
+func ListPricingCatalogs(dir string) ([]CatalogInfo, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var infos []CatalogInfo
+	for _, f := range files {
+		catalog, err := LoadPricingCatalog(f)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, *catalogInfo(catalog, f))
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].EffectiveDate != infos[j].EffectiveDate {
+			return infos[i].EffectiveDate > infos[j].EffectiveDate
+		}
+		return infos[i].Version > infos[j].Version
+	})
+	return infos, nil
+}
+
+// LatestPricingCatalog loads the newest installed catalog whose effective date is not
+// in the future. It returns nil without error when no catalog is installed.
+func LatestPricingCatalog(dir string) (*PricingCatalog, error) {
+	infos, err := ListPricingCatalogs(dir)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Now().Format(catalogDateLayout)
+	for _, info := range infos {
+		if info.EffectiveDate <= today {
+			return LoadPricingCatalog(info.Path)
+		}
+	}
+	return nil, nil
+}
+
+// ResolvePricingCatalog loads a catalog by file path or by installed name
+func ResolvePricingCatalog(dir, ref string) (*PricingCatalog, error) {
+	if _, err := os.Stat(ref); err == nil {
+		return LoadPricingCatalog(ref)
+	}
+	path := filepath.Join(dir, strings.TrimSuffix(ref, ".json")+".json")
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("pricing catalog %s not found", ref)
+	}
+	return LoadPricingCatalog(path)
+}
+
+// DiffPricingCatalogs compares the prices of two catalogs, sorted by provider, region and type
+func DiffPricingCatalogs(oldCatalog, newCatalog *PricingCatalog) []CatalogDiffEntry {
+	var diff []CatalogDiffEntry
+	spotPrice := func(data *PricingData) float64 {
+		if data.Spot == nil {
+			return 0
+		}
+		return data.Spot.HourlyPrice
+	}
+	priceEqual := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	for provider, regions := range newCatalog.Pricing {
+		for region, types := range regions {
+			for resourceType, data := range types {
+				entry := CatalogDiffEntry{
+					Provider:     provider,
+					Region:       region,
+					ResourceType: resourceType,
+					Currency:     data.Currency,
+					NewHourly:    data.HourlyPrice,
+					NewSpot:      spotPrice(data),
+				}
+				old, ok := oldCatalog.Pricing[provider][region][resourceType]
+				switch {
+				case !ok || old == nil:
+					entry.Change = "added"
+				case !priceEqual(old.HourlyPrice, data.HourlyPrice) || !priceEqual(spotPrice(old), spotPrice(data)) || old.Currency != data.Currency:
+					entry.Change = "changed"
+					entry.OldHourly = old.HourlyPrice
+					entry.OldSpot = spotPrice(old)
+				default:
+					continue
+				}
+				diff = append(diff, entry)
+			}
+		}
+	}
+	for provider, regions := range oldCatalog.Pricing {
+		for region, types := range regions {
+			for resourceType, data := range types {
+				if _, ok := newCatalog.Pricing[provider][region][resourceType]; ok || data == nil {
+					continue
+				}
+				diff = append(diff, CatalogDiffEntry{
+					Provider:     provider,
+					Region:       region,
+					ResourceType: resourceType,
+					Change:       "removed",
+					Currency:     data.Currency,
+					OldHourly:    data.HourlyPrice,
+					OldSpot:      spotPrice(data),
+				})
+			}
+		}
+	}
+
+	sort.Slice(diff, func(i, j int) bool {
+		if diff[i].Provider != diff[j].Provider {
+			return diff[i].Provider < diff[j].Provider
+		}
+		if diff[i].Region != diff[j].Region {
+			return diff[i].Region < diff[j].Region
+		}
+		return diff[i].ResourceType < diff[j].ResourceType
+	})
+	return diff
+}
+
+// ExportPricingCatalog builds a catalog from the newest installed catalog overlaid with
+// every price in the pricing cache, so prices fetched on a connected machine can be
+// carried to an air-gapped one.
+func ExportPricingCatalog(ps *PricingService, dir, version string) (*PricingCatalog, error) {
+	now := time.Now()
+	if version == "" {
+		version = now.Format("2006.01.02")
+	}
+	catalog := &PricingCatalog{
+		Version:       version,
+		EffectiveDate: now.Format(catalogDateLayout),
+		Source:        "redc cost catalog export",
+	}
+
+	base, err := LatestPricingCatalog(dir)
+	if err != nil {
+		return nil, err
+	}
+	if base != nil {
+		for _, regions := range base.Pricing {
+			for _, types := range regions {
+				for _, data := range types {
+					catalog.Set(data)
+				}
+			}
+		}
+	}
+
+	cached, err := ps.CachedPricing()
+	if err != nil {
+		return nil, err
+	}
+	for _, data := range cached {
+		if data.Provider == "" || data.ResourceType == "" || data.Currency == "" {
+			continue
+		}
+		catalog.Set(data)
+	}
+
+	if catalog.Entries() == 0 {
+		return nil, fmt.Errorf("no cached or catalog pricing to export")
+	}
+	return catalog, nil
+}
@@ -0,0 +1,246 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCatalog writes a catalog with one AWS price to a temp file
+func writeTestCatalog(t *testing.T, dir, version, effective string, hourly float64) string {
+	t.Helper()
+	catalog := &PricingCatalog{Version: version, EffectiveDate: effective}
+	catalog.Set(&PricingData{Provider: "aws", Region: "us-east-1", ResourceType: "t3.large", Currency: "USD", HourlyPrice: hourly})
+	path := filepath.Join(dir, version+".json")
+	if err := WritePricingCatalog(catalog, path); err != nil {
+		t.Fatalf("WritePricingCatalog failed: %v", err)
+	}
+	return path
+}
+
+// TestPricingCatalog_Validate tests validation and key back-filling
+func TestPricingCatalog_Validate(t *testing.T) {
+	catalog := &PricingCatalog{
+		Version:     "1.0",
+		LastUpdated: "2024-01-01",
+		Pricing: map[string]map[string]map[string]*PricingData{
+			"aws": {"us-east-1": {"t2.micro": {Currency: "USD", HourlyPrice: 0.0116}}},
+		},
+	}
+	if err := catalog.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if catalog.EffectiveDate != "2024-01-01" {
+		t.Errorf("Expected effective date from last_updated, got %q", catalog.EffectiveDate)
+	}
+	data := catalog.Pricing["aws"]["us-east-1"]["t2.micro"]
+	if data.Provider != "aws" || data.Region != "us-east-1" || data.ResourceType != "t2.micro" {
+		t.Errorf("Expected keys to be filled in, got %+v", data)
+	}
+
+	catalog.Pricing["aws"]["us-east-1"]["t2.micro"].HourlyPrice = -1
+	if err := catalog.Validate(); err == nil {
+		t.Error("Expected error for negative price")
+	}
+
+	if err := (&PricingCatalog{Version: "1.0", EffectiveDate: "2024/01/01"}).Validate(); err == nil {
+		t.Error("Expected error for invalid effective date")
+	}
+}
+
+// TestPricingCatalog_FallbackFileImport tests that the bundled fallback JSON imports as a catalog
+func TestPricingCatalog_FallbackFileImport(t *testing.T) {
+	info, err := ImportPricingCatalog(t.TempDir(), filepath.Join("providers", "pricing_fallback.json"))
+	if err != nil {
+		t.Fatalf("ImportPricingCatalog failed: %v", err)
+	}
+	if info.Entries == 0 || info.EffectiveDate == "" {
+		t.Errorf("Expected entries and effective date, got %+v", info)
+	}
+}
+
+// TestLatestPricingCatalog tests newest-first selection and skipping future catalogs
+func TestLatestPricingCatalog(t *testing.T) {
+	src := t.TempDir()
+	dir := t.TempDir()
+	future := time.Now().AddDate(0, 1, 0).Format("2006-01-02")
+
+	for _, c := range []struct {
+		version, effective string
+		hourly             float64
+	}{
+		{"old", "2025-01-01", 0.1},
+		{"new", "2026-01-01", 0.2},
+		{"future", future, 0.3},
+	} {
+		if _, err := ImportPricingCatalog(dir, writeTestCatalog(t, src, c.version, c.effective, c.hourly)); err != nil {
+			t.Fatalf("ImportPricingCatalog failed: %v", err)
+		}
+	}
+
+	infos, err := ListPricingCatalogs(dir)
+	if err != nil {
+		t.Fatalf("ListPricingCatalogs failed: %v", err)
+	}
+	if len(infos) != 3 || infos[0].Version != "future" || infos[2].Version != "old" {
+		t.Fatalf("Expected 3 catalogs newest first, got %+v", infos)
+	}
+
+	latest, err := LatestPricingCatalog(dir)
+	if err != nil {
+		t.Fatalf("LatestPricingCatalog failed: %v", err)
+	}
+	if latest == nil || latest.Version != "new" {
+		t.Errorf("Expected catalog 'new', got %+v", latest)
+	}
+
+	empty, err := LatestPricingCatalog(filepath.Join(dir, "missing"))
+	if err != nil || empty != nil {
+		t.Errorf("Expected nil catalog without error for empty dir, got %v, %v", empty, err)
+	}
+}
+
+// TestDiffPricingCatalogs tests added, removed and changed entries
+func TestDiffPricingCatalogs(t *testing.T) {
+	oldCatalog := &PricingCatalog{}
+	oldCatalog.Set(&PricingData{Provider: "aws", Region: "us-east-1", ResourceType: "t3.large", Currency: "USD", HourlyPrice: 0.08})
+	oldCatalog.Set(&PricingData{Provider: "aws", Region: "us-east-1", ResourceType: "m5.large", Currency: "USD", HourlyPrice: 0.096})
+	oldCatalog.Set(&PricingData{Provider: "aws", Region: "us-east-1", ResourceType: "c5.large", Currency: "USD", HourlyPrice: 0.085})
+
+	newCatalog := &PricingCatalog{}
+	newCatalog.Set(&PricingData{Provider: "aws", Region: "us-east-1", ResourceType: "t3.large", Currency: "USD", HourlyPrice: 0.0832})
+	newCatalog.Set(&PricingData{Provider: "aws", Region: "us-east-1", ResourceType: "m5.large", Currency: "USD", HourlyPrice: 0.096})
+	newCatalog.Set(&PricingData{Provider: "aws", Region: "us-west-2", ResourceType: "m5.large", Currency: "USD", HourlyPrice: 0.096})
+
+	diff := DiffPricingCatalogs(oldCatalog, newCatalog)
+	changes := make(map[string]string)
+	for _, d := range diff {
+		changes[d.Region+"/"+d.ResourceType] = d.Change
+	}
+	expected := map[string]string{
+		"us-east-1/t3.large": "changed",
+		"us-east-1/c5.large": "removed",
+		"us-west-2/m5.large": "added",
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d differences, got %+v", len(expected), diff)
+	}
+	for key, change := range expected {
+		if changes[key] != change {
+			t.Errorf("Expected %s to be %s, got %q", key, change, changes[key])
+		}
+	}
+}
+
+// TestInitializePricingCatalogs tests that catalog prices are used before the existing fallback
+func TestInitializePricingCatalogs(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ImportPricingCatalog(dir, writeTestCatalog(t, t.TempDir(), "v1", "2025-01-01", 0.0832)); err != nil {
+		t.Fatalf("ImportPricingCatalog failed: %v", err)
+	}
+
+	ps := NewPricingService(":memory:")
+	defer ps.Close()
+	ps.SetFallbackProvider(func(provider, region, resourceType string) (*PricingData, error) {
+		return &PricingData{Provider: provider, Region: region, ResourceType: resourceType, Currency: "USD", HourlyPrice: 1}, nil
+	})
+
+	catalog, err := InitializePricingCatalogs(ps, dir)
+	if err != nil || catalog == nil {
+		t.Fatalf("InitializePricingCatalogs failed: %v", err)
+	}
+
+	pricing, err := ps.GetPricing("aws", "us-east-1", "t3.large")
+	if err != nil {
+		t.Fatalf("GetPricing failed: %v", err)
+	}
+	if pricing.HourlyPrice != 0.0832 || pricing.Metadata["pricing_catalog"] != "v1@2025-01-01" {
+		t.Errorf("Expected catalog price 0.0832 from v1, got %f (%v)", pricing.HourlyPrice, pricing.Metadata)
+	}
+
+	pricing, err = ps.GetPricing("aws", "us-east-1", "m5.large")
+	if err != nil {
+		t.Fatalf("GetPricing failed: %v", err)
+	}
+	if pricing.HourlyPrice != 1 {
+		t.Errorf("Expected previous fallback price 1 for uncatalogued type, got %f", pricing.HourlyPrice)
+	}
+}
+
+// TestInitializePricingCatalogs_ResourcePricing tests that catalog prices of network and
+// storage resources win over the bundled resource pricing table
+func TestInitializePricingCatalogs_ResourcePricing(t *testing.T) {
+	dir := t.TempDir()
+	catalog := &PricingCatalog{Version: "v1", EffectiveDate: "2025-01-01"}
+	catalog.Set(&PricingData{Provider: "alicloud", Region: "cn-hangzhou", ResourceType: "eip", Currency: "CNY", HourlyPrice: 0.5})
+	path := filepath.Join(t.TempDir(), "v1.json")
+	if err := WritePricingCatalog(catalog, path); err != nil {
+		t.Fatalf("WritePricingCatalog failed: %v", err)
+	}
+	if _, err := ImportPricingCatalog(dir, path); err != nil {
+		t.Fatalf("ImportPricingCatalog failed: %v", err)
+	}
+
+	ps := NewPricingService(":memory:")
+	defer ps.Close()
+	if _, err := InitializePricingCatalogs(ps, dir); err != nil {
+		t.Fatalf("InitializePricingCatalogs failed: %v", err)
+	}
+
+	pricing, err := ps.GetPricing("alicloud", "cn-hangzhou", "eip")
+	if err != nil {
+		t.Fatalf("GetPricing failed: %v", err)
+	}
+	if pricing.HourlyPrice != 0.5 || pricing.Metadata["pricing_catalog"] != "v1@2025-01-01" {
+		t.Errorf("Expected catalog eip price 0.5, got %f (%v)", pricing.HourlyPrice, pricing.Metadata)
+	}
+
+	// Not in the catalog: the bundled table is the last fallback
+	pricing, err = ps.GetPricing("alicloud", "cn-hangzhou", "nat_gateway")
+	if err != nil {
+		t.Fatalf("GetPricing failed: %v", err)
+	}
+	if pricing.HourlyPrice <= 0 || pricing.Metadata["pricing_catalog"] != "" {
+		t.Errorf("Expected bundled nat_gateway price, got %f (%v)", pricing.HourlyPrice, pricing.Metadata)
+	}
+}
+
+// TestExportPricingCatalog tests that cached prices override the base catalog on export
+func TestExportPricingCatalog(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ImportPricingCatalog(dir, writeTestCatalog(t, t.TempDir(), "v1", "2025-01-01", 0.08)); err != nil {
+		t.Fatalf("ImportPricingCatalog failed: %v", err)
+	}
+
+	ps := NewPricingService(filepath.Join(t.TempDir(), "cache.db"))
+	defer ps.Close()
+	if err := ps.cachePricing(&PricingData{Provider: "aws", Region: "us-east-1", ResourceType: "t3.large", Currency: "USD", HourlyPrice: 0.0832}); err != nil {
+		t.Fatalf("cachePricing failed: %v", err)
+	}
+	if err := ps.cachePricing(&PricingData{Provider: "alicloud", Region: "cn-hangzhou", ResourceType: "ecs.g6.large", Currency: "CNY", HourlyPrice: 0.558}); err != nil {
+		t.Fatalf("cachePricing failed: %v", err)
+	}
+
+	catalog, err := ExportPricingCatalog(ps, dir, "v2")
+	if err != nil {
+		t.Fatalf("ExportPricingCatalog failed: %v", err)
+	}
+	if catalog.Entries() != 2 {
+		t.Errorf("Expected 2 entries, got %d", catalog.Entries())
+	}
+	if data, _ := catalog.Lookup("aws", "us-east-1", "t3.large"); data == nil || data.HourlyPrice != 0.0832 {
+		t.Errorf("Expected cached price to override catalog, got %+v", data)
+	}
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := WritePricingCatalog(catalog, path); err != nil {
+		t.Fatalf("WritePricingCatalog failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected exported file: %v", err)
+	}
+	if _, err := LoadPricingCatalog(path); err != nil {
+		t.Errorf("Expected exported catalog to load, got %v", err)
+	}
+}
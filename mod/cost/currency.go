@@ -1,7 +1,11 @@
 package cost
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -23,60 +27,86 @@ type ExchangeRate struct {
 	To        Currency  `json:"to"`
 	Rate      float64   `json:"rate"`
 	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source,omitempty"` // default, static, ecb or manual
+}
+
+// rateOrigin records where a rate came from and when it was published
+type rateOrigin struct {
+	source    string
+	timestamp time.Time
 }
 
 // CurrencyConverter handles currency conversion with exchange rates
 type CurrencyConverter struct {
-	mu    sync.RWMutex
-	rates map[string]float64 // key: "FROM_TO", value: rate
+	mu      sync.RWMutex
+	rates   map[string]float64    // key: "FROM_TO", value: rate
+	origins map[string]rateOrigin // key: "FROM_TO"
 }
 
 // NewCurrencyConverter creates a new currency converter with default exchange rates
 func NewCurrencyConverter() *CurrencyConverter {
 	converter := &CurrencyConverter{
-		rates: make(map[string]float64),
+		rates:   make(map[string]float64),
+		origins: make(map[string]rateOrigin),
 	}
-	
+
 	// Initialize with default exchange rates (approximate values)
-	// In production, these should be fetched from a real exchange rate API
+	// Load a RateTable from a RateSource to use published rates
 	converter.setDefaultRates()
-	
+	for key := range converter.rates {
+		converter.origins[key] = rateOrigin{source: RateSourceDefault}
+	}
+
 	return converter
 }
 
+// NewCurrencyConverterFromSource creates a converter with the default rates overlaid by
+// the rates of source. Currencies the source does not list keep their default rates.
+func NewCurrencyConverterFromSource(ctx context.Context, source RateSource) (*CurrencyConverter, error) {
+	converter := NewCurrencyConverter()
+	table, err := source.FetchRates(ctx)
+	if err != nil {
+		return converter, err
+	}
+	if err := converter.LoadRateTable(table); err != nil {
+		return converter, err
+	}
+	return converter, nil
+}
+
 // setDefaultRates sets default exchange rates
 func (c *CurrencyConverter) setDefaultRates() {
 	// Base rates (as of typical values, should be updated from API in production)
 	// USD as base currency
-	c.rates["USD_CNY"] = 7.2  // 1 USD = 7.2 CNY
-	c.rates["USD_EUR"] = 0.92 // 1 USD = 0.92 EUR
-	c.rates["USD_GBP"] = 0.79 // 1 USD = 0.79 GBP
+	c.rates["USD_CNY"] = 7.2   // 1 USD = 7.2 CNY
+	c.rates["USD_EUR"] = 0.92  // 1 USD = 0.92 EUR
+	c.rates["USD_GBP"] = 0.79  // 1 USD = 0.79 GBP
 	c.rates["USD_JPY"] = 149.0 // 1 USD = 149 JPY
-	
+
 	// CNY rates
 	c.rates["CNY_USD"] = 1.0 / 7.2
 	c.rates["CNY_EUR"] = 0.92 / 7.2
 	c.rates["CNY_GBP"] = 0.79 / 7.2
 	c.rates["CNY_JPY"] = 149.0 / 7.2
-	
+
 	// EUR rates
 	c.rates["EUR_USD"] = 1.0 / 0.92
 	c.rates["EUR_CNY"] = 7.2 / 0.92
 	c.rates["EUR_GBP"] = 0.79 / 0.92
 	c.rates["EUR_JPY"] = 149.0 / 0.92
-	
+
 	// GBP rates
 	c.rates["GBP_USD"] = 1.0 / 0.79
 	c.rates["GBP_CNY"] = 7.2 / 0.79
 	c.rates["GBP_EUR"] = 0.92 / 0.79
 	c.rates["GBP_JPY"] = 149.0 / 0.79
-	
+
 	// JPY rates
 	c.rates["JPY_USD"] = 1.0 / 149.0
 	c.rates["JPY_CNY"] = 7.2 / 149.0
 	c.rates["JPY_EUR"] = 0.92 / 149.0
 	c.rates["JPY_GBP"] = 0.79 / 149.0
-	
+
 	// Same currency rates (identity)
 	c.rates["USD_USD"] = 1.0
 	c.rates["CNY_CNY"] = 1.0
@@ -94,18 +124,18 @@ func (c *CurrencyConverter) Convert(amount float64, from, to Currency) (float64,
 	if !c.isValidCurrency(to) {
 		return 0, fmt.Errorf("不支持的目标货币: %s", to)
 	}
-	
+
 	// Same currency, no conversion needed
 	if from == to {
 		return amount, nil
 	}
-	
+
 	// Get exchange rate
 	rate, err := c.GetRate(from, to)
 	if err != nil {
 		return 0, err
 	}
-	
+
 	// Convert
 	return amount * rate, nil
 }
@@ -114,38 +144,97 @@ func (c *CurrencyConverter) Convert(amount float64, from, to Currency) (float64,
 func (c *CurrencyConverter) GetRate(from, to Currency) (float64, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	
+
 	key := fmt.Sprintf("%s_%s", from, to)
 	rate, exists := c.rates[key]
 	if !exists {
 		return 0, fmt.Errorf("汇率不可用: %s -> %s", from, to)
 	}
-	
+
 	return rate, nil
 }
 
+// Rate returns the exchange rate from one currency to another with its source and timestamp
+func (c *CurrencyConverter) Rate(from, to Currency) (ExchangeRate, error) {
+	if from == to {
+		return ExchangeRate{From: from, To: to, Rate: 1}, nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	key := fmt.Sprintf("%s_%s", from, to)
+	rate, exists := c.rates[key]
+	if !exists {
+		return ExchangeRate{}, fmt.Errorf("汇率不可用: %s -> %s", from, to)
+	}
+	origin := c.origins[key]
+	return ExchangeRate{From: from, To: to, Rate: rate, Timestamp: origin.timestamp, Source: origin.source}, nil
+}
+
+// LoadRateTable replaces the rates between every pair of currencies in table
+// (including its base) with the cross rates derived from the table
+func (c *CurrencyConverter) LoadRateTable(table *RateTable) error {
+	if table == nil {
+		return fmt.Errorf("汇率表不能为空")
+	}
+	if err := table.Validate(); err != nil {
+		return err
+	}
+	perBase := map[Currency]float64{table.Base: 1}
+	for currency, rate := range table.Rates {
+		if currency != table.Base {
+			perBase[currency] = rate
+		}
+	}
+	source := table.Source
+	if source == "" {
+		source = RateSourceStatic
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for from, fromRate := range perBase {
+		for to, toRate := range perBase {
+			key := fmt.Sprintf("%s_%s", from, to)
+			c.rates[key] = toRate / fromRate
+			c.origins[key] = rateOrigin{source: source, timestamp: table.Timestamp}
+		}
+	}
+	return nil
+}
+
 // SetRate sets a custom exchange rate
 func (c *CurrencyConverter) SetRate(from, to Currency, rate float64) error {
-	if !c.isValidCurrency(from) {
+	if !c.isValidCurrency(from) && !IsCurrencyCode(string(from)) {
 		return fmt.Errorf("不支持的源货币: %s", from)
 	}
-	if !c.isValidCurrency(to) {
+	if !c.isValidCurrency(to) && !IsCurrencyCode(string(to)) {
 		return fmt.Errorf("不支持的目标货币: %s", to)
 	}
 	if rate <= 0 {
 		return fmt.Errorf("汇率必须大于 0")
 	}
-	
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	
+
+	origin := rateOrigin{source: RateSourceManual, timestamp: time.Now()}
 	key := fmt.Sprintf("%s_%s", from, to)
 	c.rates[key] = rate
-	
+	c.origins[key] = origin
+
 	// Also set the inverse rate
 	inverseKey := fmt.Sprintf("%s_%s", to, from)
 	c.rates[inverseKey] = 1.0 / rate
-	
+	c.origins[inverseKey] = origin
+
+	// Identity rates make currencies outside the defaults convertible
+	for _, currency := range []Currency{from, to} {
+		identity := fmt.Sprintf("%s_%s", currency, currency)
+		c.rates[identity] = 1.0
+		c.origins[identity] = origin
+	}
+
 	return nil
 }
 
@@ -153,102 +242,239 @@ func (c *CurrencyConverter) SetRate(from, to Currency, rate float64) error {
 func (c *CurrencyConverter) UpdateRates(rates map[string]float64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	
+
+	origin := rateOrigin{source: RateSourceManual, timestamp: time.Now()}
 	for key, rate := range rates {
 		c.rates[key] = rate
+		c.origins[key] = origin
 	}
 }
 
-// isValidCurrency checks if a currency is supported
+// isValidCurrency checks if a currency is supported: the built-in currencies and
+// any currency with rates loaded from a rate table or set by hand
 func (c *CurrencyConverter) isValidCurrency(currency Currency) bool {
 	switch currency {
 	case CurrencyCNY, CurrencyUSD, CurrencyEUR, CurrencyGBP, CurrencyJPY:
 		return true
-	default:
-		return false
 	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.rates[fmt.Sprintf("%s_%s", currency, currency)]
+	return ok
 }
 
-// GetSupportedCurrencies returns a list of supported currencies
+// GetSupportedCurrencies returns a list of supported currencies: the built-in ones
+// followed by the currencies loaded from rate tables, sorted by code
 func (c *CurrencyConverter) GetSupportedCurrencies() []Currency {
-	return []Currency{
+	currencies := []Currency{
 		CurrencyCNY,
 		CurrencyUSD,
 		CurrencyEUR,
 		CurrencyGBP,
 		CurrencyJPY,
 	}
+	builtin := make(map[Currency]bool, len(currencies))
+	for _, currency := range currencies {
+		builtin[currency] = true
+	}
+
+	c.mu.RLock()
+	var extra []Currency
+	for key := range c.rates {
+		from, to, _ := strings.Cut(key, "_")
+		if from == to && !builtin[Currency(from)] {
+			extra = append(extra, Currency(from))
+		}
+	}
+	c.mu.RUnlock()
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	return append(currencies, extra...)
 }
 
-// ConvertCostEstimate converts a cost estimate to a different currency
+// ConvertCostEstimate converts a cost estimate to a different currency. Every breakdown is
+// converted from its own currency, so estimates mixing providers billed in different
+// currencies are normalised; the rate used is recorded on each converted breakdown.
 func (c *CurrencyConverter) ConvertCostEstimate(estimate *CostEstimate, targetCurrency Currency) (*CostEstimate, error) {
 	if estimate == nil {
 		return nil, fmt.Errorf("成本估算不能为空")
 	}
-	
+	if !c.isValidCurrency(targetCurrency) {
+		return nil, fmt.Errorf("不支持的目标货币: %s", targetCurrency)
+	}
+
 	// Parse source currency
 	sourceCurrency := Currency(estimate.Currency)
 	if !c.isValidCurrency(sourceCurrency) {
 		return nil, fmt.Errorf("不支持的源货币: %s", estimate.Currency)
 	}
-	
-	// If same currency, return a copy
-	if sourceCurrency == targetCurrency {
-		return estimate, nil
-	}
-	
-	// Convert total costs
-	convertedHourly, err := c.Convert(estimate.TotalHourlyCost, sourceCurrency, targetCurrency)
-	if err != nil {
-		return nil, fmt.Errorf("转换小时成本失败: %w", err)
+
+	// If every figure is already in the target currency, return the estimate as is
+	foreign := false
+	for _, breakdown := range estimate.Breakdown {
+		if breakdown.Available && breakdown.Currency != "" && Currency(breakdown.Currency) != targetCurrency {
+			foreign = true
+			break
+		}
 	}
-	
-	convertedMonthly, err := c.Convert(estimate.TotalMonthlyCost, sourceCurrency, targetCurrency)
-	if err != nil {
-		return nil, fmt.Errorf("转换月度成本失败: %w", err)
+	if sourceCurrency == targetCurrency && !foreign {
+		return estimate, nil
 	}
-	
-	// Create new estimate with converted values
+
+	// Create new estimate and add the converted breakdown items back up
 	converted := &CostEstimate{
-		TotalHourlyCost:  convertedHourly,
-		TotalMonthlyCost: convertedMonthly,
-		Currency:         string(targetCurrency),
-		Breakdown:        make([]ResourceCostBreakdown, len(estimate.Breakdown)),
+		Currency:          string(targetCurrency),
+		Breakdown:         make([]ResourceCostBreakdown, 0, len(estimate.Breakdown)),
 		ProviderBreakdown: make(map[string]*ProviderCostSummary),
-		UnavailableCount: estimate.UnavailableCount,
-		Timestamp:        estimate.Timestamp,
-		Disclaimer:       estimate.Disclaimer,
-		Warnings:         estimate.Warnings,
-	}
-	
-	// Convert breakdown items
-	for i, breakdown := range estimate.Breakdown {
-		convertedBreakdown := breakdown
-		
-		if breakdown.Available {
-			convertedBreakdown.UnitHourly, _ = c.Convert(breakdown.UnitHourly, sourceCurrency, targetCurrency)
-			convertedBreakdown.UnitMonthly, _ = c.Convert(breakdown.UnitMonthly, sourceCurrency, targetCurrency)
-			convertedBreakdown.TotalHourly, _ = c.Convert(breakdown.TotalHourly, sourceCurrency, targetCurrency)
-			convertedBreakdown.TotalMonthly, _ = c.Convert(breakdown.TotalMonthly, sourceCurrency, targetCurrency)
-			convertedBreakdown.Currency = string(targetCurrency)
+		UnavailableCount:  estimate.UnavailableCount,
+		Timestamp:         estimate.Timestamp,
+		Disclaimer:        estimate.Disclaimer,
+		Warnings:          estimate.Warnings,
+	}
+
+	used := make(map[Currency]bool)
+	for _, breakdown := range estimate.Breakdown {
+		if !breakdown.Available {
+			converted.Breakdown = append(converted.Breakdown, breakdown)
+			continue
 		}
-		
-		converted.Breakdown[i] = convertedBreakdown
-	}
-	
-	// Convert provider breakdown
-	for provider, summary := range estimate.ProviderBreakdown {
-		convertedSummary := &ProviderCostSummary{
-			Provider:        summary.Provider,
-			Currency:        string(targetCurrency),
-			ResourceCount:   summary.ResourceCount,
+		from := Currency(breakdown.Currency)
+		if from == "" {
+			from = sourceCurrency
 		}
-		
-		convertedSummary.TotalHourlyCost, _ = c.Convert(summary.TotalHourlyCost, sourceCurrency, targetCurrency)
-		convertedSummary.TotalMonthlyCost, _ = c.Convert(summary.TotalMonthlyCost, sourceCurrency, targetCurrency)
-		
-		converted.ProviderBreakdown[provider] = convertedSummary
+		rate, err := c.Rate(from, targetCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("转换 %s 成本失败: %w", breakdown.ResourceName, err)
+		}
+
+		convertedBreakdown := breakdown
+		convertedBreakdown.UnitHourly *= rate.Rate
+		convertedBreakdown.UnitMonthly *= rate.Rate
+		convertedBreakdown.TotalHourly *= rate.Rate
+		convertedBreakdown.TotalMonthly *= rate.Rate
+		convertedBreakdown.OnDemandHourly *= rate.Rate
+		convertedBreakdown.SpotHourly *= rate.Rate
+		convertedBreakdown.SpotMinHourly *= rate.Rate
+		convertedBreakdown.SpotMaxHourly *= rate.Rate
+		convertedBreakdown.Currency = string(targetCurrency)
+		if from != targetCurrency {
+			convertedBreakdown.ExchangeRate = &rate
+			if !used[from] {
+				used[from] = true
+				converted.ExchangeRates = append(converted.ExchangeRates, rate)
+			}
+		}
+
+		converted.Breakdown = append(converted.Breakdown, convertedBreakdown)
+		converted.addBreakdown(convertedBreakdown)
 	}
-	
+
 	return converted, nil
 }
+
+// ConvertCostDiff converts a plan cost diff to a different currency. Each change and each
+// currency of unchanged resources (Unchanged) is converted with its own rate; a part of the
+// totals not covered by either is converted from the diff currency.
+func (c *CurrencyConverter) ConvertCostDiff(diff *CostDiff, targetCurrency Currency) (*CostDiff, error) {
+	if diff == nil {
+		return nil, fmt.Errorf("费用变化不能为空")
+	}
+	base, err := c.Rate(Currency(diff.Currency), targetCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := *diff
+	converted.Currency = string(targetCurrency)
+	converted.Changes = make([]ResourceCostChange, 0, len(diff.Changes))
+	converted.ExchangeRates = nil
+	converted.Unchanged = nil
+
+	// The part of the totals not covered by the listed changes and unchanged totals
+	restBeforeHourly, restAfterHourly := diff.BeforeHourlyCost, diff.AfterHourlyCost
+	restBeforeMonthly, restAfterMonthly := diff.BeforeMonthlyCost, diff.AfterMonthlyCost
+	converted.BeforeHourlyCost, converted.AfterHourlyCost = 0, 0
+	converted.BeforeMonthlyCost, converted.AfterMonthlyCost = 0, 0
+
+	used := make(map[Currency]bool)
+	record := func(rate ExchangeRate) {
+		if rate.From != rate.To && !used[rate.From] {
+			used[rate.From] = true
+			converted.ExchangeRates = append(converted.ExchangeRates, rate)
+		}
+	}
+	rateFor := func(currency string) (ExchangeRate, error) {
+		if currency == "" {
+			return base, nil
+		}
+		return c.Rate(Currency(currency), targetCurrency)
+	}
+
+	for _, change := range diff.Changes {
+		restBeforeHourly -= change.BeforeHourly
+		restAfterHourly -= change.AfterHourly
+		restBeforeMonthly -= change.BeforeMonthly
+		restAfterMonthly -= change.AfterMonthly
+
+		rate, err := rateFor(change.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("转换 %s 成本失败: %w", change.Address, err)
+		}
+		change.BeforeHourly *= rate.Rate
+		change.AfterHourly *= rate.Rate
+		change.DeltaHourly *= rate.Rate
+		change.BeforeMonthly *= rate.Rate
+		change.AfterMonthly *= rate.Rate
+		change.DeltaMonthly *= rate.Rate
+		if change.Currency != "" {
+			change.Currency = string(targetCurrency)
+		}
+		if rate.From != rate.To {
+			r := rate
+			change.ExchangeRate = &r
+			record(rate)
+		}
+		converted.Changes = append(converted.Changes, change)
+
+		converted.BeforeHourlyCost += change.BeforeHourly
+		converted.AfterHourlyCost += change.AfterHourly
+		converted.BeforeMonthlyCost += change.BeforeMonthly
+		converted.AfterMonthlyCost += change.AfterMonthly
+	}
+
+	unchanged := CostTotals{Currency: string(targetCurrency)}
+	for _, totals := range diff.Unchanged {
+		restBeforeHourly -= totals.BeforeHourly
+		restAfterHourly -= totals.AfterHourly
+		restBeforeMonthly -= totals.BeforeMonthly
+		restAfterMonthly -= totals.AfterMonthly
+
+		rate, err := rateFor(totals.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("转换未变化资源的 %s 成本失败: %w", totals.Currency, err)
+		}
+		record(rate)
+		unchanged.BeforeHourly += totals.BeforeHourly * rate.Rate
+		unchanged.AfterHourly += totals.AfterHourly * rate.Rate
+		unchanged.BeforeMonthly += totals.BeforeMonthly * rate.Rate
+		unchanged.AfterMonthly += totals.AfterMonthly * rate.Rate
+	}
+	if len(diff.Unchanged) > 0 {
+		converted.Unchanged = []CostTotals{unchanged}
+		converted.BeforeHourlyCost += unchanged.BeforeHourly
+		converted.AfterHourlyCost += unchanged.AfterHourly
+		converted.BeforeMonthlyCost += unchanged.BeforeMonthly
+		converted.AfterMonthlyCost += unchanged.AfterMonthly
+	}
+
+	// Rounding leaves tiny remainders; only a real uncovered part uses the diff currency rate
+	if math.Abs(restBeforeHourly) > 1e-9 || math.Abs(restAfterHourly) > 1e-9 || math.Abs(restBeforeMonthly) > 1e-9 || math.Abs(restAfterMonthly) > 1e-9 {
+		record(base)
+		converted.BeforeHourlyCost += restBeforeHourly * base.Rate
+		converted.AfterHourlyCost += restAfterHourly * base.Rate
+		converted.BeforeMonthlyCost += restBeforeMonthly * base.Rate
+		converted.AfterMonthlyCost += restAfterMonthly * base.Rate
+	}
+	converted.DeltaHourlyCost = converted.AfterHourlyCost - converted.BeforeHourlyCost
+	converted.DeltaMonthlyCost = converted.AfterMonthlyCost - converted.BeforeMonthlyCost
+	return &converted, nil
+}
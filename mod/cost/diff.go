@@ -0,0 +1,154 @@
+package cost
+
+import (
+	"fmt"
+	"time"
+)
+
+// PlannedResource is one resource address of a plan with its specification before and after
+// the change. Before is nil for resources being created, After is nil for resources being deleted.
+type PlannedResource struct {
+	Address string        `json:"address"`
+	Action  string        `json:"action"` // create, update, replace, delete or no-op
+	Before  *ResourceSpec `json:"before,omitempty"`
+	After   *ResourceSpec `json:"after,omitempty"`
+}
+
+// ResourceCostChange is the before / after cost of a single resource address
+type ResourceCostChange struct {
+	Address       string  `json:"address"`
+	ResourceType  string  `json:"resource_type"`
+	Provider      string  `json:"provider"`
+	Action        string  `json:"action"`
+	BeforeHourly  float64 `json:"before_hourly"`
+	AfterHourly   float64 `json:"after_hourly"`
+	DeltaHourly   float64 `json:"delta_hourly"`
+	BeforeMonthly float64 `json:"before_monthly"`
+	AfterMonthly  float64 `json:"after_monthly"`
+	DeltaMonthly  float64 `json:"delta_monthly"`
+	Currency      string  `json:"currency"`
+	Available     bool    `json:"available"` // false if pricing is unavailable on either side
+
+	// ExchangeRate is the rate used to convert the costs into Currency, nil when not converted
+	ExchangeRate *ExchangeRate `json:"exchange_rate,omitempty"`
+}
+
+// CostTotals is the before/after cost of a group of resources priced in one currency
+type CostTotals struct {
+	Currency      string  `json:"currency"`
+	BeforeHourly  float64 `json:"before_hourly"`
+	AfterHourly   float64 `json:"after_hourly"`
+	BeforeMonthly float64 `json:"before_monthly"`
+	AfterMonthly  float64 `json:"after_monthly"`
+}
+
+// CostDiff is the cost impact of a plan: totals before and after the change and per-resource deltas.
+// Changes only lists resources the plan touches; unchanged resources still count in the totals
+// and are summed per currency in Unchanged.
+type CostDiff struct {
+	BeforeHourlyCost  float64              `json:"before_hourly_cost"`
+	AfterHourlyCost   float64              `json:"after_hourly_cost"`
+	DeltaHourlyCost   float64              `json:"delta_hourly_cost"`
+	BeforeMonthlyCost float64              `json:"before_monthly_cost"`
+	AfterMonthlyCost  float64              `json:"after_monthly_cost"`
+	DeltaMonthlyCost  float64              `json:"delta_monthly_cost"`
+	Currency          string               `json:"currency"`
+	Changes           []ResourceCostChange `json:"changes"`
+	UnavailableCount  int                  `json:"unavailable_count"`
+	Timestamp         time.Time            `json:"timestamp"`
+	Disclaimer        string               `json:"disclaimer"`
+	Warnings          []string             `json:"warnings,omitempty"`
+	ExchangeRates     []ExchangeRate       `json:"exchange_rates,omitempty"` // rates used when converted to Currency
+	Unchanged         []CostTotals         `json:"unchanged,omitempty"`      // costs of resources the plan leaves unchanged, per currency
+}
+
+// CalculateCostDiff prices every planned resource before and after the change
+func (cc *CostCalculator) CalculateCostDiff(resources []PlannedResource, pricingService *PricingService) (*CostDiff, error) {
+	diff := &CostDiff{
+		Changes:    []ResourceCostChange{},
+		Warnings:   []string{},
+		Timestamp:  time.Now(),
+		Disclaimer: "This is an estimate only. Actual costs may vary based on usage, region, and pricing changes.",
+	}
+
+	for _, planned := range resources {
+		change := ResourceCostChange{
+			Address:   planned.Address,
+			Action:    planned.Action,
+			Available: true,
+		}
+
+		price := func(spec *ResourceSpec) (hourly, monthly float64) {
+			if spec == nil {
+				return 0, 0
+			}
+			change.ResourceType = spec.Type
+			change.Provider = spec.Provider
+			breakdown := cc.calculateResourceCost(*spec, pricingService)
+			if !breakdown.Available {
+				change.Available = false
+				return 0, 0
+			}
+			if change.Currency == "" {
+				change.Currency = breakdown.Currency
+			}
+			return breakdown.TotalHourly, breakdown.TotalMonthly
+		}
+		change.BeforeHourly, change.BeforeMonthly = price(planned.Before)
+		change.AfterHourly, change.AfterMonthly = price(planned.After)
+		change.DeltaHourly = change.AfterHourly - change.BeforeHourly
+		change.DeltaMonthly = change.AfterMonthly - change.BeforeMonthly
+
+		if !change.Available {
+			diff.UnavailableCount++
+			if planned.Action != "no-op" {
+				diff.Warnings = append(diff.Warnings,
+					fmt.Sprintf("Pricing unavailable for %s (%s)", change.Address, change.ResourceType))
+			}
+		}
+
+		diff.BeforeHourlyCost += change.BeforeHourly
+		diff.AfterHourlyCost += change.AfterHourly
+		diff.BeforeMonthlyCost += change.BeforeMonthly
+		diff.AfterMonthlyCost += change.AfterMonthly
+		if diff.Currency == "" {
+			diff.Currency = change.Currency
+		}
+
+		if planned.Action != "no-op" {
+			diff.Changes = append(diff.Changes, change)
+		} else if change.Available {
+			diff.addUnchanged(change)
+		}
+	}
+
+	diff.DeltaHourlyCost = diff.AfterHourlyCost - diff.BeforeHourlyCost
+	diff.DeltaMonthlyCost = diff.AfterMonthlyCost - diff.BeforeMonthlyCost
+
+	// Set default currency if none found
+	if diff.Currency == "" {
+		diff.Currency = "USD"
+	}
+
+	return diff, nil
+}
+
+// addUnchanged adds an unchanged resource to the totals of its currency
+func (d *CostDiff) addUnchanged(change ResourceCostChange) {
+	for i := range d.Unchanged {
+		if d.Unchanged[i].Currency == change.Currency {
+			d.Unchanged[i].add(change)
+			return
+		}
+	}
+	totals := CostTotals{Currency: change.Currency}
+	totals.add(change)
+	d.Unchanged = append(d.Unchanged, totals)
+}
+
+func (t *CostTotals) add(change ResourceCostChange) {
+	t.BeforeHourly += change.BeforeHourly
+	t.AfterHourly += change.AfterHourly
+	t.BeforeMonthly += change.BeforeMonthly
+	t.AfterMonthly += change.AfterMonthly
+}
@@ -0,0 +1,232 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Exchange rate source names recorded on every ExchangeRate
+const (
+	RateSourceDefault = "default" // built-in approximate rates
+	RateSourceStatic  = "static"  // JSON rate table on disk
+	RateSourceECB     = "ecb"     // ECB-style daily XML feed
+	RateSourceManual  = "manual"  // rates set by hand (SetRate / overrides)
+)
+
+// DefaultECBRatesURL is the European Central Bank daily reference rate feed
+const DefaultECBRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// RateTable lists exchange rates against a base currency: 1 Base = Rates[X] X
+type RateTable struct {
+	Base      Currency             `json:"base"`
+	Rates     map[Currency]float64 `json:"rates"`
+	Source    string               `json:"source,omitempty"`
+	Timestamp time.Time            `json:"timestamp"` // when the rates were published
+}
+
+// RateSource provides exchange rate tables
+type RateSource interface {
+	Name() string
+	FetchRates(ctx context.Context) (*RateTable, error)
+}
+
+// StaticRateSource reads a rate table from a JSON file:
+//
+//	{"base": "USD", "timestamp": "2026-10-01T00:00:00Z", "rates": {"CNY": 7.1, "EUR": 0.91}}
+//
+// The file modification time is used when the table has no timestamp.
+type StaticRateSource struct {
+	Path string
+}
+
+func (s *StaticRateSource) Name() string { return RateSourceStatic }
+
+func (s *StaticRateSource) FetchRates(ctx context.Context) (*RateTable, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取汇率文件失败: %w", err)
+	}
+	var table RateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("解析汇率文件失败: %w", err)
+	}
+	if table.Timestamp.IsZero() {
+		if info, err := os.Stat(s.Path); err == nil {
+			table.Timestamp = info.ModTime()
+		}
+	}
+	table.Source = RateSourceStatic
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+// ECBRateSource fetches an ECB-style XML feed (EUR based daily reference rates).
+// URL defaults to DefaultECBRatesURL; any feed with the same Cube layout works.
+type ECBRateSource struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *ECBRateSource) Name() string { return RateSourceECB }
+
+func (s *ECBRateSource) FetchRates(ctx context.Context) (*RateTable, error) {
+	url := s.URL
+	if url == "" {
+		url = DefaultECBRatesURL
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("获取汇率失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取汇率失败: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return ParseECBRates(data)
+}
+
+// ecbEnvelope mirrors <Envelope><Cube><Cube time=".."><Cube currency=".." rate=".."/></Cube></Cube></Envelope>
+type ecbEnvelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string  `xml:"currency,attr"`
+				Rate     float64 `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// ParseECBRates parses an ECB-style XML feed; when it holds several days the most recent one is used
+func ParseECBRates(data []byte) (*RateTable, error) {
+	var env ecbEnvelope
+	if err := xml.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("解析 ECB 汇率失败: %w", err)
+	}
+
+	var latest time.Time
+	var table *RateTable
+	for _, day := range env.Cube.Days {
+		t, err := time.Parse("2006-01-02", day.Time)
+		if err != nil || (table != nil && !t.After(latest)) {
+			continue
+		}
+		latest = t
+		table = &RateTable{Base: CurrencyEUR, Rates: make(map[Currency]float64), Source: RateSourceECB, Timestamp: t}
+		for _, r := range day.Rates {
+			table.Rates[Currency(strings.ToUpper(r.Currency))] = r.Rate
+		}
+	}
+	if table == nil {
+		return nil, fmt.Errorf("解析 ECB 汇率失败: 没有汇率数据")
+	}
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// Validate checks the table has a base currency and positive rates
+func (t *RateTable) Validate() error {
+	if !IsCurrencyCode(string(t.Base)) {
+		return fmt.Errorf("汇率表缺少有效的基准货币: %q", t.Base)
+	}
+	if len(t.Rates) == 0 {
+		return fmt.Errorf("汇率表为空")
+	}
+	for currency, rate := range t.Rates {
+		if !IsCurrencyCode(string(currency)) {
+			return fmt.Errorf("无效的货币代码: %q", currency)
+		}
+		if rate <= 0 {
+			return fmt.Errorf("%s 汇率必须大于 0", currency)
+		}
+	}
+	return nil
+}
+
+// CachedRateSource caches the tables of another source on disk for TTL.
+// When refreshing fails the stale cache is returned so reports keep working offline.
+type CachedRateSource struct {
+	Source    RateSource
+	CachePath string
+	TTL       time.Duration
+}
+
+// rateCache is the on-disk cache layout
+type rateCache struct {
+	FetchedAt time.Time  `json:"fetched_at"`
+	Table     *RateTable `json:"table"`
+}
+
+func (s *CachedRateSource) Name() string { return s.Source.Name() }
+
+func (s *CachedRateSource) FetchRates(ctx context.Context) (*RateTable, error) {
+	cached := s.readCache()
+	if cached != nil && time.Since(cached.FetchedAt) < s.TTL {
+		return cached.Table, nil
+	}
+
+	table, err := s.Source.FetchRates(ctx)
+	if err != nil {
+		if cached != nil {
+			return cached.Table, nil
+		}
+		return nil, err
+	}
+
+	if data, err := json.MarshalIndent(rateCache{FetchedAt: time.Now(), Table: table}, "", "  "); err == nil {
+		if err := os.MkdirAll(filepath.Dir(s.CachePath), 0755); err == nil {
+			_ = os.WriteFile(s.CachePath, data, 0644)
+		}
+	}
+	return table, nil
+}
+
+func (s *CachedRateSource) readCache() *rateCache {
+	data, err := os.ReadFile(s.CachePath)
+	if err != nil {
+		return nil
+	}
+	var cached rateCache
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Table == nil || cached.Table.Validate() != nil {
+		return nil
+	}
+	return &cached
+}
+
+// IsCurrencyCode reports whether s looks like an ISO 4217 code (three upper-case letters)
+func IsCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
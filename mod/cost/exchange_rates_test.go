@@ -0,0 +1,231 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseECBRates(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "ecb_rates.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := ParseECBRates(data)
+	if err != nil {
+		t.Fatalf("ParseECBRates: %v", err)
+	}
+	if table.Base != CurrencyEUR || table.Source != RateSourceECB {
+		t.Errorf("base/source = %s/%s, want EUR/ecb", table.Base, table.Source)
+	}
+	// The most recent day wins
+	if got := table.Timestamp.Format("2006-01-02"); got != "2026-10-16" {
+		t.Errorf("timestamp = %s, want 2026-10-16", got)
+	}
+	if table.Rates[CurrencyUSD] != 1.08 || table.Rates["HKD"] != 8.424 {
+		t.Errorf("rates = %v", table.Rates)
+	}
+
+	if _, err := ParseECBRates([]byte("<Envelope></Envelope>")); err == nil {
+		t.Error("expected error for a feed without rates")
+	}
+}
+
+func TestCurrencyConverter_LoadRateTable(t *testing.T) {
+	data, _ := os.ReadFile(filepath.Join("testdata", "ecb_rates.xml"))
+	table, err := ParseECBRates(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	converter := NewCurrencyConverter()
+	if err := converter.LoadRateTable(table); err != nil {
+		t.Fatalf("LoadRateTable: %v", err)
+	}
+
+	// Cross rate USD -> CNY through EUR
+	rate, err := converter.Rate(CurrencyUSD, CurrencyCNY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(rate.Rate-7.2) > 1e-9 || rate.Source != RateSourceECB || rate.Timestamp.IsZero() {
+		t.Errorf("USD->CNY = %+v, want 7.2 from ecb", rate)
+	}
+
+	// Currencies only known from the feed become convertible
+	hkd, err := converter.Convert(8.424, "HKD", CurrencyEUR)
+	if err != nil || math.Abs(hkd-1) > 1e-9 {
+		t.Errorf("HKD->EUR = %v, %v; want 1", hkd, err)
+	}
+	if _, err := converter.Convert(1, "INVALID", CurrencyUSD); err == nil {
+		t.Error("expected error for unknown currency")
+	}
+
+	// Manual rates override the feed
+	if err := converter.SetRate(CurrencyUSD, CurrencyCNY, 7.1); err != nil {
+		t.Fatal(err)
+	}
+	if rate, _ := converter.Rate(CurrencyUSD, CurrencyCNY); rate.Rate != 7.1 || rate.Source != RateSourceManual {
+		t.Errorf("manual USD->CNY = %+v", rate)
+	}
+}
+
+func TestStaticRateSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(path, []byte(`{"base":"USD","timestamp":"2026-10-01T00:00:00Z","rates":{"CNY":7.0,"EUR":0.9}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	converter, err := NewCurrencyConverterFromSource(context.Background(), &StaticRateSource{Path: path})
+	if err != nil {
+		t.Fatalf("NewCurrencyConverterFromSource: %v", err)
+	}
+	rate, _ := converter.Rate(CurrencyEUR, CurrencyCNY)
+	if math.Abs(rate.Rate-7.0/0.9) > 1e-9 || rate.Source != RateSourceStatic {
+		t.Errorf("EUR->CNY = %+v", rate)
+	}
+	// Currencies missing from the table keep their defaults
+	if rate, _ := converter.Rate(CurrencyUSD, CurrencyJPY); rate.Source != RateSourceDefault {
+		t.Errorf("USD->JPY source = %s, want default", rate.Source)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"base":"USD","rates":{"CNY":-1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&StaticRateSource{Path: path}).FetchRates(context.Background()); err == nil {
+		t.Error("expected error for negative rate")
+	}
+}
+
+type stubRateSource struct {
+	calls int
+	err   error
+}
+
+func (s *stubRateSource) Name() string { return "stub" }
+
+func (s *stubRateSource) FetchRates(ctx context.Context) (*RateTable, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &RateTable{Base: CurrencyUSD, Rates: map[Currency]float64{CurrencyCNY: 7.0}, Source: "stub", Timestamp: time.Now()}, nil
+}
+
+func TestCachedRateSource(t *testing.T) {
+	stub := &stubRateSource{}
+	src := &CachedRateSource{Source: stub, CachePath: filepath.Join(t.TempDir(), "rates", "stub.json"), TTL: time.Hour}
+
+	if _, err := src.FetchRates(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.FetchRates(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stub.calls != 1 {
+		t.Errorf("calls = %d, want 1 (second fetch served from cache)", stub.calls)
+	}
+
+	// Expired cache is refreshed; when refreshing fails the stale table is used
+	src.TTL = 0
+	stub.err = errors.New("offline")
+	table, err := src.FetchRates(context.Background())
+	if err != nil || table.Rates[CurrencyCNY] != 7.0 {
+		t.Errorf("stale fallback = %v, %v", table, err)
+	}
+	if stub.calls != 2 {
+		t.Errorf("calls = %d, want 2", stub.calls)
+	}
+}
+
+func TestConvertCostEstimate_MixedCurrencies(t *testing.T) {
+	converter := NewCurrencyConverter()
+	if err := converter.SetRate(CurrencyUSD, CurrencyCNY, 7.0); err != nil {
+		t.Fatal(err)
+	}
+	estimate := &CostEstimate{
+		Currency: "CNY",
+		Breakdown: []ResourceCostBreakdown{
+			{ResourceName: "ecs", Provider: "alicloud", Count: 1, TotalHourly: 1, TotalMonthly: 720, OnDemandHourly: 1, Currency: "CNY", Available: true},
+			{ResourceName: "ec2", Provider: "aws", Count: 1, TotalHourly: 1, TotalMonthly: 720, OnDemandHourly: 1, Currency: "USD", Available: true},
+			{ResourceName: "missing", Provider: "aws", Count: 1},
+		},
+		UnavailableCount: 1,
+	}
+
+	converted, err := converter.ConvertCostEstimate(estimate, CurrencyCNY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(converted.TotalHourlyCost-8) > 1e-9 || math.Abs(converted.TotalMonthlyCost-5760) > 1e-9 {
+		t.Errorf("totals = %v / %v, want 8 / 5760", converted.TotalHourlyCost, converted.TotalMonthlyCost)
+	}
+	if converted.Breakdown[0].ExchangeRate != nil {
+		t.Error("breakdown already in CNY should not record a rate")
+	}
+	if r := converted.Breakdown[1].ExchangeRate; r == nil || r.Rate != 7.0 || r.Source != RateSourceManual {
+		t.Errorf("aws breakdown rate = %+v", r)
+	}
+	if len(converted.ExchangeRates) != 1 || converted.ProviderBreakdown["aws"].TotalHourlyCost != 7 {
+		t.Errorf("rates = %v, aws summary = %+v", converted.ExchangeRates, converted.ProviderBreakdown["aws"])
+	}
+	if len(converted.Breakdown) != 3 || converted.UnavailableCount != 1 {
+		t.Errorf("unavailable breakdown dropped: %+v", converted.Breakdown)
+	}
+}
+
+func TestConvertCostDiff(t *testing.T) {
+	converter := NewCurrencyConverter()
+	if err := converter.SetRate(CurrencyUSD, CurrencyCNY, 7.0); err != nil {
+		t.Fatal(err)
+	}
+	// One unchanged resource at 2 USD/h plus an update from 1 to 3 USD/h
+	diff := &CostDiff{
+		BeforeHourlyCost: 3, AfterHourlyCost: 5, DeltaHourlyCost: 2,
+		Currency: "USD",
+		Changes: []ResourceCostChange{
+			{Address: "aws_instance.web", Action: "update", BeforeHourly: 1, AfterHourly: 3, DeltaHourly: 2, Currency: "USD", Available: true},
+		},
+	}
+	converted, err := converter.ConvertCostDiff(diff, CurrencyCNY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.Currency != "CNY" || math.Abs(converted.BeforeHourlyCost-21) > 1e-9 || math.Abs(converted.AfterHourlyCost-35) > 1e-9 {
+		t.Errorf("converted = %+v", converted)
+	}
+	if c := converted.Changes[0]; math.Abs(c.DeltaHourly-14) > 1e-9 || c.ExchangeRate == nil || c.Currency != "CNY" {
+		t.Errorf("change = %+v", c)
+	}
+	if diff.Currency != "USD" || diff.Changes[0].DeltaHourly != 2 {
+		t.Error("original diff was modified")
+	}
+}
+
+func TestConvertCostDiff_UnchangedInOwnCurrency(t *testing.T) {
+	converter := NewCurrencyConverter()
+	if err := converter.SetRate(CurrencyUSD, CurrencyCNY, 7.0); err != nil {
+		t.Fatal(err)
+	}
+	// An unchanged CNY resource at 10 CNY/h in a USD diff with a 1 -> 3 USD/h update
+	diff := &CostDiff{
+		BeforeHourlyCost: 11, AfterHourlyCost: 13, DeltaHourlyCost: 2,
+		Currency: "USD",
+		Changes: []ResourceCostChange{
+			{Address: "aws_instance.web", Action: "update", BeforeHourly: 1, AfterHourly: 3, DeltaHourly: 2, Currency: "USD", Available: true},
+		},
+		Unchanged: []CostTotals{{Currency: "CNY", BeforeHourly: 10, AfterHourly: 10}},
+	}
+	converted, err := converter.ConvertCostDiff(diff, CurrencyCNY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(converted.BeforeHourlyCost-17) > 1e-9 || math.Abs(converted.AfterHourlyCost-31) > 1e-9 {
+		t.Errorf("converted = %+v", converted)
+	}
+	if len(converted.Unchanged) != 1 || converted.Unchanged[0].Currency != "CNY" || math.Abs(converted.Unchanged[0].BeforeHourly-10) > 1e-9 {
+		t.Errorf("unchanged = %+v", converted.Unchanged)
+	}
+}
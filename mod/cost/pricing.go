@@ -7,6 +7,8 @@ import (
 	"sync"
 	"time"
 
+	"red-cloud/mod/cost/providers"
+
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -162,6 +164,28 @@ func (ps *PricingService) fetchPricingWithDeduplication(provider, region, resour
 
 // fetchPricingWithRetry fetches pricing from provider API with exponential backoff retry
 func (ps *PricingService) fetchPricingWithRetry(provider, region, resourceType string) (*PricingData, error) {
+	// Network, storage and DNS resources need no API call: an offline catalog (via the
+	// fallback provider) wins, the bundled list prices are the last fallback
+	if providers.IsResourcePricingKey(resourceType) {
+		if ps.fallbackProvider != nil {
+			if fallbackData, fallbackErr := ps.fallbackProvider(provider, region, resourceType); fallbackErr == nil {
+				if cacheErr := ps.cachePricing(fallbackData); cacheErr != nil {
+					fmt.Printf("Warning: Failed to cache resource pricing data: %v\n", cacheErr)
+				}
+				return fallbackData, nil
+			}
+		}
+		providerData, err := providers.GetResourcePricing(provider, region, resourceType)
+		if err != nil {
+			return nil, err
+		}
+		pricingData := convertProviderPricingData(providerData)
+		if cacheErr := ps.cachePricing(pricingData); cacheErr != nil {
+			fmt.Printf("Warning: Failed to cache resource pricing data: %v\n", cacheErr)
+		}
+		return pricingData, nil
+	}
+	
 	// If fallback provider is set and no credential provider is configured,
 	// use fallback directly (useful for testing)
 	if ps.fallbackProvider != nil && ps.credentialProvider == nil {
@@ -221,6 +245,10 @@ func (ps *PricingService) fetchPricingFromProvider(provider, region, resourceTyp
 		"tencentcloud": true,
 		"aws":          true,
 		"volcengine":   true,
+		"huaweicloud":  true,
+		"ucloud":       true,
+		"vultr":        true,
+		"google":       true,
 	}
 	
 	if !supportedProviders[provider] {
@@ -250,18 +278,39 @@ func (ps *PricingService) fetchPricingFromProvider(provider, region, resourceTyp
 	}
 	
 	// Call provider-specific pricing function
+	var pricingData *PricingData
 	switch provider {
 	case "alicloud":
-		return ps.getAlicloudPricing(region, resourceType, accessKey, secretKey)
+		pricingData, err = ps.getAlicloudPricing(region, resourceType, accessKey, secretKey)
 	case "tencentcloud":
-		return ps.getTencentcloudPricing(region, resourceType, accessKey, secretKey)
+		pricingData, err = ps.getTencentcloudPricing(region, resourceType, accessKey, secretKey)
 	case "aws":
-		return ps.getAWSPricing(region, resourceType, accessKey, secretKey)
+		pricingData, err = ps.getAWSPricing(region, resourceType, accessKey, secretKey)
 	case "volcengine":
-		return ps.getVolcenginePricing(region, resourceType, accessKey, secretKey)
+		pricingData, err = ps.getVolcenginePricing(region, resourceType, accessKey, secretKey)
+	case "huaweicloud":
+		pricingData, err = ps.convertPricing(providers.GetHuaweicloudPricing(region, resourceType, accessKey, secretKey))
+	case "ucloud":
+		pricingData, err = ps.convertPricing(providers.GetUCloudPricing(region, resourceType, accessKey, secretKey))
+	case "vultr":
+		pricingData, err = ps.convertPricing(providers.GetVultrPricing(region, resourceType, accessKey, secretKey))
+	case "google":
+		pricingData, err = ps.convertPricing(providers.GetGCPPricing(region, resourceType, accessKey, secretKey))
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
+	if err != nil {
+		return nil, err
+	}
+	
+	// Spot pricing is best effort: the on-demand price is still usable without it
+	if pricingData.Spot == nil && ps.rateLimiters.Wait(provider) == nil {
+		if spot, spotErr := ps.getSpotPricing(provider, region, resourceType, accessKey, secretKey); spotErr == nil {
+			pricingData.Spot = spot
+		}
+	}
+	
+	return pricingData, nil
 }
 
 // getCachedPricing retrieves pricing data from the cache
@@ -346,6 +395,33 @@ func (ps *PricingService) cachePricing(data *PricingData) error {
 	return nil
 }
 
+// CachedPricing returns every entry in the pricing cache, including expired ones
+func (ps *PricingService) CachedPricing() ([]*PricingData, error) {
+	if ps.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	
+	rows, err := ps.db.Query(`SELECT pricing_data FROM pricing_cache ORDER BY provider, region, resource_type`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+	defer rows.Close()
+	
+	var result []*PricingData
+	for rows.Next() {
+		var pricingDataJSON string
+		if err := rows.Scan(&pricingDataJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan cache row: %w", err)
+		}
+		var pricingData PricingData
+		if err := json.Unmarshal([]byte(pricingDataJSON), &pricingData); err != nil {
+			continue
+		}
+		result = append(result, &pricingData)
+	}
+	return result, rows.Err()
+}
+
 // RefreshCache updates pricing data from provider APIs
 func (ps *PricingService) RefreshCache(provider, region string) error {
 	// TODO: Implement in task 3
@@ -21,6 +21,7 @@ func convertProviderPricingData(src *providers.PricingData) *PricingData {
 		HourlyPrice:  src.HourlyPrice,
 		MonthlyPrice: src.MonthlyPrice,
 		Metadata:     src.Metadata,
+		Spot:         convertSpotPricing(src.Spot),
 	}
 
 	// Convert pricing tiers if present
@@ -57,6 +58,41 @@ func InitializeFallbackPricing(ps *PricingService, fallbackFilePath string) erro
 	return nil
 }
 
+// InitializePricingCatalogs makes the newest installed offline catalog the first fallback
+// of the pricing service, ahead of any fallback provider already set. Prices from the
+// catalog are used whenever the provider APIs are unavailable (or no credentials are
+// configured), which keeps estimates reproducible on machines without network access.
+// It returns the loaded catalog, or nil when none is installed.
+func InitializePricingCatalogs(ps *PricingService, catalogDir string) (*PricingCatalog, error) {
+	catalog, err := LatestPricingCatalog(catalogDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing catalogs: %w", err)
+	}
+	if catalog == nil {
+		return nil, nil
+	}
+
+	next := ps.fallbackProvider
+	ps.SetFallbackProvider(func(provider, region, resourceType string) (*PricingData, error) {
+		if data, ok := catalog.Lookup(provider, region, resourceType); ok {
+			result := *data
+			result.Region = region
+			result.Metadata = make(map[string]string, len(data.Metadata)+1)
+			for k, v := range data.Metadata {
+				result.Metadata[k] = v
+			}
+			result.Metadata["pricing_catalog"] = catalog.Version + "@" + catalog.EffectiveDate
+			return &result, nil
+		}
+		if next != nil {
+			return next(provider, region, resourceType)
+		}
+		return nil, fmt.Errorf("resource type %s not found for provider %s, region %s in pricing catalog %s", resourceType, provider, region, catalog.Version)
+	})
+
+	return catalog, nil
+}
+
 // NewPricingServiceWithFallback creates a new pricing service with fallback pricing enabled
 func NewPricingServiceWithFallback(dbPath string, fallbackFilePath string) (*PricingService, error) {
 	ps := NewPricingService(dbPath)
@@ -29,6 +29,7 @@ func (ps *PricingService) getAlicloudPricing(region, resourceType, accessKey, se
 		MonthlyPrice: providerData.MonthlyPrice,
 		PricingTiers: convertPricingTiers(providerData.PricingTiers),
 		Metadata:     providerData.Metadata,
+		Spot:         convertSpotPricing(providerData.Spot),
 	}, nil
 }
 
@@ -54,6 +55,7 @@ func (ps *PricingService) getTencentcloudPricing(region, resourceType, accessKey
 		MonthlyPrice: providerData.MonthlyPrice,
 		PricingTiers: convertPricingTiers(providerData.PricingTiers),
 		Metadata:     providerData.Metadata,
+		Spot:         convertSpotPricing(providerData.Spot),
 	}, nil
 }
 
@@ -79,6 +81,7 @@ func (ps *PricingService) getAWSPricing(region, resourceType, accessKey, secretK
 		MonthlyPrice: providerData.MonthlyPrice,
 		PricingTiers: convertPricingTiers(providerData.PricingTiers),
 		Metadata:     providerData.Metadata,
+		Spot:         convertSpotPricing(providerData.Spot),
 	}, nil
 }
 
@@ -104,9 +107,22 @@ func (ps *PricingService) getVolcenginePricing(region, resourceType, accessKey,
 		MonthlyPrice: providerData.MonthlyPrice,
 		PricingTiers: convertPricingTiers(providerData.PricingTiers),
 		Metadata:     providerData.Metadata,
+		Spot:         convertSpotPricing(providerData.Spot),
 	}, nil
 }
 
+// convertPricing converts the result of a providers package pricing function.
+// Used for providers whose API returns no tiered pricing (Huawei Cloud, UCloud, Vultr, Google Cloud).
+func (ps *PricingService) convertPricing(providerData *providers.PricingData, err error) (*PricingData, error) {
+	if err != nil {
+		return nil, err
+	}
+	if providerData == nil {
+		return nil, fmt.Errorf("received nil pricing data from provider")
+	}
+	return convertProviderPricingData(providerData), nil
+}
+
 // convertPricingTiers converts providers.PricingTier to cost.PricingTier
 func convertPricingTiers(providerTiers []providers.PricingTier) []PricingTier {
 	if providerTiers == nil {
@@ -123,3 +139,45 @@ func convertPricingTiers(providerTiers []providers.PricingTier) []PricingTier {
 	}
 	return tiers
 }
+
+// getSpotPricing fetches spot pricing for providers that expose it.
+// Volcengine has no public spot price API, so it always returns an error.
+func (ps *PricingService) getSpotPricing(provider, region, resourceType, accessKey, secretKey string) (*SpotPricing, error) {
+	var spot *providers.SpotPricing
+	var err error
+	switch provider {
+	case "alicloud":
+		spot, err = providers.GetAlicloudSpotPricing(region, resourceType, accessKey, secretKey)
+	case "tencentcloud":
+		spot, err = providers.GetTencentcloudSpotPricing(region, resourceType, accessKey, secretKey)
+	case "aws":
+		spot, err = providers.GetAWSSpotPricing(region, resourceType, accessKey, secretKey)
+	default:
+		return nil, fmt.Errorf("spot pricing not available for provider: %s", provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return convertSpotPricing(spot), nil
+}
+
+// convertSpotPricing converts providers.SpotPricing to cost.SpotPricing
+func convertSpotPricing(src *providers.SpotPricing) *SpotPricing {
+	if src == nil {
+		return nil
+	}
+	
+	dst := &SpotPricing{
+		HourlyPrice:    src.HourlyPrice,
+		MinHourlyPrice: src.MinHourlyPrice,
+		MaxHourlyPrice: src.MaxHourlyPrice,
+		Source:         src.Source,
+	}
+	if len(src.History) > 0 {
+		dst.History = make([]SpotPricePoint, len(src.History))
+		for i, p := range src.History {
+			dst.History[i] = SpotPricePoint{Timestamp: p.Timestamp, Zone: p.Zone, Price: p.Price}
+		}
+	}
+	return dst
+}
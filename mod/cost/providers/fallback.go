@@ -18,6 +18,7 @@ type PricingData struct {
 	MonthlyPrice float64           `json:"monthly_price"`
 	PricingTiers []PricingTier     `json:"pricing_tiers,omitempty"`
 	Metadata     map[string]string `json:"metadata,omitempty"`
+	Spot         *SpotPricing      `json:"spot,omitempty"`
 }
 
 // PricingTier represents tiered pricing structure
@@ -0,0 +1,354 @@
+package providers
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gcpComputeServiceSkusURL lists the SKUs of the Compute Engine service in the Cloud Billing catalog
+const gcpComputeServiceSkusURL = "https://cloudbilling.googleapis.com/v1/services/6F81-5844-456A/skus"
+
+// gcpSku is a SKU of the Cloud Billing catalog
+type gcpSku struct {
+	Description string `json:"description"`
+	Category    struct {
+		ResourceFamily string `json:"resourceFamily"`
+		ResourceGroup  string `json:"resourceGroup"`
+		UsageType      string `json:"usageType"`
+	} `json:"category"`
+	ServiceRegions []string `json:"serviceRegions"`
+	PricingInfo    []struct {
+		PricingExpression struct {
+			UsageUnit   string `json:"usageUnit"`
+			TieredRates []struct {
+				StartUsageAmount float64 `json:"startUsageAmount"`
+				UnitPrice        struct {
+					CurrencyCode string `json:"currencyCode"`
+					Units        string `json:"units"`
+					Nanos        int64  `json:"nanos"`
+				} `json:"unitPrice"`
+			} `json:"tieredRates"`
+		} `json:"pricingExpression"`
+	} `json:"pricingInfo"`
+}
+
+// gcpMachineSpec is the vCPU and memory of a machine type
+type gcpMachineSpec struct {
+	Family   string
+	VCPU     float64
+	MemoryGB float64
+}
+
+// gcpSkuPrefixes maps a machine family to the description prefix of its core / RAM SKUs
+var gcpSkuPrefixes = map[string]string{
+	"e2":  "E2 Instance",
+	"n1":  "N1 Predefined Instance",
+	"n2":  "N2 Instance",
+	"n2d": "N2D AMD Instance",
+	"c2":  "Compute optimized",
+	"c2d": "C2D AMD Instance",
+	"t2d": "T2D AMD Instance",
+}
+
+// gcpMemoryPerVCPU is the memory (GB) per vCPU of predefined machine classes
+var gcpMemoryPerVCPU = map[string]map[string]float64{
+	"e2":  {"standard": 4, "highmem": 8, "highcpu": 1},
+	"n1":  {"standard": 3.75, "highmem": 6.5, "highcpu": 0.9},
+	"n2":  {"standard": 4, "highmem": 8, "highcpu": 1},
+	"n2d": {"standard": 4, "highmem": 8, "highcpu": 1},
+	"c2":  {"standard": 4},
+	"c2d": {"standard": 4, "highmem": 8, "highcpu": 2},
+	"t2d": {"standard": 4},
+}
+
+// gcpSharedCore are E2 shared-core types, billed as a fraction of a vCPU
+var gcpSharedCore = map[string]gcpMachineSpec{
+	"e2-micro":  {Family: "e2", VCPU: 0.25, MemoryGB: 1},
+	"e2-small":  {Family: "e2", VCPU: 0.5, MemoryGB: 2},
+	"e2-medium": {Family: "e2", VCPU: 1, MemoryGB: 4},
+}
+
+// parseGCPMachineType resolves predefined (n2-standard-4), shared-core (e2-small)
+// and custom (n2-custom-4-8192) machine types. Custom types are priced with the
+// predefined core / RAM SKUs of their family.
+func parseGCPMachineType(machineType string) (gcpMachineSpec, error) {
+	if spec, ok := gcpSharedCore[machineType]; ok {
+		return spec, nil
+	}
+	parts := strings.Split(machineType, "-")
+	if len(parts) < 3 {
+		return gcpMachineSpec{}, fmt.Errorf("unsupported GCP machine type: %s", machineType)
+	}
+	family := parts[0]
+	if _, ok := gcpSkuPrefixes[family]; !ok {
+		return gcpMachineSpec{}, fmt.Errorf("unsupported GCP machine family: %s", family)
+	}
+
+	if parts[1] == "custom" && len(parts) >= 4 {
+		vcpu, err1 := strconv.Atoi(parts[2])
+		memMB, err2 := strconv.Atoi(parts[3])
+		if err1 != nil || err2 != nil {
+			return gcpMachineSpec{}, fmt.Errorf("invalid GCP custom machine type: %s", machineType)
+		}
+		return gcpMachineSpec{Family: family, VCPU: float64(vcpu), MemoryGB: float64(memMB) / 1024}, nil
+	}
+
+	ratio, ok := gcpMemoryPerVCPU[family][parts[1]]
+	if !ok {
+		return gcpMachineSpec{}, fmt.Errorf("unsupported GCP machine class: %s", machineType)
+	}
+	vcpu, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return gcpMachineSpec{}, fmt.Errorf("invalid GCP machine type: %s", machineType)
+	}
+	return gcpMachineSpec{Family: family, VCPU: float64(vcpu), MemoryGB: float64(vcpu) * ratio}, nil
+}
+
+// gcpRegionFromZone turns a zone (us-central1-a) into its region (us-central1)
+func gcpRegionFromZone(zone string) string {
+	parts := strings.Split(zone, "-")
+	if len(parts) == 3 && len(parts[2]) == 1 {
+		return parts[0] + "-" + parts[1]
+	}
+	return zone
+}
+
+// GetGCPPricing retrieves Compute Engine machine type pricing from the Cloud Billing catalog.
+// accessKey is the path of a service account JSON key; secretKey (the project) is not needed
+// because the catalog is not project specific.
+func GetGCPPricing(region, resourceType, accessKey, _ string) (*PricingData, error) {
+	if accessKey == "" {
+		return nil, fmt.Errorf("missing GCP service account credentials")
+	}
+	if resourceType == "" {
+		return nil, fmt.Errorf("resource type cannot be empty")
+	}
+	if region == "" {
+		region = "us-central1"
+	}
+	region = gcpRegionFromZone(region)
+
+	spec, err := parseGCPMachineType(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := gcpAccessToken(accessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	var skus []gcpSku
+	pageToken := ""
+	for {
+		query := url.Values{"pageSize": {"5000"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		req, err := http.NewRequest("GET", gcpComputeServiceSkusURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call Cloud Billing catalog API: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Cloud Billing catalog response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("cloud billing catalog API error: %s", string(body))
+		}
+
+		var page struct {
+			Skus          []gcpSku `json:"skus"`
+			NextPageToken string   `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse Cloud Billing catalog response: %w", err)
+		}
+		skus = append(skus, page.Skus...)
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	return gcpPricingFromSkus(skus, region, resourceType, spec)
+}
+
+// gcpPricingFromSkus prices a machine type from its family's core and RAM SKUs.
+// Spot (Preemptible) SKUs of the same family fill the spot price when present.
+func gcpPricingFromSkus(skus []gcpSku, region, resourceType string, spec gcpMachineSpec) (*PricingData, error) {
+	prefix := gcpSkuPrefixes[spec.Family]
+	find := func(usageType, resource string) (float64, string, bool) {
+		for _, sku := range skus {
+			if sku.Category.ResourceFamily != "Compute" || sku.Category.UsageType != usageType {
+				continue
+			}
+			if !containsString(sku.ServiceRegions, region) {
+				continue
+			}
+			desc := strings.TrimPrefix(sku.Description, "Spot Preemptible ")
+			if !strings.HasPrefix(desc, prefix+" "+resource) || strings.Contains(desc, "Custom") || strings.Contains(desc, "Sole Tenancy") {
+				continue
+			}
+			if price, currency, ok := gcpSkuUnitPrice(sku); ok {
+				return price, currency, true
+			}
+		}
+		return 0, "", false
+	}
+
+	corePrice, currency, ok := find("OnDemand", "Core")
+	if !ok {
+		return nil, fmt.Errorf("no %s core SKU found for %s", strings.ToUpper(spec.Family), region)
+	}
+	ramPrice, _, ok := find("OnDemand", "Ram")
+	if !ok {
+		return nil, fmt.Errorf("no %s RAM SKU found for %s", strings.ToUpper(spec.Family), region)
+	}
+
+	hourly := corePrice*spec.VCPU + ramPrice*spec.MemoryGB
+	pricingData := &PricingData{
+		Provider:     "google",
+		Region:       region,
+		ResourceType: resourceType,
+		Currency:     currency,
+		HourlyPrice:  hourly,
+		MonthlyPrice: hourly * 720, // 720 hours per month
+		Metadata: map[string]string{
+			"vcpu":   strconv.FormatFloat(spec.VCPU, 'f', -1, 64),
+			"memory": strconv.FormatFloat(spec.MemoryGB, 'f', -1, 64) + "GB",
+			"source": "cloudbilling",
+		},
+	}
+
+	spotCore, _, okCore := find("Preemptible", "Core")
+	spotRam, _, okRam := find("Preemptible", "Ram")
+	if okCore && okRam {
+		pricingData.Spot = SummarizeSpotHistory([]SpotPricePoint{{
+			Timestamp: time.Now().UTC(),
+			Zone:      region,
+			Price:     spotCore*spec.VCPU + spotRam*spec.MemoryGB,
+		}}, "cloudbilling")
+	}
+
+	return pricingData, nil
+}
+
+// gcpSkuUnitPrice returns the first-tier unit price of a SKU
+func gcpSkuUnitPrice(sku gcpSku) (float64, string, bool) {
+	if len(sku.PricingInfo) == 0 {
+		return 0, "", false
+	}
+	rates := sku.PricingInfo[0].PricingExpression.TieredRates
+	for _, rate := range rates {
+		if rate.StartUsageAmount != 0 {
+			continue
+		}
+		units, err := strconv.ParseFloat(rate.UnitPrice.Units, 64)
+		if rate.UnitPrice.Units != "" && err != nil {
+			return 0, "", false
+		}
+		price := units + float64(rate.UnitPrice.Nanos)/1e9
+		return price, rate.UnitPrice.CurrencyCode, price > 0
+	}
+	return 0, "", false
+}
+
+// gcpAccessToken exchanges a service account key for an OAuth access token (JWT bearer grant)
+func gcpAccessToken(credentialsPath string) (string, error) {
+	data, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GCP credentials file: %w", err)
+	}
+	var sa struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return "", fmt.Errorf("failed to parse GCP credentials: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return "", fmt.Errorf("GCP credentials are not a service account key")
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("invalid GCP service account private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid GCP service account private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("GCP service account private key is not RSA")
+	}
+
+	now := time.Now()
+	encode := func(v interface{}) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	unsigned := encode(map[string]string{"alg": "RS256", "typ": "JWT"}) + "." + encode(map[string]interface{}{
+		"iss":   sa.ClientEmail,
+		"scope": "https://www.googleapis.com/auth/cloud-platform",
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GCP token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	}
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.PostForm(sa.TokenURI, form)
+	if err != nil {
+		return "", fmt.Errorf("failed to request GCP access token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GCP token request failed: %s", string(body))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("invalid GCP token response")
+	}
+	return token.AccessToken, nil
+}
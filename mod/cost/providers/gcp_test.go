@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadGCPSkus(t *testing.T) []gcpSku {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "gcp_compute_skus.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var page struct {
+		Skus []gcpSku `json:"skus"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+	return page.Skus
+}
+
+func TestParseGCPMachineType(t *testing.T) {
+	tests := []struct {
+		machineType string
+		vcpu        float64
+		memoryGB    float64
+		wantErr     bool
+	}{
+		{"e2-standard-2", 2, 8, false},
+		{"n1-standard-1", 1, 3.75, false},
+		{"n2-highcpu-8", 8, 8, false},
+		{"e2-small", 0.5, 2, false},
+		{"n2-custom-4-8192", 4, 8, false},
+		{"a2-highgpu-1g", 0, 0, true},
+		{"invalid", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.machineType, func(t *testing.T) {
+			spec, err := parseGCPMachineType(tt.machineType)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %s", tt.machineType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseGCPMachineType failed: %v", err)
+			}
+			if spec.VCPU != tt.vcpu || spec.MemoryGB != tt.memoryGB {
+				t.Errorf("Expected %v vCPU / %vGB, got %v / %v", tt.vcpu, tt.memoryGB, spec.VCPU, spec.MemoryGB)
+			}
+		})
+	}
+}
+
+func TestGCPPricingFromSkus(t *testing.T) {
+	skus := loadGCPSkus(t)
+	spec, _ := parseGCPMachineType("e2-standard-2")
+
+	pricing, err := gcpPricingFromSkus(skus, gcpRegionFromZone("us-central1-a"), "e2-standard-2", spec)
+	if err != nil {
+		t.Fatalf("gcpPricingFromSkus failed: %v", err)
+	}
+	if pricing.Provider != "google" || pricing.Region != "us-central1" || pricing.Currency != "USD" {
+		t.Errorf("Unexpected pricing keys: %+v", pricing)
+	}
+	expected := 2*0.02181159 + 8*0.00292353
+	if math.Abs(pricing.HourlyPrice-expected) > 1e-9 {
+		t.Errorf("Expected hourly price %f, got %f", expected, pricing.HourlyPrice)
+	}
+	if pricing.Spot == nil || math.Abs(pricing.Spot.HourlyPrice-(2*0.006543+8*0.000877)) > 1e-9 {
+		t.Errorf("Expected spot price from preemptible SKUs, got %+v", pricing.Spot)
+	}
+
+	// Custom SKUs are skipped; predefined N2 core and RAM SKUs are used
+	spec, _ = parseGCPMachineType("n2-standard-4")
+	pricing, err = gcpPricingFromSkus(skus, "us-central1", "n2-standard-4", spec)
+	if err != nil {
+		t.Fatalf("gcpPricingFromSkus failed: %v", err)
+	}
+	if expected := 4*0.031611 + 16*0.004237; math.Abs(pricing.HourlyPrice-expected) > 1e-9 {
+		t.Errorf("Expected hourly price %f, got %f", expected, pricing.HourlyPrice)
+	}
+	if pricing.Spot != nil {
+		t.Errorf("Expected no spot price without preemptible SKUs, got %+v", pricing.Spot)
+	}
+
+	if _, err := gcpPricingFromSkus(skus, "europe-west1", "n2-standard-4", spec); err == nil {
+		t.Error("Expected error for region without SKUs")
+	}
+}
+
+func TestGetGCPPricing_MissingCredentials(t *testing.T) {
+	if _, err := GetGCPPricing("us-central1", "e2-standard-2", "", ""); err == nil {
+		t.Error("Expected error for missing credentials, got nil")
+	}
+}
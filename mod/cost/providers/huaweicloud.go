@@ -0,0 +1,138 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/global"
+	bss "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/bss/v2"
+	bssmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/bss/v2/model"
+	bssregion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/bss/v2/region"
+	iam "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/iam/v3"
+	iammodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/iam/v3/model"
+	iamregion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/iam/v3/region"
+	"github.com/shopspring/decimal"
+)
+
+// GetHuaweicloudPricing retrieves pay-per-use ECS pricing for a flavor (e.g. "s6.small.1")
+// using the BSS ListOnDemandResourceRatings API. Only Chinese mainland accounts are supported.
+func GetHuaweicloudPricing(region, resourceType, accessKey, secretKey string) (*PricingData, error) {
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("missing Huawei Cloud access key or secret key")
+	}
+	if resourceType == "" {
+		return nil, fmt.Errorf("resource type cannot be empty")
+	}
+	if region == "" {
+		region = "cn-north-4"
+	}
+	region = huaweicloudRegionFromZone(region)
+
+	projectID, err := huaweicloudProjectID(region, accessKey, secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	reg, err := bssregion.SafeValueOf("cn-north-1")
+	if err != nil {
+		return nil, err
+	}
+	hcClient, err := bss.BssClientBuilder().
+		WithCredential(global.NewCredentialsBuilder().WithAk(accessKey).WithSk(secretKey).Build()).
+		WithRegion(reg).
+		SafeBuild()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Huawei Cloud BSS client: %w", err)
+	}
+	client := bss.NewBssClient(hcClient)
+
+	one := decimal.NewFromInt(1)
+	request := &bssmodel.ListOnDemandResourceRatingsRequest{
+		Body: &bssmodel.RateOnDemandReq{
+			ProjectId: projectID,
+			ProductInfos: []bssmodel.DemandProductInfo{{
+				Id:               "1",
+				CloudServiceType: "hws.service.type.ec2",
+				ResourceType:     "hws.resource.type.vm",
+				ResourceSpec:     resourceType + ".linux",
+				Region:           region,
+				UsageFactor:      "Duration",
+				UsageValue:       &one,
+				UsageMeasureId:   4, // hour
+				SubscriptionNum:  1,
+			}},
+		},
+	}
+	response, err := client.ListOnDemandResourceRatings(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call ListOnDemandResourceRatings API: %w", err)
+	}
+
+	return huaweicloudPricingFromRating(response, region, resourceType)
+}
+
+// huaweicloudPricingFromRating extracts the hourly price from a ListOnDemandResourceRatings response.
+// The official website (list) price is preferred over the discounted amount.
+func huaweicloudPricingFromRating(response *bssmodel.ListOnDemandResourceRatingsResponse, region, resourceType string) (*PricingData, error) {
+	if response == nil {
+		return nil, fmt.Errorf("empty pricing response from Huawei Cloud")
+	}
+
+	var hourly float64
+	switch {
+	case response.OfficialWebsiteAmount != nil && response.OfficialWebsiteAmount.IsPositive():
+		hourly = response.OfficialWebsiteAmount.InexactFloat64()
+	case response.Amount != nil && response.Amount.IsPositive():
+		hourly = response.Amount.InexactFloat64()
+	default:
+		return nil, fmt.Errorf("price not found in Huawei Cloud response")
+	}
+
+	currency := "CNY"
+	if response.Currency != nil && *response.Currency != "" {
+		currency = *response.Currency
+	}
+
+	return &PricingData{
+		Provider:     "huaweicloud",
+		Region:       region,
+		ResourceType: resourceType,
+		Currency:     currency,
+		HourlyPrice:  hourly,
+		MonthlyPrice: hourly * 720, // 720 hours per month
+		Metadata: map[string]string{
+			"source": "ListOnDemandResourceRatings",
+		},
+	}, nil
+}
+
+// huaweicloudProjectID looks up the IAM project of a region, which BSS rating requires
+func huaweicloudProjectID(region, accessKey, secretKey string) (string, error) {
+	reg, err := iamregion.SafeValueOf(region)
+	if err != nil {
+		return "", fmt.Errorf("unsupported Huawei Cloud region %s: %w", region, err)
+	}
+	hcClient, err := iam.IamClientBuilder().
+		WithCredential(global.NewCredentialsBuilder().WithAk(accessKey).WithSk(secretKey).Build()).
+		WithRegion(reg).
+		SafeBuild()
+	if err != nil {
+		return "", fmt.Errorf("failed to create Huawei Cloud IAM client: %w", err)
+	}
+	response, err := iam.NewIamClient(hcClient).KeystoneListProjects(&iammodel.KeystoneListProjectsRequest{Name: &region})
+	if err != nil {
+		return "", fmt.Errorf("failed to list Huawei Cloud projects: %w", err)
+	}
+	if response.Projects == nil || len(*response.Projects) == 0 {
+		return "", fmt.Errorf("no Huawei Cloud project found for region %s", region)
+	}
+	return (*response.Projects)[0].Id, nil
+}
+
+// huaweicloudRegionFromZone turns an availability zone (cn-north-4a) into its region
+func huaweicloudRegionFromZone(zone string) string {
+	if n := len(zone); n > 0 && zone[n-1] >= 'a' && zone[n-1] <= 'z' && strings.Count(zone, "-") >= 2 {
+		return zone[:n-1]
+	}
+	return zone
+}
@@ -0,0 +1,56 @@
+package providers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bssmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/bss/v2/model"
+)
+
+func TestHuaweicloudPricingFromRating(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "huaweicloud_rating.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var response bssmodel.ListOnDemandResourceRatingsResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	pricing, err := huaweicloudPricingFromRating(&response, "cn-north-4", "s6.small.1")
+	if err != nil {
+		t.Fatalf("huaweicloudPricingFromRating failed: %v", err)
+	}
+	if pricing.Provider != "huaweicloud" || pricing.Currency != "CNY" {
+		t.Errorf("Expected huaweicloud/CNY, got %s/%s", pricing.Provider, pricing.Currency)
+	}
+	// The official website price wins over the discounted amount
+	if pricing.HourlyPrice != 0.333 {
+		t.Errorf("Expected hourly price 0.333, got %f", pricing.HourlyPrice)
+	}
+
+	if _, err := huaweicloudPricingFromRating(&bssmodel.ListOnDemandResourceRatingsResponse{}, "cn-north-4", "s6.small.1"); err == nil {
+		t.Error("Expected error for response without price")
+	}
+}
+
+func TestHuaweicloudRegionFromZone(t *testing.T) {
+	tests := map[string]string{
+		"cn-north-4a":    "cn-north-4",
+		"cn-north-4":     "cn-north-4",
+		"ap-southeast-1": "ap-southeast-1",
+	}
+	for zone, expected := range tests {
+		if got := huaweicloudRegionFromZone(zone); got != expected {
+			t.Errorf("huaweicloudRegionFromZone(%s) = %s, want %s", zone, got, expected)
+		}
+	}
+}
+
+func TestGetHuaweicloudPricing_MissingCredentials(t *testing.T) {
+	if _, err := GetHuaweicloudPricing("cn-north-4", "s6.small.1", "", "secret"); err == nil {
+		t.Error("Expected error for missing access key, got nil")
+	}
+}
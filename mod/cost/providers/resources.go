@@ -0,0 +1,81 @@
+package providers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// resourcePricingJSON holds list prices of network, storage and DNS resources.
+// Prices of these resources are simple per-unit rates that rarely change, so they are
+// bundled instead of queried from billing APIs. GB-month prices use 720 hours per month.
+//
+//go:embed resource_pricing.json
+var resourcePricingJSON []byte
+
+// Resource pricing keys used in resource_pricing.json; disks use "disk.<category>"
+var resourcePricingKinds = map[string]bool{
+	"eip":                         true,
+	"eip_bandwidth":               true,
+	"bandwidth_package":           true,
+	"disk":                        true,
+	"nat_gateway":                 true,
+	"load_balancer":               true,
+	"object_storage":              true,
+	"object_storage_subscription": true,
+	"dns_record":                  true,
+	"dns_zone":                    true,
+}
+
+var (
+	resourceDB   *FallbackDatabase
+	resourceOnce sync.Once
+	resourceErr  error
+)
+
+// IsResourcePricingKey reports whether resourceType is a resource pricing key such as "eip" or "disk.cloud_ssd"
+func IsResourcePricingKey(resourceType string) bool {
+	kind, _, _ := strings.Cut(resourceType, ".")
+	return resourcePricingKinds[kind]
+}
+
+// GetResourcePricing returns the list price of a resource pricing key.
+// Region-specific entries win over the provider default ("*"); disk categories
+// without an entry fall back to the generic "disk" price.
+func GetResourcePricing(provider, region, key string) (*PricingData, error) {
+	resourceOnce.Do(func() {
+		var db FallbackDatabase
+		if err := json.Unmarshal(resourcePricingJSON, &db); err != nil {
+			resourceErr = fmt.Errorf("failed to parse resource pricing JSON: %w", err)
+			return
+		}
+		resourceDB = &db
+	})
+	if resourceErr != nil {
+		return nil, resourceErr
+	}
+
+	providerData, ok := resourceDB.Pricing[provider]
+	if !ok {
+		return nil, fmt.Errorf("no resource pricing for provider %s", provider)
+	}
+
+	keys := []string{key}
+	if kind, _, found := strings.Cut(key, "."); found {
+		keys = append(keys, kind)
+	}
+	for _, k := range keys {
+		for _, r := range []string{region, "*"} {
+			if data, ok := providerData[r][k]; ok {
+				result := *data
+				result.Region = region
+				result.ResourceType = key
+				return &result, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no resource pricing for %s in provider %s", key, provider)
+}
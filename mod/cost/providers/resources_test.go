@@ -0,0 +1,43 @@
+package providers
+
+import "testing"
+
+func TestGetResourcePricing(t *testing.T) {
+	pricing, err := GetResourcePricing("aws", "us-east-1", "disk.gp3")
+	if err != nil {
+		t.Fatalf("GetResourcePricing failed: %v", err)
+	}
+	if pricing.MonthlyPrice != 0.08 || pricing.Currency != "USD" || pricing.Region != "us-east-1" {
+		t.Errorf("Expected gp3 at 0.08 USD per GB-month, got %+v", pricing)
+	}
+
+	// Unknown disk categories fall back to the generic disk price
+	pricing, err = GetResourcePricing("alicloud", "cn-hangzhou", "disk.cloud_unknown")
+	if err != nil {
+		t.Fatalf("GetResourcePricing failed: %v", err)
+	}
+	if pricing.ResourceType != "disk.cloud_unknown" || pricing.MonthlyPrice != 0.35 {
+		t.Errorf("Expected generic disk price, got %+v", pricing)
+	}
+
+	if _, err := GetResourcePricing("azurerm", "eastus", "eip"); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+	if _, err := GetResourcePricing("ucloud", "cn-bj2", "object_storage"); err == nil {
+		t.Error("Expected error for resource without a price")
+	}
+}
+
+func TestIsResourcePricingKey(t *testing.T) {
+	for key, expected := range map[string]bool{
+		"eip":            true,
+		"disk.cloud_ssd": true,
+		"nat_gateway":    true,
+		"t3.large":       false,
+		"ecs.g6.large":   false,
+	} {
+		if got := IsResourcePricingKey(key); got != expected {
+			t.Errorf("IsResourcePricingKey(%s) = %v, want %v", key, got, expected)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package providers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	alicloudecs "github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	awscredentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+// spotHistoryWindow is how far back spot price history is requested
+const spotHistoryWindow = 7 * 24 * time.Hour
+
+// maxSpotHistoryPoints caps the history kept in pricing data (and therefore in the cache)
+const maxSpotHistoryPoints = 48
+
+// SpotPricing represents spot / preemptible pricing for an instance type
+type SpotPricing struct {
+	HourlyPrice    float64          `json:"hourly_price"`               // Average of the latest price in each zone
+	MinHourlyPrice float64          `json:"min_hourly_price,omitempty"` // Lowest price seen in the history window
+	MaxHourlyPrice float64          `json:"max_hourly_price,omitempty"` // Highest price seen in the history window
+	History        []SpotPricePoint `json:"history,omitempty"`
+	Source         string           `json:"source,omitempty"`
+}
+
+// SpotPricePoint is a single spot price observation
+type SpotPricePoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Zone      string    `json:"zone,omitempty"`
+	Price     float64   `json:"price"`
+}
+
+// SummarizeSpotHistory builds SpotPricing from raw observations.
+// HourlyPrice is the average of the most recent price in each zone, since the
+// zone a template lands in is usually not known when estimating.
+func SummarizeSpotHistory(points []SpotPricePoint, source string) *SpotPricing {
+	if len(points) == 0 {
+		return nil
+	}
+	sorted := make([]SpotPricePoint, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	spot := &SpotPricing{Source: source, MinHourlyPrice: sorted[0].Price, MaxHourlyPrice: sorted[0].Price}
+	latest := make(map[string]float64)
+	for _, p := range sorted {
+		if p.Price < spot.MinHourlyPrice {
+			spot.MinHourlyPrice = p.Price
+		}
+		if p.Price > spot.MaxHourlyPrice {
+			spot.MaxHourlyPrice = p.Price
+		}
+		latest[p.Zone] = p.Price
+	}
+	for _, price := range latest {
+		spot.HourlyPrice += price
+	}
+	spot.HourlyPrice /= float64(len(latest))
+
+	if len(sorted) > maxSpotHistoryPoints {
+		sorted = sorted[len(sorted)-maxSpotHistoryPoints:]
+	}
+	spot.History = sorted
+	return spot
+}
+
+// GetAlicloudSpotPricing retrieves spot price history using the DescribeSpotPriceHistory API
+func GetAlicloudSpotPricing(region, resourceType, accessKey, secretKey string) (*SpotPricing, error) {
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("missing Alibaba Cloud access key or secret key")
+	}
+	if region == "" {
+		region = "cn-hangzhou"
+	}
+
+	client, err := alicloudecs.NewClientWithAccessKey(region, accessKey, secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Alibaba Cloud ECS client: %w", err)
+	}
+	request := alicloudecs.CreateDescribeSpotPriceHistoryRequest()
+	request.Scheme = "https"
+	request.InstanceType = resourceType
+	request.NetworkType = "vpc"
+	request.StartTime = time.Now().Add(-spotHistoryWindow).UTC().Format("2006-01-02T15:04:05Z")
+
+	response, err := client.DescribeSpotPriceHistory(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call DescribeSpotPriceHistory API: %w", err)
+	}
+
+	var points []SpotPricePoint
+	for _, p := range response.SpotPrices.SpotPriceType {
+		ts, err := time.Parse(time.RFC3339, p.Timestamp)
+		if err != nil || p.SpotPrice <= 0 {
+			continue
+		}
+		points = append(points, SpotPricePoint{Timestamp: ts, Zone: p.ZoneId, Price: p.SpotPrice})
+	}
+	spot := SummarizeSpotHistory(points, "DescribeSpotPriceHistory")
+	if spot == nil {
+		return nil, fmt.Errorf("no spot price history for %s in %s", resourceType, region)
+	}
+	return spot, nil
+}
+
+// GetAWSSpotPricing retrieves Linux spot price history using the EC2 DescribeSpotPriceHistory API
+func GetAWSSpotPricing(region, resourceType, accessKey, secretKey string) (*SpotPricing, error) {
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("missing AWS access key or secret key")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		Credentials: awscredentials.NewStaticCredentials(accessKey, secretKey, ""),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	input := &ec2.DescribeSpotPriceHistoryInput{
+		InstanceTypes:       aws.StringSlice([]string{resourceType}),
+		ProductDescriptions: aws.StringSlice([]string{"Linux/UNIX"}),
+		StartTime:           aws.Time(time.Now().Add(-spotHistoryWindow)),
+	}
+	var points []SpotPricePoint
+	err = ec2.New(sess).DescribeSpotPriceHistoryPages(input, func(out *ec2.DescribeSpotPriceHistoryOutput, _ bool) bool {
+		for _, p := range out.SpotPriceHistory {
+			price, err := strconv.ParseFloat(aws.StringValue(p.SpotPrice), 64)
+			if err != nil || price <= 0 || p.Timestamp == nil {
+				continue
+			}
+			points = append(points, SpotPricePoint{Timestamp: *p.Timestamp, Zone: aws.StringValue(p.AvailabilityZone), Price: price})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call DescribeSpotPriceHistory API: %w", err)
+	}
+	spot := SummarizeSpotHistory(points, "DescribeSpotPriceHistory")
+	if spot == nil {
+		return nil, fmt.Errorf("no spot price history for %s in %s", resourceType, region)
+	}
+	return spot, nil
+}
+
+// GetTencentcloudSpotPricing retrieves the current spot price using InquiryPriceRunInstances
+// with the SPOTPAID charge type. Tencent Cloud does not expose spot price history.
+func GetTencentcloudSpotPricing(region, resourceType, secretId, secretKey string) (*SpotPricing, error) {
+	if secretId == "" || secretKey == "" {
+		return nil, fmt.Errorf("missing Tencent Cloud secret ID or secret key")
+	}
+	if region == "" {
+		region = "ap-guangzhou"
+	}
+	actualRegion, actualZone := tencentcloudRegionZone(region)
+
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Endpoint = "cvm.tencentcloudapi.com"
+	client, err := cvm.NewClient(common.NewCredential(secretId, secretKey), actualRegion, cpf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Tencent Cloud CVM client: %w", err)
+	}
+
+	request := cvm.NewInquiryPriceRunInstancesRequest()
+	request.InstanceType = common.StringPtr(resourceType)
+	request.InstanceCount = common.Int64Ptr(1)
+	request.InstanceChargeType = common.StringPtr("SPOTPAID")
+	request.Placement = &cvm.Placement{Zone: common.StringPtr(actualZone)}
+	request.ImageId = common.StringPtr("img-pi0ii46r")
+
+	response, err := client.InquiryPriceRunInstances(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call InquiryPriceRunInstances API: %w", err)
+	}
+	if response == nil || response.Response == nil || response.Response.Price == nil || response.Response.Price.InstancePrice == nil {
+		return nil, fmt.Errorf("empty or invalid pricing response from Tencent Cloud")
+	}
+	// The spot discount is applied to UnitPriceDiscount; UnitPrice stays at the on-demand price
+	item := response.Response.Price.InstancePrice
+	var price float64
+	switch {
+	case item.UnitPriceDiscount != nil && *item.UnitPriceDiscount > 0:
+		price = *item.UnitPriceDiscount
+	case item.UnitPrice != nil && *item.UnitPrice > 0:
+		price = *item.UnitPrice
+	default:
+		return nil, fmt.Errorf("spot price not found in response")
+	}
+	return SummarizeSpotHistory([]SpotPricePoint{{Timestamp: time.Now().UTC(), Zone: actualZone, Price: price}}, "InquiryPriceRunInstances"), nil
+}
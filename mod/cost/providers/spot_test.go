@@ -0,0 +1,46 @@
+package providers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeSpotHistory(t *testing.T) {
+	now := time.Now()
+	points := []SpotPricePoint{
+		{Timestamp: now.Add(-3 * time.Hour), Zone: "a", Price: 0.5},
+		{Timestamp: now.Add(-1 * time.Hour), Zone: "a", Price: 0.2},
+		{Timestamp: now.Add(-2 * time.Hour), Zone: "b", Price: 0.4},
+	}
+
+	spot := SummarizeSpotHistory(points, "test")
+	if spot == nil {
+		t.Fatal("Expected non-nil spot pricing")
+	}
+	// Latest price per zone: a=0.2, b=0.4
+	if spot.HourlyPrice < 0.2999 || spot.HourlyPrice > 0.3001 {
+		t.Errorf("Expected average latest price 0.3, got %f", spot.HourlyPrice)
+	}
+	if spot.MinHourlyPrice != 0.2 || spot.MaxHourlyPrice != 0.5 {
+		t.Errorf("Expected range 0.2-0.5, got %f-%f", spot.MinHourlyPrice, spot.MaxHourlyPrice)
+	}
+	if len(spot.History) != 3 || !spot.History[0].Timestamp.Equal(points[0].Timestamp) {
+		t.Errorf("Expected history sorted by time, got %+v", spot.History)
+	}
+
+	if SummarizeSpotHistory(nil, "test") != nil {
+		t.Error("Expected nil for empty history")
+	}
+}
+
+func TestGetSpotPricing_MissingCredentials(t *testing.T) {
+	if _, err := GetAlicloudSpotPricing("cn-hangzhou", "ecs.g6.large", "", ""); err == nil {
+		t.Error("Expected error for missing Alibaba Cloud credentials, got nil")
+	}
+	if _, err := GetAWSSpotPricing("us-east-1", "t3.large", "", ""); err == nil {
+		t.Error("Expected error for missing AWS credentials, got nil")
+	}
+	if _, err := GetTencentcloudSpotPricing("ap-guangzhou", "S2.MEDIUM4", "", ""); err == nil {
+		t.Error("Expected error for missing Tencent Cloud credentials, got nil")
+	}
+}
@@ -27,23 +27,7 @@ func GetTencentcloudPricing(region, resourceType, secretId, secretKey string) (*
 	}
 	
 	// Extract region and zone from the input
-	// If region contains a zone suffix (e.g., "ap-beijing-7"), extract both
-	// Otherwise, use the region and default to zone-1
-	actualRegion := region
-	actualZone := region
-	
-	// Check if region contains a zone suffix (e.g., "ap-beijing-7")
-	// Zone format: region + "-" + number
-	parts := strings.Split(region, "-")
-	if len(parts) >= 3 {
-		// This looks like a zone (e.g., "ap-beijing-7")
-		// Extract region by removing the last part
-		actualRegion = strings.Join(parts[:len(parts)-1], "-")
-		actualZone = region
-	} else {
-		// This is just a region, use default zone
-		actualZone = region + "-1"
-	}
+	actualRegion, actualZone := tencentcloudRegionZone(region)
 	
 	// Create credential
 	credential := common.NewCredential(secretId, secretKey)
@@ -141,3 +125,13 @@ func GetTencentcloudPricing(region, resourceType, secretId, secretKey string) (*
 	
 	return pricingData, nil
 }
+
+// tencentcloudRegionZone splits a region or zone into region and zone.
+// A zone (e.g. "ap-beijing-7") yields its region; a bare region defaults to zone-1.
+func tencentcloudRegionZone(region string) (string, string) {
+	parts := strings.Split(region, "-")
+	if len(parts) >= 3 {
+		return strings.Join(parts[:len(parts)-1], "-"), region
+	}
+	return region, region + "-1"
+}
@@ -0,0 +1,150 @@
+package providers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	"github.com/ucloud/ucloud-sdk-go/ucloud"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/auth"
+)
+
+// ucloudMemoryPerCore is the memory (GB) per core of UHost instance type categories
+var ucloudMemoryPerCore = map[string]int{
+	"highcpu":  1,
+	"basic":    2,
+	"standard": 4,
+	"highmem":  8,
+}
+
+// ucloudInstanceSpec is the machine type, cores and memory of a UHost instance type
+type ucloudInstanceSpec struct {
+	MachineType string
+	CPU         int
+	MemoryMB    int
+}
+
+// parseUCloudInstanceType resolves terraform instance types such as "n-standard-2"
+// (machine type N, 2 cores, 8GB) and "o-customized-4-16" (4 cores, 16GB)
+func parseUCloudInstanceType(instanceType string) (ucloudInstanceSpec, error) {
+	parts := strings.Split(instanceType, "-")
+	if len(parts) < 3 {
+		return ucloudInstanceSpec{}, fmt.Errorf("unsupported UCloud instance type: %s", instanceType)
+	}
+	spec := ucloudInstanceSpec{MachineType: strings.ToUpper(parts[0])}
+	cpu, err := strconv.Atoi(parts[2])
+	if err != nil || cpu <= 0 {
+		return ucloudInstanceSpec{}, fmt.Errorf("invalid UCloud instance type: %s", instanceType)
+	}
+	spec.CPU = cpu
+
+	if parts[1] == "customized" {
+		if len(parts) < 4 {
+			return ucloudInstanceSpec{}, fmt.Errorf("invalid UCloud customized instance type: %s", instanceType)
+		}
+		memGB, err := strconv.Atoi(parts[3])
+		if err != nil || memGB <= 0 {
+			return ucloudInstanceSpec{}, fmt.Errorf("invalid UCloud customized instance type: %s", instanceType)
+		}
+		spec.MemoryMB = memGB * 1024
+		return spec, nil
+	}
+
+	ratio, ok := ucloudMemoryPerCore[parts[1]]
+	if !ok {
+		return ucloudInstanceSpec{}, fmt.Errorf("unsupported UCloud instance category: %s", instanceType)
+	}
+	spec.MemoryMB = cpu * ratio * 1024
+	return spec, nil
+}
+
+// GetUCloudPricing retrieves hourly (Dynamic) UHost pricing using the GetUHostInstancePrice API.
+// The price includes a 20GB cloud SSD system disk. region may be a zone such as "cn-bj2-02".
+func GetUCloudPricing(region, resourceType, publicKey, privateKey string) (*PricingData, error) {
+	if publicKey == "" || privateKey == "" {
+		return nil, fmt.Errorf("missing UCloud public key or private key")
+	}
+	if resourceType == "" {
+		return nil, fmt.Errorf("resource type cannot be empty")
+	}
+	if region == "" {
+		region = "cn-bj2"
+	}
+	spec, err := parseUCloudInstanceType(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := ucloud.NewConfig()
+	actualRegion := region
+	// Zones add a numeric suffix to the region, e.g. cn-bj2-02
+	if parts := strings.Split(region, "-"); len(parts) == 3 {
+		if _, err := strconv.Atoi(parts[2]); err == nil {
+			actualRegion = parts[0] + "-" + parts[1]
+			cfg.Zone = region
+		}
+	}
+	cfg.Region = actualRegion
+
+	credential := auth.NewCredential()
+	credential.PublicKey = publicKey
+	credential.PrivateKey = privateKey
+	client := uhost.NewClient(&cfg, &credential)
+
+	request := client.NewGetUHostInstancePriceRequest()
+	request.CPU = ucloud.Int(spec.CPU)
+	request.Memory = ucloud.Int(spec.MemoryMB)
+	request.Count = ucloud.Int(1)
+	request.ChargeType = ucloud.String("Dynamic")
+	request.MachineType = ucloud.String(spec.MachineType)
+	request.Disks = []uhost.UHostDisk{{
+		IsBoot: ucloud.String("True"),
+		Type:   ucloud.String("CLOUD_SSD"),
+		Size:   ucloud.Int(20),
+	}}
+
+	response, err := client.GetUHostInstancePrice(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call GetUHostInstancePrice API: %w", err)
+	}
+
+	return ucloudPricingFromPriceSet(response, actualRegion, resourceType, spec)
+}
+
+// ucloudPricingFromPriceSet extracts the Dynamic (hourly) price from a GetUHostInstancePrice response.
+// The list price is preferred over the discounted price.
+func ucloudPricingFromPriceSet(response *uhost.GetUHostInstancePriceResponse, region, resourceType string, spec ucloudInstanceSpec) (*PricingData, error) {
+	if response == nil {
+		return nil, fmt.Errorf("empty pricing response from UCloud")
+	}
+
+	for _, item := range response.PriceSet {
+		if item.ChargeType != "Dynamic" {
+			continue
+		}
+		hourly := item.ListPrice
+		if hourly <= 0 {
+			hourly = item.Price
+		}
+		if hourly <= 0 {
+			break
+		}
+		return &PricingData{
+			Provider:     "ucloud",
+			Region:       region,
+			ResourceType: resourceType,
+			Currency:     "CNY",
+			HourlyPrice:  hourly,
+			MonthlyPrice: hourly * 720, // 720 hours per month
+			Metadata: map[string]string{
+				"machine_type": spec.MachineType,
+				"vcpu":         strconv.Itoa(spec.CPU),
+				"memory":       fmt.Sprintf("%dMB", spec.MemoryMB),
+				"source":       "GetUHostInstancePrice",
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("hourly price not found in UCloud response")
+}
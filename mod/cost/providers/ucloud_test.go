@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+)
+
+func TestParseUCloudInstanceType(t *testing.T) {
+	tests := []struct {
+		instanceType string
+		machineType  string
+		cpu          int
+		memoryMB     int
+		wantErr      bool
+	}{
+		{"n-standard-2", "N", 2, 8192, false},
+		{"n-highcpu-4", "N", 4, 4096, false},
+		{"o-customized-4-16", "O", 4, 16384, false},
+		{"n-unknown-2", "", 0, 0, true},
+		{"n-standard", "", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.instanceType, func(t *testing.T) {
+			spec, err := parseUCloudInstanceType(tt.instanceType)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %s", tt.instanceType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseUCloudInstanceType failed: %v", err)
+			}
+			if spec.MachineType != tt.machineType || spec.CPU != tt.cpu || spec.MemoryMB != tt.memoryMB {
+				t.Errorf("Expected %s/%d/%d, got %+v", tt.machineType, tt.cpu, tt.memoryMB, spec)
+			}
+		})
+	}
+}
+
+func TestUCloudPricingFromPriceSet(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "ucloud_uhost_price.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var response uhost.GetUHostInstancePriceResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	spec, _ := parseUCloudInstanceType("n-standard-2")
+	pricing, err := ucloudPricingFromPriceSet(&response, "cn-bj2", "n-standard-2", spec)
+	if err != nil {
+		t.Fatalf("ucloudPricingFromPriceSet failed: %v", err)
+	}
+	// The list price wins over the discounted price
+	if pricing.HourlyPrice != 0.6 || pricing.Currency != "CNY" || pricing.Provider != "ucloud" {
+		t.Errorf("Expected ucloud 0.6 CNY, got %+v", pricing)
+	}
+
+	if _, err := ucloudPricingFromPriceSet(&uhost.GetUHostInstancePriceResponse{}, "cn-bj2", "n-standard-2", spec); err == nil {
+		t.Error("Expected error for empty price set")
+	}
+}
+
+func TestGetUCloudPricing_MissingCredentials(t *testing.T) {
+	if _, err := GetUCloudPricing("cn-bj2", "n-standard-2", "", "private"); err == nil {
+		t.Error("Expected error for missing public key, got nil")
+	}
+}
@@ -0,0 +1,113 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// vultrPlansURL lists all Vultr plans; the endpoint is public and needs no API key
+const vultrPlansURL = "https://api.vultr.com/v2/plans?type=all&per_page=500"
+
+// vultrPlan is a plan entry of the Vultr /v2/plans response
+type vultrPlan struct {
+	ID          string   `json:"id"`
+	VCPUCount   int      `json:"vcpu_count"`
+	RAM         int      `json:"ram"`
+	Disk        int      `json:"disk"`
+	Bandwidth   int      `json:"bandwidth"`
+	MonthlyCost float64  `json:"monthly_cost"`
+	HourlyCost  float64  `json:"hourly_cost"`
+	Type        string   `json:"type"`
+	Locations   []string `json:"locations"`
+}
+
+// GetVultrPricing retrieves pricing for a Vultr plan (e.g. "vc2-1c-1gb") using the plans API.
+// The API key is sent when present but is not required.
+func GetVultrPricing(region, resourceType, apiKey, _ string) (*PricingData, error) {
+	if resourceType == "" {
+		return nil, fmt.Errorf("resource type cannot be empty")
+	}
+
+	req, err := http.NewRequest("GET", vultrPlansURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Vultr plans API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vultr plans response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vultr plans API error: %s", string(body))
+	}
+
+	return vultrPricingFromPlans(body, region, resourceType)
+}
+
+// vultrPricingFromPlans extracts the price of a plan from a /v2/plans response body.
+// When region is set the plan must be offered in that location.
+func vultrPricingFromPlans(body []byte, region, resourceType string) (*PricingData, error) {
+	var result struct {
+		Plans []vultrPlan `json:"plans"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Vultr plans response: %w", err)
+	}
+
+	for _, plan := range result.Plans {
+		if plan.ID != resourceType {
+			continue
+		}
+		if region != "" && !containsString(plan.Locations, region) {
+			return nil, fmt.Errorf("vultr plan %s is not available in %s", resourceType, region)
+		}
+
+		hourly := plan.HourlyCost
+		if hourly <= 0 {
+			hourly = plan.MonthlyCost / 720
+		}
+		if hourly <= 0 {
+			return nil, fmt.Errorf("no price for vultr plan %s", resourceType)
+		}
+		return &PricingData{
+			Provider:     "vultr",
+			Region:       region,
+			ResourceType: resourceType,
+			Currency:     "USD",
+			HourlyPrice:  hourly,
+			MonthlyPrice: hourly * 720, // 720 hours per month
+			Metadata: map[string]string{
+				"vcpu":      strconv.Itoa(plan.VCPUCount),
+				"memory":    fmt.Sprintf("%dMB", plan.RAM),
+				"disk":      fmt.Sprintf("%dGB", plan.Disk),
+				"plan_type": plan.Type,
+				"source":    "plans",
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("vultr plan %s not found", resourceType)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
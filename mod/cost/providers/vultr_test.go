@@ -0,0 +1,50 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVultrPricingFromPlans(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "vultr_plans.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	pricing, err := vultrPricingFromPlans(body, "nrt", "vc2-2c-4gb")
+	if err != nil {
+		t.Fatalf("vultrPricingFromPlans failed: %v", err)
+	}
+	if pricing.Provider != "vultr" || pricing.Currency != "USD" {
+		t.Errorf("Expected vultr/USD, got %s/%s", pricing.Provider, pricing.Currency)
+	}
+	if pricing.HourlyPrice != 0.03 {
+		t.Errorf("Expected hourly price 0.03, got %f", pricing.HourlyPrice)
+	}
+	if pricing.Metadata["vcpu"] != "2" || pricing.Metadata["memory"] != "4096MB" {
+		t.Errorf("Unexpected metadata: %v", pricing.Metadata)
+	}
+
+	// Plans without an hourly price are derived from the monthly price
+	pricing, err = vultrPricingFromPlans(body, "", "vhp-1c-1gb-amd")
+	if err != nil {
+		t.Fatalf("vultrPricingFromPlans failed: %v", err)
+	}
+	if diff := pricing.HourlyPrice - 6.0/720; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected hourly price derived from monthly cost, got %f", pricing.HourlyPrice)
+	}
+
+	if _, err := vultrPricingFromPlans(body, "nrt", "vhp-1c-1gb-amd"); err == nil {
+		t.Error("Expected error for plan not offered in region")
+	}
+	if _, err := vultrPricingFromPlans(body, "", "vc2-unknown"); err == nil {
+		t.Error("Expected error for unknown plan")
+	}
+}
+
+func TestGetVultrPricing_EmptyResourceType(t *testing.T) {
+	if _, err := GetVultrPricing("ewr", "", "", ""); err == nil {
+		t.Error("Expected error for empty resource type, got nil")
+	}
+}
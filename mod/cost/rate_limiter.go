@@ -118,6 +118,10 @@ type ProviderRateLimiters struct {
 // - Tencent Cloud: 20 requests/second
 // - AWS: 10 requests/second
 // - Volcengine: 20 requests/second
+// - Huawei Cloud: 10 requests/second
+// - UCloud: 10 requests/second
+// - Vultr: 5 requests/second
+// - Google Cloud: 5 requests/second
 func NewProviderRateLimiters() *ProviderRateLimiters {
 	prl := &ProviderRateLimiters{
 		limiters: make(map[string]*RateLimiter),
@@ -139,6 +143,18 @@ func NewProviderRateLimiters() *ProviderRateLimiters {
 	// Use capacity of 20 to allow bursts
 	prl.limiters["volcengine"] = NewRateLimiter(20, 20.0)
 	
+	// Huawei Cloud: 10 requests/second (BSS rating API)
+	prl.limiters["huaweicloud"] = NewRateLimiter(10, 10.0)
+	
+	// UCloud: 10 requests/second
+	prl.limiters["ucloud"] = NewRateLimiter(10, 10.0)
+	
+	// Vultr: 5 requests/second (documented API limit is 30/sec, plans are fetched whole)
+	prl.limiters["vultr"] = NewRateLimiter(5, 5.0)
+	
+	// Google Cloud Billing Catalog: 5 requests/second, each SKU listing is several pages
+	prl.limiters["google"] = NewRateLimiter(5, 5.0)
+	
 	return prl
 }
 
@@ -0,0 +1,224 @@
+package cost
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// usageRule describes how a network, storage or DNS resource is priced.
+// The unit price comes from the bundled resource price table (providers.GetResourcePricing)
+// under Kind, optionally refined by a category ("disk.cloud_ssd").
+type usageRule struct {
+	Kind string
+	// SizeAttrs is the attribute holding the billed quantity (GB or Mbps); dotted paths
+	// address nested blocks. Without SizeAttrs the resource is billed per instance.
+	SizeAttrs   []string
+	DefaultSize float64
+	// CategoryAttrs selects the price category, e.g. the disk type
+	CategoryAttrs   []string
+	DefaultCategory string
+	// ChargeAttrs and BandwidthAttrs add a per-Mbps bandwidth fee (eip_bandwidth) to EIPs
+	// billed by bandwidth; EIPs billed by traffic only pay the instance fee
+	ChargeAttrs    []string
+	BandwidthAttrs []string
+	// Usage marks resources billed purely on usage (object storage): they cost nothing
+	// until data is stored, so the estimate only notes the unit price
+	Usage bool
+}
+
+// usageRules maps terraform resource types to their pricing rules
+var usageRules = map[string]usageRule{
+	// Alibaba Cloud
+	"alicloud_eip":                      {Kind: "eip", ChargeAttrs: []string{"internet_charge_type"}, BandwidthAttrs: []string{"bandwidth"}},
+	"alicloud_eip_address":              {Kind: "eip", ChargeAttrs: []string{"internet_charge_type"}, BandwidthAttrs: []string{"bandwidth"}},
+	"alicloud_common_bandwidth_package": {Kind: "bandwidth_package", SizeAttrs: []string{"bandwidth"}},
+	"alicloud_disk":                     {Kind: "disk", SizeAttrs: []string{"size"}, CategoryAttrs: []string{"category"}, DefaultCategory: "cloud_efficiency"},
+	"alicloud_ecs_disk":                 {Kind: "disk", SizeAttrs: []string{"size"}, CategoryAttrs: []string{"category"}, DefaultCategory: "cloud_efficiency"},
+	"alicloud_nat_gateway":              {Kind: "nat_gateway"},
+	"alicloud_slb":                      {Kind: "load_balancer"},
+	"alicloud_slb_load_balancer":        {Kind: "load_balancer"},
+	"alicloud_alb_load_balancer":        {Kind: "load_balancer"},
+	"alicloud_oss_bucket":               {Kind: "object_storage", Usage: true},
+	"alicloud_alidns_record":            {Kind: "dns_record"},
+	"alicloud_dns_record":               {Kind: "dns_record"},
+	// Tencent Cloud
+	"tencentcloud_eip":                   {Kind: "eip", ChargeAttrs: []string{"internet_charge_type"}, BandwidthAttrs: []string{"internet_max_bandwidth_out"}},
+	"tencentcloud_vpc_bandwidth_package": {Kind: "bandwidth_package", SizeAttrs: []string{"internet_max_bandwidth"}},
+	"tencentcloud_cbs_storage":           {Kind: "disk", SizeAttrs: []string{"storage_size"}, CategoryAttrs: []string{"storage_type"}, DefaultCategory: "CLOUD_PREMIUM"},
+	"tencentcloud_nat_gateway":           {Kind: "nat_gateway"},
+	"tencentcloud_clb_instance":          {Kind: "load_balancer"},
+	"tencentcloud_cos_bucket":            {Kind: "object_storage", Usage: true},
+	"tencentcloud_dnspod_record":         {Kind: "dns_record"},
+	// AWS
+	"aws_eip":            {Kind: "eip"},
+	"aws_ebs_volume":     {Kind: "disk", SizeAttrs: []string{"size"}, CategoryAttrs: []string{"type"}, DefaultCategory: "gp2"},
+	"aws_nat_gateway":    {Kind: "nat_gateway"},
+	"aws_lb":             {Kind: "load_balancer"},
+	"aws_alb":            {Kind: "load_balancer"},
+	"aws_elb":            {Kind: "load_balancer"},
+	"aws_s3_bucket":      {Kind: "object_storage", Usage: true},
+	"aws_route53_record": {Kind: "dns_record"},
+	"aws_route53_zone":   {Kind: "dns_zone"},
+	// Volcengine (billing_type 2 = PostPaidByBandwidth)
+	"volcengine_eip_address":       {Kind: "eip", ChargeAttrs: []string{"billing_type"}, BandwidthAttrs: []string{"bandwidth"}},
+	"volcengine_bandwidth_package": {Kind: "bandwidth_package", SizeAttrs: []string{"bandwidth"}},
+	"volcengine_volume":            {Kind: "disk", SizeAttrs: []string{"size"}, CategoryAttrs: []string{"volume_type"}, DefaultCategory: "ESSD_PL0"},
+	"volcengine_nat_gateway":       {Kind: "nat_gateway"},
+	"volcengine_clb":               {Kind: "load_balancer"},
+	"volcengine_tos_bucket":        {Kind: "object_storage", Usage: true},
+	// Huawei Cloud
+	"huaweicloud_vpc_eip":          {Kind: "eip", ChargeAttrs: []string{"bandwidth.charge_mode"}, BandwidthAttrs: []string{"bandwidth.size"}},
+	"huaweicloud_vpc_bandwidth":    {Kind: "bandwidth_package", SizeAttrs: []string{"size"}},
+	"huaweicloud_evs_volume":       {Kind: "disk", SizeAttrs: []string{"size"}, CategoryAttrs: []string{"volume_type"}, DefaultCategory: "SAS"},
+	"huaweicloud_nat_gateway":      {Kind: "nat_gateway"},
+	"huaweicloud_elb_loadbalancer": {Kind: "load_balancer"},
+	"huaweicloud_lb_loadbalancer":  {Kind: "load_balancer"},
+	"huaweicloud_obs_bucket":       {Kind: "object_storage", Usage: true},
+	"huaweicloud_dns_recordset":    {Kind: "dns_record"},
+	"huaweicloud_dns_zone":         {Kind: "dns_zone"},
+	// UCloud
+	"ucloud_eip":         {Kind: "eip", ChargeAttrs: []string{"charge_mode"}, BandwidthAttrs: []string{"bandwidth"}},
+	"ucloud_disk":        {Kind: "disk", SizeAttrs: []string{"disk_size"}, CategoryAttrs: []string{"disk_type"}, DefaultCategory: "data_disk"},
+	"ucloud_nat_gateway": {Kind: "nat_gateway"},
+	"ucloud_lb":          {Kind: "load_balancer"},
+	// Vultr
+	"vultr_reserved_ip":    {Kind: "eip"},
+	"vultr_block_storage":  {Kind: "disk", SizeAttrs: []string{"size_gb"}, CategoryAttrs: []string{"block_type"}, DefaultCategory: "high_perf"},
+	"vultr_load_balancer":  {Kind: "load_balancer"},
+	"vultr_object_storage": {Kind: "object_storage_subscription"},
+	"vultr_dns_record":     {Kind: "dns_record"},
+	// Google Cloud
+	"google_compute_address":                {Kind: "eip"},
+	"google_compute_disk":                   {Kind: "disk", SizeAttrs: []string{"size"}, DefaultSize: 10, CategoryAttrs: []string{"type"}, DefaultCategory: "pd-standard"},
+	"google_compute_router_nat":             {Kind: "nat_gateway"},
+	"google_compute_forwarding_rule":        {Kind: "load_balancer"},
+	"google_compute_global_forwarding_rule": {Kind: "load_balancer"},
+	"google_storage_bucket":                 {Kind: "object_storage", Usage: true},
+	"google_dns_record_set":                 {Kind: "dns_record"},
+	"google_dns_managed_zone":               {Kind: "dns_zone"},
+}
+
+// calculateUsageCost prices a network, storage or DNS resource with its usage rule
+func (cc *CostCalculator) calculateUsageCost(resource ResourceSpec, rule usageRule, pricingService *PricingService) ResourceCostBreakdown {
+	breakdown := ResourceCostBreakdown{
+		ResourceType: resource.Type,
+		ResourceName: resource.Name,
+		Provider:     resource.Provider,
+		Count:        resource.Count,
+	}
+
+	key := rule.Kind
+	if len(rule.CategoryAttrs) > 0 {
+		category, ok := usageStringAttr(resource.Attributes, rule.CategoryAttrs, rule.DefaultCategory)
+		if !ok {
+			return breakdown
+		}
+		key += "." + category
+	}
+
+	quantity := 1.0
+	if len(rule.SizeAttrs) > 0 {
+		size, ok := usageNumberAttr(resource.Attributes, rule.SizeAttrs, rule.DefaultSize)
+		if !ok || size <= 0 {
+			// Size unknown or unresolved - pricing unavailable
+			return breakdown
+		}
+		quantity = size
+	}
+
+	pricing, err := pricingService.GetPricing(resource.Provider, resource.Region, key)
+	if err != nil || pricing == nil {
+		return breakdown
+	}
+
+	breakdown.Available = true
+	breakdown.Currency = pricing.Currency
+	if rule.Usage {
+		breakdown.UsageNote = fmt.Sprintf("%s %g per GB-month of storage, billed by usage", pricing.Currency, pricing.MonthlyPrice)
+		return breakdown
+	}
+	breakdown.UnitHourly = pricing.HourlyPrice * quantity
+
+	// EIPs billed by bandwidth pay per Mbps on top of the instance fee
+	if charge, ok := usageStringAttr(resource.Attributes, rule.ChargeAttrs, ""); ok && isBandwidthCharge(charge) {
+		bandwidth, ok := usageNumberAttr(resource.Attributes, rule.BandwidthAttrs, 0)
+		if ok && bandwidth > 0 {
+			bwPricing, err := pricingService.GetPricing(resource.Provider, resource.Region, "eip_bandwidth")
+			if err == nil && bwPricing != nil {
+				breakdown.UnitHourly += bwPricing.HourlyPrice * bandwidth
+			}
+		}
+	}
+
+	breakdown.OnDemandHourly = breakdown.UnitHourly
+	breakdown.UnitMonthly = breakdown.UnitHourly * 720 // 720 hours per month
+	breakdown.TotalHourly = breakdown.UnitHourly * float64(resource.Count)
+	breakdown.TotalMonthly = breakdown.UnitMonthly * float64(resource.Count)
+	return breakdown
+}
+
+// isBandwidthCharge reports whether an EIP charge type bills by bandwidth:
+// PayByBandwidth, BANDWIDTH_POSTPAID_BY_HOUR, bandwidth, or Volcengine's billing_type 2
+func isBandwidthCharge(charge string) bool {
+	return strings.Contains(strings.ToLower(charge), "bandwidth") || charge == "2"
+}
+
+// usageAttr returns the first attribute present among paths; dotted paths walk nested blocks
+func usageAttr(attrs map[string]interface{}, paths []string) (interface{}, bool) {
+	for _, path := range paths {
+		var current interface{} = attrs
+		for _, part := range strings.Split(path, ".") {
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				current = nil
+				break
+			}
+			current = m[part]
+		}
+		if current != nil {
+			return current, true
+		}
+	}
+	return nil, false
+}
+
+// usageStringAttr reads a string attribute, using def when it is missing.
+// Returns false when the value is an unresolved expression.
+func usageStringAttr(attrs map[string]interface{}, paths []string, def string) (string, bool) {
+	value, ok := usageAttr(attrs, paths)
+	if !ok {
+		return def, def != ""
+	}
+	s := fmt.Sprintf("%v", value)
+	if isUnresolvedValue(s) {
+		return "", false
+	}
+	return s, s != ""
+}
+
+// usageNumberAttr reads a numeric attribute (int, float or numeric string), using def when it is missing
+func usageNumberAttr(attrs map[string]interface{}, paths []string, def float64) (float64, bool) {
+	value, ok := usageAttr(attrs, paths)
+	if !ok {
+		return def, def > 0
+	}
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// isUnresolvedValue reports whether an attribute still contains Terraform expressions
+func isUnresolvedValue(s string) bool {
+	return strings.Contains(s, "${") ||
+		strings.Contains(s, "data.") ||
+		strings.Contains(s, "local.") ||
+		strings.Contains(s, "module.")
+}
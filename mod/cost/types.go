@@ -1,5 +1,7 @@
 package cost
 
+import "time"
+
 // PricingData represents pricing information for a cloud resource
 type PricingData struct {
 	Provider     string            `json:"provider"`
@@ -10,6 +12,7 @@ type PricingData struct {
 	MonthlyPrice float64           `json:"monthly_price"`
 	PricingTiers []PricingTier     `json:"pricing_tiers,omitempty"`
 	Metadata     map[string]string `json:"metadata,omitempty"`
+	Spot         *SpotPricing      `json:"spot,omitempty"`
 }
 
 // PricingTier represents tiered pricing structure
@@ -18,3 +21,19 @@ type PricingTier struct {
 	MaxUnits     int     `json:"max_units"`
 	PricePerUnit float64 `json:"price_per_unit"`
 }
+
+// SpotPricing represents spot / preemptible pricing for an instance type
+type SpotPricing struct {
+	HourlyPrice    float64          `json:"hourly_price"`
+	MinHourlyPrice float64          `json:"min_hourly_price,omitempty"`
+	MaxHourlyPrice float64          `json:"max_hourly_price,omitempty"`
+	History        []SpotPricePoint `json:"history,omitempty"`
+	Source         string           `json:"source,omitempty"`
+}
+
+// SpotPricePoint is a single spot price observation
+type SpotPricePoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Zone      string    `json:"zone,omitempty"`
+	Price     float64   `json:"price"`
+}
@@ -0,0 +1,266 @@
+package mod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"red-cloud/mod/cost"
+	"red-cloud/mod/gologger"
+)
+
+// CurrencySettings 费用显示币种与汇率来源，按 profile 保存
+type CurrencySettings struct {
+	DisplayCurrency string             `json:"displayCurrency,omitempty"` // 为空时保留各云厂商的计价币种
+	RateSource      string             `json:"rateSource,omitempty"`      // default / static / ecb，为空使用内置汇率
+	RateFile        string             `json:"rateFile,omitempty"`        // static 来源的 JSON 汇率表
+	RateURL         string             `json:"rateUrl,omitempty"`         // ecb 来源的 XML 地址，为空使用 ECB 每日参考汇率
+	CacheHours      int                `json:"cacheHours,omitempty"`      // 汇率缓存时长，默认 24 小时
+	Overrides       map[string]float64 `json:"overrides,omitempty"`       // 手动汇率，例如 {"USD_CNY": 7.1}，优先于汇率来源
+}
+
+const defaultRateCacheHours = 24
+
+// Validate 检查币种、汇率来源与手动汇率
+func (s *CurrencySettings) Validate() error {
+	if s.DisplayCurrency != "" && !cost.IsCurrencyCode(s.DisplayCurrency) {
+		return fmt.Errorf("无效的显示币种: %s", s.DisplayCurrency)
+	}
+	switch s.RateSource {
+	case "", cost.RateSourceDefault, cost.RateSourceECB:
+	case cost.RateSourceStatic:
+		if s.RateFile == "" {
+			return fmt.Errorf("static 汇率来源需要指定汇率文件")
+		}
+	default:
+		return fmt.Errorf("不支持的汇率来源: %s（可选 default、static、ecb）", s.RateSource)
+	}
+	if s.CacheHours < 0 {
+		return fmt.Errorf("汇率缓存时长不能为负数")
+	}
+	for pair, rate := range s.Overrides {
+		from, to, ok := strings.Cut(pair, "_")
+		if !ok || !cost.IsCurrencyCode(from) || !cost.IsCurrencyCode(to) {
+			return fmt.Errorf("无效的汇率对 %q，格式为 USD_CNY", pair)
+		}
+		if rate <= 0 {
+			return fmt.Errorf("%s 汇率必须大于 0", pair)
+		}
+	}
+	return nil
+}
+
+// rateSource 根据设置创建带磁盘缓存的汇率来源，内置汇率返回 nil
+func (s *CurrencySettings) rateSource() cost.RateSource {
+	var src cost.RateSource
+	switch s.RateSource {
+	case cost.RateSourceStatic:
+		// 本地文件无需缓存
+		return &cost.StaticRateSource{Path: s.RateFile}
+	case cost.RateSourceECB:
+		src = &cost.ECBRateSource{URL: s.RateURL}
+	default:
+		return nil
+	}
+	hours := s.CacheHours
+	if hours == 0 {
+		hours = defaultRateCacheHours
+	}
+	return &cost.CachedRateSource{
+		Source:    src,
+		CachePath: filepath.Join(RedcPath, "exchange_rates", src.Name()+".json"),
+		TTL:       time.Duration(hours) * time.Hour,
+	}
+}
+
+// ActiveCurrencySettings 读取当前 profile 的费用币种设置，未设置时返回空设置
+// 不会创建默认 profile，命令行与测试中没有 profile 时使用内置汇率
+func ActiveCurrencySettings() CurrencySettings {
+	if RedcPath == "" {
+		return CurrencySettings{}
+	}
+	id, err := getActiveProfileID()
+	if err != nil || id == "" {
+		id = "default"
+	}
+	profile, err := readProfileFile(id)
+	if err != nil || profile.Currency == nil {
+		return CurrencySettings{}
+	}
+	return *profile.Currency
+}
+
+// DisplayCurrency 当前 profile 的费用显示币种，为空表示不换算
+func DisplayCurrency() string {
+	return strings.ToUpper(ActiveCurrencySettings().DisplayCurrency)
+}
+
+// UpdateProfileCurrency 更新 profile 的费用币种设置，settings 为 nil 时清除
+func UpdateProfileCurrency(id string, settings *CurrencySettings) error {
+	if settings != nil {
+		settings.DisplayCurrency = strings.ToUpper(settings.DisplayCurrency)
+		if err := settings.Validate(); err != nil {
+			return err
+		}
+	}
+	profile, err := readProfileFile(id)
+	if err != nil {
+		return err
+	}
+	_, err = writeProfileFile(id, profilePayload{
+		Name:        profile.Name,
+		ConfigPath:  profile.ConfigPath,
+		TemplateDir: profile.TemplateDir,
+		AIConfig:    profile.AIConfig,
+		Currency:    settings,
+	})
+	resetCostCurrencyConverter()
+	return err
+}
+
+var (
+	currencyConverterMu  sync.Mutex
+	currencyConverter    *cost.CurrencyConverter
+	currencyConverterKey string
+	currencyConverterAt  time.Time
+)
+
+// CostCurrencyConverter 按当前 profile 的汇率设置创建换算器：先加载汇率来源，再叠加手动汇率
+// 汇率来源不可用时退回内置汇率。换算器在一小时内或设置变化前复用
+func CostCurrencyConverter() *cost.CurrencyConverter {
+	settings := ActiveCurrencySettings()
+	key, _ := json.Marshal(settings)
+
+	currencyConverterMu.Lock()
+	defer currencyConverterMu.Unlock()
+	if currencyConverter != nil && currencyConverterKey == string(key) && time.Since(currencyConverterAt) < time.Hour {
+		return currencyConverter
+	}
+
+	converter := cost.NewCurrencyConverter()
+	if src := settings.rateSource(); src != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		loaded, err := cost.NewCurrencyConverterFromSource(ctx, src)
+		cancel()
+		if err != nil {
+			gologger.Warning().Msgf("加载 %s 汇率失败，使用内置汇率: %v", src.Name(), err)
+		} else {
+			converter = loaded
+		}
+	}
+	pairs := make([]string, 0, len(settings.Overrides))
+	for pair := range settings.Overrides {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+	for _, pair := range pairs {
+		from, to, _ := strings.Cut(pair, "_")
+		if err := converter.SetRate(cost.Currency(from), cost.Currency(to), settings.Overrides[pair]); err != nil {
+			gologger.Warning().Msgf("忽略手动汇率 %s: %v", pair, err)
+		}
+	}
+
+	currencyConverter, currencyConverterKey, currencyConverterAt = converter, string(key), time.Now()
+	return converter
+}
+
+func resetCostCurrencyConverter() {
+	currencyConverterMu.Lock()
+	currencyConverter = nil
+	currencyConverterMu.Unlock()
+}
+
+// NormalizeCostEstimate 将费用估算换算到显示币种，未设置显示币种或换算失败时原样返回
+func NormalizeCostEstimate(estimate *cost.CostEstimate) *cost.CostEstimate {
+	target := DisplayCurrency()
+	if estimate == nil || target == "" {
+		return estimate
+	}
+	converted, err := CostCurrencyConverter().ConvertCostEstimate(estimate, cost.Currency(target))
+	if err != nil {
+		gologger.Debug().Msgf("convert cost estimate to %s: %v", target, err)
+		return estimate
+	}
+	return converted
+}
+
+// NormalizeCostDiff 将 plan 费用变化换算到显示币种，未设置显示币种或换算失败时原样返回
+func NormalizeCostDiff(diff *cost.CostDiff) *cost.CostDiff {
+	target := DisplayCurrency()
+	if diff == nil || target == "" || diff.Currency == target && len(diff.Changes) == 0 {
+		return diff
+	}
+	converted, err := CostCurrencyConverter().ConvertCostDiff(diff, cost.Currency(target))
+	if err != nil {
+		gologger.Debug().Msgf("convert cost diff to %s: %v", target, err)
+		return diff
+	}
+	return converted
+}
+
+// currencyNormalizer 将金额换算到目标币种，并记录用到的汇率与缺少汇率的币种
+type currencyNormalizer struct {
+	converter *cost.CurrencyConverter
+	target    string
+	rates     []cost.ExchangeRate
+	missing   []string
+}
+
+func newCurrencyNormalizer(target string) *currencyNormalizer {
+	return &currencyNormalizer{converter: CostCurrencyConverter(), target: strings.ToUpper(target)}
+}
+
+// convert 换算金额并返回使用的汇率，币种相同时原样返回、汇率为 nil。
+// 没有汇率时返回错误并记录该币种，调用方应将金额排除在合计之外并标记报表不完整
+func (n *currencyNormalizer) convert(amount float64, from string) (float64, *cost.ExchangeRate, error) {
+	from = strings.ToUpper(from)
+	if from == "" || n.target == "" || from == n.target {
+		return amount, nil, nil
+	}
+	rate, err := n.converter.Rate(cost.Currency(from), cost.Currency(n.target))
+	if err != nil {
+		if !slices.Contains(n.missing, from) {
+			n.missing = append(n.missing, from)
+		}
+		return 0, nil, fmt.Errorf("no exchange rate from %s to %s: %w", from, n.target, err)
+	}
+	recorded := false
+	for _, r := range n.rates {
+		if r.From == rate.From {
+			recorded = true
+			break
+		}
+	}
+	if !recorded {
+		n.rates = append(n.rates, rate)
+	}
+	return amount * rate.Rate, &rate, nil
+}
+
+// ExchangeRatesTo 列出各支持币种到 target 的汇率，target 为空时使用显示币种，均未设置时使用 USD
+func ExchangeRatesTo(target string) (string, []cost.ExchangeRate) {
+	target = strings.ToUpper(target)
+	if target == "" {
+		target = DisplayCurrency()
+	}
+	if target == "" {
+		target = string(cost.CurrencyUSD)
+	}
+	converter := CostCurrencyConverter()
+	var rates []cost.ExchangeRate
+	for _, currency := range converter.GetSupportedCurrencies() {
+		if string(currency) == target {
+			continue
+		}
+		if rate, err := converter.Rate(currency, cost.Currency(target)); err == nil {
+			rates = append(rates, rate)
+		}
+	}
+	return target, rates
+}
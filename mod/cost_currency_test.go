@@ -0,0 +1,65 @@
+package mod
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"red-cloud/mod/cost"
+)
+
+func TestDisplayCurrency_LedgerReport(t *testing.T) {
+	oldPath := RedcPath
+	RedcPath = t.TempDir()
+	t.Cleanup(func() {
+		RedcPath = oldPath
+		resetCostCurrencyConverter()
+	})
+
+	if _, err := ensureProfilesDir(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeProfileFile("default", profilePayload{Name: "default"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateProfileCurrency("default", &CurrencySettings{RateSource: "bogus"}); err == nil {
+		t.Error("expected error for unknown rate source")
+	}
+	if err := UpdateProfileCurrency("default", &CurrencySettings{
+		DisplayCurrency: "usd",
+		Overrides:       map[string]float64{"USD_CNY": 8},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := DisplayCurrency(); got != "USD" {
+		t.Fatalf("DisplayCurrency = %q, want USD", got)
+	}
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	intervals := []LedgerInterval{
+		{CaseID: "a", StartedAt: start, EndedAt: start.Add(2 * time.Hour), HourlyCost: 8, Currency: "CNY", Priced: true},
+		{CaseID: "b", StartedAt: start, EndedAt: start.Add(time.Hour), HourlyCost: 1, Currency: "USD", Priced: true},
+	}
+	report := BuildLedgerReport(intervals, time.Time{}, time.Time{}, start, "")
+	if report.Currency != "USD" || math.Abs(report.TotalCost-3) > 1e-9 {
+		t.Errorf("report = %s %v, want USD 3", report.Currency, report.TotalCost)
+	}
+	if len(report.Rates) != 1 || report.Rates[0].From != cost.CurrencyCNY || report.Rates[0].Source != cost.RateSourceManual {
+		t.Errorf("rates = %+v, want the manual CNY->USD rate", report.Rates)
+	}
+
+	// An amount without a rate is left out of the total and reported
+	intervals = append(intervals, LedgerInterval{CaseID: "c", StartedAt: start, EndedAt: start.Add(time.Hour), HourlyCost: 5, Currency: "XYZ", Priced: true})
+	report = BuildLedgerReport(intervals, time.Time{}, time.Time{}, start, "")
+	if math.Abs(report.TotalCost-3) > 1e-9 || len(report.MissingRates) != 1 || report.MissingRates[0] != "XYZ" {
+		t.Errorf("report = %v missing %v, want 3 without XYZ", report.TotalCost, report.MissingRates)
+	}
+
+	estimate := NormalizeCostEstimate(&cost.CostEstimate{
+		Currency:  "CNY",
+		Breakdown: []cost.ResourceCostBreakdown{{Provider: "alicloud", Count: 1, TotalHourly: 8, Currency: "CNY", Available: true}},
+	})
+	if estimate.Currency != "USD" || math.Abs(estimate.TotalHourlyCost-1) > 1e-9 || estimate.Breakdown[0].ExchangeRate == nil {
+		t.Errorf("estimate = %+v", estimate)
+	}
+}
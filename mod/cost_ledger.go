@@ -0,0 +1,565 @@
+package mod
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"red-cloud/mod/cost"
+	"red-cloud/mod/gologger"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// CostLedgerFile 费用台账数据库文件名（位于 RedcPath 下）
+const CostLedgerFile = "cost_ledger.db"
+
+const createLedgerTableSQL = `
+	CREATE TABLE IF NOT EXISTS cost_ledger (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project_id TEXT NOT NULL,
+		case_id TEXT NOT NULL,
+		case_name TEXT NOT NULL,
+		template TEXT DEFAULT '',
+		started_at DATETIME NOT NULL,
+		ended_at DATETIME,
+		hourly_cost REAL DEFAULT 0,
+		currency TEXT DEFAULT '',
+		priced INTEGER DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_ledger_project ON cost_ledger(project_id);
+	CREATE INDEX IF NOT EXISTS idx_ledger_case ON cost_ledger(case_id);
+	CREATE TABLE IF NOT EXISTS cost_ledger_resources (
+		case_id TEXT NOT NULL,
+		project_id TEXT NOT NULL,
+		case_name TEXT NOT NULL,
+		resource_id TEXT NOT NULL,
+		resource_type TEXT DEFAULT '',
+		provider TEXT DEFAULT '',
+		seen_at DATETIME NOT NULL,
+		PRIMARY KEY (case_id, resource_id)
+	);
+	`
+
+// LedgerPricer 返回场景当前的小时价格与币种
+type LedgerPricer func(c *Case) (float64, string, error)
+
+var (
+	ledgerMu      sync.Mutex
+	ledgerEnabled bool
+	ledgerPricer  LedgerPricer
+)
+
+// EnableCostLedger 开启费用台账：场景进入 / 离开运行状态时记录区间
+// pricer 用于在区间开始时记录当时的小时价格，可以为 nil（之后由 Reconcile 补全）
+func EnableCostLedger(pricer LedgerPricer) {
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+	ledgerEnabled = true
+	ledgerPricer = pricer
+}
+
+func costLedgerPricer() (LedgerPricer, bool) {
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+	return ledgerPricer, ledgerEnabled
+}
+
+// LedgerInterval 场景的一段运行区间，EndedAt 为零值表示仍在运行
+type LedgerInterval struct {
+	ID         int64     `json:"id"`
+	ProjectID  string    `json:"project"`
+	CaseID     string    `json:"caseId"`
+	CaseName   string    `json:"caseName"`
+	Template   string    `json:"template,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	EndedAt    time.Time `json:"endedAt,omitempty"`
+	HourlyCost float64   `json:"hourlyCost"`
+	Currency   string    `json:"currency,omitempty"`
+	Priced     bool      `json:"priced"`
+}
+
+// Open 区间是否仍在计费
+func (iv LedgerInterval) Open() bool {
+	return iv.EndedAt.IsZero()
+}
+
+// CostLedger 持久化的场景费用台账，场景删除后记录仍然保留
+type CostLedger struct {
+	mu sync.Mutex
+	db *sql.DB
+}
+
+// NewCostLedger 打开 RedcPath 下的费用台账
+func NewCostLedger() (*CostLedger, error) {
+	if err := ensureRedcPath(); err != nil {
+		return nil, err
+	}
+	return OpenCostLedger(filepath.Join(RedcPath, CostLedgerFile))
+}
+
+// OpenCostLedger 打开指定路径的费用台账
+func OpenCostLedger(path string) (*CostLedger, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("打开费用台账失败: %v", err)
+	}
+	if _, err := db.Exec(createLedgerTableSQL); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("创建费用台账表失败: %v", err)
+	}
+	return &CostLedger{db: db}, nil
+}
+
+// Close 关闭数据库连接
+func (l *CostLedger) Close() {
+	if l.db != nil {
+		l.db.Close()
+	}
+}
+
+// Start 为场景开始一段运行区间；已有未结束的区间时直接返回该区间
+func (l *CostLedger) Start(c *Case, at time.Time) (*LedgerInterval, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	open, err := l.query(`WHERE case_id = ? AND ended_at IS NULL`, c.Id)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) > 0 {
+		return &open[0], nil
+	}
+
+	iv := LedgerInterval{
+		ProjectID: c.ProjectID,
+		CaseID:    c.Id,
+		CaseName:  c.Name,
+		Template:  c.Type,
+		StartedAt: at,
+	}
+	res, err := l.db.Exec(`
+		INSERT INTO cost_ledger (project_id, case_id, case_name, template, started_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, iv.ProjectID, iv.CaseID, iv.CaseName, iv.Template, formatLedgerTime(at))
+	if err != nil {
+		return nil, err
+	}
+	iv.ID, _ = res.LastInsertId()
+	return &iv, nil
+}
+
+// Stop 结束场景当前的运行区间，没有未结束的区间时返回 nil
+func (l *CostLedger) Stop(caseID string, at time.Time) (*LedgerInterval, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	open, err := l.query(`WHERE case_id = ? AND ended_at IS NULL`, caseID)
+	if err != nil || len(open) == 0 {
+		return nil, err
+	}
+	iv := open[0]
+	if at.Before(iv.StartedAt) {
+		at = iv.StartedAt
+	}
+	if _, err := l.db.Exec(`UPDATE cost_ledger SET ended_at = ? WHERE case_id = ? AND ended_at IS NULL`,
+		formatLedgerTime(at), caseID); err != nil {
+		return nil, err
+	}
+	iv.EndedAt = at
+	return &iv, nil
+}
+
+// SetPrice 记录区间的小时价格
+func (l *CostLedger) SetPrice(id int64, hourly float64, currency string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := l.db.Exec(`UPDATE cost_ledger SET hourly_cost = ?, currency = ?, priced = 1 WHERE id = ?`,
+		hourly, currency, id)
+	return err
+}
+
+// Intervals 返回与 [from, to) 有交集的区间；projectID 为空表示所有项目，from / to 为零值表示不限制
+func (l *CostLedger) Intervals(projectID string, from, to time.Time) ([]LedgerInterval, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var all []LedgerInterval
+	var err error
+	if projectID == "" {
+		all, err = l.query(`ORDER BY started_at`)
+	} else {
+		all, err = l.query(`WHERE project_id = ? ORDER BY started_at`, projectID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []LedgerInterval
+	for _, iv := range all {
+		if !to.IsZero() && !iv.StartedAt.Before(to) {
+			continue
+		}
+		if !from.IsZero() && !iv.Open() && !iv.EndedAt.After(from) {
+			continue
+		}
+		result = append(result, iv)
+	}
+	return result, nil
+}
+
+// Reconcile 用场景当前状态校正台账：补记未被记录的运行区间、结束已停止或已删除场景的区间，
+// 并为缺少价格的运行中区间补充价格。用于台账开启前已在运行的场景，以及未开启台账的进程修改的状态
+func (l *CostLedger) Reconcile(projectID string, cases []*Case, now time.Time, pricer LedgerPricer) error {
+	l.mu.Lock()
+	open, err := l.query(`WHERE project_id = ? AND ended_at IS NULL`, projectID)
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	openByCase := make(map[string]LedgerInterval, len(open))
+	for _, iv := range open {
+		openByCase[iv.CaseID] = iv
+	}
+
+	seen := make(map[string]bool, len(cases))
+	for _, c := range cases {
+		seen[c.Id] = true
+		iv, hasOpen := openByCase[c.Id]
+		switch {
+		case IsRunningState(c.State) && !hasOpen:
+			started, err := l.Start(c, caseStateTime(c, now))
+			if err != nil {
+				return err
+			}
+			l.price(started, c, pricer)
+			l.recordResources(c, now)
+		case IsRunningState(c.State) && !iv.Priced:
+			l.price(&iv, c, pricer)
+		case !IsRunningState(c.State) && hasOpen:
+			if _, err := l.Stop(c.Id, caseStateTime(c, now)); err != nil {
+				return err
+			}
+		}
+	}
+	// 场景已被删除：在本次校正时结束区间
+	for id := range openByCase {
+		if !seen[id] {
+			if _, err := l.Stop(id, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// price 为尚未定价的区间记录价格，失败时保持未定价
+func (l *CostLedger) price(iv *LedgerInterval, c *Case, pricer LedgerPricer) {
+	if iv == nil || iv.Priced || pricer == nil {
+		return
+	}
+	hourly, currency, err := pricer(c)
+	if err != nil {
+		gologger.Debug().Msgf("cost ledger: case %s hourly cost unavailable: %v", c.Name, err)
+		return
+	}
+	if err := l.SetPrice(iv.ID, hourly, currency); err != nil {
+		gologger.Debug().Msgf("cost ledger: save price for %s: %v", c.Name, err)
+		return
+	}
+	iv.HourlyCost, iv.Currency, iv.Priced = hourly, currency, true
+}
+
+// LedgerResource 台账中记录的场景云资源，用于把账单明细归属到场景（场景销毁后仍然保留）
+type LedgerResource struct {
+	CaseID     string    `json:"caseId"`
+	ProjectID  string    `json:"project"`
+	CaseName   string    `json:"caseName"`
+	ResourceID string    `json:"resourceId"`
+	Type       string    `json:"type,omitempty"`
+	Provider   string    `json:"provider,omitempty"`
+	SeenAt     time.Time `json:"seenAt"`
+}
+
+// RecordResources 记录场景当前拥有的云资源
+func (l *CostLedger) RecordResources(c *Case, resources []CaseResource, at time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, r := range resources {
+		if _, err := l.db.Exec(`
+			INSERT OR REPLACE INTO cost_ledger_resources
+			(case_id, project_id, case_name, resource_id, resource_type, provider, seen_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, c.Id, c.ProjectID, c.Name, r.ID, r.Type, r.Provider, formatLedgerTime(at)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resources 返回台账中记录过的所有场景资源
+func (l *CostLedger) Resources() ([]LedgerResource, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rows, err := l.db.Query(`
+		SELECT case_id, project_id, case_name, resource_id, COALESCE(resource_type, ''), COALESCE(provider, ''), seen_at
+		FROM cost_ledger_resources ORDER BY seen_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []LedgerResource
+	for rows.Next() {
+		var r LedgerResource
+		var seenAt string
+		if err := rows.Scan(&r.CaseID, &r.ProjectID, &r.CaseName, &r.ResourceID, &r.Type, &r.Provider, &seenAt); err != nil {
+			continue
+		}
+		r.SeenAt, _ = time.Parse(time.RFC3339, seenAt)
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// recordResources 从 terraform state 读取场景资源并写入台账，失败时只记录日志
+func (l *CostLedger) recordResources(c *Case, at time.Time) {
+	resources, err := CaseResources(c.Path)
+	if err != nil {
+		gologger.Debug().Msgf("cost ledger: read resources of %s: %v", c.Name, err)
+		return
+	}
+	if err := l.RecordResources(c, resources, at); err != nil {
+		gologger.Debug().Msgf("cost ledger: save resources of %s: %v", c.Name, err)
+	}
+}
+
+// query 按条件读取区间，调用方需持有 l.mu
+func (l *CostLedger) query(where string, args ...interface{}) ([]LedgerInterval, error) {
+	rows, err := l.db.Query(`
+		SELECT id, project_id, case_id, case_name, COALESCE(template, ''), started_at, ended_at,
+		       COALESCE(hourly_cost, 0), COALESCE(currency, ''), COALESCE(priced, 0)
+		FROM cost_ledger `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []LedgerInterval
+	for rows.Next() {
+		var iv LedgerInterval
+		var startedAt string
+		var endedAt sql.NullString
+		var priced int
+		if err := rows.Scan(&iv.ID, &iv.ProjectID, &iv.CaseID, &iv.CaseName, &iv.Template, &startedAt, &endedAt,
+			&iv.HourlyCost, &iv.Currency, &priced); err != nil {
+			continue
+		}
+		iv.StartedAt, _ = time.Parse(time.RFC3339, startedAt)
+		if endedAt.Valid && endedAt.String != "" {
+			iv.EndedAt, _ = time.Parse(time.RFC3339, endedAt.String)
+		}
+		iv.Priced = priced != 0
+		result = append(result, iv)
+	}
+	return result, rows.Err()
+}
+
+func formatLedgerTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// caseStateTime 返回场景最近一次状态变化的时间，无法解析时返回 fallback
+func caseStateTime(c *Case, fallback time.Time) time.Time {
+	if t, err := time.Parse(time.RFC3339, c.StateTime); err == nil {
+		return t
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", c.StateTime, time.Local); err == nil {
+		return t
+	}
+	return fallback
+}
+
+// recordCostLedger 在场景状态变化时开始 / 结束运行区间
+func recordCostLedger(c *Case, prev string) {
+	pricer, enabled := costLedgerPricer()
+	if !enabled || c.ProjectID == "" || IsRunningState(prev) == IsRunningState(c.State) {
+		return
+	}
+	l, err := NewCostLedger()
+	if err != nil {
+		gologger.Debug().Msgf("cost ledger: %v", err)
+		return
+	}
+	defer l.Close()
+
+	now := time.Now()
+	var iv *LedgerInterval
+	if IsRunningState(c.State) {
+		iv, err = l.Start(c, now)
+	} else {
+		// 停止 / 销毁前 terraform state 仍然存在，此时补记价格
+		iv, err = l.Stop(c.Id, now)
+	}
+	if err != nil {
+		gologger.Debug().Msgf("cost ledger: case %s: %v", c.Name, err)
+		return
+	}
+	l.price(iv, c, pricer)
+	l.recordResources(c, now)
+}
+
+// ReconcileCostLedger 用项目当前的场景状态校正费用台账（台账未开启时不做任何事）
+func ReconcileCostLedger(projectID string) error {
+	pricer, enabled := costLedgerPricer()
+	if !enabled {
+		return nil
+	}
+	cases, err := LoadProjectCases(projectID)
+	if err != nil {
+		return err
+	}
+	l, err := NewCostLedger()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	return l.Reconcile(projectID, cases, time.Now(), pricer)
+}
+
+// LedgerCaseCost 费用报表中单个场景的汇总
+type LedgerCaseCost struct {
+	ProjectID     string  `json:"project"`
+	CaseID        string  `json:"caseId"`
+	CaseName      string  `json:"caseName"`
+	Template      string  `json:"template,omitempty"`
+	Intervals     int     `json:"intervals"`
+	Hours         float64 `json:"hours"`
+	UnpricedHours float64 `json:"unpricedHours,omitempty"` // 没有价格记录的运行时长
+	Cost          float64 `json:"cost"`
+	Running       bool    `json:"running"`
+}
+
+// LedgerReport 某个时间窗口内的实际花费
+type LedgerReport struct {
+	From          *time.Time       `json:"from,omitempty"` // nil 表示不限制
+	To            *time.Time       `json:"to,omitempty"`
+	Currency      string           `json:"currency"`
+	TotalHours    float64          `json:"totalHours"`
+	TotalCost     float64          `json:"totalCost"`
+	UnpricedHours float64          `json:"unpricedHours,omitempty"`
+	Cases         []LedgerCaseCost `json:"cases"`
+	// Rates 换算到 Currency 时使用的汇率
+	Rates []cost.ExchangeRate `json:"rates,omitempty"`
+	// MissingRates 没有汇率、未计入费用的币种，非空时合计不完整
+	MissingRates []string `json:"missingRates,omitempty"`
+}
+
+// BuildLedgerReport 将区间裁剪到 [from, to) 后按场景汇总，未结束的区间计到 now
+// currency 为空时使用 profile 的显示币种，未设置则使用第一个已定价区间的币种，其余币种按汇率换算
+func BuildLedgerReport(intervals []LedgerInterval, from, to, now time.Time, currency string) *LedgerReport {
+	if currency == "" {
+		currency = DisplayCurrency()
+	}
+	if currency == "" {
+		for _, iv := range intervals {
+			if iv.Priced && iv.Currency != "" {
+				currency = iv.Currency
+				break
+			}
+		}
+		if currency == "" {
+			currency = string(cost.CurrencyCNY)
+		}
+	}
+	currency = strings.ToUpper(currency)
+
+	report := &LedgerReport{Currency: currency, Cases: []LedgerCaseCost{}}
+	if !from.IsZero() {
+		report.From = &from
+	}
+	if !to.IsZero() {
+		report.To = &to
+	}
+	normalizer := newCurrencyNormalizer(currency)
+	index := make(map[string]int)
+	for _, iv := range intervals {
+		start, end := iv.StartedAt, iv.EndedAt
+		if iv.Open() {
+			end = now
+		}
+		if !from.IsZero() && start.Before(from) {
+			start = from
+		}
+		if !to.IsZero() && end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+		hours := end.Sub(start).Hours()
+
+		i, ok := index[iv.CaseID]
+		if !ok {
+			report.Cases = append(report.Cases, LedgerCaseCost{
+				ProjectID: iv.ProjectID,
+				CaseID:    iv.CaseID,
+				CaseName:  iv.CaseName,
+				Template:  iv.Template,
+			})
+			i = len(report.Cases) - 1
+			index[iv.CaseID] = i
+		}
+		row := &report.Cases[i]
+		row.Intervals++
+		row.Hours += hours
+		row.Running = row.Running || iv.Open()
+		report.TotalHours += hours
+		if !iv.Priced {
+			row.UnpricedHours += hours
+			report.UnpricedHours += hours
+			continue
+		}
+
+		hourly, _, err := normalizer.convert(iv.HourlyCost, iv.Currency)
+		if err != nil {
+			continue
+		}
+		row.Cost += hourly * hours
+		report.TotalCost += hourly * hours
+	}
+
+	report.Rates, report.MissingRates = normalizer.rates, normalizer.missing
+	sort.SliceStable(report.Cases, func(i, j int) bool {
+		return report.Cases[i].Cost > report.Cases[j].Cost
+	})
+	return report
+}
+
+// ParseLedgerTime 解析报表时间：RFC3339、2006-01-02 或 2006-01
+// end 为 true 时日期 / 月份表示该日 / 该月结束（即下一日 / 下一月的开始）
+func ParseLedgerTime(s string, end bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01", s, time.Local); err == nil {
+		if end {
+			t = t.AddDate(0, 1, 0)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间 %q，支持 2006-01-02、2006-01 或 RFC3339", s)
+}
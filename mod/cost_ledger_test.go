@@ -0,0 +1,150 @@
+package mod
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestCostLedger_StartStop(t *testing.T) {
+	l, err := OpenCostLedger(filepath.Join(t.TempDir(), CostLedgerFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	base := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	c := &Case{Id: "c1", Name: "web", Type: "aliyun/ecs", ProjectID: "x"}
+
+	first, err := l.Start(c, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Starting an already running case keeps the open interval
+	again, _ := l.Start(c, base.Add(time.Hour))
+	if again.ID != first.ID || !again.StartedAt.Equal(base) {
+		t.Errorf("second Start = %+v, want interval %d", again, first.ID)
+	}
+	l.SetPrice(first.ID, 2, "CNY")
+
+	stopped, err := l.Stop("c1", base.Add(3*time.Hour))
+	if err != nil || stopped == nil || !stopped.Priced || stopped.HourlyCost != 2 {
+		t.Fatalf("Stop = %+v, %v", stopped, err)
+	}
+	if iv, _ := l.Stop("c1", base.Add(4*time.Hour)); iv != nil {
+		t.Errorf("Stop without open interval = %+v, want nil", iv)
+	}
+
+	l.Start(c, base.Add(24*time.Hour))
+	all, _ := l.Intervals("x", time.Time{}, time.Time{})
+	if len(all) != 2 || all[0].Open() || !all[1].Open() {
+		t.Fatalf("Intervals = %+v", all)
+	}
+	// Window after the first interval only returns the open one
+	window, _ := l.Intervals("x", base.Add(4*time.Hour), time.Time{})
+	if len(window) != 1 || !window[0].Open() {
+		t.Errorf("windowed Intervals = %+v", window)
+	}
+	if other, _ := l.Intervals("y", time.Time{}, time.Time{}); len(other) != 0 {
+		t.Errorf("other project Intervals = %+v", other)
+	}
+
+	// Resources are kept per case and deduplicated by ID
+	l.RecordResources(c, []CaseResource{{ID: "i-1", Type: "alicloud_instance"}}, base)
+	l.RecordResources(c, []CaseResource{{ID: "i-1", Type: "alicloud_instance"}, {ID: "d-1", Type: "alicloud_disk"}}, base.Add(time.Hour))
+	if res, err := l.Resources(); err != nil || len(res) != 2 || res[0].CaseName != "web" {
+		t.Errorf("Resources = %+v, %v", res, err)
+	}
+}
+
+func TestCostLedger_Reconcile(t *testing.T) {
+	l, err := OpenCostLedger(filepath.Join(t.TempDir(), CostLedgerFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	running := &Case{Id: "a", Name: "web", State: StateRunning, ProjectID: "x", StateTime: now.Add(-2 * time.Hour).Format(time.RFC3339)}
+	stopped := &Case{Id: "b", Name: "db", State: StateStopped, ProjectID: "x", StateTime: now.Add(-time.Hour).Format(time.RFC3339)}
+	l.Start(stopped, now.Add(-5*time.Hour))
+	l.Start(&Case{Id: "gone", Name: "old", ProjectID: "x"}, now.Add(-3*time.Hour))
+
+	pricer := func(c *Case) (float64, string, error) { return 1.5, "USD", nil }
+	if err := l.Reconcile("x", []*Case{running, stopped}, now, pricer); err != nil {
+		t.Fatal(err)
+	}
+
+	byCase := map[string]LedgerInterval{}
+	all, _ := l.Intervals("x", time.Time{}, time.Time{})
+	for _, iv := range all {
+		byCase[iv.CaseID] = iv
+	}
+	if iv := byCase["a"]; !iv.Open() || !iv.StartedAt.Equal(now.Add(-2*time.Hour)) || !iv.Priced || iv.Currency != "USD" {
+		t.Errorf("running case interval = %+v", iv)
+	}
+	if iv := byCase["b"]; iv.Open() || !iv.EndedAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("stopped case interval = %+v", iv)
+	}
+	if iv := byCase["gone"]; iv.Open() || !iv.EndedAt.Equal(now) {
+		t.Errorf("deleted case interval = %+v", iv)
+	}
+}
+
+func TestBuildLedgerReport(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	intervals := []LedgerInterval{
+		// Crosses the start of the window: only 2h counted
+		{CaseID: "a", CaseName: "web", StartedAt: time.Date(2026, 2, 28, 22, 0, 0, 0, time.UTC), EndedAt: day(1, 2), HourlyCost: 1, Currency: "CNY", Priced: true},
+		{CaseID: "a", CaseName: "web", StartedAt: day(5, 0), EndedAt: day(5, 10), HourlyCost: 1, Currency: "CNY", Priced: true},
+		// USD price converted to CNY
+		{CaseID: "b", CaseName: "c2", StartedAt: day(10, 0), EndedAt: day(10, 1), HourlyCost: 1, Currency: "USD", Priced: true},
+		// Still running: counted until now
+		{CaseID: "c", CaseName: "proxy", StartedAt: day(20, 0)},
+	}
+	from := day(1, 0)
+	to := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	report := BuildLedgerReport(intervals, from, to, day(20, 4), "")
+
+	if report.Currency != "CNY" || len(report.Cases) != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	if c := report.Cases[0]; c.CaseID != "a" || c.Intervals != 2 || !approx(c.Hours, 12) || !approx(c.Cost, 12) {
+		t.Errorf("case a = %+v", c)
+	}
+	if c := report.Cases[1]; c.CaseID != "b" || !approx(c.Cost, 7.2) {
+		t.Errorf("case b = %+v", c)
+	}
+	if c := report.Cases[2]; c.CaseID != "c" || !c.Running || !approx(c.UnpricedHours, 4) || c.Cost != 0 {
+		t.Errorf("case c = %+v", c)
+	}
+	if !approx(report.TotalHours, 17) || !approx(report.TotalCost, 19.2) || !approx(report.UnpricedHours, 4) {
+		t.Errorf("totals = %+v", report)
+	}
+}
+
+func TestParseLedgerTime(t *testing.T) {
+	march, err := ParseLedgerTime("2026-03", false)
+	if err != nil || march.Day() != 1 || march.Month() != time.March {
+		t.Errorf("ParseLedgerTime(month) = %v, %v", march, err)
+	}
+	end, _ := ParseLedgerTime("2026-03", true)
+	if end.Month() != time.April || end.Day() != 1 {
+		t.Errorf("ParseLedgerTime(month, end) = %v", end)
+	}
+	dayEnd, _ := ParseLedgerTime("2026-03-31", true)
+	if dayEnd.Month() != time.April || dayEnd.Day() != 1 {
+		t.Errorf("ParseLedgerTime(day, end) = %v", dayEnd)
+	}
+	if zero, err := ParseLedgerTime("", true); err != nil || !zero.IsZero() {
+		t.Errorf("ParseLedgerTime(empty) = %v, %v", zero, err)
+	}
+	if _, err := ParseLedgerTime("march", false); err == nil {
+		t.Error("ParseLedgerTime should reject free text")
+	}
+}
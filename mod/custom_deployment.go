@@ -106,6 +106,7 @@ func (s *CustomDeploymentService) EstimateCost(config *DeploymentConfig, pricing
 	if err != nil {
 		return nil, fmt.Errorf("计算成本失败: %w", err)
 	}
+	estimate = NormalizeCostEstimate(estimate)
 
 	// 7. 转换为自定义部署的成本估算格式
 	result := &CostEstimate{
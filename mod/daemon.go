@@ -0,0 +1,137 @@
+package mod
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DaemonState daemon 运行状态，写入 RedcPath/daemon.json 供 CLI 查询
+type DaemonState struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"startedAt"`
+	Version   string    `json:"version"`
+	Project   string    `json:"project"`
+	PIDFile   string    `json:"pidFile,omitempty"`
+	HealthURL string    `json:"healthUrl,omitempty"` // 未启用 HTTP 服务时为空
+}
+
+// DaemonPIDPath 返回 daemon PID 文件路径，daemon 在加载配置前就需要获取锁
+func DaemonPIDPath() string {
+	return filepath.Join(daemonDir(), "daemon.pid")
+}
+
+// DaemonStatePath 返回 daemon 状态文件路径
+func DaemonStatePath() string {
+	return filepath.Join(daemonDir(), "daemon.json")
+}
+
+func daemonDir() string {
+	if RedcPath == "" {
+		home, _ := os.UserHomeDir()
+		RedcPath = filepath.Join(home, "redc")
+	}
+	return RedcPath
+}
+
+// AcquireDaemonLock 创建 PID 文件作为单实例锁
+// 若已有存活的 daemon 返回错误；遗留的过期 PID 文件会被覆盖
+func AcquireDaemonLock(pidPath string) error {
+	if err := os.MkdirAll(filepath.Dir(pidPath), 0755); err != nil {
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		f, err := os.OpenFile(pidPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return err
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+		pid, rerr := ReadDaemonPID(pidPath)
+		if rerr == nil && pid != os.Getpid() && ProcessAlive(pid) {
+			return fmt.Errorf("daemon 已在运行 (pid %d, %s)", pid, pidPath)
+		}
+		// 过期的 PID 文件，删除后重试
+		os.Remove(pidPath)
+	}
+	return fmt.Errorf("无法创建 PID 文件: %s", pidPath)
+}
+
+// ReleaseDaemonLock 删除 PID 文件（仅当其属于当前进程）
+func ReleaseDaemonLock(pidPath string) {
+	if pid, err := ReadDaemonPID(pidPath); err == nil && pid == os.Getpid() {
+		os.Remove(pidPath)
+	}
+}
+
+// ReadDaemonPID 读取 PID 文件
+func ReadDaemonPID(pidPath string) (int, error) {
+	data, err := os.ReadFile(pidPath)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("无效的 PID 文件: %s", pidPath)
+	}
+	return pid, nil
+}
+
+// OtherDaemonRunning 判断是否有其他存活的进程持有 daemon 锁，返回其 PID
+// 同时检查默认 PID 文件与 daemon 状态中记录的 --pid-file
+func OtherDaemonRunning() (int, bool) {
+	paths := []string{DaemonPIDPath()}
+	if state, err := LoadDaemonState(); err == nil && state.PIDFile != "" && state.PIDFile != paths[0] {
+		paths = append(paths, state.PIDFile)
+	}
+	for _, path := range paths {
+		if pid, err := ReadDaemonPID(path); err == nil && pid != os.Getpid() && ProcessAlive(pid) {
+			return pid, true
+		}
+	}
+	return 0, false
+}
+
+// ProcessAlive 判断进程是否存在
+func ProcessAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Windows 上 FindProcess 成功即表示进程存在，且不支持 signal 0
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// SaveDaemonState 写入 daemon 状态文件
+func SaveDaemonState(state *DaemonState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(DaemonStatePath(), data, 0644)
+}
+
+// LoadDaemonState 读取 daemon 状态文件
+func LoadDaemonState() (*DaemonState, error) {
+	data, err := os.ReadFile(DaemonStatePath())
+	if err != nil {
+		return nil, err
+	}
+	var state DaemonState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
@@ -0,0 +1,82 @@
+package mod
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestAcquireDaemonLock(t *testing.T) {
+	pidPath := filepath.Join(t.TempDir(), "daemon.pid")
+
+	if err := AcquireDaemonLock(pidPath); err != nil {
+		t.Fatalf("AcquireDaemonLock failed: %v", err)
+	}
+	pid, err := ReadDaemonPID(pidPath)
+	if err != nil || pid != os.Getpid() {
+		t.Fatalf("ReadDaemonPID = %d, %v; want %d", pid, err, os.Getpid())
+	}
+
+	// Re-acquiring from the same process is allowed (stale-file path)
+	if err := AcquireDaemonLock(pidPath); err != nil {
+		t.Errorf("re-acquire by owner failed: %v", err)
+	}
+
+	ReleaseDaemonLock(pidPath)
+	if _, err := os.Stat(pidPath); !os.IsNotExist(err) {
+		t.Errorf("PID file should be removed after release")
+	}
+}
+
+func TestAcquireDaemonLock_Stale(t *testing.T) {
+	pidPath := filepath.Join(t.TempDir(), "daemon.pid")
+	// PID that cannot belong to a live process
+	if err := os.WriteFile(pidPath, []byte("999999999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AcquireDaemonLock(pidPath); err != nil {
+		t.Fatalf("stale PID file should be replaced: %v", err)
+	}
+	if pid, _ := ReadDaemonPID(pidPath); pid != os.Getpid() {
+		t.Errorf("PID = %d, want %d", pid, os.Getpid())
+	}
+}
+
+func TestReadDaemonPID_Invalid(t *testing.T) {
+	pidPath := filepath.Join(t.TempDir(), "daemon.pid")
+	os.WriteFile(pidPath, []byte("garbage"), 0644)
+	if _, err := ReadDaemonPID(pidPath); err == nil {
+		t.Error("expected error for invalid PID file")
+	}
+}
+
+func TestOtherDaemonRunning(t *testing.T) {
+	oldPath := RedcPath
+	RedcPath = t.TempDir()
+	t.Cleanup(func() { RedcPath = oldPath })
+
+	if _, ok := OtherDaemonRunning(); ok {
+		t.Error("no PID file: want no daemon")
+	}
+	// The lock held by this process is not another daemon
+	if err := AcquireDaemonLock(DaemonPIDPath()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := OtherDaemonRunning(); ok {
+		t.Error("own lock: want no other daemon")
+	}
+	ReleaseDaemonLock(DaemonPIDPath())
+
+	// A daemon started with --pid-file, found through the daemon state
+	pidFile := filepath.Join(t.TempDir(), "custom.pid")
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getppid())+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveDaemonState(&DaemonState{PID: os.Getppid(), PIDFile: pidFile}); err != nil {
+		t.Fatal(err)
+	}
+	if pid, ok := OtherDaemonRunning(); !ok || pid != os.Getppid() {
+		t.Errorf("OtherDaemonRunning = %d, %v; want %d", pid, ok, os.Getppid())
+	}
+}
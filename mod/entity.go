@@ -1,6 +1,10 @@
 package mod
 
-import "github.com/hashicorp/terraform-exec/tfexec"
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
 
 const (
 	StateRunning  string = "running"
@@ -13,16 +17,36 @@ const (
 	StateStopping string = "stopping" // 正在停止
 	StateRemoving    string = "removing"    // 正在删除
 	StateTerminated  string = "terminated"  // 被云厂商回收（Spot 实例）
+	StateDegraded    string = "degraded"    // 运行中但健康探测失败
 )
 
+// IsRunningState 资源仍在运行（running 或 degraded）
+func IsRunningState(state string) bool {
+	return state == StateRunning || state == StateDegraded
+}
+
 // RedcProject 项目结构体
 type RedcProject struct {
 	ProjectName string `json:"project_name"`
 	ProjectPath string `json:"project_path"`
 	CreateTime  string `json:"create_time"`
 	User        string `json:"user"`
+
+	pluginHookRunner PluginHookRunner
+}
+
+// PluginHookEvent 插件钩子的附加信息
+type PluginHookEvent struct {
+	Project string   // 项目名称，场景钩子为空时使用场景所属项目
+	Plugins []string // 项目级钩子（compose pre-up/post-up）允许运行的插件
+	Error   string   // on-error / on-spot-terminated / on-drift 的错误或事件描述
+	Command string   // pre-ssh-exec 将要执行的命令
 }
 
+// PluginHookRunner 运行插件钩子，c 为 nil 表示项目级钩子
+// 仅当声明 on_failure: abort 的钩子失败时返回错误
+type PluginHookRunner func(hookPoint string, c *Case, ev PluginHookEvent) error
+
 // Case 项目信息
 type Case struct {
 	// Id uuid
@@ -31,6 +55,9 @@ type Case struct {
 	Type         string   `json:"type"`
 	Module       string   `json:"module,omitempty"`
 	Plugins      string   `json:"plugins,omitempty"`
+	// TemplateVersion/TemplateSHA256 创建时模板的版本与内容摘要（模板名称即 Type）
+	TemplateVersion string `json:"template_version,omitempty"`
+	TemplateSHA256  string `json:"template_sha256,omitempty"`
 	Operator     string   `json:"operator"`
 	Path         string   `json:"path"`
 	Node         int      `json:"node"`
@@ -43,5 +70,6 @@ type Case struct {
 	output       map[string]tfexec.OutputMeta
 	saveHandler  func() error
 	removeHandle func() error
-	pluginHookRunner func(hookPoint string, c *Case) error
+	pluginHookRunner PluginHookRunner
+	expiresAt    time.Time // 尚未保存的生命周期策略的 TTL 到期时间，见 SetExpiresAt
 }
@@ -0,0 +1,301 @@
+package mod
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HealthFile 场景目录下保存健康检查历史的文件名
+const HealthFile = "redc-health.json"
+
+// 健康状态
+const (
+	HealthHealthy  = "healthy"
+	HealthDegraded = "degraded"
+	HealthUnknown  = "unknown"
+)
+
+const (
+	// healthSampleLimit 保留的采样条数（5 分钟一次约 7 天）
+	healthSampleLimit = 2016
+	// healthRetention 采样最长保留时间
+	healthRetention = 30 * 24 * time.Hour
+)
+
+// HealthSample 一次健康检查的结果
+type HealthSample struct {
+	At    time.Time `json:"at"`
+	Up    int       `json:"up"`
+	Total int       `json:"total"`
+	Error string    `json:"error,omitempty"`
+}
+
+// Healthy 所有探测目标都存活
+func (s HealthSample) Healthy() bool {
+	return s.Up == s.Total
+}
+
+// HealthRecord 场景的健康状态与采样历史
+type HealthRecord struct {
+	Status              string         `json:"status"`
+	Since               time.Time      `json:"since,omitempty"` // 进入当前状态的时间
+	LastCheck           time.Time      `json:"lastCheck,omitempty"`
+	ConsecutiveFailures int            `json:"consecutiveFailures"`
+	LastError           string         `json:"lastError,omitempty"`
+	Notified            string         `json:"notified,omitempty"` // 最近一次已发送通知的状态
+	Samples             []HealthSample `json:"samples,omitempty"`
+}
+
+// Add 追加一次采样，连续失败达到 threshold 后进入 degraded，返回状态是否变化
+func (r *HealthRecord) Add(s HealthSample, threshold int) bool {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	r.Samples = append(r.Samples, s)
+	cutoff := s.At.Add(-healthRetention)
+	i := 0
+	for i < len(r.Samples) && r.Samples[i].At.Before(cutoff) {
+		i++
+	}
+	r.Samples = r.Samples[i:]
+	if len(r.Samples) > healthSampleLimit {
+		r.Samples = r.Samples[len(r.Samples)-healthSampleLimit:]
+	}
+	r.LastCheck = s.At
+
+	status := r.Status
+	if s.Healthy() {
+		r.ConsecutiveFailures = 0
+		r.LastError = ""
+		status = HealthHealthy
+	} else {
+		r.ConsecutiveFailures++
+		r.LastError = s.Error
+		if r.ConsecutiveFailures >= threshold {
+			status = HealthDegraded
+		}
+	}
+	if status == "" {
+		status = HealthUnknown
+	}
+	if status == r.Status {
+		return false
+	}
+	r.Status = status
+	r.Since = s.At
+	return true
+}
+
+// Uptime 返回 window 时间窗口内健康采样的百分比，没有采样时返回 -1
+func (r *HealthRecord) Uptime(window time.Duration, now time.Time) float64 {
+	from := now.Add(-window)
+	total, healthy := 0, 0
+	for _, s := range r.Samples {
+		if s.At.Before(from) {
+			continue
+		}
+		total++
+		if s.Healthy() {
+			healthy++
+		}
+	}
+	if total == 0 {
+		return -1
+	}
+	return float64(healthy) * 100 / float64(total)
+}
+
+// LoadHealthRecord 读取场景的健康记录，不存在时返回 unknown 状态
+func LoadHealthRecord(casePath string) (*HealthRecord, error) {
+	rec := &HealthRecord{Status: HealthUnknown}
+	data, err := os.ReadFile(filepath.Join(casePath, HealthFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rec, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, fmt.Errorf("解析健康记录失败: %v", err)
+	}
+	return rec, nil
+}
+
+// SaveHealthRecord 写入场景的健康记录
+func SaveHealthRecord(casePath string, rec *HealthRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(casePath, HealthFile), data, 0644)
+}
+
+// CasePublicIPs 从 terraform outputs 中提取公网 IP
+// 匹配名称包含 ip 的输出（public_ip、ecs_ip、instance_ip 等），跳过 private，支持单值和列表
+func CasePublicIPs(c *Case) []string {
+	outputs, err := c.TfOutput()
+	if err != nil {
+		return nil
+	}
+	var ips []string
+	for name, meta := range outputs {
+		lower := strings.ToLower(name)
+		if !strings.Contains(lower, "ip") || strings.Contains(lower, "private") {
+			continue
+		}
+		ips = append(ips, ExtractIPs(string(meta.Value))...)
+	}
+	return ips
+}
+
+// ExtractIPs 解析 terraform 输出值中的 IP："1.2.3.4"、["1.2.3.4","5.6.7.8"] 或 1.2.3.4
+func ExtractIPs(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "null" || raw == "\"\"" || raw == "[]" {
+		return nil
+	}
+
+	if strings.HasPrefix(raw, "[") {
+		var arr []string
+		if err := json.Unmarshal([]byte(raw), &arr); err == nil {
+			var result []string
+			for _, v := range arr {
+				v = strings.TrimSpace(v)
+				if v != "" && net.ParseIP(v) != nil {
+					result = append(result, v)
+				}
+			}
+			return result
+		}
+	}
+
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		raw = raw[1 : len(raw)-1]
+	}
+	if net.ParseIP(raw) != nil {
+		return []string{raw}
+	}
+	return nil
+}
+
+// CaseProbeTargets 返回场景的探测目标；cloud 探测时按公网 IP 关联 terraform state 中的实例
+func CaseProbeTargets(c *Case, ips []string, cfg ProbeConfig) []ProbeTarget {
+	var byIP map[string]*CloudInstance
+	if cfg.Type == ProbeCloud {
+		if instances, err := CaseCloudInstances(c.Path); err == nil {
+			byIP = make(map[string]*CloudInstance, len(instances))
+			for i := range instances {
+				if ip := instances[i].PublicIP; ip != "" {
+					byIP[ip] = &instances[i]
+				}
+			}
+		}
+	}
+	targets := make([]ProbeTarget, 0, len(ips))
+	for _, ip := range ips {
+		targets = append(targets, ProbeTarget{IP: ip, Instance: byIP[ip]})
+	}
+	return targets
+}
+
+// ProbeCase 使用模板配置的探测方式检查场景的所有公网 IP
+// 无法判断的目标不计入；没有可探测目标时 ok 为 false
+func ProbeCase(ctx context.Context, c *Case, cfg ProbeConfig) (sample HealthSample, ok bool) {
+	sample.At = time.Now()
+	prober, err := NewProber(cfg)
+	if err != nil {
+		return sample, false
+	}
+	var errs []string
+	for _, target := range CaseProbeTargets(c, CasePublicIPs(c), cfg) {
+		err := RunProbe(ctx, prober, cfg, target)
+		if errors.Is(err, ErrProbeUnknown) {
+			continue
+		}
+		sample.Total++
+		if err == nil {
+			sample.Up++
+		} else {
+			errs = append(errs, target.IP+": "+err.Error())
+		}
+	}
+	sample.Error = strings.Join(errs, "; ")
+	return sample, sample.Total > 0
+}
+
+// CheckCaseHealth 探测一次并写入健康记录，同时在 running 与 degraded 之间切换场景状态
+// 返回最新记录以及健康状态是否发生变化
+func CheckCaseHealth(ctx context.Context, c *Case) (*HealthRecord, bool, error) {
+	rec, err := LoadHealthRecord(c.Path)
+	if err != nil {
+		rec = &HealthRecord{Status: HealthUnknown}
+	}
+	cfg, cfgErr := LoadProbeConfig(c.Path)
+	sample, ok := ProbeCase(ctx, c, cfg)
+	if !ok {
+		return rec, false, cfgErr
+	}
+	changed := rec.Add(sample, cfg.FailureThreshold)
+	if err := SaveHealthRecord(c.Path, rec); err != nil {
+		return rec, changed, err
+	}
+
+	switch {
+	case rec.Status == HealthDegraded && c.State == StateRunning:
+		c.StatusChange(StateDegraded)
+	case rec.Status == HealthHealthy && c.State == StateDegraded:
+		c.StatusChange(StateRunning)
+	}
+	return rec, changed, cfgErr
+}
+
+// CaseHealth 健康报表中的一行
+type CaseHealth struct {
+	CaseID              string    `json:"caseId"`
+	Name                string    `json:"name"`
+	Type                string    `json:"type"`
+	State               string    `json:"state"`
+	Status              string    `json:"status"`
+	Since               time.Time `json:"since,omitempty"`
+	LastCheck           time.Time `json:"lastCheck,omitempty"`
+	Uptime24h           float64   `json:"uptime24h"` // -1 表示没有数据
+	Uptime7d            float64   `json:"uptime7d"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastError           string    `json:"lastError,omitempty"`
+}
+
+// HealthReport 汇总场景的健康记录；all 为 false 时只包含运行中的场景
+func HealthReport(cases []*Case, all bool) []CaseHealth {
+	now := time.Now()
+	var report []CaseHealth
+	for _, c := range cases {
+		if !all && !IsRunningState(c.State) {
+			continue
+		}
+		rec, err := LoadHealthRecord(c.Path)
+		if err != nil {
+			rec = &HealthRecord{Status: HealthUnknown, LastError: err.Error()}
+		}
+		report = append(report, CaseHealth{
+			CaseID:              c.Id,
+			Name:                c.Name,
+			Type:                c.Type,
+			State:               c.State,
+			Status:              rec.Status,
+			Since:               rec.Since,
+			LastCheck:           rec.LastCheck,
+			Uptime24h:           rec.Uptime(24*time.Hour, now),
+			Uptime7d:            rec.Uptime(7*24*time.Hour, now),
+			ConsecutiveFailures: rec.ConsecutiveFailures,
+			LastError:           rec.LastError,
+		})
+	}
+	return report
+}
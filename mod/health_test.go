@@ -0,0 +1,122 @@
+package mod
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHealthRecord_Add(t *testing.T) {
+	rec := &HealthRecord{Status: HealthUnknown}
+	now := time.Now()
+	up := func(i int) HealthSample {
+		return HealthSample{At: now.Add(time.Duration(i) * time.Minute), Up: 2, Total: 2}
+	}
+	down := func(i int) HealthSample {
+		return HealthSample{At: now.Add(time.Duration(i) * time.Minute), Up: 1, Total: 2, Error: "1.2.3.4: timeout"}
+	}
+
+	if !rec.Add(up(0), 2) || rec.Status != HealthHealthy {
+		t.Fatalf("first healthy sample: status = %s", rec.Status)
+	}
+	// Below threshold: still healthy
+	if rec.Add(down(1), 2) || rec.Status != HealthHealthy || rec.ConsecutiveFailures != 1 {
+		t.Errorf("one failure: status = %s, failures = %d", rec.Status, rec.ConsecutiveFailures)
+	}
+	if !rec.Add(down(2), 2) || rec.Status != HealthDegraded || rec.LastError == "" || !rec.Since.Equal(down(2).At) {
+		t.Errorf("threshold reached: %+v", rec)
+	}
+	if rec.Add(down(3), 2) {
+		t.Error("staying degraded should not report a change")
+	}
+	if !rec.Add(up(4), 2) || rec.Status != HealthHealthy || rec.ConsecutiveFailures != 0 || rec.LastError != "" {
+		t.Errorf("recovery: %+v", rec)
+	}
+
+	// A failing first sample below threshold leaves the status unknown
+	fresh := &HealthRecord{}
+	fresh.Add(down(0), 3)
+	if fresh.Status != HealthUnknown {
+		t.Errorf("fresh record status = %s, want unknown", fresh.Status)
+	}
+}
+
+func TestHealthRecord_Uptime(t *testing.T) {
+	now := time.Now()
+	rec := &HealthRecord{}
+	if got := rec.Uptime(24*time.Hour, now); got != -1 {
+		t.Errorf("empty uptime = %v, want -1", got)
+	}
+	rec.Samples = []HealthSample{
+		{At: now.Add(-48 * time.Hour), Up: 0, Total: 1}, // outside 24h
+		{At: now.Add(-3 * time.Hour), Up: 1, Total: 1},
+		{At: now.Add(-2 * time.Hour), Up: 1, Total: 1},
+		{At: now.Add(-1 * time.Hour), Up: 1, Total: 1},
+		{At: now.Add(-time.Minute), Up: 0, Total: 1},
+	}
+	if got := rec.Uptime(24*time.Hour, now); got != 75 {
+		t.Errorf("24h uptime = %v, want 75", got)
+	}
+	if got := rec.Uptime(7*24*time.Hour, now); got != 60 {
+		t.Errorf("7d uptime = %v, want 60", got)
+	}
+}
+
+func TestHealthRecord_Retention(t *testing.T) {
+	now := time.Now()
+	rec := &HealthRecord{Samples: []HealthSample{{At: now.Add(-40 * 24 * time.Hour), Up: 1, Total: 1}}}
+	rec.Add(HealthSample{At: now, Up: 1, Total: 1}, 1)
+	if len(rec.Samples) != 1 || !rec.Samples[0].At.Equal(now) {
+		t.Errorf("samples older than retention should be dropped: %+v", rec.Samples)
+	}
+}
+
+func TestHealthReport(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	rec := &HealthRecord{Status: HealthDegraded, LastCheck: now, ConsecutiveFailures: 3, LastError: "down",
+		Samples: []HealthSample{{At: now, Up: 0, Total: 1}}}
+	if err := SaveHealthRecord(dir, rec); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadHealthRecord(dir)
+	if err != nil || loaded.Status != HealthDegraded || len(loaded.Samples) != 1 {
+		t.Fatalf("LoadHealthRecord = %+v, %v", loaded, err)
+	}
+
+	cases := []*Case{
+		{Id: "a", Name: "web", State: StateDegraded, Path: dir},
+		{Id: "b", Name: "old", State: StateStopped, Path: t.TempDir()},
+	}
+	report := HealthReport(cases, false)
+	if len(report) != 1 || report[0].Status != HealthDegraded || report[0].Uptime24h != 0 || report[0].ConsecutiveFailures != 3 {
+		t.Errorf("HealthReport = %+v", report)
+	}
+	all := HealthReport(cases, true)
+	if len(all) != 2 || all[1].Status != HealthUnknown || all[1].Uptime7d != -1 {
+		t.Errorf("HealthReport(all) = %+v", all)
+	}
+}
+
+func TestExtractIPs(t *testing.T) {
+	tests := map[string][]string{
+		`"1.2.3.4"`:                  {"1.2.3.4"},
+		`1.2.3.4`:                    {"1.2.3.4"},
+		`["1.2.3.4", "", "5.6.7.8"]`: {"1.2.3.4", "5.6.7.8"},
+		`null`:                       nil,
+		`"not-an-ip"`:                nil,
+	}
+	for raw, want := range tests {
+		if got := ExtractIPs(raw); !reflect.DeepEqual(got, want) {
+			t.Errorf("ExtractIPs(%s) = %v, want %v", raw, got, want)
+		}
+	}
+	if !IsRunningState(StateDegraded) || !IsRunningState(StateRunning) || IsRunningState(StateTerminated) {
+		t.Error("IsRunningState mismatch")
+	}
+	// degraded 的场景不能再次 apply
+	c := &Case{Id: "d", State: StateDegraded, Path: t.TempDir()}
+	if err := c.TfApply(); err == nil || c.State != StateDegraded {
+		t.Errorf("TfApply on degraded case: err=%v state=%s", err, c.State)
+	}
+}
@@ -0,0 +1,203 @@
+package mod
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// PolicyKindCase 单个场景的生命周期策略
+	PolicyKindCase = "case"
+	// PolicyKindCompose compose 编排项目的生命周期策略（预算按所有服务合计）
+	PolicyKindCompose = "compose"
+
+	// PolicyActionStop 超限后停止（terraform destroy，保留场景）
+	PolicyActionStop = "stop"
+	// PolicyActionDestroy 超限后停止并删除场景
+	PolicyActionDestroy = "destroy"
+
+	// PolicyWarnRatio 达到 TTL/预算的该比例时发送预警
+	PolicyWarnRatio = 0.8
+)
+
+// LifecyclePolicy 场景 / compose 项目的 TTL 与预算策略
+type LifecyclePolicy struct {
+	ID           string             `json:"id"`   // case ID，或 "compose:<file>"
+	Kind         string             `json:"kind"` // "case" | "compose"
+	Name         string             `json:"name"`
+	ProjectID    string             `json:"projectId"`
+	CaseIDs      []string           `json:"caseIds"`
+	TTL          time.Duration      `json:"ttl,omitempty"`
+	ExpiresAt    time.Time          `json:"expiresAt,omitempty"`
+	Budget       float64            `json:"budget,omitempty"`
+	Currency     string             `json:"currency,omitempty"`
+	Action       string             `json:"action"`               // "stop" | "destroy"
+	HourlyCost   map[string]float64 `json:"hourlyCost,omitempty"` // caseID -> hourly price in policy currency
+	Accrued      float64            `json:"accrued"`
+	CheckedAt    time.Time          `json:"checkedAt,omitempty"`
+	TTLWarned    bool               `json:"ttlWarned"`    // 已发送 TTL 预警
+	BudgetWarned bool               `json:"budgetWarned"` // 已发送预算预警
+	Triggered    bool               `json:"triggered"`
+	CreatedAt    time.Time          `json:"createdAt"`
+}
+
+// PolicyVerdict 策略评估结果
+type PolicyVerdict struct {
+	Warn       bool   // 首次达到 TTL 或预算的预警阈值
+	TTLWarn    bool   // 首次达到 TTL 预警阈值
+	BudgetWarn bool   // 首次达到预算预警阈值
+	Exceed     bool   // 超出 TTL 或预算
+	Reason     string // 触发原因，用于通知
+}
+
+var budgetPattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([A-Za-z]{3})?$`)
+
+// ParseBudget 解析预算字符串，例如 "20USD"、"150 CNY"、"30"（默认 USD）
+func ParseBudget(s string) (float64, string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, "", nil
+	}
+	switch {
+	case strings.HasPrefix(s, "$"):
+		s = strings.TrimPrefix(s, "$") + "USD"
+	case strings.HasPrefix(s, "¥"):
+		s = strings.TrimPrefix(s, "¥") + "CNY"
+	}
+	m := budgetPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, "", fmt.Errorf("无效的预算格式: %s (示例: 20USD)", s)
+	}
+	amount, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || amount <= 0 {
+		return 0, "", fmt.Errorf("预算金额必须大于0: %s", s)
+	}
+	currency := strings.ToUpper(m[2])
+	if currency == "" {
+		currency = "USD"
+	}
+	return amount, currency, nil
+}
+
+// ParseTTL 解析 TTL，支持 Go duration 格式以及天数后缀，例如 "8h"、"90m"、"2d"
+func ParseTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err != nil || days <= 0 {
+			return 0, fmt.Errorf("无效的 TTL: %s", s)
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("无效的 TTL: %s (示例: 8h, 30m, 2d)", s)
+	}
+	return d, nil
+}
+
+// NewLifecyclePolicy 根据 TTL / 预算字符串构建策略
+func NewLifecyclePolicy(kind, id, name, projectID string, caseIDs []string, ttl, budget, action string) (*LifecyclePolicy, error) {
+	d, err := ParseTTL(ttl)
+	if err != nil {
+		return nil, err
+	}
+	amount, currency, err := ParseBudget(budget)
+	if err != nil {
+		return nil, err
+	}
+	if d == 0 && amount == 0 {
+		return nil, fmt.Errorf("TTL 与预算至少需要设置一项")
+	}
+	if action == "" {
+		action = PolicyActionStop
+	}
+	if action != PolicyActionStop && action != PolicyActionDestroy {
+		return nil, fmt.Errorf("无效的超限操作: %s (可选: stop, destroy)", action)
+	}
+	if kind != PolicyKindCase && kind != PolicyKindCompose {
+		return nil, fmt.Errorf("无效的策略类型: %s", kind)
+	}
+
+	now := time.Now()
+	p := &LifecyclePolicy{
+		ID:         id,
+		Kind:       kind,
+		Name:       name,
+		ProjectID:  projectID,
+		CaseIDs:    caseIDs,
+		TTL:        d,
+		Budget:     amount,
+		Currency:   currency,
+		Action:     action,
+		HourlyCost: map[string]float64{},
+		CheckedAt:  now,
+		CreatedAt:  now,
+	}
+	if d > 0 {
+		p.ExpiresAt = now.Add(d)
+	}
+	return p, nil
+}
+
+// ComposePolicyID 返回 compose 项目策略的 ID
+func ComposePolicyID(file string) string {
+	return PolicyKindCompose + ":" + file
+}
+
+// Accrue 按经过的时间累计花费，running 为当前处于运行状态的 caseID 集合
+func (p *LifecyclePolicy) Accrue(now time.Time, running map[string]bool) {
+	if !p.CheckedAt.IsZero() && now.After(p.CheckedAt) {
+		hours := now.Sub(p.CheckedAt).Hours()
+		for id, hourly := range p.HourlyCost {
+			if running[id] {
+				p.Accrued += hourly * hours
+			}
+		}
+	}
+	p.CheckedAt = now
+}
+
+// Evaluate 判断是否需要预警或执行超限操作，TTL 与预算的预警各自只发送一次
+func (p *LifecyclePolicy) Evaluate(now time.Time) PolicyVerdict {
+	var v PolicyVerdict
+	if p.Triggered {
+		return v
+	}
+
+	var reasons []string
+	if !p.ExpiresAt.IsZero() {
+		if !now.Before(p.ExpiresAt) {
+			return PolicyVerdict{Exceed: true, Reason: fmt.Sprintf("TTL %s 已到期", p.TTL)}
+		}
+		if p.TTL > 0 && now.Sub(p.CreatedAt) >= time.Duration(float64(p.TTL)*PolicyWarnRatio) && !p.TTLWarned {
+			v.TTLWarn = true
+			reasons = append(reasons, fmt.Sprintf("TTL 将于 %s 到期", p.ExpiresAt.Format("2006-01-02 15:04:05")))
+		}
+	}
+
+	if p.Budget > 0 {
+		if p.Accrued >= p.Budget {
+			return PolicyVerdict{Exceed: true, Reason: fmt.Sprintf("已花费 %.2f %s，超出预算 %.2f %s", p.Accrued, p.Currency, p.Budget, p.Currency)}
+		}
+		if p.Accrued >= p.Budget*PolicyWarnRatio && !p.BudgetWarned {
+			v.BudgetWarn = true
+			reasons = append(reasons, fmt.Sprintf("已花费 %.2f %s，达到预算 %.2f %s 的 %.0f%%", p.Accrued, p.Currency, p.Budget, p.Currency, p.Accrued/p.Budget*100))
+		}
+	}
+	v.Warn = v.TTLWarn || v.BudgetWarn
+	v.Reason = strings.Join(reasons, "；")
+	return v
+}
+
+// MarkWarned 记录已发送的预警
+func (p *LifecyclePolicy) MarkWarned(v PolicyVerdict) {
+	p.TTLWarned = p.TTLWarned || v.TTLWarn
+	p.BudgetWarned = p.BudgetWarned || v.BudgetWarn
+}
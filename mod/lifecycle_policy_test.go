@@ -0,0 +1,235 @@
+package mod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		input    string
+		amount   float64
+		currency string
+		wantErr  bool
+	}{
+		{"20USD", 20, "USD", false},
+		{"150 cny", 150, "CNY", false},
+		{"12.5", 12.5, "USD", false},
+		{"$30", 30, "USD", false},
+		{"", 0, "", false},
+		{"abc", 0, "", true},
+		{"0USD", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		amount, currency, err := ParseBudget(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBudget(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if amount != tt.amount || currency != tt.currency {
+			t.Errorf("ParseBudget(%q) = %v %s, want %v %s", tt.input, amount, currency, tt.amount, tt.currency)
+		}
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"8h", 8 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"2d", 48 * time.Hour, false},
+		{"", 0, false},
+		{"-1h", 0, true},
+		{"xd", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTTL(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTTL(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTTL(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNewLifecyclePolicy_Validation(t *testing.T) {
+	if _, err := NewLifecyclePolicy(PolicyKindCase, "id", "n", "default", nil, "", "", ""); err == nil {
+		t.Error("expected error when neither TTL nor budget is set")
+	}
+	if _, err := NewLifecyclePolicy(PolicyKindCase, "id", "n", "default", nil, "1h", "", "explode"); err == nil {
+		t.Error("expected error for invalid action")
+	}
+	p, err := NewLifecyclePolicy(PolicyKindCase, "id", "n", "default", []string{"id"}, "1h", "", "")
+	if err != nil {
+		t.Fatalf("NewLifecyclePolicy failed: %v", err)
+	}
+	if p.Action != PolicyActionStop {
+		t.Errorf("Action = %s, want %s", p.Action, PolicyActionStop)
+	}
+	if p.ExpiresAt.Sub(p.CreatedAt) != time.Hour {
+		t.Errorf("ExpiresAt - CreatedAt = %v, want 1h", p.ExpiresAt.Sub(p.CreatedAt))
+	}
+}
+
+func TestLifecyclePolicy_AccrueOnlyRunning(t *testing.T) {
+	start := time.Now()
+	p := &LifecyclePolicy{
+		HourlyCost: map[string]float64{"a": 1.0, "b": 2.0},
+		CheckedAt:  start,
+	}
+
+	p.Accrue(start.Add(2*time.Hour), map[string]bool{"a": true})
+	if p.Accrued != 2.0 {
+		t.Errorf("Accrued = %v, want 2.0", p.Accrued)
+	}
+
+	p.Accrue(start.Add(3*time.Hour), map[string]bool{"a": true, "b": true})
+	if p.Accrued != 5.0 {
+		t.Errorf("Accrued = %v, want 5.0", p.Accrued)
+	}
+}
+
+func TestLifecyclePolicy_Evaluate(t *testing.T) {
+	now := time.Now()
+
+	budget := &LifecyclePolicy{Budget: 10, Currency: "USD", CreatedAt: now}
+	budget.Accrued = 7
+	if v := budget.Evaluate(now); v.Warn || v.Exceed {
+		t.Errorf("70%% of budget: got %+v, want no action", v)
+	}
+	budget.Accrued = 8.5
+	if v := budget.Evaluate(now); !v.Warn || v.Exceed {
+		t.Errorf("85%% of budget: got %+v, want warn", v)
+	}
+	budget.MarkWarned(budget.Evaluate(now))
+	if v := budget.Evaluate(now); v.Warn {
+		t.Errorf("already warned: got %+v, want no second warning", v)
+	}
+	budget.Accrued = 10
+	if v := budget.Evaluate(now); !v.Exceed {
+		t.Errorf("100%% of budget: got %+v, want exceed", v)
+	}
+
+	ttl := &LifecyclePolicy{TTL: 10 * time.Hour, CreatedAt: now, ExpiresAt: now.Add(10 * time.Hour)}
+	if v := ttl.Evaluate(now.Add(5 * time.Hour)); v.Warn || v.Exceed {
+		t.Errorf("TTL 50%%: got %+v, want no action", v)
+	}
+	if v := ttl.Evaluate(now.Add(9 * time.Hour)); !v.Warn {
+		t.Errorf("TTL 90%%: got %+v, want warn", v)
+	}
+	if v := ttl.Evaluate(now.Add(10 * time.Hour)); !v.Exceed {
+		t.Errorf("TTL expired: got %+v, want exceed", v)
+	}
+
+	ttl.Triggered = true
+	if v := ttl.Evaluate(now.Add(11 * time.Hour)); v.Exceed {
+		t.Errorf("already triggered: got %+v, want no action", v)
+	}
+}
+
+func TestLifecyclePolicy_EvaluateWarnsTTLAndBudgetSeparately(t *testing.T) {
+	now := time.Now()
+	p := &LifecyclePolicy{TTL: 10 * time.Hour, CreatedAt: now, ExpiresAt: now.Add(10 * time.Hour), Budget: 10, Currency: "USD"}
+
+	v := p.Evaluate(now.Add(9 * time.Hour))
+	if !v.TTLWarn || v.BudgetWarn {
+		t.Fatalf("TTL 90%%: got %+v, want only the TTL warning", v)
+	}
+	p.MarkWarned(v)
+
+	p.Accrued = 9
+	v = p.Evaluate(now.Add(9 * time.Hour))
+	if !v.Warn || !v.BudgetWarn || v.TTLWarn {
+		t.Fatalf("budget 90%% after TTL warning: got %+v, want only the budget warning", v)
+	}
+	p.MarkWarned(v)
+	if v := p.Evaluate(now.Add(9 * time.Hour)); v.Warn {
+		t.Errorf("both warned: got %+v, want no warning", v)
+	}
+}
+
+func TestTaskScheduler_CaseHourlyCostRefresh(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "terraform.tfstate")
+	if err := os.WriteFile(statePath, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := &Case{Id: "case1", Path: dir}
+
+	calls := 0
+	s := NewTaskScheduler(nil, "")
+	s.SetCaseCostCallback(func(projectID, caseID string) (float64, string, error) {
+		calls++
+		return float64(calls), "", nil
+	})
+
+	now := time.Now()
+	if hourly, ok := s.caseHourlyCost("default", c, "USD", now); !ok || hourly != 1 {
+		t.Fatalf("first estimate = %v, %v; want 1", hourly, ok)
+	}
+	if hourly, _ := s.caseHourlyCost("default", c, "USD", now.Add(time.Minute)); hourly != 1 || calls != 1 {
+		t.Errorf("cached estimate = %v after %d calls, want 1 from cache", hourly, calls)
+	}
+
+	// Scaling rewrites the terraform state
+	later := now.Add(2 * time.Minute)
+	if err := os.Chtimes(statePath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if hourly, _ := s.caseHourlyCost("default", c, "USD", later.Add(time.Second)); hourly != 2 {
+		t.Errorf("estimate after state change = %v, want 2", hourly)
+	}
+	if hourly, _ := s.caseHourlyCost("default", c, "USD", later.Add(policyPriceTTL+time.Second)); hourly != 3 {
+		t.Errorf("estimate after %s = %v, want 3", policyPriceTTL, hourly)
+	}
+}
+
+func TestTaskScheduler_PolicyPersistence(t *testing.T) {
+	s := NewTaskScheduler(nil, filepath.Join(t.TempDir(), "scheduler.db"))
+	if err := s.InitDB(); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	defer s.Stop()
+
+	p, err := NewLifecyclePolicy(PolicyKindCompose, ComposePolicyID("/tmp/redc-compose.yaml"), "redc-compose.yaml", "default",
+		[]string{"case1", "case2"}, "8h", "20USD", PolicyActionDestroy)
+	if err != nil {
+		t.Fatalf("NewLifecyclePolicy failed: %v", err)
+	}
+	p.HourlyCost["case1"] = 0.5
+	if err := s.SetPolicy(p); err != nil {
+		t.Fatalf("SetPolicy failed: %v", err)
+	}
+
+	got, err := s.GetPolicy(p.ID)
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	if got.Kind != PolicyKindCompose || got.Action != PolicyActionDestroy || got.Budget != 20 || got.Currency != "USD" {
+		t.Errorf("GetPolicy = %+v, fields not persisted", got)
+	}
+	if got.TTL != 8*time.Hour || len(got.CaseIDs) != 2 || got.HourlyCost["case1"] != 0.5 {
+		t.Errorf("GetPolicy = %+v, TTL/case IDs/hourly cost not persisted", got)
+	}
+
+	list, err := s.ListPolicies()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListPolicies = %d, %v; want 1 policy", len(list), err)
+	}
+
+	if err := s.RemovePolicy(p.ID); err != nil {
+		t.Fatalf("RemovePolicy failed: %v", err)
+	}
+	if _, err := s.GetPolicy(p.ID); err == nil {
+		t.Error("GetPolicy after RemovePolicy should fail")
+	}
+}
@@ -15,6 +15,7 @@ type AppBridge interface {
 
 	// Cost & Resources
 	MCPGetCostEstimate(templateName string, variables map[string]string) (interface{}, error)
+	MCPGetPlanCostDiff(caseID string) (interface{}, error)
 	MCPGetBalances(providers []string) (interface{}, error)
 	MCPGetResourceSummary() (interface{}, error)
 	MCPGetPredictedMonthlyCost() (string, error)
@@ -138,7 +138,8 @@ type MCPServer struct {
 	project        *redc.RedcProject
 	app            AppBridge
 	logWriter      LogCallback
-	execTimeoutAsk sync.Map // map[conversationId] → ExecTimeoutAskFunc
+	execTimeoutAsk sync.Map              // map[conversationId] → ExecTimeoutAskFunc
+	plugins        *plugin.PluginManager // optional, adds plugin-provided tools
 }
 
 // ExecTimeoutAskFunc is called when exec_command/exec_userdata times out.
@@ -320,7 +321,7 @@ func (s *MCPServer) getTools() []Tool {
 					},
 					"registry_url": {
 						Type:        "string",
-						Description: "Registry base URL (optional, default: https://redc.wgpsec.org)",
+						Description: "Registry name or base URL (optional, default: all configured registries)",
 					},
 				},
 				Required: []string{"query"},
@@ -334,11 +335,11 @@ func (s *MCPServer) getTools() []Tool {
 				Properties: map[string]Property{
 					"template": {
 						Type:        "string",
-						Description: "Template name (e.g., 'aliyun/ecs' or 'aliyun/ecs:1.0.1')",
+						Description: "Template name (e.g., 'aliyun/ecs', 'aliyun/ecs:1.0.1' or 'aliyun/ecs:1.0.1@team')",
 					},
 					"registry_url": {
 						Type:        "string",
-						Description: "Registry base URL (optional, default: https://redc.wgpsec.org)",
+						Description: "Registry name or base URL (optional, default: all configured registries)",
 					},
 					"force": {
 						Type:        "boolean",
@@ -633,6 +634,8 @@ func (s *MCPServer) getTools() []Tool {
 		},
 	}
 
+	tools = append(tools, healthToolSchemas()...)
+
 	// Append extended tools (require AppBridge)
 	if s.app != nil {
 		tools = append(tools, composeToolSchemas()...)
@@ -740,6 +743,8 @@ func (s *MCPServer) getTools() []Tool {
 		},
 	})
 
+	tools = append(tools, s.pluginToolSchemas()...)
+
 	return tools
 }
 
@@ -1048,6 +1053,12 @@ func (s *MCPServer) executeTool(name string, args map[string]interface{}) (ToolR
 		profiles, _ := args["profiles"].(string)
 		return s.toolComposeDown(file, profiles)
 
+	case "get_health_report":
+		caseID, _ := args["case_id"].(string)
+		check, _ := args["check"].(bool)
+		all, _ := args["all"].(bool)
+		return s.toolGetHealthReport(caseID, check, all)
+
 	// --- Cost & Resource tools ---
 	case "get_cost_estimate":
 		template, ok := args["template"].(string)
@@ -1056,6 +1067,13 @@ func (s *MCPServer) executeTool(name string, args map[string]interface{}) (ToolR
 		}
 		return s.toolGetCostEstimate(template)
 
+	case "get_plan_cost_diff":
+		caseID, ok := args["case_id"].(string)
+		if !ok {
+			return ToolResult{}, fmt.Errorf("missing or invalid 'case_id' parameter")
+		}
+		return s.toolGetPlanCostDiff(caseID)
+
 	case "get_balances":
 		providers, _ := args["providers"].(string)
 		return s.toolGetBalances(providers)
@@ -1171,6 +1189,9 @@ func (s *MCPServer) executeTool(name string, args map[string]interface{}) (ToolR
 		return s.toolReadSkill(id)
 
 	default:
+		if result, ok, err := s.toolRunPlugin(name, args); ok {
+			return result, err
+		}
 		return ToolResult{}, fmt.Errorf("unknown tool: %s", name)
 	}
 }
@@ -1229,14 +1250,11 @@ func (s *MCPServer) toolSearchTemplates(query string, registryURL string) (ToolR
 	if strings.TrimSpace(query) == "" {
 		return ToolResult{}, fmt.Errorf("query cannot be empty")
 	}
-	if strings.TrimSpace(registryURL) == "" {
-		registryURL = "https://redc.wgpsec.org"
-	}
-
+	// Empty registry searches all configured registries
 	opts := redc.PullOptions{
-		RegistryURL: registryURL,
-		Timeout:     30 * time.Second,
+		Timeout: 30 * time.Second,
 	}
+	opts.SetRegistry(registryURL)
 
 	results, err := redc.Search(context.Background(), query, opts)
 	if err != nil {
@@ -1257,6 +1275,7 @@ func (s *MCPServer) toolSearchTemplates(query string, registryURL string) (ToolR
 		output += fmt.Sprintf("%d. %s\n", i+1, result.Key)
 		output += fmt.Sprintf("   Version: %s\n", result.Version)
 		output += fmt.Sprintf("   Provider: %s\n", result.Provider)
+		output += fmt.Sprintf("   Registry: %s\n", result.Registry)
 		if result.Author != "" {
 			output += fmt.Sprintf("   Author: %s\n", result.Author)
 		}
@@ -1284,20 +1303,19 @@ func (s *MCPServer) toolPullTemplate(template string, registryURL string, force
 	if strings.TrimSpace(template) == "" {
 		return ToolResult{}, fmt.Errorf("template cannot be empty")
 	}
-	if strings.TrimSpace(registryURL) == "" {
-		registryURL = "https://redc.wgpsec.org"
-	}
-
 	opts := redc.PullOptions{
-		RegistryURL: registryURL,
-		Force:       force,
-		Timeout:     120 * time.Second,
+		Force:   force,
+		Timeout: 120 * time.Second,
 	}
+	opts.SetRegistry(registryURL)
 
 	if err := redc.Pull(context.Background(), template, opts); err != nil {
 		return ToolResult{}, fmt.Errorf("failed to pull template: %v", err)
 	}
 
+	if strings.TrimSpace(registryURL) == "" {
+		registryURL = "configured registries"
+	}
 	output := fmt.Sprintf("Template pulled successfully:\n- Template: %s\n- Registry: %s\n", template, registryURL)
 	if force {
 		output += "- Force: true\n"
@@ -1394,7 +1412,7 @@ func (s *MCPServer) toolStopCase(caseID string) (ToolResult, error) {
 		return ToolResult{}, fmt.Errorf("case not found: %v", err)
 	}
 
-	if c.State != redc.StateRunning {
+	if !redc.IsRunningState(c.State) {
 		return ToolResult{}, fmt.Errorf("case '%s' (%s) is not running (current state: %s), cannot stop", c.Name, c.GetId(), c.State)
 	}
 
@@ -1464,6 +1482,10 @@ func (s *MCPServer) toolExecCommand(caseID string, command string, timeoutSec in
 	if err != nil {
 		return ToolResult{}, fmt.Errorf("failed to get SSH config: %v", err)
 	}
+	// pre-ssh-exec policy plugins can block the command
+	if err := c.BeforeSSHExec(command); err != nil {
+		return ToolResult{}, err
+	}
 
 	client, err := sshutil.NewClient(sshConfig)
 	if err != nil {
@@ -1654,6 +1676,9 @@ func (s *MCPServer) toolExecUserdata(caseID string, templateName string, convers
 	if err != nil {
 		return ToolResult{}, fmt.Errorf("failed to get SSH config: %v", err)
 	}
+	if err := c.BeforeSSHExec(found.Script); err != nil {
+		return ToolResult{}, err
+	}
 
 	client, err := sshutil.NewClient(sshConfig)
 	if err != nil {
@@ -1962,7 +1987,7 @@ func (s *MCPServer) toolGetCaseOutputs(caseID string) (ToolResult, error) {
 		return ToolResult{}, fmt.Errorf("case not found: %v", err)
 	}
 
-	if c.State != "running" {
+	if !redc.IsRunningState(c.State) {
 		return ToolResult{}, fmt.Errorf("case is not running, current state: %s", c.State)
 	}
 
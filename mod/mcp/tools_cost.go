@@ -22,6 +22,20 @@ func costToolSchemas() []Tool {
 				Required: []string{"template"},
 			},
 		},
+		{
+			Name:        "get_plan_cost_diff",
+			Description: "Estimate how a case's pending terraform plan changes its cost: before/after/delta per resource (run plan_case or change variables first)",
+			InputSchema: ToolSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"case_id": {
+						Type:        "string",
+						Description: "Case ID or name",
+					},
+				},
+				Required: []string{"case_id"},
+			},
+		},
 		{
 			Name:        "get_balances",
 			Description: "Query cloud account balances for configured providers",
@@ -103,6 +117,20 @@ func (s *MCPServer) toolGetCostEstimate(template string) (ToolResult, error) {
 	}, nil
 }
 
+func (s *MCPServer) toolGetPlanCostDiff(caseID string) (ToolResult, error) {
+	if s.app == nil {
+		return ToolResult{}, fmt.Errorf("cost tools require GUI mode (AppBridge not available)")
+	}
+	result, err := s.app.MCPGetPlanCostDiff(caseID)
+	if err != nil {
+		return ToolResult{}, err
+	}
+	data, _ := json.MarshalIndent(result, "", "  ")
+	return ToolResult{
+		Content: []ContentItem{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
 func (s *MCPServer) toolGetBalances(providers string) (ToolResult, error) {
 	if s.app == nil {
 		return ToolResult{}, fmt.Errorf("cost tools require GUI mode (AppBridge not available)")
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	redc "red-cloud/mod"
+)
+
+func healthToolSchemas() []Tool {
+	return []Tool{
+		{
+			Name:        "get_health_report",
+			Description: "Get the health table of running cases: probe status (healthy/degraded/unknown), last check time, uptime over 24h and 7d, consecutive failures and last error. Optionally probe a case right now.",
+			InputSchema: ToolSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"case_id": {
+						Type:        "string",
+						Description: "Only report this case (optional)",
+					},
+					"check": {
+						Type:        "boolean",
+						Description: "Run the liveness probes now before reporting (default: false, uses the last recorded result)",
+					},
+					"all": {
+						Type:        "boolean",
+						Description: "Include cases that are not running (default: false)",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *MCPServer) toolGetHealthReport(caseID string, check bool, all bool) (ToolResult, error) {
+	var cases []*redc.Case
+	if caseID != "" {
+		c, err := s.project.GetCase(caseID)
+		if err != nil {
+			return ToolResult{}, fmt.Errorf("case not found: %v", err)
+		}
+		cases = []*redc.Case{c}
+		all = true
+	} else {
+		list, err := redc.LoadProjectCases(s.project.ProjectName)
+		if err != nil {
+			return ToolResult{}, fmt.Errorf("failed to load cases: %v", err)
+		}
+		cases = list
+	}
+
+	if check {
+		for _, c := range cases {
+			if redc.IsRunningState(c.State) {
+				redc.CheckCaseHealth(context.Background(), c)
+			}
+		}
+	}
+
+	report := redc.HealthReport(cases, all)
+	if len(report) == 0 {
+		return ToolResult{
+			Content: []ContentItem{{Type: "text", Text: "No running cases."}},
+		}, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("| Case | ID | State | Health | Uptime 24h | Uptime 7d | Failures | Last check | Last error |\n")
+	b.WriteString("|------|----|-------|--------|------------|-----------|----------|------------|------------|\n")
+	for _, h := range report {
+		lastCheck := "-"
+		if !h.LastCheck.IsZero() {
+			lastCheck = h.LastCheck.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %d | %s | %s |\n",
+			h.Name, h.CaseID, h.State, h.Status, formatUptime(h.Uptime24h), formatUptime(h.Uptime7d),
+			h.ConsecutiveFailures, lastCheck, h.LastError)
+	}
+	return ToolResult{
+		Content: []ContentItem{{Type: "text", Text: b.String()}},
+	}, nil
+}
+
+func formatUptime(v float64) string {
+	if v < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", v)
+}
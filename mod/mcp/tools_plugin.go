@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"red-cloud/mod/plugin"
+)
+
+// SetPluginManager exposes the tools of enabled plugins through this server
+func (s *MCPServer) SetPluginManager(pm *plugin.PluginManager) {
+	s.plugins = pm
+}
+
+// pluginToolSchemas converts the tools declared by enabled plugins. Schema keywords that
+// ToolSchema does not model are dropped.
+func (s *MCPServer) pluginToolSchemas() []Tool {
+	if s.plugins == nil {
+		return nil
+	}
+	var tools []Tool
+	for _, t := range s.plugins.GetTools() {
+		schema := ToolSchema{Type: "object"}
+		if len(t.Spec.InputSchema) > 0 {
+			if err := json.Unmarshal(t.Spec.InputSchema, &schema); err != nil {
+				s.log("plugin tool %s: invalid input_schema: %v", t.QualifiedName(), err)
+				continue
+			}
+			schema.Type = "object"
+		}
+		if schema.Properties == nil {
+			schema.Properties = map[string]Property{}
+		}
+		tools = append(tools, Tool{
+			Name:        t.QualifiedName(),
+			Description: fmt.Sprintf("[plugin %s] %s", t.Plugin, t.Spec.Description),
+			InputSchema: schema,
+		})
+	}
+	return tools
+}
+
+// toolRunPlugin runs a plugin tool; ok is false when name is not a plugin tool
+func (s *MCPServer) toolRunPlugin(name string, args map[string]interface{}) (result ToolResult, ok bool, err error) {
+	if s.plugins == nil {
+		return ToolResult{}, false, nil
+	}
+	tool, found := s.plugins.FindTool(name)
+	if !found {
+		return ToolResult{}, false, nil
+	}
+	s.log("Running plugin tool %s", name)
+	out, err := s.plugins.RunTool(tool, args)
+	if err != nil {
+		return ToolResult{}, true, err
+	}
+	return ToolResult{
+		Content: []ContentItem{{Type: "text", Text: string(out)}},
+	}, true, nil
+}
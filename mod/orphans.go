@@ -0,0 +1,210 @@
+package mod
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CloudResource 云厂商 API 返回的资源
+type CloudResource struct {
+	Provider  string            `json:"provider"`
+	Region    string            `json:"region"`
+	Type      string            `json:"type"`
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	State     string            `json:"state,omitempty"`
+	CreatedAt string            `json:"createdAt,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// CloudInventory 列出并删除云账号下的资源，按云厂商注册在 inventoryFactories 中
+type CloudInventory interface {
+	Provider() string
+	List(ctx context.Context) ([]CloudResource, error)
+	Delete(ctx context.Context, r CloudResource) error
+}
+
+// 孤儿资源的识别依据
+const (
+	OrphanByTag     = "tag"     // 带有 redc_case_id 标签，项目在本地 redc.db 中，但场景已不存在
+	OrphanByName    = "name"    // 名称符合 RandomName 规则，但没有同名场景
+	OrphanByForeign = "foreign" // 带有 redc_case_id 标签，但项目不在本地 redc.db 中，可能属于其他机器或操作者
+)
+
+// OrphanResource 不属于任何场景的云资源
+// Deletable 为 false 的资源无法确认归属于本机，清理时只能逐个确认删除，--yes 不会删除
+type OrphanResource struct {
+	CloudResource
+	Reason    string `json:"reason"`
+	CaseID    string `json:"caseId,omitempty"`
+	Project   string `json:"project,omitempty"`
+	Deletable bool   `json:"deletable"`
+}
+
+// OrphanProvider 单个云厂商的扫描结果
+type OrphanProvider struct {
+	Provider string `json:"provider"`
+	Scanned  int    `json:"scanned"`
+	Orphans  int    `json:"orphans"`
+	Error    string `json:"error,omitempty"`
+}
+
+// OrphanReport 孤儿资源扫描报表
+type OrphanReport struct {
+	Providers []OrphanProvider `json:"providers"`
+	Orphans   []OrphanResource `json:"orphans"`
+}
+
+// OrphanCleanup 单个孤儿资源的清理结果
+type OrphanCleanup struct {
+	Resource OrphanResource `json:"resource"`
+	Deleted  bool           `json:"deleted"`
+	Skipped  bool           `json:"skipped,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// KnownCases redc.db 中记录的项目与场景，用于判断云资源是否有归属
+type KnownCases struct {
+	IDs      map[string]bool
+	Names    []string
+	Projects map[string]bool
+}
+
+// NewKnownCases 由场景列表构建归属索引
+func NewKnownCases(cases []*Case) *KnownCases {
+	k := &KnownCases{IDs: make(map[string]bool, len(cases)), Projects: make(map[string]bool)}
+	for _, c := range cases {
+		k.IDs[c.Id] = true
+		if c.Name != "" {
+			k.Names = append(k.Names, c.Name)
+		}
+		if c.ProjectID != "" {
+			k.Projects[c.ProjectID] = true
+		}
+	}
+	return k
+}
+
+// LoadKnownCases 读取所有项目下的场景
+func LoadKnownCases() (*KnownCases, error) {
+	projects, err := ListAllProjects()
+	if err != nil {
+		return nil, err
+	}
+	var cases []*Case
+	for _, p := range projects {
+		list, err := LoadProjectCases(p.ProjectName)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, list...)
+	}
+	known := NewKnownCases(cases)
+	// 没有场景的项目同样属于本机
+	for _, p := range projects {
+		known.Projects[p.ProjectName] = true
+	}
+	return known, nil
+}
+
+// ownsName 资源名等于场景名，或以场景名加分隔符开头（模板常用 "${var.instance_name}-1"）
+func (k *KnownCases) ownsName(name string) bool {
+	for _, n := range k.Names {
+		if name == n || strings.HasPrefix(name, n+"-") || strings.HasPrefix(name, n+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+var randomNamePattern = regexp.MustCompile(`^(` + strings.Join(randomFirstNames, "|") + `)[_-](` + strings.Join(randomLastNames, "|") + `)[_-][^\s]+$`)
+
+// MatchRandomName 判断名称是否符合 RandomName 生成的场景名规则
+func MatchRandomName(name string) bool {
+	return randomNamePattern.MatchString(name)
+}
+
+// classifyOrphan 判断资源是否为孤儿资源：带 redc 标签的按场景 ID 判断，否则按名称规则判断
+// 同一云账号可能被多台机器上的 redc 共用，只有 redc_project 属于本地项目的资源才允许直接删除
+func classifyOrphan(r CloudResource, known *KnownCases) (OrphanResource, bool) {
+	if id := r.Tags[TagCaseID]; id != "" {
+		if known.IDs[id] {
+			return OrphanResource{}, false
+		}
+		o := OrphanResource{CloudResource: r, Reason: OrphanByForeign, CaseID: id, Project: r.Tags[TagProject]}
+		if o.Project != "" && known.Projects[o.Project] {
+			o.Reason, o.Deletable = OrphanByTag, true
+		}
+		return o, true
+	}
+	if MatchRandomName(r.Name) && !known.ownsName(r.Name) {
+		return OrphanResource{CloudResource: r, Reason: OrphanByName}, true
+	}
+	return OrphanResource{}, false
+}
+
+// ScanOrphans 列出各云账号下的资源并找出不属于任何场景的资源
+// 单个云厂商失败不影响其他云厂商，错误记录在报表的 providers 中
+func ScanOrphans(ctx context.Context, inventories []CloudInventory, known *KnownCases) *OrphanReport {
+	report := &OrphanReport{Providers: []OrphanProvider{}, Orphans: []OrphanResource{}}
+	for _, inv := range inventories {
+		status := OrphanProvider{Provider: inv.Provider()}
+		resources, err := inv.List(ctx)
+		if err != nil {
+			status.Error = err.Error()
+		}
+		// 部分地域失败时仍然报告已列出的资源
+		status.Scanned = len(resources)
+		for _, r := range resources {
+			if o, ok := classifyOrphan(r, known); ok {
+				report.Orphans = append(report.Orphans, o)
+				status.Orphans++
+			}
+		}
+		report.Providers = append(report.Providers, status)
+	}
+	sort.SliceStable(report.Orphans, func(i, j int) bool {
+		a, b := report.Orphans[i], report.Orphans[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.ID < b.ID
+	})
+	return report
+}
+
+// CleanupOrphans 逐个删除孤儿资源；confirm 返回 false 的资源跳过
+// confirm 为 nil 时只删除 Deletable 的资源，其余资源跳过
+func CleanupOrphans(ctx context.Context, inventories []CloudInventory, orphans []OrphanResource, confirm func(OrphanResource) bool) []OrphanCleanup {
+	byProvider := make(map[string]CloudInventory, len(inventories))
+	for _, inv := range inventories {
+		byProvider[inv.Provider()] = inv
+	}
+	results := make([]OrphanCleanup, 0, len(orphans))
+	for _, o := range orphans {
+		res := OrphanCleanup{Resource: o}
+		inv, ok := byProvider[o.Provider]
+		switch {
+		case !ok:
+			res.Error = fmt.Sprintf("未配置 %s 的资源清单", o.Provider)
+		case confirm == nil && !o.Deletable:
+			res.Skipped = true
+		case confirm != nil && !confirm(o):
+			res.Skipped = true
+		default:
+			if err := inv.Delete(ctx, o.CloudResource); err != nil {
+				res.Error = err.Error()
+			} else {
+				res.Deleted = true
+			}
+		}
+		results = append(results, res)
+	}
+	return results
+}
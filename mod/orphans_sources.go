@@ -0,0 +1,325 @@
+package mod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	alicloudecs "github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	awscredentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+// inventoryFactories 各云厂商资源清单的构造函数，regions 为需要扫描的地域
+var inventoryFactories = map[string]func(regions []string) (CloudInventory, error){
+	"alicloud":     newAlicloudInventory,
+	"tencentcloud": newTencentInventory,
+	"aws":          newAWSInventory,
+}
+
+// OrphanProviders 支持孤儿资源扫描的云厂商
+func OrphanProviders() []string {
+	providers := make([]string, 0, len(inventoryFactories))
+	for p := range inventoryFactories {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// NewCloudInventory 创建云厂商资源清单；regions 为空时使用配置文件中的默认地域
+func NewCloudInventory(provider string, regions []string) (CloudInventory, error) {
+	factory, ok := inventoryFactories[provider]
+	if !ok {
+		return nil, fmt.Errorf("%s 暂不支持孤儿资源扫描", provider)
+	}
+	if len(regions) == 0 {
+		region := providerRegion(provider)
+		if region == "" {
+			return nil, fmt.Errorf("未配置 %s 的默认地域，请使用 --regions 指定", provider)
+		}
+		regions = []string{region}
+	}
+	return factory(regions)
+}
+
+// listRegions 依次列出每个地域的资源，某个地域失败时继续列出其他地域
+func listRegions(ctx context.Context, regions []string, list func(ctx context.Context, region string) ([]CloudResource, error)) ([]CloudResource, error) {
+	var all []CloudResource
+	var errs []error
+	for _, region := range regions {
+		if err := ctx.Err(); err != nil {
+			return all, err
+		}
+		resources, err := list(ctx, region)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", region, err))
+			continue
+		}
+		all = append(all, resources...)
+	}
+	return all, errors.Join(errs...)
+}
+
+// alicloudInventory 通过 ECS DescribeInstances 列出云主机
+type alicloudInventory struct {
+	accessKey, secretKey string
+	regions              []string
+}
+
+func newAlicloudInventory(regions []string) (CloudInventory, error) {
+	accessKey, secretKey, err := probeCredentials("alicloud")
+	if err != nil {
+		return nil, err
+	}
+	return &alicloudInventory{accessKey: accessKey, secretKey: secretKey, regions: regions}, nil
+}
+
+func (s *alicloudInventory) Provider() string { return "alicloud" }
+
+func (s *alicloudInventory) List(ctx context.Context) ([]CloudResource, error) {
+	return listRegions(ctx, s.regions, s.listRegion)
+}
+
+func (s *alicloudInventory) listRegion(ctx context.Context, region string) ([]CloudResource, error) {
+	client, err := alicloudecs.NewClientWithAccessKey(region, s.accessKey, s.secretKey)
+	if err != nil {
+		return nil, err
+	}
+	var resources []CloudResource
+	nextToken := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		req := alicloudecs.CreateDescribeInstancesRequest()
+		req.Scheme = "https"
+		req.MaxResults = requests.NewInteger(100)
+		req.NextToken = nextToken
+		resp, err := client.DescribeInstances(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, inst := range resp.Instances.Instance {
+			var tags map[string]string
+			for _, t := range inst.Tags.Tag {
+				key, value := t.TagKey, t.TagValue
+				if key == "" {
+					key, value = t.Key, t.Value
+				}
+				if key != "" {
+					if tags == nil {
+						tags = make(map[string]string)
+					}
+					tags[key] = value
+				}
+			}
+			resources = append(resources, CloudResource{
+				Provider:  s.Provider(),
+				Region:    region,
+				Type:      "alicloud_instance",
+				ID:        inst.InstanceId,
+				Name:      inst.InstanceName,
+				State:     inst.Status,
+				CreatedAt: inst.CreationTime,
+				Tags:      tags,
+			})
+		}
+		nextToken = resp.NextToken
+		if nextToken == "" || len(resp.Instances.Instance) == 0 {
+			return resources, nil
+		}
+	}
+}
+
+func (s *alicloudInventory) Delete(ctx context.Context, r CloudResource) error {
+	client, err := alicloudecs.NewClientWithAccessKey(r.Region, s.accessKey, s.secretKey)
+	if err != nil {
+		return err
+	}
+	req := alicloudecs.CreateDeleteInstanceRequest()
+	req.Scheme = "https"
+	req.InstanceId = r.ID
+	// 运行中的实例需要强制释放
+	req.Force = requests.NewBoolean(true)
+	_, err = client.DeleteInstance(req)
+	return err
+}
+
+// tencentInventory 通过 CVM DescribeInstances 列出云主机
+type tencentInventory struct {
+	secretID, secretKey string
+	regions             []string
+}
+
+func newTencentInventory(regions []string) (CloudInventory, error) {
+	secretID, secretKey, err := probeCredentials("tencentcloud")
+	if err != nil {
+		return nil, err
+	}
+	return &tencentInventory{secretID: secretID, secretKey: secretKey, regions: regions}, nil
+}
+
+func (s *tencentInventory) Provider() string { return "tencentcloud" }
+
+func (s *tencentInventory) client(region string) (*cvm.Client, error) {
+	return cvm.NewClient(common.NewCredential(s.secretID, s.secretKey), region, profile.NewClientProfile())
+}
+
+func (s *tencentInventory) List(ctx context.Context) ([]CloudResource, error) {
+	return listRegions(ctx, s.regions, s.listRegion)
+}
+
+func (s *tencentInventory) listRegion(ctx context.Context, region string) ([]CloudResource, error) {
+	client, err := s.client(region)
+	if err != nil {
+		return nil, err
+	}
+	const limit = 100
+	var resources []CloudResource
+	for offset := int64(0); ; offset += limit {
+		req := cvm.NewDescribeInstancesRequest()
+		req.Offset = common.Int64Ptr(offset)
+		req.Limit = common.Int64Ptr(limit)
+		resp, err := client.DescribeInstancesWithContext(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Response == nil {
+			return resources, nil
+		}
+		for _, inst := range resp.Response.InstanceSet {
+			if inst == nil {
+				continue
+			}
+			var tags map[string]string
+			for _, t := range inst.Tags {
+				if t != nil && t.Key != nil {
+					if tags == nil {
+						tags = make(map[string]string)
+					}
+					tags[*t.Key] = stringValue(t.Value)
+				}
+			}
+			resources = append(resources, CloudResource{
+				Provider:  s.Provider(),
+				Region:    region,
+				Type:      "tencentcloud_instance",
+				ID:        stringValue(inst.InstanceId),
+				Name:      stringValue(inst.InstanceName),
+				State:     stringValue(inst.InstanceState),
+				CreatedAt: stringValue(inst.CreatedTime),
+				Tags:      tags,
+			})
+		}
+		if len(resp.Response.InstanceSet) < limit {
+			return resources, nil
+		}
+	}
+}
+
+func (s *tencentInventory) Delete(ctx context.Context, r CloudResource) error {
+	client, err := s.client(r.Region)
+	if err != nil {
+		return err
+	}
+	req := cvm.NewTerminateInstancesRequest()
+	req.InstanceIds = common.StringPtrs([]string{r.ID})
+	_, err = client.TerminateInstancesWithContext(ctx, req)
+	return err
+}
+
+// awsInventory 通过 EC2 DescribeInstances 列出未终止的实例
+type awsInventory struct {
+	accessKey, secretKey string
+	regions              []string
+}
+
+func newAWSInventory(regions []string) (CloudInventory, error) {
+	accessKey, secretKey, err := probeCredentials("aws")
+	if err != nil {
+		return nil, err
+	}
+	return &awsInventory{accessKey: accessKey, secretKey: secretKey, regions: regions}, nil
+}
+
+func (s *awsInventory) Provider() string { return "aws" }
+
+func (s *awsInventory) client(region string) (*ec2.EC2, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		Credentials: awscredentials.NewStaticCredentials(s.accessKey, s.secretKey, ""),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ec2.New(sess), nil
+}
+
+func (s *awsInventory) List(ctx context.Context) ([]CloudResource, error) {
+	return listRegions(ctx, s.regions, s.listRegion)
+}
+
+func (s *awsInventory) listRegion(ctx context.Context, region string) ([]CloudResource, error) {
+	client, err := s.client(region)
+	if err != nil {
+		return nil, err
+	}
+	var resources []CloudResource
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+		}},
+	}
+	err = client.DescribeInstancesPagesWithContext(ctx, input, func(out *ec2.DescribeInstancesOutput, _ bool) bool {
+		for _, res := range out.Reservations {
+			for _, inst := range res.Instances {
+				r := CloudResource{
+					Provider: s.Provider(),
+					Region:   region,
+					Type:     "aws_instance",
+					ID:       aws.StringValue(inst.InstanceId),
+				}
+				if inst.State != nil {
+					r.State = aws.StringValue(inst.State.Name)
+				}
+				if inst.LaunchTime != nil {
+					r.CreatedAt = inst.LaunchTime.UTC().Format(time.RFC3339)
+				}
+				for _, t := range inst.Tags {
+					if r.Tags == nil {
+						r.Tags = make(map[string]string)
+					}
+					r.Tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+				}
+				r.Name = r.Tags["Name"]
+				resources = append(resources, r)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+func (s *awsInventory) Delete(ctx context.Context, r CloudResource) error {
+	client, err := s.client(r.Region)
+	if err != nil {
+		return err
+	}
+	_, err = client.TerminateInstancesWithContext(ctx, &ec2.TerminateInstancesInput{
+		InstanceIds: []*string{aws.String(r.ID)},
+	})
+	return err
+}
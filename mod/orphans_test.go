@@ -0,0 +1,115 @@
+package mod
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeInventory struct {
+	provider  string
+	resources []CloudResource
+	listErr   error
+	deleted   []string
+	deleteErr map[string]error
+}
+
+func (f *fakeInventory) Provider() string { return f.provider }
+
+func (f *fakeInventory) List(ctx context.Context) ([]CloudResource, error) {
+	return f.resources, f.listErr
+}
+
+func (f *fakeInventory) Delete(ctx context.Context, r CloudResource) error {
+	if err := f.deleteErr[r.ID]; err != nil {
+		return err
+	}
+	f.deleted = append(f.deleted, r.ID)
+	return nil
+}
+
+func TestMatchRandomName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"red_pig_aliyun-ecs":   true,
+		"zen_hacker_aws_ec2-1": true,
+		"calm-panda-tencent":   true,
+		"red_pig_":             false,
+		"my-web-server":        false,
+		"purple_unicorn_x":     false,
+		"xred_pig_aliyun":      false,
+	} {
+		if got := MatchRandomName(name); got != want {
+			t.Errorf("MatchRandomName(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if name := RandomName("aliyun/ecs"); !MatchRandomName(name) {
+		t.Errorf("RandomName output %q should match", name)
+	}
+}
+
+func TestScanOrphans(t *testing.T) {
+	known := NewKnownCases([]*Case{
+		{Id: "live", Name: "red_pig_aliyun", ProjectID: "p1"},
+	})
+	ali := &fakeInventory{provider: "alicloud", resources: []CloudResource{
+		{Provider: "alicloud", Region: "cn-hangzhou", ID: "i-1", Name: "anything", Tags: map[string]string{TagCaseID: "live"}},
+		{Provider: "alicloud", Region: "cn-hangzhou", ID: "i-2", Name: "anything", Tags: map[string]string{TagCaseID: "gone", TagProject: "p1"}},
+		{Provider: "alicloud", Region: "cn-hangzhou", ID: "i-3", Name: "red_pig_aliyun-1"},
+		{Provider: "alicloud", Region: "cn-hangzhou", ID: "i-4", Name: "blue_fox_aliyun"},
+		{Provider: "alicloud", Region: "cn-hangzhou", ID: "i-5", Name: "prod-db"},
+		{Provider: "alicloud", Region: "cn-hangzhou", ID: "i-6", Name: "anything", Tags: map[string]string{TagCaseID: "other", TagProject: "p2"}},
+		{Provider: "alicloud", Region: "cn-hangzhou", ID: "i-7", Name: "anything", Tags: map[string]string{TagCaseID: "other"}},
+	}}
+	aws := &fakeInventory{provider: "aws", listErr: errors.New("us-east-1: denied"), resources: []CloudResource{
+		{Provider: "aws", Region: "ap-east-1", ID: "i-aws", Name: "lazy_wolf_aws", Tags: map[string]string{"Name": "lazy_wolf_aws"}},
+	}}
+
+	report := ScanOrphans(context.Background(), []CloudInventory{ali, aws}, known)
+	if len(report.Orphans) != 5 {
+		t.Fatalf("orphans = %+v", report.Orphans)
+	}
+	if o := report.Orphans[0]; o.ID != "i-2" || o.Reason != OrphanByTag || o.CaseID != "gone" || o.Project != "p1" || !o.Deletable {
+		t.Errorf("tagged orphan = %+v", o)
+	}
+	if o := report.Orphans[1]; o.ID != "i-4" || o.Reason != OrphanByName || o.Deletable {
+		t.Errorf("named orphan = %+v", o)
+	}
+	// 项目不在本地（或没有项目标签）的资源可能属于其他机器上的 redc
+	for i, id := range []string{"i-6", "i-7"} {
+		if o := report.Orphans[2+i]; o.ID != id || o.Reason != OrphanByForeign || o.Deletable {
+			t.Errorf("foreign orphan = %+v", o)
+		}
+	}
+	if o := report.Orphans[4]; o.ID != "i-aws" {
+		t.Errorf("partial listing should still report orphans: %+v", o)
+	}
+	if p := report.Providers[1]; p.Error == "" || p.Scanned != 1 || p.Orphans != 1 {
+		t.Errorf("aws status = %+v", p)
+	}
+}
+
+func TestCleanupOrphans(t *testing.T) {
+	inv := &fakeInventory{provider: "alicloud", deleteErr: map[string]error{"i-3": errors.New("denied")}}
+	orphans := []OrphanResource{
+		{CloudResource: CloudResource{Provider: "alicloud", ID: "i-1"}, Deletable: true},
+		{CloudResource: CloudResource{Provider: "alicloud", ID: "i-2"}, Deletable: true},
+		{CloudResource: CloudResource{Provider: "alicloud", ID: "i-3"}, Deletable: true},
+		{CloudResource: CloudResource{Provider: "gcp", ID: "vm-1"}, Deletable: true},
+		{CloudResource: CloudResource{Provider: "alicloud", ID: "i-4"}, Reason: OrphanByForeign},
+	}
+	confirm := func(o OrphanResource) bool { return o.ID != "i-2" }
+	results := CleanupOrphans(context.Background(), []CloudInventory{inv}, orphans, confirm)
+	if !results[0].Deleted || !results[1].Skipped || results[2].Error == "" || results[3].Error == "" || !results[4].Deleted {
+		t.Errorf("results = %+v", results)
+	}
+	if len(inv.deleted) != 2 || inv.deleted[0] != "i-1" || inv.deleted[1] != "i-4" {
+		t.Errorf("deleted = %v", inv.deleted)
+	}
+
+	// --yes：不确认直接删除，但不删除无法归属到本地项目的资源
+	inv.deleted = nil
+	results = CleanupOrphans(context.Background(), []CloudInventory{inv}, []OrphanResource{orphans[0], orphans[1], orphans[4]}, nil)
+	if len(inv.deleted) != 2 || !results[2].Skipped {
+		t.Errorf("deleted without confirm = %v, results = %+v", inv.deleted, results)
+	}
+}
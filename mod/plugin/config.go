@@ -0,0 +1,402 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"red-cloud/mod/gologger"
+)
+
+// Config field types accepted in config_schema
+const (
+	FieldString  = "string"
+	FieldNumber  = "number"
+	FieldInteger = "integer"
+	FieldBoolean = "boolean"
+)
+
+// SecretPlaceholder replaces stored secret values in config returned to the GUI and CLI.
+// Saving the placeholder back keeps the stored value.
+const SecretPlaceholder = "********"
+
+// secretsFileName is the default credential store for secret config fields
+const secretsFileName = "secrets.json"
+
+// validate checks a config_schema field declaration
+func (f ConfigField) validate() error {
+	switch f.Type {
+	case "", FieldString, FieldNumber, FieldInteger, FieldBoolean:
+	default:
+		return fmt.Errorf("unknown type %q", f.Type)
+	}
+	if f.Pattern != "" {
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return fmt.Errorf("min is greater than max")
+	}
+	return nil
+}
+
+// fieldType returns the declared type, defaulting to string
+func (f ConfigField) fieldType() string {
+	if f.Type == "" {
+		return FieldString
+	}
+	return f.Type
+}
+
+// coerce converts a value to the field type. Strings are parsed so values set on the
+// command line (k=v) and JSON values from the GUI are handled alike.
+func (f ConfigField) coerce(v interface{}) (interface{}, error) {
+	s, isString := v.(string)
+	switch f.fieldType() {
+	case FieldString:
+		if !isString {
+			return fmt.Sprintf("%v", v), nil
+		}
+		return s, nil
+	case FieldBoolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		if isString {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("expected a boolean")
+	default:
+		var n float64
+		switch x := v.(type) {
+		case float64:
+			n = x
+		case int:
+			n = float64(x)
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected a number")
+			}
+			n = parsed
+		default:
+			return nil, fmt.Errorf("expected a number")
+		}
+		if f.fieldType() == FieldInteger {
+			if n != float64(int64(n)) {
+				return nil, fmt.Errorf("expected an integer")
+			}
+			return int64(n), nil
+		}
+		return n, nil
+	}
+}
+
+// check validates a coerced value against enum, pattern and min/max. Min and max bound
+// numbers by value and strings by length.
+func (f ConfigField) check(v interface{}) error {
+	if len(f.Enum) > 0 && !containsString(f.Enum, fmt.Sprintf("%v", v)) {
+		return fmt.Errorf("must be one of %s", strings.Join(f.Enum, ", "))
+	}
+	var size float64
+	switch x := v.(type) {
+	case string:
+		if f.Pattern != "" {
+			if re, err := regexp.Compile(f.Pattern); err == nil && !re.MatchString(x) {
+				return fmt.Errorf("does not match pattern %s", f.Pattern)
+			}
+		}
+		size = float64(len([]rune(x)))
+	case float64:
+		size = x
+	case int64:
+		size = float64(x)
+	default:
+		return nil
+	}
+	if f.Min != nil && size < *f.Min {
+		if _, ok := v.(string); ok {
+			return fmt.Errorf("must be at least %v characters", *f.Min)
+		}
+		return fmt.Errorf("must be at least %v", *f.Min)
+	}
+	if f.Max != nil && size > *f.Max {
+		if _, ok := v.(string); ok {
+			return fmt.Errorf("must be at most %v characters", *f.Max)
+		}
+		return fmt.Errorf("must be at most %v", *f.Max)
+	}
+	return nil
+}
+
+// ConfigError lists every invalid field of a config
+type ConfigError struct {
+	Plugin string
+	Fields map[string]string // field → problem
+}
+
+func (e *ConfigError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+": "+e.Fields[k])
+	}
+	return fmt.Sprintf("invalid config for plugin %s: %s", e.Plugin, strings.Join(parts, "; "))
+}
+
+// ValidateConfig checks cfg against the plugin's config_schema and returns it with values
+// converted to their declared types. Empty strings count as unset. Without a schema the
+// config is returned unchanged.
+func ValidateConfig(m PluginManifest, cfg map[string]interface{}) (map[string]interface{}, error) {
+	if len(m.ConfigSchema) == 0 {
+		return cfg, nil
+	}
+	problems := make(map[string]string)
+	result := make(map[string]interface{})
+	for key, v := range cfg {
+		field, ok := m.ConfigSchema[key]
+		if !ok {
+			problems[key] = "unknown field"
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		if s, ok := v.(string); ok && s == "" {
+			continue
+		}
+		coerced, err := field.coerce(v)
+		if err == nil {
+			err = field.check(coerced)
+		}
+		if err != nil {
+			problems[key] = err.Error()
+			continue
+		}
+		result[key] = coerced
+	}
+	for key, field := range m.ConfigSchema {
+		if _, set := result[key]; !set && field.Required && field.Default == "" && problems[key] == "" {
+			problems[key] = "required"
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ConfigError{Plugin: m.Name, Fields: problems}
+	}
+	return result, nil
+}
+
+// SecretStore keeps the values of secret config fields outside the plugin directory.
+// The default store is a 0600 JSON file in the plugins directory; SetSecretStore can
+// replace it with an OS keychain.
+type SecretStore interface {
+	Get(plugin, key string) (string, bool)
+	Set(plugin, key, value string) error
+	Delete(plugin, key string) error
+	DeletePlugin(plugin string) error
+}
+
+// SetSecretStore replaces the credential store used for secret config fields
+func (pm *PluginManager) SetSecretStore(s SecretStore) {
+	pm.secrets = s
+}
+
+// fileSecretStore stores secrets as plugin → key → value in a file readable by the owner only
+type fileSecretStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (s *fileSecretStore) read() map[string]map[string]string {
+	all := make(map[string]map[string]string)
+	if data, err := os.ReadFile(s.path); err == nil {
+		_ = json.Unmarshal(data, &all)
+	}
+	return all
+}
+
+func (s *fileSecretStore) write(all map[string]map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+func (s *fileSecretStore) Get(plugin, key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.read()[plugin][key]
+	return v, ok
+}
+
+func (s *fileSecretStore) Set(plugin, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := s.read()
+	if all[plugin] == nil {
+		all[plugin] = make(map[string]string)
+	}
+	all[plugin][key] = value
+	return s.write(all)
+}
+
+func (s *fileSecretStore) Delete(plugin, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := s.read()
+	if _, ok := all[plugin][key]; !ok {
+		return nil
+	}
+	delete(all[plugin], key)
+	if len(all[plugin]) == 0 {
+		delete(all, plugin)
+	}
+	return s.write(all)
+}
+
+func (s *fileSecretStore) DeletePlugin(plugin string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := s.read()
+	if _, ok := all[plugin]; !ok {
+		return nil
+	}
+	delete(all, plugin)
+	return s.write(all)
+}
+
+// secretFields returns the names of the secret fields in a schema
+func secretFields(m PluginManifest) []string {
+	var keys []string
+	for key, field := range m.ConfigSchema {
+		if field.Secret {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SaveConfig validates config against the schema and saves it. Secret fields go to the
+// credential store, everything else to config.json in the plugin dir. A secret set to
+// SecretPlaceholder keeps its stored value; an empty secret is removed.
+func (pm *PluginManager) SaveConfig(name string, config map[string]interface{}) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	p, ok := pm.plugins[name]
+	if !ok {
+		return fmt.Errorf("plugin %s not found", name)
+	}
+	store := pm.secrets
+
+	input := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		input[k] = v
+	}
+	for _, key := range secretFields(p.Manifest) {
+		if v, ok := input[key]; ok && v != SecretPlaceholder {
+			continue
+		}
+		if stored, ok := store.Get(name, key); ok {
+			input[key] = stored
+		} else {
+			delete(input, key)
+		}
+	}
+	validated, err := ValidateConfig(p.Manifest, input)
+	if err != nil {
+		return err
+	}
+
+	plain := make(map[string]interface{})
+	for k, v := range validated {
+		if field, ok := p.Manifest.ConfigSchema[k]; ok && field.Secret {
+			continue
+		}
+		plain[k] = v
+	}
+	data, err := json.MarshalIndent(plain, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal config: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(p.Dir, "config.json"), data, 0644); err != nil {
+		return fmt.Errorf("cannot write config: %w", err)
+	}
+
+	for _, key := range secretFields(p.Manifest) {
+		if v, ok := validated[key]; ok {
+			err = store.Set(name, key, fmt.Sprintf("%v", v))
+		} else {
+			err = store.Delete(name, key)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot store secret %s: %w", key, err)
+		}
+	}
+
+	p.Config = plain
+	return nil
+}
+
+// ConfigView returns the saved config of a plugin with stored secrets shown as SecretPlaceholder
+func (pm *PluginManager) ConfigView(name string) (map[string]interface{}, error) {
+	p, ok := pm.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s not found", name)
+	}
+	view := make(map[string]interface{}, len(p.Config))
+	for k, v := range p.Config {
+		view[k] = v
+	}
+	for _, key := range secretFields(p.Manifest) {
+		if _, ok := pm.secrets.Get(name, key); ok {
+			view[key] = SecretPlaceholder
+		}
+	}
+	return view, nil
+}
+
+// migrateSecrets moves secret fields still present in config.json to the credential store,
+// for plugins that marked a field secret after it was saved
+func (pm *PluginManager) migrateSecrets(p *Plugin) {
+	moved := false
+	for _, key := range secretFields(p.Manifest) {
+		v, ok := p.Config[key]
+		if !ok {
+			continue
+		}
+		if err := pm.secrets.Set(p.Manifest.Name, key, fmt.Sprintf("%v", v)); err != nil {
+			gologger.Warning().Msgf("plugin: cannot store secret %s of %s: %v", key, p.Manifest.Name, err)
+			return
+		}
+		delete(p.Config, key)
+		moved = true
+	}
+	if !moved {
+		return
+	}
+	data, _ := json.MarshalIndent(p.Config, "", "  ")
+	if err := os.WriteFile(filepath.Join(p.Dir, "config.json"), data, 0644); err != nil {
+		gologger.Warning().Msgf("plugin: cannot rewrite config of %s: %v", p.Manifest.Name, err)
+		return
+	}
+	gologger.Info().Msgf("plugin: moved secret config of %s to the credential store", p.Manifest.Name)
+}
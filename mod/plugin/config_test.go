@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func float(v float64) *float64 { return &v }
+
+func TestValidateConfig(t *testing.T) {
+	m := PluginManifest{Name: "p", ConfigSchema: map[string]ConfigField{
+		"port":    {Type: FieldInteger, Required: true, Min: float(1), Max: float(65535)},
+		"mode":    {Type: FieldString, Enum: []string{"fast", "safe"}, Default: "safe"},
+		"name":    {Type: FieldString, Pattern: `^[a-z]+$`, Max: float(5)},
+		"ratio":   {Type: FieldNumber},
+		"verbose": {Type: FieldBoolean},
+	}}
+
+	got, err := ValidateConfig(m, map[string]interface{}{"port": "8080", "ratio": "0.5", "verbose": "true", "mode": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["port"] != int64(8080) || got["ratio"] != 0.5 || got["verbose"] != true {
+		t.Errorf("coerced = %#v", got)
+	}
+	if _, ok := got["mode"]; ok {
+		t.Error("empty string should be treated as unset")
+	}
+
+	cases := []struct {
+		cfg  map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{}, "port: required"},
+		{map[string]interface{}{"port": 0.0}, "port: must be at least 1"},
+		{map[string]interface{}{"port": "1.5"}, "port: expected an integer"},
+		{map[string]interface{}{"port": 80.0, "mode": "slow"}, "mode: must be one of fast, safe"},
+		{map[string]interface{}{"port": 80.0, "name": "Abc"}, "name: does not match pattern"},
+		{map[string]interface{}{"port": 80.0, "name": "abcdef"}, "name: must be at most 5 characters"},
+		{map[string]interface{}{"port": 80.0, "verbose": "maybe"}, "verbose: expected a boolean"},
+		{map[string]interface{}{"port": 80.0, "extra": "x"}, "extra: unknown field"},
+	}
+	for _, tc := range cases {
+		if _, err := ValidateConfig(m, tc.cfg); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: err = %v, want %q", tc.cfg, err, tc.want)
+		}
+	}
+}
+
+func TestLoadManifest_InvalidConfigSchema(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "p")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(`{"name": "p", "config_schema": {"host": {"type": "string", "pattern": "("}}}`), 0644)
+	if _, err := loadManifest(dir); err == nil || !strings.Contains(err.Error(), "host") {
+		t.Errorf("err = %v, want invalid pattern", err)
+	}
+}
+
+func TestSaveConfig_Secrets(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "notify", "version": "1.0.0", "capabilities": {"hooks": {"post-apply": "hooks/env.sh"}},
+		"config_schema": {"url": {"type": "string", "required": true}, "token": {"type": "string", "secret": true}}}`, map[string]string{
+		"hooks/env.sh": "echo \"REDC_OUTPUT:token=$REDC_PLUGIN_CONFIG_TOKEN\"\n",
+	})
+	dir := filepath.Join(root, "notify")
+	// a token saved before the field was marked secret
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"url": "https://hook", "token": "old"}`), 0644)
+
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "config.json"))
+	if strings.Contains(string(data), "old") {
+		t.Errorf("config.json still holds the secret: %s", data)
+	}
+	if v, _ := pm.secrets.Get("notify", "token"); v != "old" {
+		t.Errorf("migrated secret = %q", v)
+	}
+
+	if err := pm.SaveConfig("notify", map[string]interface{}{"url": "https://hook", "token": "s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+	view, _ := pm.ConfigView("notify")
+	if view["token"] != SecretPlaceholder || view["url"] != "https://hook" {
+		t.Errorf("view = %v", view)
+	}
+	p, _ := pm.Get("notify")
+	if raw, _ := json.Marshal(p); strings.Contains(string(raw), "s3cret") {
+		t.Error("secret must not be part of the plugin config")
+	}
+
+	// saving the placeholder back keeps the stored value and hooks receive it
+	if err := pm.SaveConfig("notify", view); err != nil {
+		t.Fatal(err)
+	}
+	results, err := pm.RunHooks(HookPostApply, &HookContext{AllowedPlugins: []string{"notify"}})
+	if err != nil || len(results) != 1 || results[0].Outputs["token"] != "s3cret" {
+		t.Fatalf("results = %+v, err = %v", results, err)
+	}
+
+	if err := pm.SaveConfig("notify", map[string]interface{}{"token": "x"}); err == nil {
+		t.Error("missing required url should fail")
+	}
+	if err := pm.Uninstall("notify"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pm.secrets.Get("notify", "token"); ok {
+		t.Error("uninstall should remove stored secrets")
+	}
+}
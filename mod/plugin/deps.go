@@ -0,0 +1,333 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+
+	redc "red-cloud/mod"
+	"red-cloud/mod/gologger"
+)
+
+// redcVersion returns the running redc version plugins are checked against
+var redcVersion = func() string { return redc.Version }
+
+// parseConstraint parses a version constraint such as ">= 1.2, < 2.0" or "~> 1.4".
+// An empty constraint or "*" accepts any version and yields nil.
+func parseConstraint(s string) (version.Constraints, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "*" {
+		return nil, nil
+	}
+	c, err := version.NewConstraint(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+	}
+	return c, nil
+}
+
+// satisfies reports whether v meets constraint; an unparsable version only meets an empty constraint
+func satisfies(v, constraint string) bool {
+	c, err := parseConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	if c == nil {
+		return true
+	}
+	parsed, err := version.NewVersion(v)
+	if err != nil {
+		return false
+	}
+	return c.Check(parsed)
+}
+
+// validateConstraints checks the redc and dependency constraints declared in plugin.json
+func validateConstraints(m PluginManifest) error {
+	if m.MinRedCVersion != "" {
+		if _, err := version.NewVersion(m.MinRedCVersion); err != nil {
+			return fmt.Errorf("invalid min_redc_version %q", m.MinRedCVersion)
+		}
+	}
+	if _, err := parseConstraint(m.RedcVersion); err != nil {
+		return fmt.Errorf("redc_version: %w", err)
+	}
+	for dep, constraint := range m.Dependencies {
+		if dep == m.Name {
+			return fmt.Errorf("plugin cannot depend on itself")
+		}
+		if _, err := parseConstraint(constraint); err != nil {
+			return fmt.Errorf("dependency %s: %w", dep, err)
+		}
+	}
+	return nil
+}
+
+// checkRedcCompatible checks min_redc_version and redc_version against the running redc.
+// Development builds whose version cannot be parsed are not checked.
+func checkRedcCompatible(m PluginManifest) error {
+	current, err := version.NewVersion(redcVersion())
+	if err != nil {
+		return nil
+	}
+	if m.MinRedCVersion != "" {
+		if min, err := version.NewVersion(m.MinRedCVersion); err == nil && current.LessThan(min) {
+			return fmt.Errorf("plugin %s requires redc >= %s (running %s)", m.Name, m.MinRedCVersion, redcVersion())
+		}
+	}
+	if c, err := parseConstraint(m.RedcVersion); err == nil && c != nil && !c.Check(current) {
+		return fmt.Errorf("plugin %s requires redc %s (running %s)", m.Name, m.RedcVersion, redcVersion())
+	}
+	return nil
+}
+
+// dependencyProblems lists the unmet dependencies of m among plugins. Callers hold pm.mu.
+func dependencyProblems(m PluginManifest, plugins map[string]*Plugin) []string {
+	var problems []string
+	for _, dep := range sortedDependencies(m) {
+		constraint := m.Dependencies[dep]
+		p, ok := plugins[dep]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("requires %s %s (not installed)", dep, constraintText(constraint)))
+		case !satisfies(p.Manifest.Version, constraint):
+			problems = append(problems, fmt.Sprintf("requires %s %s (installed v%s)", dep, constraintText(constraint), p.Manifest.Version))
+		case !p.Enabled:
+			problems = append(problems, fmt.Sprintf("requires %s (disabled)", dep))
+		}
+	}
+	return problems
+}
+
+func sortedDependencies(m PluginManifest) []string {
+	deps := make([]string, 0, len(m.Dependencies))
+	for dep := range m.Dependencies {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+func constraintText(c string) string {
+	if strings.TrimSpace(c) == "" {
+		return "*"
+	}
+	return c
+}
+
+// checkDependencies disables plugins that cannot run with this redc or whose dependencies are
+// missing, too old or disabled, repeating until no more plugins are affected. Callers hold pm.mu.
+func (pm *PluginManager) checkDependencies() {
+	for changed := true; changed; {
+		changed = false
+		for _, p := range pm.plugins {
+			if !p.Enabled {
+				continue
+			}
+			problem := ""
+			if err := checkRedcCompatible(p.Manifest); err != nil {
+				problem = err.Error()
+			} else if problems := dependencyProblems(p.Manifest, pm.plugins); len(problems) > 0 {
+				problem = strings.Join(problems, "; ")
+			}
+			if problem != "" {
+				p.DependencyError = problem
+				p.Enabled = false
+				changed = true
+				gologger.Warning().Msgf("plugin: %s disabled: %s", p.Manifest.Name, problem)
+			}
+		}
+	}
+}
+
+// dependents returns the plugins that declare name as a dependency, only enabled ones when
+// enabledOnly is set. Callers hold pm.mu.
+func (pm *PluginManager) dependents(name string, enabledOnly bool) []string {
+	var result []string
+	for _, p := range pm.plugins {
+		if _, ok := p.Manifest.Dependencies[name]; ok && (p.Enabled || !enabledOnly) {
+			result = append(result, p.Manifest.Name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// resolveDependencies makes sure every dependency of a newly installed or updated plugin is
+// installed, satisfies its constraint and is enabled. Missing or outdated dependencies are
+// installed from the registry when it offers a matching version. stack holds the plugins being
+// resolved to detect cycles.
+func (pm *PluginManager) resolveDependencies(m PluginManifest, stack []string) error {
+	if err := checkRedcCompatible(m); err != nil {
+		return err
+	}
+	stack = append(stack, m.Name)
+	for _, dep := range sortedDependencies(m) {
+		constraint := m.Dependencies[dep]
+		if containsString(stack, dep) {
+			return fmt.Errorf("dependency cycle: %s -> %s", strings.Join(stack, " -> "), dep)
+		}
+		p, installed := pm.Get(dep)
+		if installed && satisfies(p.Manifest.Version, constraint) {
+			if !p.Enabled && p.IntegrityError == "" {
+				if err := pm.enableDependency(dep); err != nil {
+					return fmt.Errorf("%s requires %s: %w", m.Name, dep, err)
+				}
+			}
+			continue
+		}
+
+		rp, ok := pm.registryEntry(dep)
+		if !ok {
+			if installed {
+				return fmt.Errorf("%s requires %s %s, installed v%s and the registry has no other version", m.Name, dep, constraintText(constraint), p.Manifest.Version)
+			}
+			return fmt.Errorf("%s requires %s %s, which is not installed and not in the registry", m.Name, dep, constraintText(constraint))
+		}
+		if !satisfies(rp.Version, constraint) {
+			return fmt.Errorf("%s requires %s %s, the registry offers v%s", m.Name, dep, constraintText(constraint), rp.Version)
+		}
+		if installed {
+			if conflict := pm.constraintConflict(dep, rp.Version, m.Name); conflict != "" {
+				return fmt.Errorf("%s requires %s %s, but %s", m.Name, dep, constraintText(constraint), conflict)
+			}
+		}
+		gologger.Info().Msgf("plugin: installing dependency %s v%s for %s", dep, rp.Version, m.Name)
+		var err error
+		if installed {
+			_, err = pm.reinstall(dep, rp.URL, rp.Integrity(), stack)
+		} else {
+			_, err = pm.install(rp.URL, rp.Integrity(), stack)
+		}
+		if err == nil {
+			if p, ok := pm.Get(dep); ok && !p.Enabled {
+				err = pm.enableDependency(dep)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s requires %s: %w", m.Name, dep, err)
+		}
+	}
+	return nil
+}
+
+// constraintConflict reports an installed plugin other than except whose constraint on dep
+// rejects version v. Callers must not hold pm.mu.
+func (pm *PluginManager) constraintConflict(dep, v, except string) string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	for _, name := range pm.dependents(dep, false) {
+		if name == except {
+			continue
+		}
+		if constraint := pm.plugins[name].Manifest.Dependencies[dep]; !satisfies(v, constraint) {
+			return fmt.Sprintf("%s requires %s %s", name, dep, constraint)
+		}
+	}
+	return ""
+}
+
+// enableDependency enables a disabled dependency after checking its own requirements
+func (pm *PluginManager) enableDependency(name string) error {
+	pm.mu.Lock()
+	p := pm.plugins[name]
+	problems := dependencyProblems(p.Manifest, pm.plugins)
+	pm.mu.Unlock()
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return pm.Enable(name)
+}
+
+// finishInstall checks the requirements of a freshly installed plugin and removes it again
+// when they cannot be met
+func (pm *PluginManager) finishInstall(name string, stack []string) error {
+	p, ok := pm.Get(name)
+	if !ok {
+		return nil
+	}
+	if err := pm.resolveDependencies(p.Manifest, stack); err != nil {
+		pm.mu.Lock()
+		delete(pm.plugins, name)
+		pm.mu.Unlock()
+		if rerr := os.RemoveAll(p.Dir); rerr != nil {
+			gologger.Warning().Msgf("plugin: cannot remove %s: %v", name, rerr)
+		}
+		if uerr := pm.unpin(name); uerr != nil {
+			gologger.Warning().Msgf("plugin: cannot update %s: %v", lockFileName, uerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// DependencyNode is one plugin in the dependency tree
+type DependencyNode struct {
+	Name       string            `json:"name"`
+	Version    string            `json:"version,omitempty"`
+	Constraint string            `json:"constraint,omitempty"` // as required by the parent
+	Status     string            `json:"status"`               // ok, disabled, missing, unsatisfied or cycle
+	Children   []*DependencyNode `json:"children,omitempty"`
+}
+
+// DependencyTree returns installed plugins as trees rooted at plugins no other plugin depends on.
+// Plugins that are only part of a cycle become roots as well.
+func (pm *PluginManager) DependencyTree() []*DependencyNode {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	required := make(map[string]bool)
+	for _, p := range pm.plugins {
+		for dep := range p.Manifest.Dependencies {
+			required[dep] = true
+		}
+	}
+	names := make([]string, 0, len(pm.plugins))
+	for name := range pm.plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var roots []*DependencyNode
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !required[name] {
+			roots = append(roots, pm.dependencyNode(name, "", nil, seen))
+		}
+	}
+	for _, name := range names {
+		if !seen[name] {
+			roots = append(roots, pm.dependencyNode(name, "", nil, seen))
+		}
+	}
+	return roots
+}
+
+func (pm *PluginManager) dependencyNode(name, constraint string, path []string, seen map[string]bool) *DependencyNode {
+	node := &DependencyNode{Name: name, Constraint: constraint, Status: "ok"}
+	p, ok := pm.plugins[name]
+	if !ok {
+		node.Status = "missing"
+		return node
+	}
+	seen[name] = true
+	node.Version = p.Manifest.Version
+	switch {
+	case !satisfies(p.Manifest.Version, constraint):
+		node.Status = "unsatisfied"
+	case !p.Enabled:
+		node.Status = "disabled"
+	}
+	if containsString(path, name) {
+		node.Status = "cycle"
+		return node
+	}
+	path = append(path, name)
+	for _, dep := range sortedDependencies(p.Manifest) {
+		node.Children = append(node.Children, pm.dependencyNode(dep, p.Manifest.Dependencies[dep], path, seen))
+	}
+	return node
+}
@@ -0,0 +1,204 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// depManifest returns plugin.json content declaring the given dependencies
+func depManifest(t *testing.T, name, version string, deps map[string]string) string {
+	t.Helper()
+	data, err := json.Marshal(PluginManifest{Name: name, Version: version, Dependencies: deps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSatisfies(t *testing.T) {
+	cases := []struct {
+		version, constraint string
+		want                bool
+	}{
+		{"1.2.0", "", true},
+		{"1.2.0", "*", true},
+		{"1.2.0", ">= 1.0, < 2.0", true},
+		{"2.0.0", ">= 1.0, < 2.0", false},
+		{"1.4.9", "~> 1.4", true},
+		{"1.5.0", "~> 1.4.0", false},
+		{"v3.2.2", ">= 3.2", true},
+		{"dev", ">= 1.0", false},
+	}
+	for _, tc := range cases {
+		if got := satisfies(tc.version, tc.constraint); got != tc.want {
+			t.Errorf("satisfies(%q, %q) = %v, want %v", tc.version, tc.constraint, got, tc.want)
+		}
+	}
+}
+
+func TestLoadManifest_InvalidConstraints(t *testing.T) {
+	cases := map[string]PluginManifest{
+		"redc_version": {Name: "p", Version: "1.0.0", RedcVersion: ">= banana"},
+		"dependency":   {Name: "p", Version: "1.0.0", Dependencies: map[string]string{"q": "=> 1"}},
+		"itself":       {Name: "p", Version: "1.0.0", Dependencies: map[string]string{"p": ""}},
+	}
+	for name, m := range cases {
+		dir := t.TempDir()
+		data, _ := json.Marshal(m)
+		os.WriteFile(filepath.Join(dir, "plugin.json"), data, 0644)
+		if _, err := loadManifest(dir); err == nil {
+			t.Errorf("%s: invalid constraint accepted", name)
+		}
+	}
+}
+
+func TestLoadAll_DisablesUnmetDependencies(t *testing.T) {
+	old := redcVersion
+	redcVersion = func() string { return "v3.2.2" }
+	defer func() { redcVersion = old }()
+
+	root := t.TempDir()
+	writePlugin(t, root, depManifest(t, "base", "1.0.0", nil), nil)
+	writePlugin(t, root, depManifest(t, "scanner", "1.0.0", map[string]string{"base": ">= 2.0"}), nil)
+	writePlugin(t, root, depManifest(t, "report", "1.0.0", map[string]string{"scanner": ""}), nil)
+	writePlugin(t, root, depManifest(t, "ok", "1.0.0", map[string]string{"base": "~> 1.0"}), nil)
+	writePlugin(t, root, depManifest(t, "future", "1.0.0", nil), nil)
+	m, _ := json.Marshal(PluginManifest{Name: "future", Version: "1.0.0", RedcVersion: ">= 4.0"})
+	os.WriteFile(filepath.Join(root, "future", "plugin.json"), m, 0644)
+
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"scanner": "requires base >= 2.0 (installed v1.0.0)",
+		"report":  "requires scanner (disabled)",
+		"future":  "requires redc >= 4.0",
+	} {
+		p, _ := pm.Get(name)
+		if p.Enabled || !strings.Contains(p.DependencyError, want) {
+			t.Errorf("%s: enabled=%v dependency_error=%q, want %q", name, p.Enabled, p.DependencyError, want)
+		}
+	}
+	if p, _ := pm.Get("ok"); !p.Enabled || p.DependencyError != "" {
+		t.Errorf("ok = %+v", p)
+	}
+
+	if err := pm.Disable("base"); err == nil || !strings.Contains(err.Error(), "required by ok") {
+		t.Errorf("Disable(base) err = %v", err)
+	}
+	if err := pm.Uninstall("base"); err == nil || !strings.Contains(err.Error(), "required by ok, scanner") {
+		t.Errorf("Uninstall(base) err = %v", err)
+	}
+	if err := pm.Enable("scanner"); err == nil {
+		t.Error("Enable(scanner) succeeded with an unmet dependency")
+	}
+
+	tree := pm.DependencyTree()
+	var names []string
+	for _, n := range tree {
+		names = append(names, n.Name)
+	}
+	if strings.Join(names, ",") != "future,ok,report" {
+		t.Fatalf("roots = %v", names)
+	}
+	scanner := tree[2].Children[0]
+	if scanner.Name != "scanner" || scanner.Status != "disabled" || scanner.Children[0].Status != "unsatisfied" {
+		t.Errorf("report subtree = %+v / %+v", scanner, scanner.Children[0])
+	}
+}
+
+func TestInstall_ResolvesDependencies(t *testing.T) {
+	archives := map[string][]byte{
+		"base":  pluginZip(t, depManifest(t, "base", "1.2.0", nil)),
+		"loopa": pluginZip(t, depManifest(t, "loopa", "1.0.0", map[string]string{"loopb": ""})),
+		"loopb": pluginZip(t, depManifest(t, "loopb", "1.0.0", map[string]string{"loopa": ""})),
+	}
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	var index RegistryIndex
+	for name, archive := range archives {
+		archive := archive
+		mux.HandleFunc("/"+name+".zip", func(w http.ResponseWriter, r *http.Request) { w.Write(archive) })
+		version := "1.0.0"
+		if name == "base" {
+			version = "1.2.0"
+		}
+		index.Plugins = append(index.Plugins, RegistryPlugin{Name: name, Version: version, URL: srv.URL + "/" + name + ".zip"})
+	}
+	mux.HandleFunc("/registry.json", func(w http.ResponseWriter, r *http.Request) { json.NewEncoder(w).Encode(index) })
+
+	pm := NewPluginManager(t.TempDir())
+	pm.SetRegistryURL(srv.URL + "/registry.json")
+
+	src := t.TempDir()
+	writePlugin(t, src, depManifest(t, "app", "1.0.0", map[string]string{"base": ">= 1.0"}), nil)
+	if _, err := pm.Install(filepath.Join(src, "app")); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := pm.Get("base"); !ok || !p.Enabled || p.Manifest.Version != "1.2.0" {
+		t.Errorf("dependency base = %+v, %v", p, ok)
+	}
+
+	writePlugin(t, src, depManifest(t, "strict", "1.0.0", map[string]string{"base": ">= 2.0"}), nil)
+	if _, err := pm.Install(filepath.Join(src, "strict")); err == nil || !strings.Contains(err.Error(), "registry offers v1.2.0") {
+		t.Errorf("Install(strict) err = %v", err)
+	}
+	if _, ok := pm.Get("strict"); ok {
+		t.Error("plugin with unmet dependencies stayed installed")
+	}
+
+	_, err := pm.Install(srv.URL + "/loopa.zip")
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle: loopa -> loopb -> loopa") {
+		t.Fatalf("Install(loopa) err = %v", err)
+	}
+	for _, name := range []string{"loopa", "loopb"} {
+		if _, err := os.Stat(filepath.Join(pm.PluginsDir(), name)); err == nil {
+			t.Errorf("%s was left installed after the cycle was detected", name)
+		}
+		if _, ok := pm.Lock().Plugins[name]; ok {
+			t.Errorf("%s is still pinned", name)
+		}
+	}
+}
+
+func TestUpdate_RejectsVersionDependentsRefuse(t *testing.T) {
+	v1, v2 := pluginZip(t, depManifest(t, "base", "1.0.0", nil)), pluginZip(t, depManifest(t, "base", "2.0.0", nil))
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/base-1.zip", func(w http.ResponseWriter, r *http.Request) { w.Write(v1) })
+	mux.HandleFunc("/base-2.zip", func(w http.ResponseWriter, r *http.Request) { w.Write(v2) })
+	index := RegistryIndex{Plugins: []RegistryPlugin{{Name: "base", Version: "2.0.0", URL: srv.URL + "/base-2.zip"}}}
+	mux.HandleFunc("/registry.json", func(w http.ResponseWriter, r *http.Request) { json.NewEncoder(w).Encode(index) })
+
+	pm := NewPluginManager(t.TempDir())
+	pm.SetRegistryURL(srv.URL + "/registry.json")
+	if _, err := pm.Install(srv.URL + "/base-1.zip"); err != nil {
+		t.Fatal(err)
+	}
+	src := t.TempDir()
+	writePlugin(t, src, depManifest(t, "app", "1.0.0", map[string]string{"base": "< 2.0"}), nil)
+	if _, err := pm.Install(filepath.Join(src, "app")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := pm.Update("base"); err == nil || !strings.Contains(err.Error(), "app requires base") {
+		t.Fatalf("Update(base) err = %v", err)
+	}
+	if p, ok := pm.Get("base"); !ok || p.Manifest.Version != "1.0.0" {
+		t.Errorf("base after rejected update = %+v, %v", p, ok)
+	}
+	if entry := pm.Lock().Plugins["base"]; entry.Version != "1.0.0" {
+		t.Errorf("lock entry after rejected update = %+v", entry)
+	}
+	if m, err := loadManifest(filepath.Join(pm.PluginsDir(), "base")); err != nil || m.Version != "1.0.0" {
+		t.Errorf("base on disk = %+v, %v", m, err)
+	}
+}
@@ -0,0 +1,261 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultToolTimeout is used when a plugin tool does not declare a timeout
+const DefaultToolTimeout = 2 * time.Minute
+
+// ToolSpec declares an MCP tool implemented by a plugin script. The script receives the tool
+// arguments as a JSON object on stdin and writes its JSON result to stdout:
+//
+//	{"name": "lookup", "description": "...", "input_schema": {"type": "object", ...}, "command": "tools/lookup.sh"}
+type ToolSpec struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema,omitempty"` // JSON schema of the arguments, default an empty object
+	Command     string          `json:"command"`                // script relative to the plugin dir
+	Timeout     string          `json:"timeout,omitempty"`      // Go duration, default 2m
+}
+
+// CommandSpec declares a CLI subcommand run as `redc <plugin> <name> [args...]`.
+// The script gets the remaining arguments and the terminal's stdin/stdout/stderr.
+type CommandSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Command     string `json:"command"` // script relative to the plugin dir
+}
+
+// PluginTool is a tool contributed by an enabled plugin
+type PluginTool struct {
+	Plugin string   `json:"plugin"`
+	Spec   ToolSpec `json:"spec"`
+}
+
+// QualifiedName is the tool name exposed over MCP: <plugin>__<tool>
+func (t PluginTool) QualifiedName() string {
+	return t.Plugin + "__" + t.Spec.Name
+}
+
+var extensionNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,48}$`)
+
+// validateExtensions checks the tools and commands declared in plugin.json
+func validateExtensions(c PluginCapabilities) error {
+	seen := make(map[string]bool)
+	for _, t := range c.Tools {
+		if !extensionNamePattern.MatchString(t.Name) || strings.Contains(t.Name, "__") {
+			return fmt.Errorf("invalid tool name %q", t.Name)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("duplicate tool %s", t.Name)
+		}
+		seen[t.Name] = true
+		if t.Command == "" {
+			return fmt.Errorf("tool %s: missing command", t.Name)
+		}
+		if err := checkScriptPath(t.Command); err != nil {
+			return fmt.Errorf("tool %s: %w", t.Name, err)
+		}
+		if len(t.InputSchema) > 0 {
+			var schema struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(t.InputSchema, &schema); err != nil {
+				return fmt.Errorf("tool %s: invalid input_schema: %w", t.Name, err)
+			}
+			if schema.Type != "" && schema.Type != "object" {
+				return fmt.Errorf("tool %s: input_schema type must be object", t.Name)
+			}
+		}
+		if t.Timeout != "" {
+			if d, err := time.ParseDuration(t.Timeout); err != nil || d <= 0 {
+				return fmt.Errorf("tool %s: invalid timeout %q", t.Name, t.Timeout)
+			}
+		}
+	}
+	seen = make(map[string]bool)
+	for _, cmd := range c.Commands {
+		if !extensionNamePattern.MatchString(cmd.Name) {
+			return fmt.Errorf("invalid command name %q", cmd.Name)
+		}
+		if seen[cmd.Name] {
+			return fmt.Errorf("duplicate command %s", cmd.Name)
+		}
+		seen[cmd.Name] = true
+		if cmd.Command == "" {
+			return fmt.Errorf("command %s: missing command", cmd.Name)
+		}
+		if err := checkScriptPath(cmd.Command); err != nil {
+			return fmt.Errorf("command %s: %w", cmd.Name, err)
+		}
+	}
+	return nil
+}
+
+// GetTools returns the MCP tools of all enabled plugins, sorted by qualified name
+func (pm *PluginManager) GetTools() []PluginTool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	var tools []PluginTool
+	for _, p := range pm.plugins {
+		if !p.Enabled {
+			continue
+		}
+		for _, spec := range p.Manifest.Capabilities.Tools {
+			tools = append(tools, PluginTool{Plugin: p.Manifest.Name, Spec: spec})
+		}
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].QualifiedName() < tools[j].QualifiedName() })
+	return tools
+}
+
+// FindTool looks up an enabled plugin tool by its qualified name
+func (pm *PluginManager) FindTool(qualifiedName string) (PluginTool, bool) {
+	for _, t := range pm.GetTools() {
+		if t.QualifiedName() == qualifiedName {
+			return t, true
+		}
+	}
+	return PluginTool{}, false
+}
+
+// enabledPlugin returns an enabled plugin or an error explaining why it cannot run
+func (pm *PluginManager) enabledPlugin(name string) (*Plugin, error) {
+	p, ok := pm.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s not found", name)
+	}
+	if !p.Enabled {
+		return nil, fmt.Errorf("plugin %s is disabled", name)
+	}
+	return p, nil
+}
+
+// RunTool runs a plugin tool with args as JSON on stdin and returns the JSON it wrote to stdout.
+// The script runs in the same sandbox as hooks. A non-zero exit returns an error with the
+// script's stderr; stdout that is not valid JSON is an error too.
+func (pm *PluginManager) RunTool(tool PluginTool, args map[string]interface{}) (json.RawMessage, error) {
+	p, err := pm.enabledPlugin(tool.Plugin)
+	if err != nil {
+		return nil, err
+	}
+	entry := pm.scriptEntry(p, tool.Spec.Command, pm.Approvals(), pm.Lock())
+	dataDir, err := prepareDataDir(p.Dir)
+	if err != nil {
+		return nil, err
+	}
+	warnPendingCredentials(entry)
+
+	timeout := DefaultToolTimeout
+	if d, err := time.ParseDuration(tool.Spec.Timeout); err == nil && d > 0 {
+		timeout = d
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	input, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode arguments: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "bash", entry.ScriptPath)
+	cmd.Dir = dataDir
+	cmd.WaitDelay = 5 * time.Second
+	cmd.Env = append(sandboxEnv(entry, dataDir), "REDC_TOOL_NAME="+tool.Spec.Name)
+	cmd.Stdin = bytes.NewReader(input)
+	stdout := &limitedBuffer{limit: MaxHookOutput}
+	stderr := &limitedBuffer{limit: MaxHookOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("tool %s timed out after %s", tool.QualifiedName(), timeout)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(string(stderr.buf))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("tool %s failed: %s", tool.QualifiedName(), msg)
+	}
+	if stdout.truncated {
+		return nil, fmt.Errorf("tool %s wrote more than %d bytes", tool.QualifiedName(), MaxHookOutput)
+	}
+	out := bytes.TrimSpace(stdout.buf)
+	if !json.Valid(out) {
+		return nil, fmt.Errorf("tool %s did not write JSON to stdout", tool.QualifiedName())
+	}
+	return json.RawMessage(out), nil
+}
+
+// PluginCommands returns the CLI subcommands of each enabled plugin by plugin name
+func (pm *PluginManager) PluginCommands() map[string][]CommandSpec {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	result := make(map[string][]CommandSpec)
+	for _, p := range pm.plugins {
+		if p.Enabled && len(p.Manifest.Capabilities.Commands) > 0 {
+			result[p.Manifest.Name] = p.Manifest.Capabilities.Commands
+		}
+	}
+	return result
+}
+
+// RunCommand runs a plugin CLI subcommand attached to the given streams and returns its exit code.
+// The script runs in the plugin data directory; REDC_CALLER_DIR holds the directory redc was
+// started from so relative paths in args can be resolved.
+func (pm *PluginManager) RunCommand(pluginName, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	p, err := pm.enabledPlugin(pluginName)
+	if err != nil {
+		return 1, err
+	}
+	var spec *CommandSpec
+	for i := range p.Manifest.Capabilities.Commands {
+		if p.Manifest.Capabilities.Commands[i].Name == name {
+			spec = &p.Manifest.Capabilities.Commands[i]
+		}
+	}
+	if spec == nil {
+		return 1, fmt.Errorf("plugin %s has no command %s", pluginName, name)
+	}
+	entry := pm.scriptEntry(p, spec.Command, pm.Approvals(), pm.Lock())
+	dataDir, err := prepareDataDir(p.Dir)
+	if err != nil {
+		return 1, err
+	}
+	warnPendingCredentials(entry)
+
+	callerDir, _ := os.Getwd()
+	cmd := exec.Command("bash", append([]string{entry.ScriptPath}, args...)...)
+	cmd.Dir = dataDir
+	cmd.Env = append(sandboxEnv(entry, dataDir), "REDC_COMMAND_NAME="+name, "REDC_CALLER_DIR="+callerDir)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return 1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeExtensionPlugin creates a plugin declaring the given capabilities JSON and scripts under tools/
+func TestRunTool(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "dns", "version": "1.0.0", "capabilities": {"tools": [
+		{"name": "echo", "description": "Echo arguments", "command": "tools/echo.sh",
+		 "input_schema": {"type": "object", "properties": {"host": {"type": "string"}}}},
+		{"name": "broken", "command": "tools/broken.sh"},
+		{"name": "text", "command": "tools/text.sh"}]}}`, map[string]string{
+		"tools/echo.sh":   "echo \"{\\\"args\\\": $(cat), \\\"tool\\\": \\\"$REDC_TOOL_NAME\\\"}\"\n",
+		"tools/broken.sh": "echo 'zone not found' >&2\nexit 1\n",
+		"tools/text.sh":   "echo not json\n",
+	})
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	tools := pm.GetTools()
+	if len(tools) != 3 || tools[0].QualifiedName() != "dns__broken" {
+		t.Fatalf("tools = %+v", tools)
+	}
+	tool, ok := pm.FindTool("dns__echo")
+	if !ok {
+		t.Fatal("dns__echo not found")
+	}
+	out, err := pm.RunTool(tool, map[string]interface{}{"host": "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"args": {"host":"example.com"}, "tool": "echo"}` {
+		t.Errorf("out = %s", out)
+	}
+
+	broken, _ := pm.FindTool("dns__broken")
+	if _, err := pm.RunTool(broken, nil); err == nil || !strings.Contains(err.Error(), "zone not found") {
+		t.Errorf("err = %v, want stderr message", err)
+	}
+	text, _ := pm.FindTool("dns__text")
+	if _, err := pm.RunTool(text, nil); err == nil || !strings.Contains(err.Error(), "JSON") {
+		t.Errorf("err = %v, want invalid JSON", err)
+	}
+
+	if err := pm.Disable("dns"); err != nil {
+		t.Fatal(err)
+	}
+	if len(pm.GetTools()) != 0 {
+		t.Error("disabled plugin tools must not be listed")
+	}
+	if _, err := pm.RunTool(tool, nil); err == nil {
+		t.Error("RunTool should refuse a disabled plugin")
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "dns", "version": "1.0.0", "capabilities": {"commands": [{"name": "records", "description": "List records", "command": "tools/records.sh"}]}}`,
+		map[string]string{"tools/records.sh": "echo \"$REDC_COMMAND_NAME $*\"\nexit 4\n"})
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if cmds := pm.PluginCommands()["dns"]; len(cmds) != 1 || cmds[0].Name != "records" {
+		t.Fatalf("commands = %+v", pm.PluginCommands())
+	}
+	var stdout bytes.Buffer
+	code, err := pm.RunCommand("dns", "records", []string{"example.com", "--type", "A"}, nil, &stdout, &stdout)
+	if err != nil || code != 4 || stdout.String() != "records example.com --type A\n" {
+		t.Errorf("code = %d, err = %v, output = %q", code, err, stdout.String())
+	}
+	if _, err := pm.RunCommand("dns", "missing", nil, nil, &stdout, &stdout); err == nil {
+		t.Error("unknown command should fail")
+	}
+}
+
+func TestLoadManifest_InvalidExtensions(t *testing.T) {
+	cases := map[string]string{
+		`{"tools": [{"name": "bad name", "command": "x.sh"}]}`:                              "invalid tool name",
+		`{"tools": [{"name": "a", "command": "x.sh"}, {"name": "a", "command": "y.sh"}]}`:   "duplicate tool",
+		`{"tools": [{"name": "a"}]}`:                                                        "missing command",
+		`{"tools": [{"name": "a", "command": "x.sh", "input_schema": {"type": "string"}}]}`: "must be object",
+		`{"commands": [{"name": "a/b", "command": "x.sh"}]}`:                                "invalid command name",
+		`{"tools": [{"name": "a", "command": "../../bin/sh"}]}`:                             "inside the plugin directory",
+		`{"commands": [{"name": "a", "command": "/bin/sh"}]}`:                               "inside the plugin directory",
+		`{"commands": [{"name": "a", "command": "tools/../../x.sh"}]}`:                      "inside the plugin directory",
+	}
+	for capabilities, want := range cases {
+		root := t.TempDir()
+		writePlugin(t, root, `{"name": "p", "version": "1.0.0", "capabilities": `+capabilities+`}`, nil)
+		if _, err := loadManifest(filepath.Join(root, "p")); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", capabilities, err, want)
+		}
+	}
+}
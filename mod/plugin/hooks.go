@@ -22,39 +22,113 @@ const (
 	HookPostApply   = "post-apply"
 	HookPreDestroy  = "pre-destroy"
 	HookPostDestroy = "post-destroy"
+
+	HookPostCreate       = "post-create"        // case created and planned
+	HookPreStop          = "pre-stop"           // before a case is stopped; can block
+	HookPostStop         = "post-stop"          // after a case is stopped
+	HookOnError          = "on-error"           // a case operation failed, REDC_ERROR holds the reason
+	HookOnSpotTerminated = "on-spot-terminated" // spot instances were reclaimed by the provider
+	HookOnDrift          = "on-drift"           // refresh-only plan found changes made outside redc
+	HookPreSSHExec       = "pre-ssh-exec"       // before a command runs over SSH; can block
+	HookPreUp            = "pre-up"             // before compose up; can block
+	HookPostUp           = "post-up"            // after compose up finished
+)
+
+// HookPoints lists every valid hook point
+var HookPoints = []string{
+	HookPrePlan, HookPostPlan, HookPreApply, HookPostApply, HookPreDestroy, HookPostDestroy,
+	HookPostCreate, HookPreStop, HookPostStop, HookOnError, HookOnSpotTerminated, HookOnDrift,
+	HookPreSSHExec, HookPreUp, HookPostUp,
+}
+
+// IsHookPoint reports whether name is a valid hook point
+func IsHookPoint(name string) bool {
+	for _, h := range HookPoints {
+		if h == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Hook failure policies declared per hook in plugin.json
+const (
+	OnFailureAbort  = "abort"  // stop the operation with the plugin's message
+	OnFailureWarn   = "warn"   // log a warning and continue (default)
+	OnFailureIgnore = "ignore" // continue silently
 )
 
+// DefaultHookTimeout is used when a hook does not declare a timeout
+const DefaultHookTimeout = 5 * time.Minute
+
 // HookEntry represents a single hook script to execute
 type HookEntry struct {
-	PluginName string
-	ScriptPath string
-	PluginDir  string
-	Config     map[string]interface{}
+	PluginName         string
+	ScriptPath         string
+	PluginDir          string
+	Config             map[string]interface{} // fields declared in config_schema only
+	OnFailure          string
+	Timeout            time.Duration
+	Credentials        []string // approved credential variables passed to the script
+	PendingCredentials []string // requested but not approved; withheld
+}
+
+// HookResult is the outcome of a single hook script
+type HookResult struct {
+	Plugin    string            `json:"plugin"`
+	HookPoint string            `json:"hook_point"`
+	OnFailure string            `json:"on_failure"`
+	Success   bool              `json:"success"`
+	Message   string            `json:"message,omitempty"` // REDC_MESSAGE lines, or the last output line on failure
+	Error     string            `json:"error,omitempty"`
+	Duration  time.Duration     `json:"duration"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+}
+
+// HookError is returned by RunHooks when a hook with on_failure abort fails
+type HookError struct {
+	Result HookResult
 }
 
-// HookContext provides case information to hook scripts via environment variables
+func (e *HookError) Error() string {
+	msg := e.Result.Message
+	if msg == "" {
+		msg = e.Result.Error
+	}
+	return fmt.Sprintf("plugin %s blocked %s: %s", e.Result.Plugin, e.Result.HookPoint, msg)
+}
+
+// HookContext provides case information to hook scripts via environment variables.
+// Project-level hooks (pre-up / post-up) have no case and only set Project.
 type HookContext struct {
+	CaseID         string
 	CaseName       string
 	CasePath       string
 	CaseTemplate   string
 	CaseState      string
-	OutputJSON     string // JSON-encoded terraform outputs
-	CaseVars       string // JSON-encoded case parameters (terraform -var values)
+	Project        string
+	Operator       string
+	Error          string   // failure or event description for on-error, on-spot-terminated and on-drift
+	Command        string   // command about to run for pre-ssh-exec
+	OutputJSON     string   // JSON-encoded terraform outputs
+	CaseVars       string   // JSON-encoded case parameters (terraform -var values)
 	AllowedPlugins []string // if non-empty, only run hooks from these plugins
 }
 
 const pluginOutputsFile = "plugin_outputs.json"
 
-// RunHooks executes all hook scripts for a given hook point
-func (pm *PluginManager) RunHooks(hookPoint string, ctx *HookContext) error {
+// RunHooks executes all hook scripts for a given hook point and returns the result of each.
+// A failing hook is handled by its on_failure policy: warn and ignore continue with the next
+// hook, abort stops and returns a *HookError carrying the plugin's message.
+func (pm *PluginManager) RunHooks(hookPoint string, ctx *HookContext) ([]HookResult, error) {
 	hooks := pm.GetHooks(hookPoint)
 	if len(hooks) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// If no plugins are allowed for this case, skip all hooks
 	if ctx != nil && len(ctx.AllowedPlugins) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Build allowed set and order map from context
@@ -97,6 +171,8 @@ func (pm *PluginManager) RunHooks(hookPoint string, ctx *HookContext) error {
 		}
 	}
 
+	var results []HookResult
+	var blocked error
 	for _, hook := range hooks {
 		// Skip plugins not in allowed list
 		if allowed != nil && !allowed[hook.PluginName] {
@@ -104,13 +180,24 @@ func (pm *PluginManager) RunHooks(hookPoint string, ctx *HookContext) error {
 		}
 
 		gologger.Info().Msgf("plugin: running %s hook from %s", hookPoint, hook.PluginName)
-		hookOutputs, err := executeHook(hook, hookPoint, ctx)
-		if err != nil {
-			gologger.Warning().Msgf("plugin: %s hook from %s failed: %v (continuing)", hookPoint, hook.PluginName, err)
-			// Continue to next hook instead of aborting
+		result := executeHook(hook, hookPoint, ctx)
+		results = append(results, result)
+		if !result.Success {
+			switch result.OnFailure {
+			case OnFailureAbort:
+				gologger.Error().Msgf("plugin: %s hook from %s failed: %s (aborting)", hookPoint, hook.PluginName, result.Error)
+				blocked = &HookError{Result: result}
+			case OnFailureIgnore:
+				gologger.Debug().Msgf("plugin: %s hook from %s failed: %s (ignored)", hookPoint, hook.PluginName, result.Error)
+			default:
+				gologger.Warning().Msgf("plugin: %s hook from %s failed: %s (continuing)", hookPoint, hook.PluginName, result.Error)
+			}
+			if blocked != nil {
+				break
+			}
 			continue
 		}
-		for k, v := range hookOutputs {
+		for k, v := range result.Outputs {
 			allOutputs[k] = v
 		}
 		gologger.Info().Msgf("plugin: %s hook from %s completed", hookPoint, hook.PluginName)
@@ -121,31 +208,61 @@ func (pm *PluginManager) RunHooks(hookPoint string, ctx *HookContext) error {
 		savePluginOutputs(ctx.CasePath, allOutputs)
 	}
 
-	return nil
+	return results, blocked
 }
 
-func executeHook(hook HookEntry, hookPoint string, hctx *HookContext) (map[string]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+// executeHook runs one hook script. Scripts report outputs with REDC_OUTPUT:key=value lines
+// and the message shown when they block an operation with REDC_MESSAGE:text lines.
+// The script does not inherit the redc environment: it runs in the plugin's data directory
+// with the variables built by sandboxEnv, and only the first MaxHookOutput bytes of its
+// output are kept.
+func executeHook(hook HookEntry, hookPoint string, hctx *HookContext) HookResult {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	onFailure := hook.OnFailure
+	if onFailure == "" {
+		onFailure = OnFailureWarn
+	}
+	result := HookResult{Plugin: hook.PluginName, HookPoint: hookPoint, OnFailure: onFailure}
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	dataDir, err := prepareDataDir(hook.PluginDir)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	warnPendingCredentials(hook)
+
 	cmd := exec.CommandContext(ctx, "bash", hook.ScriptPath)
-	cmd.Dir = hook.PluginDir
+	cmd.Dir = dataDir
+	// Child processes may keep the output pipe open after bash is killed on timeout
+	cmd.WaitDelay = 5 * time.Second
 
 	// Build environment
-	env := os.Environ()
-	env = append(env,
-		"REDC_HOOK_POINT="+hookPoint,
-		"REDC_PLUGIN_NAME="+hook.PluginName,
-		"REDC_PLUGIN_DIR="+hook.PluginDir,
-	)
+	env := sandboxEnv(hook, dataDir)
+	env = append(env, "REDC_HOOK_POINT="+hookPoint)
 
 	if hctx != nil {
 		env = append(env,
+			"REDC_CASE_ID="+hctx.CaseID,
 			"REDC_CASE_NAME="+hctx.CaseName,
 			"REDC_CASE_PATH="+hctx.CasePath,
 			"REDC_CASE_TEMPLATE="+hctx.CaseTemplate,
 			"REDC_CASE_STATE="+hctx.CaseState,
+			"REDC_PROJECT="+hctx.Project,
+			"REDC_OPERATOR="+hctx.Operator,
 		)
+		if hctx.Error != "" {
+			env = append(env, "REDC_ERROR="+hctx.Error)
+		}
+		if hctx.Command != "" {
+			env = append(env, "REDC_SSH_COMMAND="+hctx.Command)
+		}
 		if hctx.OutputJSON != "" {
 			env = append(env, "REDC_OUTPUT_JSON="+hctx.OutputJSON)
 		}
@@ -154,23 +271,22 @@ func executeHook(hook HookEntry, hookPoint string, hctx *HookContext) (map[strin
 		}
 	}
 
-	// Inject plugin config as REDC_PLUGIN_CONFIG_<KEY>
-	if hook.Config != nil {
-		configJSON, _ := json.Marshal(hook.Config)
-		env = append(env, "REDC_PLUGIN_CONFIG="+string(configJSON))
-		for k, v := range hook.Config {
-			key := "REDC_PLUGIN_CONFIG_" + strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
-			env = append(env, key+"="+fmt.Sprintf("%v", v))
-		}
-	}
-
 	cmd.Env = env
 
-	out, err := cmd.CombinedOutput()
-	output := strings.TrimSpace(string(out))
+	out := &limitedBuffer{limit: MaxHookOutput}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err = cmd.Run()
+	output := strings.TrimSpace(string(out.buf))
+	result.Duration = time.Since(start)
+	if out.truncated {
+		gologger.Warning().Msgf("plugin: %s hook from %s wrote more than %d bytes, output truncated", hookPoint, hook.PluginName, MaxHookOutput)
+	}
 
-	// Parse REDC_OUTPUT:key=value lines from stdout
+	// Parse REDC_OUTPUT:key=value and REDC_MESSAGE:text lines from stdout
 	parsedOutputs := make(map[string]string)
+	var messages []string
+	lastLine := ""
 	if output != "" {
 		for _, line := range strings.Split(output, "\n") {
 			if strings.HasPrefix(line, "REDC_OUTPUT:") {
@@ -180,15 +296,31 @@ func executeHook(hook HookEntry, hookPoint string, hctx *HookContext) (map[strin
 					continue
 				}
 			}
+			if strings.HasPrefix(line, "REDC_MESSAGE:") {
+				messages = append(messages, strings.TrimSpace(strings.TrimPrefix(line, "REDC_MESSAGE:")))
+				continue
+			}
+			if trimmed := strings.TrimSpace(line); trimmed != "" {
+				lastLine = trimmed
+			}
 			gologger.Info().Msgf("plugin[%s]: %s", hook.PluginName, line)
 		}
 	}
+	result.Outputs = parsedOutputs
+	result.Message = strings.Join(messages, "; ")
 
 	if ctx.Err() == context.DeadlineExceeded {
-		return parsedOutputs, fmt.Errorf("hook script timed out after 5 minutes")
+		result.Error = fmt.Sprintf("hook script timed out after %s", timeout)
+	} else if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Success = true
+		return result
 	}
-
-	return parsedOutputs, err
+	if result.Message == "" {
+		result.Message = lastLine
+	}
+	return result
 }
 
 // LoadPluginOutputs reads plugin_outputs.json from the case directory
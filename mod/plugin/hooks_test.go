@@ -0,0 +1,226 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePlugin creates a plugin directory named after the manifest, with files given by path relative to it
+func writePlugin(t *testing.T, root, manifestJSON string, files map[string]string) {
+	t.Helper()
+	var m struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(manifestJSON), &m); err != nil || m.Name == "" {
+		t.Fatalf("invalid test manifest %s: %v", manifestJSON, err)
+	}
+	dir := filepath.Join(root, m.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(manifestJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for file, body := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunHooks_FailurePolicy(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "warner", "version": "1.0.0", "capabilities": {"hooks": {"pre-apply": "hooks/fail.sh"}}}`, map[string]string{
+		"hooks/fail.sh": "echo something went wrong\nexit 1\n",
+	})
+	writePlugin(t, root, `{"name": "policy", "version": "1.0.0", "capabilities": {"hooks": {"pre-apply": {"script": "hooks/check.sh", "on_failure": "abort", "timeout": "10s"}}}}`, map[string]string{
+		"hooks/check.sh": "echo checking\necho 'REDC_MESSAGE:instance type not allowed'\nexit 2\n",
+	})
+	writePlugin(t, root, `{"name": "after", "version": "1.0.0", "capabilities": {"hooks": {"pre-apply": "hooks/ok.sh"}}}`, map[string]string{
+		"hooks/ok.sh": "echo REDC_OUTPUT:ran=yes\n",
+	})
+
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// warn continues, abort stops before the remaining hooks
+	results, err := pm.RunHooks(HookPreApply, &HookContext{AllowedPlugins: []string{"warner", "policy", "after"}})
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) {
+		t.Fatalf("err = %v, want *HookError", err)
+	}
+	if hookErr.Result.Plugin != "policy" || hookErr.Result.Message != "instance type not allowed" {
+		t.Errorf("hook error = %+v", hookErr.Result)
+	}
+	if !strings.Contains(err.Error(), "instance type not allowed") {
+		t.Errorf("error message = %q", err.Error())
+	}
+	if len(results) != 2 || results[0].Success || results[0].OnFailure != OnFailureWarn || results[0].Message != "something went wrong" {
+		t.Errorf("results = %+v", results)
+	}
+
+	// without the blocking plugin every hook runs and outputs are collected
+	results, err = pm.RunHooks(HookPreApply, &HookContext{AllowedPlugins: []string{"warner", "after"}})
+	if err != nil {
+		t.Fatalf("RunHooks: %v", err)
+	}
+	if len(results) != 2 || !results[1].Success || results[1].Outputs["ran"] != "yes" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestRunHooks_Timeout(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "slow", "version": "1.0.0", "capabilities": {"hooks": {"pre-destroy": {"script": "hooks/slow.sh", "on_failure": "abort", "timeout": "200ms"}}}}`, map[string]string{
+		"hooks/slow.sh": "exec sleep 30\n",
+	})
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	results, err := pm.RunHooks(HookPreDestroy, &HookContext{AllowedPlugins: []string{"slow"}})
+	if err == nil || len(results) != 1 || !strings.Contains(results[0].Error, "timed out after 200ms") {
+		t.Fatalf("results = %+v, err = %v", results, err)
+	}
+}
+
+func TestLoadManifest_InvalidHook(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "bad", "version": "1.0.0", "capabilities": {"hooks": {"pre-apply": {"script": "hooks/x.sh", "on_failure": "explode"}}}}`, nil)
+	if _, err := loadManifest(filepath.Join(root, "bad")); err == nil || !strings.Contains(err.Error(), "on_failure") {
+		t.Errorf("err = %v, want invalid on_failure", err)
+	}
+	writePlugin(t, root, `{"name": "escape", "version": "1.0.0", "capabilities": {"hooks": {"pre-apply": "../escape.sh"}}}`, nil)
+	if _, err := loadManifest(filepath.Join(root, "escape")); err == nil || !strings.Contains(err.Error(), "inside the plugin directory") {
+		t.Errorf("err = %v, want script outside the plugin directory rejected", err)
+	}
+}
+
+func TestRunHooks_ContextEnv(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "guard", "version": "1.0.0", "capabilities": {"hooks": {"pre-ssh-exec": "hooks/guard.sh", "on-error": "hooks/report.sh"}}}`, map[string]string{
+		"hooks/guard.sh":  "echo \"REDC_OUTPUT:seen=$REDC_CASE_ID/$REDC_PROJECT/$REDC_OPERATOR/$REDC_SSH_COMMAND\"\n",
+		"hooks/report.sh": "echo \"REDC_OUTPUT:error=$REDC_ERROR\"\n",
+	})
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &HookContext{CaseID: "c1", Project: "default", Operator: "alice", Command: "id", AllowedPlugins: []string{"guard"}}
+	results, err := pm.RunHooks(HookPreSSHExec, ctx)
+	if err != nil || len(results) != 1 || results[0].Outputs["seen"] != "c1/default/alice/id" {
+		t.Fatalf("results = %+v, err = %v", results, err)
+	}
+	results, err = pm.RunHooks(HookOnError, &HookContext{Error: "apply failed", AllowedPlugins: []string{"guard"}})
+	if err != nil || len(results) != 1 || results[0].Outputs["error"] != "apply failed" {
+		t.Fatalf("results = %+v, err = %v", results, err)
+	}
+}
+
+func TestLoadManifest_UnknownHookPoint(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "typo", "version": "1.0.0", "capabilities": {"hooks": {"post-aply": "hooks/x.sh"}}}`, nil)
+	if _, err := loadManifest(filepath.Join(root, "typo")); err == nil || !strings.Contains(err.Error(), "post-aply") {
+		t.Errorf("err = %v, want unknown hook point", err)
+	}
+}
+
+func TestRunHooks_Sandbox(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("ALICLOUD_SECRET_KEY", "alisecret")
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "notify", "version": "1.0.0", "capabilities": {"hooks": {"post-apply": "hooks/env.sh"}}}`, map[string]string{
+		"hooks/env.sh": "echo \"REDC_OUTPUT:aws=$AWS_ACCESS_KEY_ID\"\n" +
+			"echo \"REDC_OUTPUT:ali=$ALICLOUD_SECRET_KEY\"\n" +
+			"echo \"REDC_OUTPUT:webhook=$REDC_PLUGIN_CONFIG_WEBHOOK\"\n" +
+			"echo \"REDC_OUTPUT:extra=$REDC_PLUGIN_CONFIG_EXTRA\"\n" +
+			"echo \"REDC_OUTPUT:cwd=$(pwd)\"\n",
+	})
+	manifest := `{"name": "notify", "version": "1.0.0",
+		"capabilities": {"hooks": {"post-apply": "hooks/env.sh"}, "credentials": ["AWS_ACCESS_KEY_ID"]},
+		"config_schema": {"webhook": {"type": "string"}}}`
+	dir := filepath.Join(root, "notify")
+	os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(manifest), 0644)
+	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`{"webhook": "https://hook", "extra": "x"}`), 0644)
+
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	run := func() map[string]string {
+		t.Helper()
+		results, err := pm.RunHooks(HookPostApply, &HookContext{AllowedPlugins: []string{"notify"}})
+		if err != nil || len(results) != 1 {
+			t.Fatalf("results = %+v, err = %v", results, err)
+		}
+		return results[0].Outputs
+	}
+
+	out := run()
+	if out["aws"] != "" || out["ali"] != "" {
+		t.Errorf("credentials leaked before approval: %v", out)
+	}
+	if out["webhook"] != "https://hook" || out["extra"] != "" {
+		t.Errorf("config = %v, want declared fields only", out)
+	}
+	if want, _ := filepath.EvalSymlinks(filepath.Join(dir, "data")); out["cwd"] != want && out["cwd"] != filepath.Join(dir, "data") {
+		t.Errorf("cwd = %q, want plugin data dir", out["cwd"])
+	}
+	if pending := pm.PendingCredentials("notify"); len(pending) != 1 || pending[0] != "AWS_ACCESS_KEY_ID" {
+		t.Errorf("pending = %v", pending)
+	}
+
+	if err := pm.ApproveCredentials("notify"); err != nil {
+		t.Fatal(err)
+	}
+	out = run()
+	if out["aws"] != "AKIDEXAMPLE" || out["ali"] != "" {
+		t.Errorf("after approval outputs = %v", out)
+	}
+	if a := pm.Approvals()["notify"]; a.ApprovedAt == "" || a.SHA256 == "" {
+		t.Errorf("approval record = %+v", a)
+	}
+
+	// Changed plugin code loses the grant until it is approved again
+	script := filepath.Join(dir, "hooks", "env.sh")
+	data, _ := os.ReadFile(script)
+	os.WriteFile(script, append(data, []byte("echo changed\n")...), 0755)
+	if err := pm.Repin("notify"); err != nil {
+		t.Fatal(err)
+	}
+	if out = run(); out["aws"] != "" {
+		t.Errorf("credentials kept after the plugin changed: %v", out)
+	}
+	if pending := pm.PendingCredentials("notify"); len(pending) != 1 {
+		t.Errorf("pending after repin = %v", pending)
+	}
+}
+
+func TestRunHooks_OutputLimit(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "noisy", "version": "1.0.0", "capabilities": {"hooks": {"post-apply": "hooks/noisy.sh"}}}`, map[string]string{
+		"hooks/noisy.sh": "head -c 3000000 /dev/zero | tr '\\0' 'a'\necho\necho REDC_OUTPUT:late=1\n",
+	})
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	results, err := pm.RunHooks(HookPostApply, &HookContext{AllowedPlugins: []string{"noisy"}})
+	if err != nil || len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v, err = %v", results, err)
+	}
+	if _, ok := results[0].Outputs["late"]; ok {
+		t.Error("output past the limit must be discarded")
+	}
+}
@@ -15,19 +15,61 @@ import (
 	"red-cloud/mod/gologger"
 )
 
-// Install installs a plugin from a git URL, ZIP URL, or local path
+// Install installs a plugin from a git URL, ZIP URL, or local path.
+// Remote sources listed in the registry are verified against its sha256 and signature.
 func (pm *PluginManager) Install(source string) (string, error) {
+	var expect *Integrity
+	if isGitURL(source) {
+		expect = pm.registryIntegrity(source)
+	}
+	return pm.install(source, expect, nil)
+}
+
+// InstallVerified installs a remote plugin that must match the given digest and signature
+func (pm *PluginManager) InstallVerified(source string, expect *Integrity) (string, error) {
+	return pm.install(source, expect, nil)
+}
+
+// install fetches a plugin and resolves its dependencies; stack lists the plugins whose
+// dependencies are being installed
+func (pm *PluginManager) install(source string, expect *Integrity, stack []string) (string, error) {
 	if err := os.MkdirAll(pm.pluginsDir, 0755); err != nil {
 		return "", fmt.Errorf("cannot create plugins dir: %w", err)
 	}
 
-	if isGitURL(source) {
-		if isZipURL(source) {
-			return pm.installFromZipURL(source)
+	var name string
+	var err error
+	switch {
+	case isGitURL(source) && isZipURL(source):
+		name, err = pm.installFromZipURL(source, expect)
+	case isGitURL(source):
+		name, err = pm.installFromGit(source, expect)
+	default:
+		name, err = pm.installFromLocal(source)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := pm.finishInstall(name, stack); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// registryIntegrity looks up the expected digest and signature of a source URL in the registry.
+// An unreachable registry or unlisted source yields nil, i.e. only the trust policy applies.
+func (pm *PluginManager) registryIntegrity(source string) *Integrity {
+	index, err := FetchRegistry(pm.registryURL)
+	if err != nil {
+		gologger.Debug().Msgf("plugin: registry unavailable, cannot verify %s: %v", source, err)
+		return nil
+	}
+	for _, rp := range index.Plugins {
+		if rp.URL == source {
+			return rp.Integrity()
 		}
-		return pm.installFromGit(source)
 	}
-	return pm.installFromLocal(source)
+	return nil
 }
 
 func isGitURL(s string) bool {
@@ -41,7 +83,7 @@ func isZipURL(s string) bool {
 	return strings.HasSuffix(strings.ToLower(s), ".zip")
 }
 
-func (pm *PluginManager) installFromGit(url string) (string, error) {
+func (pm *PluginManager) installFromGit(url string, expect *Integrity) (string, error) {
 	// Clone to temp dir first, read manifest, then move
 	tmpDir, err := os.MkdirTemp("", "redc-plugin-*")
 	if err != nil {
@@ -55,6 +97,15 @@ func (pm *PluginManager) installFromGit(url string) (string, error) {
 		return "", fmt.Errorf("git clone failed: %s\n%s", err, string(out))
 	}
 
+	digest, err := DirHash(tmpDir)
+	if err != nil {
+		return "", fmt.Errorf("cannot hash plugin: %w", err)
+	}
+	signer, err := pm.verifyArtifact(expect, digest, []byte(digest))
+	if err != nil {
+		return "", fmt.Errorf("plugin %s: %w", url, err)
+	}
+
 	manifest, err := loadManifest(tmpDir)
 	if err != nil {
 		return "", fmt.Errorf("invalid plugin: %w", err)
@@ -82,12 +133,16 @@ func (pm *PluginManager) installFromGit(url string) (string, error) {
 	}
 	pm.mu.Unlock()
 
+	if err := pm.pin(manifest.Name, destDir, manifest.Version, url, "", signer); err != nil {
+		gologger.Warning().Msgf("plugin: %v", err)
+	}
+
 	gologger.Info().Msgf("plugin: installed %s v%s", manifest.Name, manifest.Version)
 	return manifest.Name, nil
 }
 
 // installFromZipURL downloads a ZIP archive and extracts it
-func (pm *PluginManager) installFromZipURL(url string) (string, error) {
+func (pm *PluginManager) installFromZipURL(url string, expect *Integrity) (string, error) {
 	tmpFile, err := os.CreateTemp("", "redc-plugin-*.zip")
 	if err != nil {
 		return "", fmt.Errorf("cannot create temp file: %w", err)
@@ -115,6 +170,20 @@ func (pm *PluginManager) installFromZipURL(url string) (string, error) {
 	}
 	tmpFile.Close()
 
+	// Verify the archive before extracting anything
+	digest, err := fileSHA256(tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot hash download: %w", err)
+	}
+	archive, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	signer, err := pm.verifyArtifact(expect, digest, archive)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s: %w", url, err)
+	}
+
 	// Extract to temp dir
 	tmpDir, err := os.MkdirTemp("", "redc-plugin-extract-*")
 	if err != nil {
@@ -151,6 +220,10 @@ func (pm *PluginManager) installFromZipURL(url string) (string, error) {
 	}
 	pm.mu.Unlock()
 
+	if err := pm.pin(manifest.Name, destDir, manifest.Version, url, digest, signer); err != nil {
+		gologger.Warning().Msgf("plugin: %v", err)
+	}
+
 	gologger.Info().Msgf("plugin: installed %s v%s from ZIP", manifest.Name, manifest.Version)
 	return manifest.Name, nil
 }
@@ -229,6 +302,10 @@ func (pm *PluginManager) installFromLocal(srcPath string) (string, error) {
 	}
 	pm.mu.Unlock()
 
+	if err := pm.pin(manifest.Name, destDir, manifest.Version, absPath, "", ""); err != nil {
+		gologger.Warning().Msgf("plugin: %v", err)
+	}
+
 	gologger.Info().Msgf("plugin: installed %s v%s from local path", manifest.Name, manifest.Version)
 	return manifest.Name, nil
 }
@@ -242,12 +319,24 @@ func (pm *PluginManager) Uninstall(name string) error {
 	if !ok {
 		return fmt.Errorf("plugin %s not found", name)
 	}
+	if deps := pm.dependents(name, false); len(deps) > 0 {
+		return fmt.Errorf("plugin %s is required by %s", name, strings.Join(deps, ", "))
+	}
 
 	if err := os.RemoveAll(p.Dir); err != nil {
 		return fmt.Errorf("cannot remove plugin: %w", err)
 	}
 
 	delete(pm.plugins, name)
+	if err := pm.unpin(name); err != nil {
+		gologger.Warning().Msgf("plugin: cannot update %s: %v", lockFileName, err)
+	}
+	if err := pm.RevokeCredentials(name); err != nil {
+		gologger.Warning().Msgf("plugin: cannot update %s: %v", approvalsFileName, err)
+	}
+	if err := pm.secrets.DeletePlugin(name); err != nil {
+		gologger.Warning().Msgf("plugin: cannot remove secrets of %s: %v", name, err)
+	}
 	gologger.Info().Msgf("plugin: uninstalled %s", name)
 	return nil
 }
@@ -261,9 +350,19 @@ func (pm *PluginManager) Enable(name string) error {
 	if !ok {
 		return fmt.Errorf("plugin %s not found", name)
 	}
+	if p.IntegrityError != "" {
+		return fmt.Errorf("plugin %s failed integrity check: %s", name, p.IntegrityError)
+	}
+	if err := checkRedcCompatible(p.Manifest); err != nil {
+		return err
+	}
+	if problems := dependencyProblems(p.Manifest, pm.plugins); len(problems) > 0 {
+		return fmt.Errorf("plugin %s %s", name, strings.Join(problems, "; "))
+	}
 
 	os.Remove(filepath.Join(p.Dir, ".disabled"))
 	p.Enabled = true
+	p.DependencyError = ""
 	gologger.Info().Msgf("plugin: enabled %s", name)
 	return nil
 }
@@ -277,6 +376,9 @@ func (pm *PluginManager) Disable(name string) error {
 	if !ok {
 		return fmt.Errorf("plugin %s not found", name)
 	}
+	if deps := pm.dependents(name, true); len(deps) > 0 {
+		return fmt.Errorf("plugin %s is required by %s; disable them first", name, strings.Join(deps, ", "))
+	}
 
 	if err := os.WriteFile(filepath.Join(p.Dir, ".disabled"), []byte("disabled"), 0644); err != nil {
 		return fmt.Errorf("cannot disable plugin: %w", err)
@@ -304,50 +406,105 @@ func (pm *PluginManager) Update(name string) (string, error) {
 		return pm.updateFromRegistry(name)
 	}
 
+	head, err := exec.Command("git", "-C", p.Dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse failed: %w", err)
+	}
 	cmd := exec.Command("git", "-C", p.Dir, "pull", "--ff-only")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("git pull failed: %s\n%s", err, string(out))
 	}
 
+	// Verify the new content against the registry; roll back on mismatch
+	rollback := func(cause error) (string, error) {
+		if out, err := exec.Command("git", "-C", p.Dir, "reset", "--hard", strings.TrimSpace(string(head))).CombinedOutput(); err != nil {
+			gologger.Error().Msgf("plugin: cannot roll back %s: %s\n%s", name, err, string(out))
+		}
+		return "", cause
+	}
+	digest, err := DirHash(p.Dir)
+	if err != nil {
+		return rollback(fmt.Errorf("cannot hash plugin: %w", err))
+	}
+	var expect *Integrity
+	if rp, ok := pm.registryEntry(name); ok {
+		expect = rp.Integrity()
+	}
+	signer, err := pm.verifyArtifact(expect, digest, []byte(digest))
+	if err != nil {
+		return rollback(fmt.Errorf("plugin %s: %w", name, err))
+	}
+
 	// Reload manifest
 	manifest, err := loadManifest(p.Dir)
 	if err != nil {
-		return "", fmt.Errorf("manifest reload failed: %w", err)
+		return rollback(fmt.Errorf("manifest reload failed: %w", err))
+	}
+	if err := pm.resolveDependencies(manifest, nil); err != nil {
+		return rollback(err)
+	}
+	// Installed plugins that depend on this one must accept the new version
+	if conflict := pm.constraintConflict(name, manifest.Version, ""); conflict != "" {
+		return rollback(fmt.Errorf("plugin %s v%s: %s", name, manifest.Version, conflict))
 	}
 
 	pm.mu.Lock()
 	p.Manifest = manifest
 	p.Config = loadPluginConfig(p.Dir)
+	p.IntegrityError = ""
 	pm.mu.Unlock()
 
+	source := ""
+	if entry, ok := pm.Lock().Plugins[name]; ok {
+		source = entry.Source
+	}
+	if err := pm.pin(name, p.Dir, manifest.Version, source, "", signer); err != nil {
+		gologger.Warning().Msgf("plugin: %v", err)
+	}
+
 	gologger.Info().Msgf("plugin: updated %s to v%s", name, manifest.Version)
 	return manifest.Version, nil
 }
 
+// registryEntry finds a plugin by name in the registry
+func (pm *PluginManager) registryEntry(name string) (RegistryPlugin, bool) {
+	index, err := FetchRegistry(pm.registryURL)
+	if err != nil {
+		gologger.Debug().Msgf("plugin: registry unavailable: %v", err)
+		return RegistryPlugin{}, false
+	}
+	for _, rp := range index.Plugins {
+		if rp.Name == name {
+			return rp, true
+		}
+	}
+	return RegistryPlugin{}, false
+}
+
 // updateFromRegistry fetches registry, finds the plugin URL, and reinstalls
 func (pm *PluginManager) updateFromRegistry(name string) (string, error) {
-	index, err := FetchRegistry("")
+	index, err := FetchRegistry(pm.registryURL)
 	if err != nil {
 		return "", fmt.Errorf("cannot fetch registry for update: %w", err)
 	}
 
-	var downloadURL string
 	for _, rp := range index.Plugins {
 		if rp.Name == name {
-			downloadURL = rp.URL
-			break
+			return pm.reinstall(name, rp.URL, rp.Integrity(), nil)
 		}
 	}
-	if downloadURL == "" {
-		return "", fmt.Errorf("plugin %s not found in registry, cannot update (not a git repo)", name)
-	}
-
-	return pm.ReinstallFromURL(name, downloadURL)
+	return "", fmt.Errorf("plugin %s not found in registry, cannot update (not a git repo)", name)
 }
 
 // ReinstallFromURL removes old plugin and reinstalls from URL (preserves config)
 func (pm *PluginManager) ReinstallFromURL(name, url string) (string, error) {
+	return pm.reinstall(name, url, pm.registryIntegrity(url), nil)
+}
+
+// reinstall replaces a plugin with a fresh copy from url. The old copy is kept aside
+// and restored when the download or its verification fails.
+func (pm *PluginManager) reinstall(name, url string, expect *Integrity, stack []string) (string, error) {
 	pm.mu.Lock()
 	oldPlugin, ok := pm.plugins[name]
 	pm.mu.Unlock()
@@ -355,23 +512,67 @@ func (pm *PluginManager) ReinstallFromURL(name, url string) (string, error) {
 	// Preserve config and enabled state
 	var oldConfig map[string]interface{}
 	wasEnabled := true
+	backupDir := ""
 	if ok {
 		oldConfig = oldPlugin.Config
-		wasEnabled = oldPlugin.Enabled
-		// Remove old
-		if err := os.RemoveAll(oldPlugin.Dir); err != nil {
-			return "", fmt.Errorf("cannot remove old plugin: %w", err)
+		wasEnabled = oldPlugin.Enabled && oldPlugin.IntegrityError == ""
+		backupDir = filepath.Join(pm.pluginsDir, fmt.Sprintf(".%s.bak-%d", name, time.Now().UnixNano()))
+		if err := os.Rename(oldPlugin.Dir, backupDir); err != nil {
+			return "", fmt.Errorf("cannot move old plugin aside: %w", err)
 		}
 		pm.mu.Lock()
 		delete(pm.plugins, name)
 		pm.mu.Unlock()
 	}
 
+	oldPin, pinned := pm.Lock().Plugins[name]
+	restore := func() {
+		if pinned {
+			if perr := pm.restorePin(name, oldPin); perr != nil {
+				gologger.Warning().Msgf("plugin: cannot update %s: %v", lockFileName, perr)
+			}
+		} else if uerr := pm.unpin(name); uerr != nil {
+			gologger.Warning().Msgf("plugin: cannot update %s: %v", lockFileName, uerr)
+		}
+		if backupDir != "" {
+			if rerr := os.Rename(backupDir, oldPlugin.Dir); rerr != nil {
+				gologger.Error().Msgf("plugin: cannot restore %s: %v", name, rerr)
+			} else {
+				pm.mu.Lock()
+				pm.plugins[name] = oldPlugin
+				pm.mu.Unlock()
+			}
+		}
+	}
+
 	// Install fresh
-	installedName, err := pm.Install(url)
+	installedName, err := pm.install(url, expect, stack)
 	if err != nil {
+		restore()
 		return "", fmt.Errorf("reinstall failed: %w", err)
 	}
+	// Installed plugins that depend on this one must accept the new version. When a
+	// dependency is upgraded for the plugin on top of stack, that plugin's old
+	// constraint is about to be replaced and is not checked.
+	except := ""
+	if len(stack) > 0 {
+		except = stack[len(stack)-1]
+	}
+	if p, ok := pm.Get(installedName); ok {
+		if conflict := pm.constraintConflict(installedName, p.Manifest.Version, except); conflict != "" {
+			pm.mu.Lock()
+			delete(pm.plugins, installedName)
+			pm.mu.Unlock()
+			if rerr := os.RemoveAll(p.Dir); rerr != nil {
+				gologger.Warning().Msgf("plugin: cannot remove %s: %v", installedName, rerr)
+			}
+			restore()
+			return "", fmt.Errorf("reinstall failed: plugin %s v%s: %s", installedName, p.Manifest.Version, conflict)
+		}
+	}
+	if backupDir != "" {
+		os.RemoveAll(backupDir)
+	}
 
 	// Restore config and enabled state
 	pm.mu.Lock()
@@ -393,29 +594,6 @@ func (pm *PluginManager) ReinstallFromURL(name, url string) (string, error) {
 	return installedName, nil
 }
 
-// SaveConfig saves plugin config to config.json in plugin dir
-func (pm *PluginManager) SaveConfig(name string, config map[string]interface{}) error {
-	pm.mu.Lock()
-	defer pm.mu.Unlock()
-
-	p, ok := pm.plugins[name]
-	if !ok {
-		return fmt.Errorf("plugin %s not found", name)
-	}
-
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("cannot marshal config: %w", err)
-	}
-
-	if err := os.WriteFile(filepath.Join(p.Dir, "config.json"), data, 0644); err != nil {
-		return fmt.Errorf("cannot write config: %w", err)
-	}
-
-	p.Config = config
-	return nil
-}
-
 // --- helpers ---
 
 func copyDir(src, dst string) error {
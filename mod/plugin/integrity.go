@@ -0,0 +1,479 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	lockFileName  = "plugins.lock.json"
+	trustFileName = "trust.json"
+)
+
+// Integrity is the expected digest and optional signature of a plugin artifact.
+// For ZIP archives SHA256 is the digest of the archive and the signature covers the archive bytes;
+// for git repositories SHA256 is the content hash (DirHash) and the signature covers its hex string.
+type Integrity struct {
+	SHA256    string `json:"sha256,omitempty"`
+	Signature string `json:"signature,omitempty"` // base64 ed25519 signature or a minisign .minisig file
+}
+
+// TrustedKey is a public key accepted for plugin signatures: a base64 ed25519 key (32 bytes)
+// or a minisign public key (the second line of a minisign .pub file)
+type TrustedKey struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// TrustConfig is stored as trust.json in the plugins directory
+type TrustConfig struct {
+	TrustedKeys      []TrustedKey `json:"trusted_keys"`
+	RequireSignature bool         `json:"require_signature,omitempty"` // refuse remote plugins without a trusted signature
+}
+
+// LockEntry pins the content of an installed plugin
+type LockEntry struct {
+	Version       string    `json:"version"`
+	Source        string    `json:"source"`
+	SHA256        string    `json:"sha256"`                   // DirHash of the installed plugin
+	ArchiveSHA256 string    `json:"archive_sha256,omitempty"` // digest of the downloaded ZIP
+	SignedBy      string    `json:"signed_by,omitempty"`
+	InstalledAt   time.Time `json:"installed_at"`
+}
+
+// LockFile lists the pinned hashes of installed plugins
+type LockFile struct {
+	Version int                  `json:"version"`
+	Plugins map[string]LockEntry `json:"plugins"`
+}
+
+// hashExcluded are paths a plugin or redc may change after installation
+var hashExcluded = map[string]bool{
+	".git":        true,
+	".disabled":   true,
+	"config.json": true,
+	"config.yaml": true,
+	"data":        true, // writable state directory of the plugin
+}
+
+// DirHash returns the SHA-256 content hash of a plugin directory. It covers every file path
+// and content except .git, .disabled, config.json, config.yaml and data/.
+func DirHash(dir string) (string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if hashExcluded[filepath.ToSlash(rel)] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(files, func(i, j int) bool { return filepath.ToSlash(files[i]) < filepath.ToSlash(files[j]) })
+
+	h := sha256.New()
+	for _, rel := range files {
+		path := filepath.Join(dir, rel)
+		info, err := os.Lstat(path)
+		if err != nil {
+			return "", err
+		}
+		var sum []byte
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return "", err
+			}
+			s := sha256.Sum256([]byte("symlink:" + target))
+			sum = s[:]
+		} else {
+			fh := sha256.New()
+			f, err := os.Open(path)
+			if err != nil {
+				return "", err
+			}
+			_, err = io.Copy(fh, f)
+			f.Close()
+			if err != nil {
+				return "", err
+			}
+			sum = fh.Sum(nil)
+		}
+		fmt.Fprintf(h, "%s\x00%x\n", filepath.ToSlash(rel), sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileSHA256 returns the hex SHA-256 digest of a file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyArtifact checks digest against expect.SHA256 and the signature over message against
+// the trusted keys. It returns the name of the signing key, or "" when the artifact is unsigned.
+func (pm *PluginManager) verifyArtifact(expect *Integrity, digest string, message []byte) (string, error) {
+	trust := pm.TrustConfig()
+	if expect == nil {
+		expect = &Integrity{}
+	}
+	if expect.SHA256 != "" && !strings.EqualFold(strings.TrimPrefix(expect.SHA256, "sha256:"), digest) {
+		return "", fmt.Errorf("sha256 mismatch: expected %s, got %s", expect.SHA256, digest)
+	}
+	if expect.Signature == "" {
+		if trust.RequireSignature {
+			return "", fmt.Errorf("plugin is not signed and require_signature is enabled")
+		}
+		return "", nil
+	}
+	if len(trust.TrustedKeys) == 0 {
+		return "", fmt.Errorf("plugin is signed but no trusted keys are configured in %s", filepath.Join(pm.pluginsDir, trustFileName))
+	}
+	signer, err := verifySignature(expect.Signature, message, trust.TrustedKeys)
+	if err != nil {
+		return "", fmt.Errorf("signature verification failed: %w", err)
+	}
+	return signer, nil
+}
+
+// publicKey is a parsed trusted key; keyID is set for minisign keys
+type publicKey struct {
+	name  string
+	keyID []byte
+	key   ed25519.PublicKey
+}
+
+// parseTrustedKey accepts a raw base64 ed25519 key or a minisign public key
+func parseTrustedKey(k TrustedKey) (publicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(k.Key))
+	if err != nil {
+		return publicKey{}, fmt.Errorf("key %s: invalid base64: %w", k.Name, err)
+	}
+	switch {
+	case len(raw) == ed25519.PublicKeySize:
+		return publicKey{name: k.Name, key: ed25519.PublicKey(raw)}, nil
+	case len(raw) == 42 && string(raw[:2]) == "Ed":
+		return publicKey{name: k.Name, keyID: raw[2:10], key: ed25519.PublicKey(raw[10:])}, nil
+	}
+	return publicKey{}, fmt.Errorf("key %s: expected an ed25519 or minisign public key", k.Name)
+}
+
+// verifySignature verifies a base64 ed25519 signature or a minisign signature file over message
+func verifySignature(signature string, message []byte, trusted []TrustedKey) (string, error) {
+	var keys []publicKey
+	for _, k := range trusted {
+		pk, err := parseTrustedKey(k)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, pk)
+	}
+	if strings.Contains(signature, "untrusted comment:") {
+		return verifyMinisign(signature, message, keys)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return "", fmt.Errorf("invalid ed25519 signature")
+	}
+	for _, k := range keys {
+		if ed25519.Verify(k.key, message, sig) {
+			return k.name, nil
+		}
+	}
+	return "", fmt.Errorf("not signed by a trusted key")
+}
+
+// verifyMinisign verifies a minisign signature file: the signature over the message
+// (or its BLAKE2b-512 hash for prehashed "ED" signatures) and the global signature over
+// the signature and trusted comment
+func verifyMinisign(signature string, message []byte, keys []publicKey) (string, error) {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(signature, "\r\n", "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return "", fmt.Errorf("invalid minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return "", fmt.Errorf("invalid minisign signature")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return "", fmt.Errorf("invalid minisign global signature")
+	}
+	alg, keyID, rawSig := string(sig[:2]), sig[2:10], sig[10:]
+	signed := message
+	switch alg {
+	case "Ed":
+	case "ED":
+		sum := blake2b.Sum512(message)
+		signed = sum[:]
+	default:
+		return "", fmt.Errorf("unsupported minisign algorithm %q", alg)
+	}
+	trustedComment := strings.TrimPrefix(lines[2], "trusted comment: ")
+	for _, k := range keys {
+		if k.keyID != nil && !bytes.Equal(k.keyID, keyID) {
+			continue
+		}
+		if !ed25519.Verify(k.key, signed, rawSig) {
+			continue
+		}
+		if !ed25519.Verify(k.key, append(append([]byte{}, rawSig...), trustedComment...), global) {
+			return "", fmt.Errorf("minisign trusted comment signature is invalid")
+		}
+		return k.name, nil
+	}
+	return "", fmt.Errorf("not signed by a trusted key")
+}
+
+// TrustConfig reads trust.json from the plugins directory; a missing file means no trusted keys
+func (pm *PluginManager) TrustConfig() TrustConfig {
+	var cfg TrustConfig
+	data, err := os.ReadFile(filepath.Join(pm.pluginsDir, trustFileName))
+	if err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
+	return cfg
+}
+
+// SaveTrustConfig validates the keys and writes trust.json
+func (pm *PluginManager) SaveTrustConfig(cfg TrustConfig) error {
+	seen := make(map[string]bool)
+	for _, k := range cfg.TrustedKeys {
+		if k.Name == "" {
+			return fmt.Errorf("trusted key name is required")
+		}
+		if seen[k.Name] {
+			return fmt.Errorf("duplicate trusted key %s", k.Name)
+		}
+		seen[k.Name] = true
+		if _, err := parseTrustedKey(k); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(pm.pluginsDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(pm.pluginsDir, trustFileName), data, 0644)
+}
+
+// AddTrustedKey adds or replaces a trusted key
+func (pm *PluginManager) AddTrustedKey(key TrustedKey) error {
+	cfg := pm.TrustConfig()
+	replaced := false
+	for i, k := range cfg.TrustedKeys {
+		if k.Name == key.Name {
+			cfg.TrustedKeys[i] = key
+			replaced = true
+		}
+	}
+	if !replaced {
+		cfg.TrustedKeys = append(cfg.TrustedKeys, key)
+	}
+	return pm.SaveTrustConfig(cfg)
+}
+
+// RemoveTrustedKey removes a trusted key by name
+func (pm *PluginManager) RemoveTrustedKey(name string) error {
+	cfg := pm.TrustConfig()
+	for i, k := range cfg.TrustedKeys {
+		if k.Name == name {
+			cfg.TrustedKeys = append(cfg.TrustedKeys[:i], cfg.TrustedKeys[i+1:]...)
+			return pm.SaveTrustConfig(cfg)
+		}
+	}
+	return fmt.Errorf("trusted key %s not found", name)
+}
+
+// readLock reads plugins.lock.json; a missing file yields an empty lock
+func (pm *PluginManager) readLock() *LockFile {
+	lock := &LockFile{Version: 1, Plugins: make(map[string]LockEntry)}
+	data, err := os.ReadFile(filepath.Join(pm.pluginsDir, lockFileName))
+	if err != nil {
+		return lock
+	}
+	if err := json.Unmarshal(data, lock); err != nil || lock.Plugins == nil {
+		lock.Plugins = make(map[string]LockEntry)
+	}
+	return lock
+}
+
+func (pm *PluginManager) writeLock(lock *LockFile) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(pm.pluginsDir, lockFileName), data, 0644)
+}
+
+// Lock returns the pinned hashes of installed plugins
+func (pm *PluginManager) Lock() LockFile {
+	pm.lockMu.Lock()
+	defer pm.lockMu.Unlock()
+	return *pm.readLock()
+}
+
+// pin records the content hash of an installed plugin in the lockfile
+func (pm *PluginManager) pin(name, dir, version, source, archiveSHA256, signedBy string) error {
+	pm.lockMu.Lock()
+	defer pm.lockMu.Unlock()
+	hash, err := DirHash(dir)
+	if err != nil {
+		return fmt.Errorf("hash plugin %s: %w", name, err)
+	}
+	lock := pm.readLock()
+	lock.Plugins[name] = LockEntry{
+		Version:       version,
+		Source:        source,
+		SHA256:        hash,
+		ArchiveSHA256: archiveSHA256,
+		SignedBy:      signedBy,
+		InstalledAt:   time.Now(),
+	}
+	return pm.writeLock(lock)
+}
+
+// unpin removes a plugin from the lockfile
+func (pm *PluginManager) unpin(name string) error {
+	pm.lockMu.Lock()
+	defer pm.lockMu.Unlock()
+	lock := pm.readLock()
+	if _, ok := lock.Plugins[name]; !ok {
+		return nil
+	}
+	delete(lock.Plugins, name)
+	return pm.writeLock(lock)
+}
+
+// restorePin puts back a lockfile entry saved before a failed reinstall
+func (pm *PluginManager) restorePin(name string, entry LockEntry) error {
+	pm.lockMu.Lock()
+	defer pm.lockMu.Unlock()
+	lock := pm.readLock()
+	lock.Plugins[name] = entry
+	return pm.writeLock(lock)
+}
+
+// checkPinned compares a plugin directory with its lockfile entry. Plugins installed before the
+// lockfile existed are pinned on first load. It returns a non-empty reason when the content changed.
+func (pm *PluginManager) checkPinned(lock *LockFile, name, version, dir string) (string, bool) {
+	hash, err := DirHash(dir)
+	if err != nil {
+		return fmt.Sprintf("cannot hash plugin: %v", err), false
+	}
+	entry, ok := lock.Plugins[name]
+	if !ok {
+		lock.Plugins[name] = LockEntry{Version: version, Source: "local", SHA256: hash, InstalledAt: time.Now()}
+		return "", true
+	}
+	if entry.SHA256 != hash {
+		return fmt.Sprintf("content changed since installation (expected %s, got %s)", shortHash(entry.SHA256), shortHash(hash)), false
+	}
+	return "", false
+}
+
+// Verify re-checks installed plugins against the lockfile and returns the problem of each
+// plugin that no longer matches; names limits the check to the given plugins
+func (pm *PluginManager) Verify(names ...string) map[string]string {
+	pm.lockMu.Lock()
+	lock := pm.readLock()
+	pm.lockMu.Unlock()
+
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	problems := make(map[string]string)
+	for name, p := range pm.plugins {
+		if len(names) > 0 && !containsString(names, name) {
+			continue
+		}
+		if _, ok := lock.Plugins[name]; !ok {
+			problems[name] = "not pinned in " + lockFileName
+			continue
+		}
+		if reason, _ := pm.checkPinned(lock, name, p.Manifest.Version, p.Dir); reason != "" {
+			problems[name] = reason
+		}
+	}
+	return problems
+}
+
+// Repin accepts the current content of a plugin, e.g. after editing it locally,
+// and clears its integrity error
+func (pm *PluginManager) Repin(name string) error {
+	pm.mu.Lock()
+	p, ok := pm.plugins[name]
+	pm.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("plugin %s not found", name)
+	}
+	source := "local"
+	if entry, ok := pm.Lock().Plugins[name]; ok {
+		source = entry.Source
+	}
+	if err := pm.pin(name, p.Dir, p.Manifest.Version, source, "", ""); err != nil {
+		return err
+	}
+	pm.mu.Lock()
+	p.IntegrityError = ""
+	p.Enabled = isEnabled(p.Dir)
+	pm.mu.Unlock()
+	return nil
+}
+
+func shortHash(h string) string {
+	if len(h) > 12 {
+		return h[:12]
+	}
+	return h
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
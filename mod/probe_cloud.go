@@ -0,0 +1,256 @@
+package mod
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	alicloudecs "github.com/aliyun/alibaba-cloud-sdk-go/services/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	awscredentials "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+// CloudInstance 从 terraform state 中提取的云主机信息
+type CloudInstance struct {
+	Provider string `json:"provider"`
+	Resource string `json:"resource"` // terraform 资源地址
+	ID       string `json:"id"`
+	PublicIP string `json:"publicIp"`
+	Region   string `json:"region"`
+}
+
+// instanceResourceTypes 支持云 API 探测的资源类型，值为 [provider, 实例 ID 属性]
+var instanceResourceTypes = map[string][2]string{
+	"alicloud_instance":         {"alicloud", "id"},
+	"alicloud_ecs_instance":     {"alicloud", "id"},
+	"tencentcloud_instance":     {"tencentcloud", "id"},
+	"aws_instance":              {"aws", "id"},
+	"aws_spot_instance_request": {"aws", "spot_instance_id"},
+}
+
+// cloudStatusFunc 查询实例状态，返回 true 表示运行中；实例不存在时返回 false
+type cloudStatusFunc func(ctx context.Context, region, instanceID string) (bool, error)
+
+var cloudStatusFuncs = map[string]cloudStatusFunc{
+	"alicloud":     alicloudInstanceRunning,
+	"tencentcloud": tencentInstanceRunning,
+	"aws":          awsInstanceRunning,
+}
+
+// CaseCloudInstances 读取场景的 terraform state，返回其中的云主机
+func CaseCloudInstances(casePath string) ([]CloudInstance, error) {
+	state, err := TfStatus(casePath)
+	if err != nil {
+		return nil, err
+	}
+	return cloudInstancesFromState(state), nil
+}
+
+func cloudInstancesFromState(state *tfjson.State) []CloudInstance {
+	if state == nil || state.Values == nil {
+		return nil
+	}
+	var instances []CloudInstance
+	var walk func(m *tfjson.StateModule)
+	walk = func(m *tfjson.StateModule) {
+		if m == nil {
+			return
+		}
+		for _, r := range m.Resources {
+			if r.Mode != tfjson.ManagedResourceMode {
+				continue
+			}
+			meta, ok := instanceResourceTypes[r.Type]
+			if !ok {
+				continue
+			}
+			id := stateString(r.AttributeValues, meta[1])
+			if id == "" {
+				continue
+			}
+			instances = append(instances, CloudInstance{
+				Provider: meta[0],
+				Resource: r.Address,
+				ID:       id,
+				PublicIP: stateString(r.AttributeValues, "public_ip"),
+				Region:   regionFromZone(meta[0], stateString(r.AttributeValues, "availability_zone")),
+			})
+		}
+		for _, child := range m.ChildModules {
+			walk(child)
+		}
+	}
+	walk(state.Values.RootModule)
+	return instances
+}
+
+func stateString(attrs map[string]interface{}, key string) string {
+	if v, ok := attrs[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// regionFromZone 由可用区推导地域：aws us-east-1a -> us-east-1，阿里云 cn-hangzhou-h / 腾讯云 ap-guangzhou-3 去掉最后一段
+func regionFromZone(provider, zone string) string {
+	if zone == "" {
+		return ""
+	}
+	if provider == "aws" {
+		return strings.TrimRight(zone, "abcdefghijklmnopqrstuvwxyz")
+	}
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
+
+// cloudProber 通过云厂商 API 查询实例状态
+type cloudProber struct{}
+
+func (p *cloudProber) Probe(ctx context.Context, target ProbeTarget) error {
+	inst := target.Instance
+	if inst == nil {
+		return fmt.Errorf("%w: terraform state 中没有 %s 对应的实例", ErrProbeUnknown, target.IP)
+	}
+	fn, ok := cloudStatusFuncs[inst.Provider]
+	if !ok {
+		return fmt.Errorf("%w: %s 暂不支持云 API 探测", ErrProbeUnknown, inst.Provider)
+	}
+	region := inst.Region
+	if region == "" {
+		region = providerRegion(inst.Provider)
+	}
+	running, err := fn(ctx, region, inst.ID)
+	if err != nil {
+		// API 调用失败（凭据、网络等）不能说明实例已被回收
+		return fmt.Errorf("%w: %v", ErrProbeUnknown, err)
+	}
+	if !running {
+		return fmt.Errorf("实例 %s 未处于运行状态", inst.ID)
+	}
+	return nil
+}
+
+// probeCredentials 优先使用配置文件中的凭据，其次使用环境变量
+func probeCredentials(provider string) (string, string, error) {
+	accessKey, secretKey := GetProviderCredentials(provider)
+	if accessKey == "" || secretKey == "" {
+		switch provider {
+		case "alicloud":
+			accessKey, secretKey = os.Getenv("ALICLOUD_ACCESS_KEY"), os.Getenv("ALICLOUD_SECRET_KEY")
+		case "tencentcloud":
+			accessKey, secretKey = os.Getenv("TENCENTCLOUD_SECRET_ID"), os.Getenv("TENCENTCLOUD_SECRET_KEY")
+		case "aws":
+			accessKey, secretKey = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+		}
+	}
+	if accessKey == "" || secretKey == "" {
+		return "", "", fmt.Errorf("未配置 %s 凭据", provider)
+	}
+	return accessKey, secretKey, nil
+}
+
+func providerRegion(provider string) string {
+	if LoadedConfig == nil {
+		return ""
+	}
+	switch provider {
+	case "alicloud":
+		return LoadedConfig.Providers.Alicloud.Region
+	case "tencentcloud":
+		return LoadedConfig.Providers.Tencentcloud.Region
+	case "aws":
+		return LoadedConfig.Providers.Aws.Region
+	}
+	return ""
+}
+
+func alicloudInstanceRunning(ctx context.Context, region, instanceID string) (bool, error) {
+	accessKey, secretKey, err := probeCredentials("alicloud")
+	if err != nil {
+		return false, err
+	}
+	client, err := alicloudecs.NewClientWithAccessKey(region, accessKey, secretKey)
+	if err != nil {
+		return false, err
+	}
+	req := alicloudecs.CreateDescribeInstanceStatusRequest()
+	req.Scheme = "https"
+	req.InstanceId = &[]string{instanceID}
+	resp, err := client.DescribeInstanceStatus(req)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range resp.InstanceStatuses.InstanceStatus {
+		if s.InstanceId == instanceID {
+			return s.Status == "Running", nil
+		}
+	}
+	return false, nil
+}
+
+func tencentInstanceRunning(ctx context.Context, region, instanceID string) (bool, error) {
+	secretID, secretKey, err := probeCredentials("tencentcloud")
+	if err != nil {
+		return false, err
+	}
+	client, err := cvm.NewClient(common.NewCredential(secretID, secretKey), region, profile.NewClientProfile())
+	if err != nil {
+		return false, err
+	}
+	req := cvm.NewDescribeInstancesStatusRequest()
+	req.InstanceIds = common.StringPtrs([]string{instanceID})
+	req.SetContext(ctx)
+	resp, err := client.DescribeInstancesStatus(req)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range resp.Response.InstanceStatusSet {
+		if s.InstanceId != nil && *s.InstanceId == instanceID {
+			return s.InstanceState != nil && *s.InstanceState == "RUNNING", nil
+		}
+	}
+	return false, nil
+}
+
+func awsInstanceRunning(ctx context.Context, region, instanceID string) (bool, error) {
+	accessKey, secretKey, err := probeCredentials("aws")
+	if err != nil {
+		return false, err
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		Credentials: awscredentials.NewStaticCredentials(accessKey, secretKey, ""),
+	})
+	if err != nil {
+		return false, err
+	}
+	out, err := ec2.New(sess).DescribeInstanceStatusWithContext(ctx, &ec2.DescribeInstanceStatusInput{
+		InstanceIds:         []*string{aws.String(instanceID)},
+		IncludeAllInstances: aws.Bool(true),
+	})
+	if err != nil {
+		// 已被回收并清理的实例会返回 InvalidInstanceID.NotFound
+		if strings.Contains(err.Error(), "InvalidInstanceID.NotFound") {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, s := range out.InstanceStatuses {
+		if aws.StringValue(s.InstanceId) == instanceID {
+			return s.InstanceState != nil && aws.StringValue(s.InstanceState.Name) == ec2.InstanceStateNameRunning, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,193 @@
+package mod
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestProbeConfig_Normalize(t *testing.T) {
+	cfg := DefaultProbeConfig()
+	if cfg.Type != ProbeSSH || cfg.Port != 22 || cfg.Retries != 3 || cfg.RetryInterval != 15 || cfg.FailureThreshold != 1 {
+		t.Errorf("DefaultProbeConfig = %+v", cfg)
+	}
+
+	https := ProbeConfig{Type: "HTTPS", Path: "health"}
+	if err := https.Normalize(); err != nil || https.Port != 443 || https.Path != "/health" {
+		t.Errorf("https Normalize = %+v, %v", https, err)
+	}
+	cloud := ProbeConfig{Type: "cloud"}
+	if err := cloud.Normalize(); err != nil || cloud.Retries != 1 {
+		t.Errorf("cloud Normalize = %+v, %v", cloud, err)
+	}
+
+	for _, bad := range []ProbeConfig{{Type: "icmp"}, {Type: "tcp"}, {Type: "tcp", Port: 70000}} {
+		if err := bad.Normalize(); err == nil {
+			t.Errorf("Normalize(%+v) should fail", bad)
+		}
+	}
+}
+
+func TestLoadProbeConfig(t *testing.T) {
+	dir := t.TempDir()
+	if cfg, err := LoadProbeConfig(dir); err != nil || cfg.Type != ProbeSSH {
+		t.Errorf("missing case.json = %+v, %v", cfg, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, TmplCaseFile), []byte(`{"name":"x","liveness_probe":{"type":"http","port":8080,"path":"/ping","failure_threshold":3}}`), 0644)
+	cfg, err := LoadProbeConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadProbeConfig failed: %v", err)
+	}
+	if cfg.Type != ProbeHTTP || cfg.Port != 8080 || cfg.Path != "/ping" || cfg.FailureThreshold != 3 || cfg.Timeout != 10 {
+		t.Errorf("LoadProbeConfig = %+v", cfg)
+	}
+
+	// Invalid config falls back to the default probe
+	os.WriteFile(filepath.Join(dir, TmplCaseFile), []byte(`{"liveness_probe":{"type":"icmp"}}`), 0644)
+	if cfg, err := LoadProbeConfig(dir); err == nil || cfg.Type != ProbeSSH {
+		t.Errorf("invalid probe = %+v, %v", cfg, err)
+	}
+}
+
+func listenTCP(t *testing.T, banner string) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			if banner != "" {
+				conn.Write([]byte(banner))
+			}
+			conn.Close()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func probeOnce(t *testing.T, cfg ProbeConfig) error {
+	t.Helper()
+	cfg.Timeout = 2
+	cfg.Retries = 1
+	p, err := NewProber(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return RunProbe(context.Background(), p, cfg, ProbeTarget{IP: "127.0.0.1"})
+}
+
+func TestProbers(t *testing.T) {
+	sshPort := listenTCP(t, "SSH-2.0-OpenSSH_8.9\r\n")
+	httpPort := listenTCP(t, "HTTP/1.1 400 Bad Request\r\n\r\n")
+
+	if err := probeOnce(t, ProbeConfig{Type: ProbeSSH, Port: sshPort}); err != nil {
+		t.Errorf("ssh probe on sshd: %v", err)
+	}
+	if err := probeOnce(t, ProbeConfig{Type: ProbeSSH, Port: httpPort}); err == nil {
+		t.Error("ssh probe on non-ssh service should fail")
+	}
+	if err := probeOnce(t, ProbeConfig{Type: ProbeTCP, Port: httpPort}); err != nil {
+		t.Errorf("tcp probe: %v", err)
+	}
+
+	// Closed port
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	if err := probeOnce(t, ProbeConfig{Type: ProbeTCP, Port: closed}); err == nil || errors.Is(err, ErrProbeUnknown) {
+		t.Errorf("tcp probe on closed port = %v, want failure", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	port, _ := strconv.Atoi(srv.URL[len("http://127.0.0.1:"):])
+	if err := probeOnce(t, ProbeConfig{Type: ProbeHTTP, Port: port, Path: "/health"}); err != nil {
+		t.Errorf("http probe /health: %v", err)
+	}
+	if err := probeOnce(t, ProbeConfig{Type: ProbeHTTP, Port: port, Path: "/health", ExpectStatus: 200}); err == nil {
+		t.Error("http probe with mismatched expect_status should fail")
+	}
+	if err := probeOnce(t, ProbeConfig{Type: ProbeHTTP, Port: port, Path: "/hung"}); err == nil {
+		t.Error("http probe on 503 should fail")
+	}
+
+	// Cloud probe without a matching instance is inconclusive
+	if err := probeOnce(t, ProbeConfig{Type: ProbeCloud}); !errors.Is(err, ErrProbeUnknown) {
+		t.Errorf("cloud probe without instance = %v, want ErrProbeUnknown", err)
+	}
+}
+
+type countingProber struct {
+	calls int
+	err   error
+}
+
+func (p *countingProber) Probe(context.Context, ProbeTarget) error {
+	p.calls++
+	return p.err
+}
+
+func TestRunProbe_Retries(t *testing.T) {
+	cfg := ProbeConfig{Type: ProbeTCP, Port: 1, Retries: 3, RetryInterval: 1}
+	cfg.Normalize()
+
+	p := &countingProber{err: errors.New("down")}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// Cancelled while waiting between retries: inconclusive, no false positive
+	if err := RunProbe(ctx, p, cfg, ProbeTarget{}); !errors.Is(err, ErrProbeUnknown) || p.calls != 1 {
+		t.Errorf("cancelled RunProbe = %v after %d calls", err, p.calls)
+	}
+
+	p = &countingProber{err: ErrProbeUnknown}
+	if err := RunProbe(context.Background(), p, cfg, ProbeTarget{}); !errors.Is(err, ErrProbeUnknown) || p.calls != 1 {
+		t.Errorf("unknown result should not be retried: %v after %d calls", err, p.calls)
+	}
+}
+
+func TestCloudInstancesFromState(t *testing.T) {
+	state := &tfjson.State{Values: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+		Resources: []*tfjson.StateResource{
+			{Address: "alicloud_instance.web[0]", Type: "alicloud_instance", Mode: tfjson.ManagedResourceMode,
+				AttributeValues: map[string]interface{}{"id": "i-abc", "public_ip": "1.1.1.1", "availability_zone": "cn-hangzhou-h"}},
+			{Address: "alicloud_security_group.sg", Type: "alicloud_security_group", Mode: tfjson.ManagedResourceMode,
+				AttributeValues: map[string]interface{}{"id": "sg-1"}},
+		},
+		ChildModules: []*tfjson.StateModule{{Resources: []*tfjson.StateResource{
+			{Address: "module.vm.aws_spot_instance_request.x", Type: "aws_spot_instance_request", Mode: tfjson.ManagedResourceMode,
+				AttributeValues: map[string]interface{}{"id": "sir-1", "spot_instance_id": "i-0aws", "public_ip": "2.2.2.2", "availability_zone": "us-east-1a"}},
+		}}},
+	}}}
+
+	got := cloudInstancesFromState(state)
+	if len(got) != 2 {
+		t.Fatalf("cloudInstancesFromState = %+v", got)
+	}
+	if got[0].Provider != "alicloud" || got[0].ID != "i-abc" || got[0].Region != "cn-hangzhou" {
+		t.Errorf("alicloud instance = %+v", got[0])
+	}
+	if got[1].Provider != "aws" || got[1].ID != "i-0aws" || got[1].PublicIP != "2.2.2.2" || got[1].Region != "us-east-1" {
+		t.Errorf("aws instance = %+v", got[1])
+	}
+}
@@ -20,6 +20,8 @@ type ProfileInfo struct {
 	ConfigPath  string    `json:"configPath"`
 	TemplateDir string    `json:"templateDir"`
 	AIConfig    *AIConfig `json:"aiConfig,omitempty"`
+	// Currency 费用显示币种与汇率来源
+	Currency *CurrencySettings `json:"currency,omitempty"`
 }
 
 // AIConfig represents AI provider configuration
@@ -49,6 +51,8 @@ type profilePayload struct {
 	ConfigPath  string    `json:"configPath"`
 	TemplateDir string    `json:"templateDir"`
 	AIConfig    *AIConfig `json:"aiConfig,omitempty"`
+	// Currency 费用显示币种与汇率来源
+	Currency *CurrencySettings `json:"currency,omitempty"`
 }
 
 func ensureRedcPath() error {
@@ -131,6 +135,7 @@ func readProfileFile(id string) (ProfileInfo, error) {
 		ConfigPath:  payload.ConfigPath,
 		TemplateDir: payload.TemplateDir,
 		AIConfig:    payload.AIConfig,
+		Currency:    payload.Currency,
 	}, nil
 }
 
@@ -152,6 +157,7 @@ func writeProfileFile(id string, payload profilePayload) (ProfileInfo, error) {
 		ConfigPath:  payload.ConfigPath,
 		TemplateDir: payload.TemplateDir,
 		AIConfig:    payload.AIConfig,
+		Currency:    payload.Currency,
 	}, nil
 }
 
@@ -285,11 +291,15 @@ func UpdateProfile(id string, name string, configPath string, templateDir string
 		}
 		templateDir = path
 	}
-	return writeProfileFile(id, profilePayload{
+	payload := profilePayload{
 		Name:        name,
 		ConfigPath:  configPath,
 		TemplateDir: templateDir,
-	})
+	}
+	if existing, err := readProfileFile(id); err == nil {
+		payload.Currency = existing.Currency
+	}
+	return writeProfileFile(id, payload)
 }
 
 // UpdateProfileAIConfig updates only the AI config for a profile
@@ -311,6 +321,7 @@ func writeProfileFileWithAIConfig(id string, profile ProfileInfo, aiConfig *AICo
 		ConfigPath:  profile.ConfigPath,
 		TemplateDir: profile.TemplateDir,
 		AIConfig:    aiConfig,
+		Currency:    profile.Currency,
 	}
 	data, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
@@ -86,9 +86,30 @@ func (p *RedcProject) GetCase(identifier string) (*Case, error) {
 
 	// 2. 绑定运行时逻辑 (复活对象)
 	c.bindHandlers()
+	c.pluginHookRunner = p.pluginHookRunner
 	return c, nil
 }
 
+// SetPluginHookRunner 设置项目的插件钩子回调，之后创建或查找到的场景都会使用它
+func (p *RedcProject) SetPluginHookRunner(runner PluginHookRunner) {
+	p.pluginHookRunner = runner
+}
+
+// RunPluginHook 运行项目级插件钩子（compose pre-up/post-up），未设置回调时直接返回
+func (p *RedcProject) RunPluginHook(hookPoint string, ev PluginHookEvent) error {
+	if p.pluginHookRunner == nil {
+		return nil
+	}
+	if ev.Project == "" {
+		ev.Project = p.ProjectName
+	}
+	if err := p.pluginHookRunner(hookPoint, nil, ev); err != nil {
+		gologger.Error().Msgf("%s", i18n.Tf("case_hook_blocked", hookPoint, err))
+		return fmt.Errorf("%s", i18n.Tf("case_hook_blocked", hookPoint, err))
+	}
+	return nil
+}
+
 // FindCaseBySearch 数据库层面的搜索 (Docker 风格：ID精确 -> Name精确 -> ID前缀)
 // 这个函数虽然会遍历，但它不占用内存，因为它边读边丢，只留匹配的那一个
 func FindCaseBySearch(projectID, keyword string) (*Case, error) {
@@ -215,6 +236,11 @@ func (p *RedcProject) CaseList() {
 		if len(c.Id) > 12 {
 			displayID = c.Id[:12]
 		}
+		// 模板名带上创建时的版本，与 pull 的 name:tag 一致
+		displayType := c.Type
+		if c.TemplateVersion != "" {
+			displayType += ":" + c.TemplateVersion
+		}
 		createTime := parseTime(c.StateTime) // 解析字符串时间
 		var displayStatus string
 		// 3. 这里使用 c.State 和新的常量
@@ -222,6 +248,9 @@ func (p *RedcProject) CaseList() {
 		case StateRunning:
 			displayStatus = fmt.Sprintf("Up %s", humanDuration(createTime))
 
+		case StateDegraded:
+			displayStatus = fmt.Sprintf("Up %s (degraded)", humanDuration(createTime))
+
 		case StateStopped:
 			displayStatus = fmt.Sprintf("Exited (0) %s ago", humanDurationShort(createTime))
 
@@ -242,7 +271,7 @@ func (p *RedcProject) CaseList() {
 		// 使用 Fprintf 配合 \t 格式化输出
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
 			displayID,
-			c.Type,
+			displayType,
 			c.Name,
 			c.Operator,
 			c.CreateTime,
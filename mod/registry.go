@@ -0,0 +1,360 @@
+package mod
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"red-cloud/i18n"
+	"red-cloud/mod/gologger"
+)
+
+// 官方模板仓库，未配置仓库时使用
+const (
+	DefaultRegistryName = "official"
+	DefaultRegistryURL  = "https://redc.wgpsec.org"
+)
+
+// Registry 模板仓库，配置在 config.yaml 的 registries 中，按顺序查找模板。
+// URL 支持 http(s):// 和 file://（本地静态目录，包含 index.json 与模板 ZIP）
+type Registry struct {
+	Name string `yaml:"name" json:"name"`
+	URL  string `yaml:"url" json:"url"`
+	// Token 为 Bearer token；Username 非空时使用 Basic 认证，Token 作为密码
+	Token    string `yaml:"token,omitempty" json:"-"`
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	// CAFile 校验仓库证书的 CA bundle（PEM），用于自签名证书的私有仓库
+	CAFile string `yaml:"ca_file,omitempty" json:"ca_file,omitempty"`
+}
+
+// DefaultRegistry 返回官方模板仓库
+func DefaultRegistry() Registry {
+	return Registry{Name: DefaultRegistryName, URL: DefaultRegistryURL}
+}
+
+// LoadRegistries 读取配置的模板仓库，未配置时返回官方仓库
+func LoadRegistries() ([]Registry, error) {
+	conf, _, err := ReadConfig(ActiveConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(conf.Registries) == 0 {
+		return []Registry{DefaultRegistry()}, nil
+	}
+	return conf.Registries, nil
+}
+
+// AddRegistry 添加模板仓库，first 为 true 时放在最前面（优先查找）。
+// 首次添加时保留官方仓库
+func AddRegistry(r Registry, first bool) error {
+	r.URL = normalizeRegistryURL(r.URL)
+	if err := r.validate(); err != nil {
+		return err
+	}
+	conf, path, err := ReadConfig(ActiveConfigPath)
+	if err != nil {
+		return err
+	}
+	regs := conf.Registries
+	if len(regs) == 0 {
+		regs = []Registry{DefaultRegistry()}
+	}
+	for _, existing := range regs {
+		if existing.Name == r.Name {
+			return fmt.Errorf("registry %q already exists", r.Name)
+		}
+	}
+	if first {
+		regs = append([]Registry{r}, regs...)
+	} else {
+		regs = append(regs, r)
+	}
+	conf.Registries = regs
+	return SaveConfig(conf, path)
+}
+
+// RemoveRegistry 删除模板仓库。全部删除后恢复为官方仓库
+func RemoveRegistry(name string) error {
+	conf, path, err := ReadConfig(ActiveConfigPath)
+	if err != nil {
+		return err
+	}
+	regs := conf.Registries
+	if len(regs) == 0 {
+		regs = []Registry{DefaultRegistry()}
+	}
+	kept := make([]Registry, 0, len(regs))
+	for _, r := range regs {
+		if r.Name != name {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == len(regs) {
+		return fmt.Errorf("registry %q not found", name)
+	}
+	conf.Registries = kept
+	return SaveConfig(conf, path)
+}
+
+// ParseImageRef 解析 name[:tag][@registry]，tag 为空时返回 latest
+func ParseImageRef(ref string) (name, tag, registry string) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		ref, registry = ref[:i], ref[i+1:]
+	}
+	name, tag, _ = strings.Cut(ref, ":")
+	if tag == "" {
+		tag = "latest"
+	}
+	return name, tag, registry
+}
+
+// SetRegistry 设置使用的仓库，取值为地址（含 :// 或本地目录）时设置 RegistryURL，否则作为仓库名称
+func (o *PullOptions) SetRegistry(registry string) {
+	registry = strings.TrimSpace(registry)
+	if strings.Contains(registry, "://") {
+		o.RegistryURL = registry
+	} else if info, err := os.Stat(registry); err == nil && info.IsDir() {
+		o.RegistryURL = registry
+	} else {
+		o.Registry = registry
+	}
+}
+
+// registries 返回本次操作使用的仓库：RegistryURL 指定的仓库，Registry 指定名称的仓库，
+// 或按顺序使用所有配置的仓库
+func (o PullOptions) registries() ([]Registry, error) {
+	regs := o.Registries
+	if regs == nil {
+		loaded, err := LoadRegistries()
+		if err != nil && o.RegistryURL == "" {
+			return nil, err
+		}
+		regs = loaded
+	}
+	if o.RegistryURL != "" {
+		u := normalizeRegistryURL(o.RegistryURL)
+		// 与配置中的仓库地址相同时沿用其认证信息
+		for _, r := range regs {
+			if normalizeRegistryURL(r.URL) == u {
+				return []Registry{r}, nil
+			}
+		}
+		return []Registry{{Name: u, URL: u}}, nil
+	}
+	if o.Registry != "" {
+		for _, r := range regs {
+			if r.Name == o.Registry {
+				return []Registry{r}, nil
+			}
+		}
+		return nil, fmt.Errorf("registry %q is not configured", o.Registry)
+	}
+	return regs, nil
+}
+
+// findTemplate 按顺序在仓库中查找模板，返回第一个包含该模板的仓库
+func findTemplate(ctx context.Context, name string, opts PullOptions) (Registry, TemplateItem, error) {
+	regs, err := opts.registries()
+	if err != nil {
+		return Registry{}, TemplateItem{}, err
+	}
+	var errs []error
+	for _, reg := range regs {
+		gologger.Info().Msgf("🔍 Connecting to registry %s...", reg)
+		idx, err := reg.Index(ctx)
+		if err != nil {
+			if len(regs) == 1 {
+				return reg, TemplateItem{}, err
+			}
+			gologger.Warning().Msgf("%s", i18n.Tf("registry_unavailable", reg.Name, err))
+			errs = append(errs, fmt.Errorf("%s: %w", reg.Name, err))
+			continue
+		}
+		if tmpl, ok := idx.Templates[name]; ok {
+			return reg, tmpl, nil
+		}
+	}
+	if len(errs) > 0 {
+		return Registry{}, TemplateItem{}, fmt.Errorf("template '%s' not found in registry (%w)", name, errors.Join(errs...))
+	}
+	return Registry{}, TemplateItem{}, fmt.Errorf("template '%s' not found in registry", name)
+}
+
+// String 返回 "name (url)"，临时指定的仓库只返回 URL
+func (r Registry) String() string {
+	if r.Name == "" || r.Name == r.URL {
+		return r.URL
+	}
+	return fmt.Sprintf("%s (%s)", r.Name, r.URL)
+}
+
+// Index 获取并解析仓库的 index.json
+func (r Registry) Index(ctx context.Context) (*RemoteIndex, error) {
+	var idx RemoteIndex
+	indexURL := strings.TrimSuffix(r.URL, "/") + "/index.json"
+	if !r.isLocal() {
+		// 添加时间戳防止 CDN 缓存
+		indexURL = fmt.Sprintf("%s?t=%d", indexURL, time.Now().Unix())
+	}
+	if err := fetchJSON(ctx, r, indexURL, &idx); err != nil {
+		return nil, fmt.Errorf("fetch index failed: %w", err)
+	}
+	return &idx, nil
+}
+
+// open 读取仓库中的文件，ref 可以是相对仓库地址的路径。
+// 认证信息只发送给与仓库同源的地址，避免泄露给第三方下载地址；file:// 地址只允许出现在本地仓库中
+func (r Registry) open(ctx context.Context, ref string, timeout time.Duration) (io.ReadCloser, int64, error) {
+	base, err := url.Parse(strings.TrimSuffix(r.URL, "/") + "/")
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid registry url: %w", err)
+	}
+	target, err := base.Parse(ref)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid url %s: %w", ref, err)
+	}
+
+	if target.Scheme == "file" {
+		// 远程仓库的 index.json 不能让客户端读取本地文件
+		if base.Scheme != "file" {
+			return nil, 0, fmt.Errorf("registry %s: local url %s is only allowed in a file:// registry", r.Name, ref)
+		}
+		f, err := os.Open(fileURLPath(target))
+		if err != nil {
+			return nil, 0, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		return f, info.Size(), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", target.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if target.Scheme == base.Scheme && target.Host == base.Host {
+		r.authorize(req)
+	}
+	client, err := r.client(timeout)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("http status %d", resp.StatusCode)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+func (r Registry) authorize(req *http.Request) {
+	switch {
+	case r.Username != "":
+		req.SetBasicAuth(r.Username, r.Token)
+	case r.Token != "":
+		req.Header.Set("Authorization", "Bearer "+r.Token)
+	}
+}
+
+// client 返回使用代理配置的 HTTP 客户端，配置了 CAFile 时信任其中的证书
+func (r Registry) client(timeout time.Duration) (*http.Client, error) {
+	client := NewProxyHTTPClient(timeout)
+	if r.CAFile == "" {
+		return client, nil
+	}
+	pem, err := os.ReadFile(r.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ca bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", r.CAFile)
+	}
+	var transport *http.Transport
+	if t, ok := client.Transport.(*http.Transport); ok {
+		transport = t.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	client.Transport = transport
+	return client, nil
+}
+
+func (r Registry) isLocal() bool {
+	return strings.HasPrefix(r.URL, "file://")
+}
+
+func (r Registry) validate() error {
+	if r.Name == "" || strings.ContainsAny(r.Name, "@:/ ") {
+		return fmt.Errorf("invalid registry name %q", r.Name)
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file") {
+		return fmt.Errorf("invalid registry url %q (http, https or file)", r.URL)
+	}
+	if r.CAFile != "" {
+		if _, err := os.Stat(r.CAFile); err != nil {
+			return fmt.Errorf("ca bundle: %w", err)
+		}
+	}
+	return nil
+}
+
+// normalizeRegistryURL 去掉末尾的 /，本地目录转换为 file:// 地址
+func normalizeRegistryURL(raw string) string {
+	raw = strings.TrimSuffix(strings.TrimSpace(raw), "/")
+	if strings.Contains(raw, "://") {
+		return raw
+	}
+	if info, err := os.Stat(raw); err == nil && info.IsDir() {
+		if abs, err := filepath.Abs(raw); err == nil {
+			p := filepath.ToSlash(abs)
+			if !strings.HasPrefix(p, "/") {
+				p = "/" + p
+			}
+			return (&url.URL{Scheme: "file", Path: p}).String()
+		}
+	}
+	return raw
+}
+
+// fileURLPath 将 file:// 地址转换为本地路径
+func fileURLPath(u *url.URL) string {
+	p := u.Path
+	if runtime.GOOS == "windows" {
+		// file:///C:/templates -> C:/templates
+		p = strings.TrimPrefix(p, "/")
+	}
+	return filepath.FromSlash(p)
+}
+
+// fetchJSON 读取仓库中的 JSON 文件
+func fetchJSON(ctx context.Context, reg Registry, url string, v interface{}) error {
+	body, _, err := reg.open(ctx, url, 30*time.Second)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
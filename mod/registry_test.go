@@ -0,0 +1,192 @@
+package mod
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseImageRef(t *testing.T) {
+	cases := map[string][3]string{
+		"aws/ec2":            {"aws/ec2", "latest", ""},
+		"aws/ec2:1.0.0":      {"aws/ec2", "1.0.0", ""},
+		"aws/ec2@team":       {"aws/ec2", "latest", "team"},
+		"aws/ec2:1.0.0@team": {"aws/ec2", "1.0.0", "team"},
+	}
+	for ref, want := range cases {
+		if name, tag, reg := ParseImageRef(ref); [3]string{name, tag, reg} != want {
+			t.Errorf("ParseImageRef(%q) = %s %s %s", ref, name, tag, reg)
+		}
+	}
+}
+
+// staticRegistry 写入本地静态仓库，ZIP 使用相对 index.json 的路径
+func staticRegistry(t *testing.T, versions ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	item := TemplateItem{ID: "aws/ec2", Latest: versions[len(versions)-1], Versions: map[string]TemplateVersion{}}
+	for _, v := range versions {
+		archive, sum := templateZip(t, v)
+		name := "aws/ec2-" + v + ".zip"
+		writeFiles(t, dir, map[string]string{name: string(archive)})
+		item.Versions[v] = TemplateVersion{URL: name, SHA256: sum}
+	}
+	data, _ := json.Marshal(RemoteIndex{Templates: map[string]TemplateItem{"aws/ec2": item}})
+	writeFiles(t, dir, map[string]string{"index.json": string(data)})
+	return dir
+}
+
+// privateRegistry 启动需要 Bearer token 的 HTTPS 仓库，ZIP 放在另一个地址上，返回仓库与其 CA 文件
+func privateRegistry(t *testing.T) (Registry, *[]string) {
+	t.Helper()
+	var leaked []string
+	archive, sum := templateZip(t, "2.0.0")
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			leaked = append(leaked, auth)
+		}
+		w.Write(archive)
+	}))
+	t.Cleanup(cdn.Close)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	idx := RemoteIndex{Templates: map[string]TemplateItem{
+		"aws/ec2":      {ID: "aws/ec2", Latest: "2.0.0", Versions: map[string]TemplateVersion{"2.0.0": {URL: "/ec2.zip", SHA256: sum}}},
+		"team/private": {ID: "team/private", Latest: "2.0.0", Versions: map[string]TemplateVersion{"2.0.0": {URL: cdn.URL + "/private.zip", SHA256: sum}}},
+	}}
+	mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) { json.NewEncoder(w).Encode(idx) })
+	mux.HandleFunc("/ec2.zip", func(w http.ResponseWriter, r *http.Request) { w.Write(archive) })
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0644)
+	return Registry{Name: "team", URL: srv.URL, Token: "s3cret", CAFile: caFile}, &leaked
+}
+
+func TestPull_MultipleRegistries(t *testing.T) {
+	useTemplateDir(t)
+	team, leaked := privateRegistry(t)
+	local := Registry{Name: "local", URL: normalizeRegistryURL(staticRegistry(t, "1.0.0"))}
+	lockFile := filepath.Join(t.TempDir(), TemplateLockFile)
+	opts := PullOptions{Registries: []Registry{local, team}, LockFile: lockFile}
+	ctx := context.Background()
+
+	// 按顺序查找，第一个包含模板的仓库生效
+	if err := Pull(ctx, "aws/ec2", opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, v, _ := CheckLocalImage("aws/ec2"); v != "1.0.0" {
+		t.Errorf("aws/ec2 installed v%s from the wrong registry", v)
+	}
+	if lock, _ := LoadTemplateLock(lockFile); lock.Templates["aws/ec2"].Registry != "local" {
+		t.Errorf("lock entry = %+v", lock.Templates["aws/ec2"])
+	}
+
+	if err := Pull(ctx, "aws/ec2@team", opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, v, _ := CheckLocalImage("aws/ec2"); v != "2.0.0" {
+		t.Errorf("aws/ec2@team installed v%s", v)
+	}
+	if err := Pull(ctx, "team/private", opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(*leaked) > 0 {
+		t.Errorf("registry token sent to the download host: %v", *leaked)
+	}
+
+	results, err := Search(ctx, "ec2", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sources []string
+	for _, r := range results {
+		sources = append(sources, r.Key+"@"+r.Registry)
+	}
+	if strings.Join(sources, ",") != "aws/ec2@local,aws/ec2@team" {
+		t.Errorf("search results = %v", sources)
+	}
+
+	team.Token = ""
+	opts.Registries = []Registry{team}
+	if err := Pull(ctx, "team/private", opts); err == nil || !strings.Contains(err.Error(), "http status 401") {
+		t.Errorf("pull without token: err = %v", err)
+	}
+	if err := Pull(ctx, "aws/ec2@nope", opts); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("unknown registry: err = %v", err)
+	}
+}
+
+func TestRegistryOpen_FileURLOnlyInLocalRegistry(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret.txt")
+	os.WriteFile(secret, []byte("key"), 0600)
+	fileURL := normalizeRegistryURL(filepath.Dir(secret)) + "/secret.txt"
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	ctx := context.Background()
+
+	remote := Registry{Name: "remote", URL: srv.URL}
+	if _, _, err := remote.open(ctx, fileURL, time.Second); err == nil || !strings.Contains(err.Error(), "only allowed") {
+		t.Errorf("remote registry opened %s: err = %v", fileURL, err)
+	}
+	local := Registry{Name: "local", URL: normalizeRegistryURL(filepath.Dir(secret))}
+	body, _, err := local.open(ctx, "secret.txt", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+}
+
+func TestAddRemoveRegistry(t *testing.T) {
+	oldPath, oldConf := ActiveConfigPath, LoadedConfig
+	ActiveConfigPath = filepath.Join(t.TempDir(), "config.yaml")
+	t.Cleanup(func() { ActiveConfigPath, LoadedConfig = oldPath, oldConf })
+
+	if regs, _ := LoadRegistries(); len(regs) != 1 || regs[0] != DefaultRegistry() {
+		t.Fatalf("default registries = %+v", regs)
+	}
+	dir := t.TempDir()
+	if err := AddRegistry(Registry{Name: "local", URL: dir}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddRegistry(Registry{Name: "team", URL: "https://templates.example.com/", Token: "x"}, true); err != nil {
+		t.Fatal(err)
+	}
+	regs, _ := LoadRegistries()
+	var names []string
+	for _, r := range regs {
+		names = append(names, r.Name)
+	}
+	if strings.Join(names, ",") != "team,official,local" || regs[0].URL != "https://templates.example.com" || !strings.HasPrefix(regs[2].URL, "file://") {
+		t.Fatalf("registries = %+v", regs)
+	}
+
+	for _, bad := range []Registry{{Name: "team", URL: "https://a"}, {Name: "a@b", URL: "https://a"}, {Name: "ftp", URL: "ftp://a"}} {
+		if err := AddRegistry(bad, false); err == nil {
+			t.Errorf("AddRegistry(%+v) succeeded", bad)
+		}
+	}
+	if err := RemoveRegistry("official"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveRegistry("official"); err == nil {
+		t.Error("removing a missing registry succeeded")
+	}
+	if regs, _ := LoadRegistries(); len(regs) != 2 {
+		t.Errorf("registries after rm = %+v", regs)
+	}
+}
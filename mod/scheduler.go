@@ -36,6 +36,8 @@ type TaskScheduler struct {
 	onExecute     func(caseID string, action string) error
 	onSSHCommand  func(caseID string, command string) (string, error)
 	onNotify      func(title string, message string)
+	onCaseCost    func(projectID string, caseID string) (float64, string, error)
+	casePrices    map[string]casePrice // 策略使用的场景小时价格缓存，仅在调度循环中访问
 	db            *sql.DB
 	dbPath        string
 }
@@ -109,6 +111,13 @@ func (s *TaskScheduler) InitDB() error {
 		db.Exec(col) // ignore "duplicate column" errors
 	}
 
+	// TTL / 预算策略表
+	if _, err := db.Exec(createPolicyTableSQL); err != nil {
+		db.Close()
+		return fmt.Errorf("创建策略表失败: %v", err)
+	}
+	db.Exec("ALTER TABLE lifecycle_policies ADD COLUMN budget_warned INTEGER DEFAULT 0") // ignore "duplicate column" errors
+
 	s.db = db
 
 	// 从数据库加载待执行的任务
@@ -121,21 +130,33 @@ func (s *TaskScheduler) InitDB() error {
 
 // loadTasksFromDB 从数据库加载待执行的任务
 func (s *TaskScheduler) loadTasksFromDB() error {
+	tasks, err := s.queryTasks(`WHERE status = 'pending'`)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, task := range tasks {
+		s.tasks[task.ID] = task
+	}
+	return nil
+}
+
+// queryTasks 按条件从数据库读取任务
+func (s *TaskScheduler) queryTasks(where string, args ...interface{}) ([]*ScheduledTask, error) {
 	rows, err := s.db.Query(`
 		SELECT id, case_id, case_name, action, scheduled_at, created_at, status, error,
 		       COALESCE(repeat_type, 'once'), COALESCE(repeat_interval, 0), completed_at,
 		       COALESCE(ssh_command, ''), COALESCE(task_result, ''), COALESCE(notify_enabled, 0)
-		FROM scheduled_tasks
-		WHERE status = 'pending'
-	`)
+		FROM scheduled_tasks `+where, args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer rows.Close()
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
+	var tasks []*ScheduledTask
 	for rows.Next() {
 		task := &ScheduledTask{}
 		var scheduledAtStr, createdAtStr string
@@ -143,20 +164,10 @@ func (s *TaskScheduler) loadTasksFromDB() error {
 		var notifyInt int
 
 		err := rows.Scan(
-			&task.ID,
-			&task.CaseID,
-			&task.CaseName,
-			&task.Action,
-			&scheduledAtStr,
-			&createdAtStr,
-			&task.Status,
-			&errorStr,
-			&task.RepeatType,
-			&task.RepeatInterval,
-			&completedAtStr,
-			&task.SSHCommand,
-			&task.TaskResult,
-			&notifyInt,
+			&task.ID, &task.CaseID, &task.CaseName, &task.Action,
+			&scheduledAtStr, &createdAtStr, &task.Status, &errorStr,
+			&task.RepeatType, &task.RepeatInterval, &completedAtStr,
+			&task.SSHCommand, &task.TaskResult, &notifyInt,
 		)
 		if err != nil {
 			continue
@@ -174,11 +185,46 @@ func (s *TaskScheduler) loadTasksFromDB() error {
 		if task.RepeatType == "" {
 			task.RepeatType = "once"
 		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
 
-		s.tasks[task.ID] = task
+// syncPendingFromDB 与数据库同步待执行任务（调用方需持有 mu）
+// CLI / 其他进程可能直接修改 scheduler.db：新增的任务加入内存，已被取消、删除或执行的任务从内存移除
+func (s *TaskScheduler) syncPendingFromDB() {
+	if s.db == nil {
+		return
+	}
+	pending, err := s.queryTasks(`WHERE status = 'pending'`)
+	if err != nil {
+		return
+	}
+	inDB := make(map[string]bool, len(pending))
+	for _, task := range pending {
+		inDB[task.ID] = true
+		if _, exists := s.tasks[task.ID]; !exists {
+			s.tasks[task.ID] = task
+		}
 	}
+	for id, task := range s.tasks {
+		if task.Status == "pending" && !inDB[id] {
+			delete(s.tasks, id)
+		}
+	}
+}
 
-	return rows.Err()
+// claimTask 将任务从 pending 原子地标记为 executing，避免多个进程重复执行
+func (s *TaskScheduler) claimTask(taskID string) bool {
+	if s.db == nil {
+		return true
+	}
+	res, err := s.db.Exec(`UPDATE scheduled_tasks SET status = 'executing' WHERE id = ? AND status = 'pending'`, taskID)
+	if err != nil {
+		return false
+	}
+	n, _ := res.RowsAffected()
+	return n > 0
 }
 
 // saveTaskToDB 保存任务到数据库
@@ -280,6 +326,8 @@ func (s *TaskScheduler) periodicCleanup() {
 func (s *TaskScheduler) run() {
 	ticker := time.NewTicker(10 * time.Second) // 每10秒检查一次
 	defer ticker.Stop()
+	policyTicker := time.NewTicker(1 * time.Minute) // TTL / 预算策略每分钟检查一次
+	defer policyTicker.Stop()
 
 	for {
 		select {
@@ -287,6 +335,9 @@ func (s *TaskScheduler) run() {
 			return
 		case <-ticker.C:
 			s.checkAndExecuteTasks()
+		case <-policyTicker.C:
+			s.checkPolicies()
+			s.reconcileCostLedger()
 		}
 	}
 }
@@ -296,9 +347,15 @@ func (s *TaskScheduler) checkAndExecuteTasks() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.syncPendingFromDB()
+
 	now := time.Now()
 	for id, task := range s.tasks {
 		if task.Status == "pending" && now.After(task.ScheduledAt) {
+			if !s.claimTask(id) {
+				continue
+			}
+			task.Status = "executing"
 			// 执行任务
 			go s.executeTask(id, task)
 		}
@@ -306,12 +363,8 @@ func (s *TaskScheduler) checkAndExecuteTasks() {
 }
 
 // executeTask 执行任务
+// 调用方需已通过 claimTask 将任务标记为 executing
 func (s *TaskScheduler) executeTask(id string, task *ScheduledTask) {
-	s.mu.Lock()
-	task.Status = "executing"
-	s.updateTaskStatusInDB(id, "executing", "")
-	s.mu.Unlock()
-
 	var err error
 	var result string
 
@@ -522,10 +575,59 @@ func (s *TaskScheduler) GetTask(taskID string) (*ScheduledTask, error) {
 	defer s.mu.RUnlock()
 
 	task, exists := s.tasks[taskID]
-	if !exists {
-		return nil, fmt.Errorf("任务不存在")
+	if exists {
+		return task, nil
 	}
 
+	// 已执行 / 取消的任务只在数据库中
+	if s.db != nil {
+		if tasks, err := s.queryTasks(`WHERE id = ?`, taskID); err == nil && len(tasks) > 0 {
+			return tasks[0], nil
+		}
+	}
+	return nil, fmt.Errorf("任务不存在")
+}
+
+// RemoveTask 删除任务（任意状态），运行中的调度器会在下次同步时移除
+func (s *TaskScheduler) RemoveTask(taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task, exists := s.tasks[taskID]; exists && task.Status == "executing" {
+		return fmt.Errorf("任务正在执行，无法删除")
+	}
+	if s.db != nil {
+		res, err := s.db.Exec(`DELETE FROM scheduled_tasks WHERE id = ? AND status != 'executing'`, taskID)
+		if err != nil {
+			return fmt.Errorf("删除任务失败: %v", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			if _, exists := s.tasks[taskID]; !exists {
+				return fmt.Errorf("任务不存在或正在执行")
+			}
+		}
+	}
+	delete(s.tasks, taskID)
+	return nil
+}
+
+// RunTaskNow 立即同步执行一个待执行任务，周期任务会照常生成下一次
+func (s *TaskScheduler) RunTaskNow(taskID string) (*ScheduledTask, error) {
+	s.mu.Lock()
+	s.syncPendingFromDB()
+	task, exists := s.tasks[taskID]
+	if !exists || task.Status != "pending" {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("只能立即执行待执行的任务: %s", taskID)
+	}
+	if !s.claimTask(taskID) {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("任务已被其他调度器执行或取消: %s", taskID)
+	}
+	task.Status = "executing"
+	s.mu.Unlock()
+
+	s.executeTask(taskID, task)
 	return task, nil
 }
 
@@ -564,50 +666,13 @@ func (s *TaskScheduler) ListAllTasksFromDB() []*ScheduledTask {
 	}
 
 	cutoff := time.Now().Add(-7 * 24 * time.Hour).Format(time.RFC3339)
-	rows, err := s.db.Query(`
-		SELECT id, case_id, case_name, action, scheduled_at, created_at, status, error,
-		       COALESCE(repeat_type, 'once'), COALESCE(repeat_interval, 0), completed_at,
-		       COALESCE(ssh_command, ''), COALESCE(task_result, ''), COALESCE(notify_enabled, 0)
-		FROM scheduled_tasks
+	tasks, err := s.queryTasks(`
 		WHERE created_at > ? OR status = 'pending'
 		ORDER BY scheduled_at DESC
 	`, cutoff)
 	if err != nil {
 		return s.ListTasks()
 	}
-	defer rows.Close()
-
-	var tasks []*ScheduledTask
-	for rows.Next() {
-		task := &ScheduledTask{}
-		var scheduledAtStr, createdAtStr string
-		var errorStr, completedAtStr sql.NullString
-		var notifyInt int
-
-		err := rows.Scan(
-			&task.ID, &task.CaseID, &task.CaseName, &task.Action,
-			&scheduledAtStr, &createdAtStr, &task.Status, &errorStr,
-			&task.RepeatType, &task.RepeatInterval, &completedAtStr,
-			&task.SSHCommand, &task.TaskResult, &notifyInt,
-		)
-		if err != nil {
-			continue
-		}
-
-		task.NotifyEnabled = notifyInt != 0
-		task.ScheduledAt, _ = time.Parse(time.RFC3339, scheduledAtStr)
-		task.CreatedAt, _ = time.Parse(time.RFC3339, createdAtStr)
-		if errorStr.Valid {
-			task.Error = errorStr.String
-		}
-		if completedAtStr.Valid {
-			task.CompletedAt, _ = time.Parse(time.RFC3339, completedAtStr.String)
-		}
-		if task.RepeatType == "" {
-			task.RepeatType = "once"
-		}
-		tasks = append(tasks, task)
-	}
 	return tasks
 }
 
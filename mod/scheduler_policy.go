@@ -0,0 +1,345 @@
+package mod
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"red-cloud/mod/cost"
+	"red-cloud/mod/gologger"
+)
+
+const createPolicyTableSQL = `
+	CREATE TABLE IF NOT EXISTS lifecycle_policies (
+		id TEXT PRIMARY KEY,
+		kind TEXT NOT NULL,
+		name TEXT NOT NULL,
+		project_id TEXT NOT NULL,
+		case_ids TEXT DEFAULT '[]',
+		ttl_seconds INTEGER DEFAULT 0,
+		expires_at DATETIME,
+		budget REAL DEFAULT 0,
+		currency TEXT DEFAULT '',
+		action TEXT NOT NULL,
+		hourly_cost TEXT DEFAULT '{}',
+		accrued REAL DEFAULT 0,
+		checked_at DATETIME,
+		warned INTEGER DEFAULT 0,
+		budget_warned INTEGER DEFAULT 0,
+		triggered INTEGER DEFAULT 0,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_policy_project ON lifecycle_policies(project_id);
+	`
+
+// policyPriceTTL 策略使用的场景小时价格缓存时长，到期后重新估算（跟上竞价实例等价格变化）；
+// 场景的 terraform state 更新（扩缩容、变更配置）后会立即重新估算
+const policyPriceTTL = time.Hour
+
+// casePrice 场景小时价格缓存
+type casePrice struct {
+	hourly   float64
+	currency string
+	at       time.Time
+}
+
+// SetCaseCostCallback 设置场景小时费用回调，返回值为 (小时价格, 币种)
+func (s *TaskScheduler) SetCaseCostCallback(callback func(projectID string, caseID string) (float64, string, error)) {
+	s.onCaseCost = callback
+}
+
+// SetPolicy 新增或覆盖生命周期策略
+func (s *TaskScheduler) SetPolicy(p *LifecyclePolicy) error {
+	if s.db == nil {
+		return fmt.Errorf("调度器数据库未初始化")
+	}
+	if p.ID == "" {
+		return fmt.Errorf("策略 ID 不能为空")
+	}
+	return s.savePolicyToDB(p)
+}
+
+// GetPolicy 获取指定 ID 的生命周期策略
+func (s *TaskScheduler) GetPolicy(id string) (*LifecyclePolicy, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("调度器数据库未初始化")
+	}
+	policies, err := s.queryPolicies(`WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("策略不存在: %s", id)
+	}
+	return policies[0], nil
+}
+
+// ListPolicies 列出所有生命周期策略
+func (s *TaskScheduler) ListPolicies() ([]*LifecyclePolicy, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("调度器数据库未初始化")
+	}
+	return s.queryPolicies(`ORDER BY created_at DESC`)
+}
+
+// RemovePolicy 删除生命周期策略
+func (s *TaskScheduler) RemovePolicy(id string) error {
+	if s.db == nil {
+		return fmt.Errorf("调度器数据库未初始化")
+	}
+	res, err := s.db.Exec(`DELETE FROM lifecycle_policies WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("策略不存在: %s", id)
+	}
+	return nil
+}
+
+// savePolicyToDB 保存策略到数据库
+func (s *TaskScheduler) savePolicyToDB(p *LifecyclePolicy) error {
+	caseIDs, _ := json.Marshal(p.CaseIDs)
+	hourly, _ := json.Marshal(p.HourlyCost)
+	var expiresAt, checkedAt string
+	if !p.ExpiresAt.IsZero() {
+		expiresAt = p.ExpiresAt.Format(time.RFC3339)
+	}
+	if !p.CheckedAt.IsZero() {
+		checkedAt = p.CheckedAt.Format(time.RFC3339)
+	}
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO lifecycle_policies
+		(id, kind, name, project_id, case_ids, ttl_seconds, expires_at, budget, currency, action, hourly_cost, accrued, checked_at, warned, budget_warned, triggered, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		p.ID,
+		p.Kind,
+		p.Name,
+		p.ProjectID,
+		string(caseIDs),
+		int64(p.TTL/time.Second),
+		expiresAt,
+		p.Budget,
+		p.Currency,
+		p.Action,
+		string(hourly),
+		p.Accrued,
+		checkedAt,
+		boolToInt(p.TTLWarned),
+		boolToInt(p.BudgetWarned),
+		boolToInt(p.Triggered),
+		p.CreatedAt.Format(time.RFC3339),
+	)
+	return err
+}
+
+// queryPolicies 按条件读取策略
+func (s *TaskScheduler) queryPolicies(where string, args ...interface{}) ([]*LifecyclePolicy, error) {
+	rows, err := s.db.Query(`
+		SELECT id, kind, name, project_id, COALESCE(case_ids, '[]'), COALESCE(ttl_seconds, 0), expires_at,
+		       COALESCE(budget, 0), COALESCE(currency, ''), action, COALESCE(hourly_cost, '{}'),
+		       COALESCE(accrued, 0), checked_at, COALESCE(warned, 0), COALESCE(budget_warned, 0), COALESCE(triggered, 0), created_at
+		FROM lifecycle_policies `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*LifecyclePolicy
+	for rows.Next() {
+		p := &LifecyclePolicy{}
+		var caseIDs, hourly, createdAtStr string
+		var expiresAtStr, checkedAtStr sql.NullString
+		var ttlSeconds int64
+		var warned, budgetWarned, triggered int
+
+		if err := rows.Scan(
+			&p.ID, &p.Kind, &p.Name, &p.ProjectID, &caseIDs, &ttlSeconds, &expiresAtStr,
+			&p.Budget, &p.Currency, &p.Action, &hourly,
+			&p.Accrued, &checkedAtStr, &warned, &budgetWarned, &triggered, &createdAtStr,
+		); err != nil {
+			continue
+		}
+
+		json.Unmarshal([]byte(caseIDs), &p.CaseIDs)
+		json.Unmarshal([]byte(hourly), &p.HourlyCost)
+		if p.HourlyCost == nil {
+			p.HourlyCost = map[string]float64{}
+		}
+		p.TTL = time.Duration(ttlSeconds) * time.Second
+		if expiresAtStr.Valid && expiresAtStr.String != "" {
+			p.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAtStr.String)
+		}
+		if checkedAtStr.Valid && checkedAtStr.String != "" {
+			p.CheckedAt, _ = time.Parse(time.RFC3339, checkedAtStr.String)
+		}
+		p.CreatedAt, _ = time.Parse(time.RFC3339, createdAtStr)
+		p.TTLWarned = warned != 0
+		p.BudgetWarned = budgetWarned != 0
+		p.Triggered = triggered != 0
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// checkPolicies 累计花费并执行 TTL / 预算策略
+// 每次都从数据库重新读取，以便 CLI 写入的策略也能被 GUI / daemon 调度器执行；
+// 有其他进程运行 daemon 时由 daemon 负责执行，避免 GUI 与 daemon 重复停止 / 删除场景
+func (s *TaskScheduler) checkPolicies() {
+	if s.db == nil {
+		return
+	}
+	if pid, ok := OtherDaemonRunning(); ok {
+		gologger.Debug().Msgf("lifecycle policies are enforced by daemon (pid %d)", pid)
+		return
+	}
+	policies, err := s.queryPolicies(`WHERE triggered = 0`)
+	if err != nil {
+		gologger.Debug().Msgf("load lifecycle policies: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, p := range policies {
+		cases := s.policyCases(p)
+		running := make(map[string]bool)
+		for id, c := range cases {
+			if IsRunningState(c.State) {
+				running[id] = true
+			}
+		}
+
+		// 累计自上次检查以来的花费，再刷新价格（新价格只影响之后的区间）
+		p.Accrue(now, running)
+		for id := range running {
+			if hourly, ok := s.caseHourlyCost(p.ProjectID, cases[id], p.Currency, now); ok {
+				p.HourlyCost[id] = hourly
+			}
+		}
+
+		// 所有成员都已被删除的策略直接清理
+		if len(cases) == 0 {
+			s.db.Exec(`DELETE FROM lifecycle_policies WHERE id = ?`, p.ID)
+			continue
+		}
+
+		verdict := p.Evaluate(now)
+		if verdict.Warn {
+			p.MarkWarned(verdict)
+			s.notifyPolicy(fmt.Sprintf("⚠️ [%s] %s，将在超限后执行 %s", p.Name, verdict.Reason, p.Action))
+		}
+		if verdict.Exceed {
+			p.Triggered = true
+			s.enforcePolicy(p, cases)
+			s.notifyPolicy(fmt.Sprintf("⛔ [%s] %s，已执行 %s", p.Name, verdict.Reason, p.Action))
+		}
+
+		if err := s.savePolicyToDB(p); err != nil {
+			gologger.Debug().Msgf("save lifecycle policy %s: %v", p.ID, err)
+		}
+	}
+}
+
+// reconcileCostLedger 用当前项目的场景状态校正费用台账，并为运行中的区间补充价格
+func (s *TaskScheduler) reconcileCostLedger() {
+	if s.project == nil {
+		return
+	}
+	if err := ReconcileCostLedger(s.project.ProjectName); err != nil {
+		gologger.Debug().Msgf("reconcile cost ledger: %v", err)
+	}
+}
+
+// policyCases 读取策略关联的、仍然存在的场景
+func (s *TaskScheduler) policyCases(p *LifecyclePolicy) map[string]*Case {
+	result := make(map[string]*Case)
+	cases, err := LoadProjectCases(p.ProjectID)
+	if err != nil {
+		return result
+	}
+	wanted := make(map[string]bool, len(p.CaseIDs))
+	for _, id := range p.CaseIDs {
+		wanted[id] = true
+	}
+	for _, c := range cases {
+		if wanted[c.Id] {
+			result[c.Id] = c
+		}
+	}
+	return result
+}
+
+// caseHourlyCost 通过回调获取场景小时费用并换算到策略币种
+// 价格按场景缓存 policyPriceTTL，state 文件更新后重新估算；估算失败时沿用上次的价格
+func (s *TaskScheduler) caseHourlyCost(projectID string, c *Case, currency string, now time.Time) (float64, bool) {
+	if s.onCaseCost == nil {
+		return 0, false
+	}
+	if s.casePrices == nil {
+		s.casePrices = make(map[string]casePrice)
+	}
+	price, cached := s.casePrices[c.Id]
+	if !cached || now.Sub(price.at) >= policyPriceTTL || caseStateChangedSince(c, price.at) {
+		hourly, from, err := s.onCaseCost(projectID, c.Id)
+		if err != nil {
+			gologger.Debug().Msgf("case %s hourly cost unavailable: %v", c.Id, err)
+			if !cached {
+				return 0, false
+			}
+		} else {
+			price = casePrice{hourly: hourly, currency: from, at: now}
+			s.casePrices[c.Id] = price
+		}
+	}
+
+	hourly, from := price.hourly, price.currency
+	if from == "" || currency == "" || from == currency {
+		return hourly, true
+	}
+	converted, err := CostCurrencyConverter().Convert(hourly, cost.Currency(from), cost.Currency(currency))
+	if err != nil {
+		gologger.Debug().Msgf("convert %s -> %s: %v", from, currency, err)
+		return hourly, true
+	}
+	return converted, true
+}
+
+// caseStateChangedSince 判断场景的 terraform state 是否在 t 之后被更新
+func caseStateChangedSince(c *Case, t time.Time) bool {
+	info, err := os.Stat(filepath.Join(c.Path, "terraform.tfstate"))
+	return err == nil && info.ModTime().After(t)
+}
+
+// enforcePolicy 对策略关联的场景执行 stop / destroy
+func (s *TaskScheduler) enforcePolicy(p *LifecyclePolicy, cases map[string]*Case) {
+	if s.onExecute == nil {
+		return
+	}
+	for id, c := range cases {
+		if p.Action == PolicyActionStop && !IsRunningState(c.State) {
+			continue
+		}
+		if err := s.onExecute(id, p.Action); err != nil {
+			gologger.Error().Msgf("lifecycle policy %s: %s %s failed: %v", p.ID, p.Action, c.Name, err)
+		}
+	}
+}
+
+// notifyPolicy 通过通知 / webhook 回调发送策略消息
+func (s *TaskScheduler) notifyPolicy(msg string) {
+	gologger.Info().Msg(msg)
+	if s.onNotify != nil {
+		s.onNotify("预算 / TTL 策略", msg)
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package mod
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestScheduler(t *testing.T, dbPath string) *TaskScheduler {
+	t.Helper()
+	s := NewTaskScheduler(nil, dbPath)
+	if err := s.InitDB(); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	t.Cleanup(s.Stop)
+	return s
+}
+
+func TestTaskScheduler_SyncAcrossProcesses(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "scheduler.db")
+	gui := newTestScheduler(t, dbPath)
+	cli := newTestScheduler(t, dbPath)
+
+	task, err := cli.AddTaskFull("case1", "web", "ssh_command", time.Now().Add(time.Hour), "interval", 30, "uptime", true)
+	if err != nil {
+		t.Fatalf("AddTaskFull failed: %v", err)
+	}
+
+	// Task written by another process is picked up on the next tick
+	gui.mu.Lock()
+	gui.syncPendingFromDB()
+	got, ok := gui.tasks[task.ID]
+	gui.mu.Unlock()
+	if !ok {
+		t.Fatalf("task %s not synced into running scheduler", task.ID)
+	}
+	if got.SSHCommand != "uptime" || got.RepeatInterval != 30 || !got.NotifyEnabled {
+		t.Errorf("synced task = %+v, fields not persisted", got)
+	}
+
+	// Removal by another process drops it from memory
+	if err := cli.RemoveTask(task.ID); err != nil {
+		t.Fatalf("RemoveTask failed: %v", err)
+	}
+	gui.mu.Lock()
+	gui.syncPendingFromDB()
+	_, ok = gui.tasks[task.ID]
+	gui.mu.Unlock()
+	if ok {
+		t.Errorf("removed task still present in running scheduler")
+	}
+}
+
+func TestTaskScheduler_ClaimOnce(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "scheduler.db")
+	a := newTestScheduler(t, dbPath)
+	b := newTestScheduler(t, dbPath)
+
+	task, err := a.AddTask("case1", "web", "stop", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	if !a.claimTask(task.ID) {
+		t.Fatal("first claim should succeed")
+	}
+	if b.claimTask(task.ID) {
+		t.Error("second claim from another scheduler should fail")
+	}
+}
+
+func TestTaskScheduler_RunTaskNow(t *testing.T) {
+	s := newTestScheduler(t, filepath.Join(t.TempDir(), "scheduler.db"))
+
+	var executed []string
+	s.SetExecuteCallback(func(caseID, action string) error {
+		executed = append(executed, caseID+":"+action)
+		return nil
+	})
+
+	task, err := s.AddTaskWithRepeat("case1", "web", "stop", time.Now().Add(time.Hour), "daily", 0)
+	if err != nil {
+		t.Fatalf("AddTaskWithRepeat failed: %v", err)
+	}
+
+	done, err := s.RunTaskNow(task.ID)
+	if err != nil {
+		t.Fatalf("RunTaskNow failed: %v", err)
+	}
+	if done.Status != "completed" || len(executed) != 1 || executed[0] != "case1:stop" {
+		t.Errorf("status = %s, executed = %v", done.Status, executed)
+	}
+
+	// A daily task schedules its next occurrence
+	if pending := s.ListTasksByCase("case1"); len(pending) != 2 {
+		t.Errorf("ListTasksByCase = %d tasks, want completed + next occurrence", len(pending))
+	}
+
+	// Finished tasks are still visible via GetTask (from the DB)
+	s.mu.Lock()
+	delete(s.tasks, task.ID)
+	s.mu.Unlock()
+	if got, err := s.GetTask(task.ID); err != nil || got.Status != "completed" {
+		t.Errorf("GetTask after completion = %v, %v", got, err)
+	}
+
+	if _, err := s.RunTaskNow(task.ID); err == nil {
+		t.Error("RunTaskNow on a completed task should fail")
+	}
+}
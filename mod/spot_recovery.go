@@ -0,0 +1,281 @@
+package mod
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SpotRecoveryFile 场景目录下保存恢复策略与历史的文件名
+const SpotRecoveryFile = "redc-recovery.json"
+
+const (
+	// DefaultRecoveryMaxAttempts 未配置时的最大尝试次数（与旧行为一致：只重试一次）
+	DefaultRecoveryMaxAttempts = 1
+	// recoveryHistoryLimit 保留的恢复记录条数
+	recoveryHistoryLimit = 50
+)
+
+// onDemandVars 切换为按量（非抢占式）实例时使用的常见模板变量
+var onDemandVars = map[string]string{
+	"spot":             "false",
+	"use_spot":         "false",
+	"is_spot":          "false",
+	"is_spot_instance": "false",
+	"spot_instance":    "false",
+	"spot_strategy":    "NoSpot",
+}
+
+// RecoveryStep 一个回退方案，只覆盖设置了的字段
+type RecoveryStep struct {
+	Region       string            `json:"region,omitempty"`
+	InstanceType string            `json:"instanceType,omitempty"`
+	OnDemand     bool              `json:"onDemand,omitempty"` // 改用按量实例
+	Vars         map[string]string `json:"vars,omitempty"`     // 其它模板变量
+}
+
+// SpotRecoveryPolicy 抢占式实例被回收后的恢复策略
+type SpotRecoveryPolicy struct {
+	// Fallbacks 按顺序尝试的回退方案；第一次尝试总是使用原参数
+	Fallbacks       []RecoveryStep `json:"fallbacks,omitempty"`
+	MaxAttempts     int            `json:"maxAttempts,omitempty"`
+	CooldownSeconds int            `json:"cooldownSeconds,omitempty"` // 两次尝试之间的等待时间（秒）
+	// 模板中对应的变量名，默认 region / instance_type
+	RegionVar       string `json:"regionVar,omitempty"`
+	InstanceTypeVar string `json:"instanceTypeVar,omitempty"`
+	// ComposeFile 场景所属的 compose 文件，恢复后重新部署依赖它的服务
+	ComposeFile string `json:"composeFile,omitempty"`
+}
+
+// RecoveryAttempt 一次恢复尝试的记录
+type RecoveryAttempt struct {
+	At       time.Time `json:"at"`
+	Attempt  int       `json:"attempt"`
+	Step     int       `json:"step"` // 0 = 原参数, n = Fallbacks[n-1]
+	Params   []string  `json:"params,omitempty"`
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty"`
+	OldIPs   []string  `json:"oldIps,omitempty"`
+	NewIPs   []string  `json:"newIps,omitempty"`
+	Duration string    `json:"duration,omitempty"`
+}
+
+// SpotRecoveryRecord 保存在场景目录中的策略与历史
+type SpotRecoveryRecord struct {
+	Policy  SpotRecoveryPolicy `json:"policy"`
+	History []RecoveryAttempt  `json:"history,omitempty"`
+}
+
+// Normalize 填充默认值并校验
+func (p *SpotRecoveryPolicy) Normalize() error {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRecoveryMaxAttempts
+	}
+	if p.CooldownSeconds < 0 {
+		return fmt.Errorf("冷却时间不能为负数")
+	}
+	if p.RegionVar == "" {
+		p.RegionVar = "region"
+	}
+	if p.InstanceTypeVar == "" {
+		p.InstanceTypeVar = "instance_type"
+	}
+	for i, s := range p.Fallbacks {
+		if s.Region == "" && s.InstanceType == "" && !s.OnDemand && len(s.Vars) == 0 {
+			return fmt.Errorf("回退方案 #%d 为空", i+1)
+		}
+	}
+	return nil
+}
+
+// CooldownDuration 两次尝试之间的等待时间
+func (p *SpotRecoveryPolicy) CooldownDuration() time.Duration {
+	return time.Duration(p.CooldownSeconds) * time.Second
+}
+
+// StepFor 返回第 attempt 次尝试（从 0 开始）使用的方案序号：0 为原参数，之后依次回退，超出后停留在最后一个
+func (p *SpotRecoveryPolicy) StepFor(attempt int) int {
+	if attempt > len(p.Fallbacks) {
+		return len(p.Fallbacks)
+	}
+	return attempt
+}
+
+// ApplyStep 在原参数上叠加回退方案，返回新的参数列表
+// declared 为模板声明的变量集合（nil 表示不校验），未声明的变量会被跳过并返回在 skipped 中
+func (p *SpotRecoveryPolicy) ApplyStep(params []string, step int, declared map[string]bool) (result []string, skipped []string) {
+	if step <= 0 || step > len(p.Fallbacks) {
+		return append([]string(nil), params...), nil
+	}
+	s := p.Fallbacks[step-1]
+
+	overrides := map[string]string{}
+	if s.Region != "" {
+		overrides[p.RegionVar] = s.Region
+	}
+	if s.InstanceType != "" {
+		overrides[p.InstanceTypeVar] = s.InstanceType
+	}
+	if s.OnDemand {
+		if declared == nil {
+			// 无法确定模板变量时只使用最常见的写法
+			overrides["spot"] = "false"
+		} else {
+			matched := false
+			for k, v := range onDemandVars {
+				if declared[k] {
+					overrides[k] = v
+					matched = true
+				}
+			}
+			if !matched {
+				skipped = append(skipped, "on-demand")
+			}
+		}
+	}
+	for k, v := range s.Vars {
+		overrides[k] = v
+	}
+
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	applied := map[string]bool{}
+	for _, param := range params {
+		key, _, ok := strings.Cut(param, "=")
+		if ok {
+			if v, exists := overrides[key]; exists {
+				result = append(result, key+"="+v)
+				applied[key] = true
+				continue
+			}
+		}
+		result = append(result, param)
+	}
+	for _, k := range keys {
+		if applied[k] {
+			continue
+		}
+		if declared != nil && !declared[k] {
+			skipped = append(skipped, k)
+			continue
+		}
+		result = append(result, k+"="+overrides[k])
+	}
+	return result, skipped
+}
+
+var tfVariablePattern = regexp.MustCompile(`(?m)^\s*variable\s+"([^"]+)"`)
+
+// DeclaredTfVars 扫描模板目录中的 *.tf，返回声明的变量名集合
+func DeclaredTfVars(dir string) map[string]bool {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil || len(files) == 0 {
+		return nil
+	}
+	vars := map[string]bool{}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		for _, m := range tfVariablePattern.FindAllStringSubmatch(string(data), -1) {
+			vars[m[1]] = true
+		}
+	}
+	return vars
+}
+
+// LoadSpotRecovery 读取场景的恢复策略与历史，不存在时返回默认策略
+func LoadSpotRecovery(casePath string) (*SpotRecoveryRecord, error) {
+	rec := &SpotRecoveryRecord{}
+	data, err := os.ReadFile(filepath.Join(casePath, SpotRecoveryFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(data, rec); err != nil {
+		return nil, fmt.Errorf("解析恢复策略失败: %v", err)
+	}
+	if err := rec.Policy.Normalize(); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// SaveSpotRecovery 写入场景的恢复策略与历史
+func SaveSpotRecovery(casePath string, rec *SpotRecoveryRecord) error {
+	if err := rec.Policy.Normalize(); err != nil {
+		return err
+	}
+	if len(rec.History) > recoveryHistoryLimit {
+		rec.History = rec.History[len(rec.History)-recoveryHistoryLimit:]
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(casePath, SpotRecoveryFile), data, 0644)
+}
+
+// SetSpotRecoveryPolicy 更新策略，保留历史
+func SetSpotRecoveryPolicy(casePath string, policy SpotRecoveryPolicy) error {
+	rec, err := LoadSpotRecovery(casePath)
+	if err != nil {
+		rec = &SpotRecoveryRecord{}
+	}
+	rec.Policy = policy
+	return SaveSpotRecovery(casePath, rec)
+}
+
+// AppendRecoveryAttempt 追加一条恢复记录
+func AppendRecoveryAttempt(casePath string, attempt RecoveryAttempt) error {
+	rec, err := LoadSpotRecovery(casePath)
+	if err != nil {
+		return err
+	}
+	rec.History = append(rec.History, attempt)
+	return SaveSpotRecovery(casePath, rec)
+}
+
+// ParseRecoveryStep 解析 CLI 回退方案，例如 "region=ap-southeast-1,instance_type=t3.small"、"on-demand"
+func ParseRecoveryStep(s string) (RecoveryStep, error) {
+	var step RecoveryStep
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if part == "on-demand" || part == "ondemand" {
+			step.OnDemand = true
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		if !ok || k == "" {
+			return step, fmt.Errorf("无效的回退方案: %s (示例: region=ap-southeast-1,instance_type=t3.small 或 on-demand)", s)
+		}
+		switch k {
+		case "region":
+			step.Region = v
+		case "instance_type", "instance-type":
+			step.InstanceType = v
+		default:
+			if step.Vars == nil {
+				step.Vars = map[string]string{}
+			}
+			step.Vars[k] = v
+		}
+	}
+	if step.Region == "" && step.InstanceType == "" && !step.OnDemand && len(step.Vars) == 0 {
+		return step, fmt.Errorf("回退方案为空: %s", s)
+	}
+	return step, nil
+}
@@ -0,0 +1,121 @@
+package mod
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSpotRecoveryPolicy_StepFor(t *testing.T) {
+	p := SpotRecoveryPolicy{Fallbacks: []RecoveryStep{{Region: "a"}, {OnDemand: true}}}
+	want := []int{0, 1, 2, 2, 2}
+	for attempt, w := range want {
+		if got := p.StepFor(attempt); got != w {
+			t.Errorf("StepFor(%d) = %d, want %d", attempt, got, w)
+		}
+	}
+}
+
+func TestSpotRecoveryPolicy_ApplyStep(t *testing.T) {
+	p := SpotRecoveryPolicy{Fallbacks: []RecoveryStep{
+		{Region: "ap-southeast-1", InstanceType: "t3.small"},
+		{OnDemand: true},
+		{Vars: map[string]string{"zone": "b"}},
+	}}
+	if err := p.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	params := []string{"region=cn-hangzhou", "node=2"}
+	declared := map[string]bool{"region": true, "node": true, "instance_type": true, "spot_strategy": true}
+
+	got, skipped := p.ApplyStep(params, 0, declared)
+	if !reflect.DeepEqual(got, params) || len(skipped) != 0 {
+		t.Errorf("step 0 = %v %v, want original params", got, skipped)
+	}
+
+	got, _ = p.ApplyStep(params, 1, declared)
+	want := []string{"region=ap-southeast-1", "node=2", "instance_type=t3.small"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("step 1 = %v, want %v", got, want)
+	}
+
+	got, _ = p.ApplyStep(params, 2, declared)
+	want = []string{"region=cn-hangzhou", "node=2", "spot_strategy=NoSpot"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("step 2 = %v, want %v", got, want)
+	}
+
+	// Undeclared variables are skipped instead of breaking terraform
+	got, skipped = p.ApplyStep(params, 3, declared)
+	if !reflect.DeepEqual(got, params) || !reflect.DeepEqual(skipped, []string{"zone"}) {
+		t.Errorf("step 3 = %v skipped %v, want zone skipped", got, skipped)
+	}
+
+	// Original slice must not be modified
+	if params[0] != "region=cn-hangzhou" {
+		t.Errorf("ApplyStep modified input params: %v", params)
+	}
+}
+
+func TestParseRecoveryStep(t *testing.T) {
+	step, err := ParseRecoveryStep("region=us-west-2, instance_type=t3.micro,zone=b")
+	if err != nil {
+		t.Fatalf("ParseRecoveryStep failed: %v", err)
+	}
+	if step.Region != "us-west-2" || step.InstanceType != "t3.micro" || step.Vars["zone"] != "b" {
+		t.Errorf("ParseRecoveryStep = %+v", step)
+	}
+	if step, err := ParseRecoveryStep("on-demand"); err != nil || !step.OnDemand {
+		t.Errorf("ParseRecoveryStep(on-demand) = %+v, %v", step, err)
+	}
+	for _, bad := range []string{"", "region", ","} {
+		if _, err := ParseRecoveryStep(bad); err == nil {
+			t.Errorf("ParseRecoveryStep(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSpotRecovery_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "variables.tf"), []byte("variable \"region\" {}\n  variable \"instance_type\" {\n}\n"), 0644)
+
+	vars := DeclaredTfVars(dir)
+	if !vars["region"] || !vars["instance_type"] || len(vars) != 2 {
+		t.Errorf("DeclaredTfVars = %v", vars)
+	}
+
+	// Missing file yields the default policy
+	rec, err := LoadSpotRecovery(dir)
+	if err != nil || rec.Policy.MaxAttempts != DefaultRecoveryMaxAttempts {
+		t.Fatalf("LoadSpotRecovery default = %+v, %v", rec, err)
+	}
+
+	policy := SpotRecoveryPolicy{MaxAttempts: 3, CooldownSeconds: 60, Fallbacks: []RecoveryStep{{OnDemand: true}}}
+	if err := SetSpotRecoveryPolicy(dir, policy); err != nil {
+		t.Fatalf("SetSpotRecoveryPolicy failed: %v", err)
+	}
+	if err := AppendRecoveryAttempt(dir, RecoveryAttempt{Attempt: 1, Success: true, NewIPs: []string{"1.2.3.4"}}); err != nil {
+		t.Fatalf("AppendRecoveryAttempt failed: %v", err)
+	}
+
+	// Updating the policy keeps the history
+	policy.MaxAttempts = 4
+	if err := SetSpotRecoveryPolicy(dir, policy); err != nil {
+		t.Fatal(err)
+	}
+	rec, err = LoadSpotRecovery(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Policy.MaxAttempts != 4 || rec.Policy.CooldownSeconds != 60 || len(rec.Policy.Fallbacks) != 1 {
+		t.Errorf("policy = %+v", rec.Policy)
+	}
+	if len(rec.History) != 1 || rec.History[0].NewIPs[0] != "1.2.3.4" {
+		t.Errorf("history = %+v", rec.History)
+	}
+
+	if err := SetSpotRecoveryPolicy(dir, SpotRecoveryPolicy{Fallbacks: []RecoveryStep{{}}}); err == nil {
+		t.Error("empty fallback should be rejected")
+	}
+}
@@ -68,6 +68,9 @@ func (c *Case) toProto() *pb.Case {
 		StateTime:  c.StateTime,
 		Parameter:  c.Parameter,
 
+		TemplateVersion: c.TemplateVersion,
+		TemplateSha256:  c.TemplateSHA256,
+
 		// 【简化】直接强转 string，不再使用 Enum 映射
 		State: c.State,
 
@@ -91,6 +94,9 @@ func caseFromProto(p *pb.Case) *Case {
 		StateTime:  p.StateTime,
 		Parameter:  p.Parameter,
 		State:      p.State,
+
+		TemplateVersion: p.TemplateVersion,
+		TemplateSHA256:  p.TemplateSha256,
 	}
 
 	// 还原 output map
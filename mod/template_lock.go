@@ -0,0 +1,223 @@
+package mod
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"red-cloud/i18n"
+	"red-cloud/mod/gologger"
+)
+
+// TemplateLockFile 项目级模板锁文件，位于项目目录下
+const TemplateLockFile = "redc.lock"
+
+// TemplateLock 对应 redc.lock，锁定项目使用的模板版本
+type TemplateLock struct {
+	Version   int                          `json:"version"`
+	Templates map[string]TemplateLockEntry `json:"templates"`
+}
+
+// TemplateLockEntry 单个模板的锁定信息
+type TemplateLockEntry struct {
+	Version       string `json:"version"`
+	SHA256        string `json:"sha256,omitempty"`         // 模板目录内容摘要，见 TemplateHash
+	ArchiveSHA256 string `json:"archive_sha256,omitempty"` // registry 中 ZIP 包的摘要，本地模板为空
+	Registry      string `json:"registry,omitempty"`       // 拉取时使用的仓库名称
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// templateHashExcluded 不参与模板摘要的文件和目录（terraform init 产物与状态文件）
+var templateHashExcluded = map[string]bool{
+	".git":                     true,
+	".terraform":               true,
+	".terraform.lock.hcl":      true,
+	"terraform.tfstate":        true,
+	"terraform.tfstate.backup": true,
+	".DS_Store":                true,
+}
+
+// TemplateHash 计算模板目录的 SHA-256 内容摘要，覆盖所有文件的相对路径与内容，
+// 不包含 .git、.terraform、.terraform.lock.hcl 与 tfstate，redc init 不会改变摘要
+func TemplateHash(dir string) (string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if templateHashExcluded[info.Name()] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, rel := range files {
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return "", err
+		}
+		fh := sha256.New()
+		_, err = io.Copy(fh, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%x\n", rel, fh.Sum(nil))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// LoadTemplateLock 读取锁文件，文件不存在时返回空锁
+func LoadTemplateLock(path string) (*TemplateLock, error) {
+	lock := &TemplateLock{Version: 1, Templates: make(map[string]TemplateLockEntry)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if lock.Templates == nil {
+		lock.Templates = make(map[string]TemplateLockEntry)
+	}
+	return lock, nil
+}
+
+// Save 写入锁文件
+func (l *TemplateLock) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// PinTemplate 在锁文件中记录模板版本
+func PinTemplate(lockPath, name string, entry TemplateLockEntry) error {
+	lock, err := LoadTemplateLock(lockPath)
+	if err != nil {
+		return err
+	}
+	entry.UpdatedAt = time.Now().Format(time.RFC3339)
+	lock.Templates[name] = entry
+	return lock.Save(lockPath)
+}
+
+// TemplateLockPath 返回项目的 redc.lock 路径
+func (p *RedcProject) TemplateLockPath() string {
+	return filepath.Join(p.ProjectPath, TemplateLockFile)
+}
+
+// checkTemplateLock 创建场景时对照项目锁文件：模板未锁定时锁定当前版本，
+// 本地模板与锁定版本不一致时给出警告
+func (p *RedcProject) checkTemplateLock(name, version, hash string) {
+	lockPath := p.TemplateLockPath()
+	lock, err := LoadTemplateLock(lockPath)
+	if err != nil {
+		gologger.Warning().Msgf("%s", i18n.Tf("template_lock_read_failed", lockPath, err))
+		return
+	}
+	entry, ok := lock.Templates[name]
+	if !ok {
+		if err := PinTemplate(lockPath, name, TemplateLockEntry{Version: version, SHA256: hash}); err != nil {
+			gologger.Warning().Msgf("%s", i18n.Tf("template_lock_write_failed", lockPath, err))
+		}
+		return
+	}
+	if entry.Version != version || (entry.SHA256 != "" && hash != "" && entry.SHA256 != hash) {
+		gologger.Warning().Msgf("%s", i18n.Tf("case_template_lock_mismatch", name, version, TemplateLockFile, entry.Version))
+	}
+}
+
+// TemplateChanged 检查场景的模板自创建以来是否在本地被修改（重新拉取或编辑）。
+// 旧场景没有记录摘要，模板已删除时也不视为修改
+func (c *Case) TemplateChanged() (bool, string) {
+	if c.TemplateSHA256 == "" {
+		return false, ""
+	}
+	tpPath, err := GetTemplatePath(c.Type)
+	if err != nil {
+		return false, ""
+	}
+	hash, err := TemplateHash(tpPath)
+	if err != nil || hash == c.TemplateSHA256 {
+		return false, ""
+	}
+	version := "unknown"
+	if meta, err := readTemplateMeta(tpPath); err == nil && meta.Version != "" {
+		version = meta.Version
+	}
+	return true, version
+}
+
+// warnTemplateChanged 启动场景前提示模板已在本地修改
+func (c *Case) warnTemplateChanged() {
+	if changed, version := c.TemplateChanged(); changed {
+		created := c.TemplateVersion
+		if created == "" {
+			created = "unknown"
+		}
+		gologger.Warning().Msgf("%s", i18n.Tf("case_template_changed", c.Type, c.Name, created, version))
+	}
+}
+
+// PullLocked 按锁文件安装所有锁定的模板版本
+func PullLocked(ctx context.Context, opts PullOptions) error {
+	lock, err := LoadTemplateLock(opts.LockFile)
+	if err != nil {
+		return err
+	}
+	if len(lock.Templates) == 0 {
+		return fmt.Errorf("no templates pinned in %s", opts.LockFile)
+	}
+	names := make([]string, 0, len(lock.Templates))
+	for name := range lock.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	opts.Locked = true
+	var failed []string
+	for _, name := range names {
+		if err := Pull(ctx, name, opts); err != nil {
+			gologger.Error().Msgf("❌ %s: %v", name, err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to pull %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package mod
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// templateZip 构造只包含 case.json 与 main.tf 的模板压缩包
+func templateZip(t *testing.T, version string) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range map[string]string{
+		TmplCaseFile: `{"name": "ec2", "version": "` + version + `"}`,
+		"main.tf":    "# v" + version + "\n",
+	} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(sum[:])
+}
+
+// templateRegistry 启动提供 aws/ec2 1.0.0 与 1.1.0 两个版本的 registry
+func templateRegistry(t *testing.T) (*httptest.Server, *RemoteIndex) {
+	t.Helper()
+	idx := &RemoteIndex{Templates: map[string]TemplateItem{}}
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	item := TemplateItem{ID: "aws/ec2", Latest: "1.1.0", Versions: map[string]TemplateVersion{}}
+	for _, v := range []string{"1.0.0", "1.1.0"} {
+		archive, sum := templateZip(t, v)
+		mux.HandleFunc("/ec2-"+v+".zip", func(w http.ResponseWriter, r *http.Request) { w.Write(archive) })
+		item.Versions[v] = TemplateVersion{URL: srv.URL + "/ec2-" + v + ".zip", SHA256: sum}
+	}
+	idx.Templates["aws/ec2"] = item
+	mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) { json.NewEncoder(w).Encode(idx) })
+	return srv, idx
+}
+
+func useTemplateDir(t *testing.T) string {
+	t.Helper()
+	old := TemplateDir
+	TemplateDir = t.TempDir()
+	t.Cleanup(func() { TemplateDir = old })
+	return TemplateDir
+}
+
+func TestTemplateHash_IgnoresTerraformState(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource {}"), 0644)
+	before, err := TemplateHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(dir, ".terraform", "providers"), 0755)
+	os.WriteFile(filepath.Join(dir, ".terraform", "providers", "bin"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, ".terraform.lock.hcl"), []byte("lock"), 0644)
+	os.WriteFile(filepath.Join(dir, "terraform.tfstate"), []byte("{}"), 0644)
+	if after, _ := TemplateHash(dir); after != before {
+		t.Error("terraform init output changed the template hash")
+	}
+	os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource { changed }"), 0644)
+	if after, _ := TemplateHash(dir); after == before {
+		t.Error("editing main.tf did not change the template hash")
+	}
+}
+
+func TestPull_RecordsAndHonoursLock(t *testing.T) {
+	tmplDir := useTemplateDir(t)
+	srv, idx := templateRegistry(t)
+	lockFile := filepath.Join(t.TempDir(), TemplateLockFile)
+	opts := PullOptions{RegistryURL: srv.URL, LockFile: lockFile}
+	ctx := context.Background()
+
+	if err := Pull(ctx, "aws/ec2:1.0.0", opts); err != nil {
+		t.Fatal(err)
+	}
+	lock, _ := LoadTemplateLock(lockFile)
+	entry := lock.Templates["aws/ec2"]
+	localHash, _ := TemplateHash(filepath.Join(tmplDir, "aws", "ec2"))
+	if entry.Version != "1.0.0" || entry.ArchiveSHA256 != idx.Templates["aws/ec2"].Versions["1.0.0"].SHA256 || entry.SHA256 != localHash {
+		t.Fatalf("lock entry = %+v", entry)
+	}
+
+	// 解锁拉取升级到最新版并更新锁文件
+	if err := Pull(ctx, "aws/ec2", opts); err != nil {
+		t.Fatal(err)
+	}
+	if lock, _ := LoadTemplateLock(lockFile); lock.Templates["aws/ec2"].Version != "1.1.0" {
+		t.Fatalf("lock after pulling latest = %+v", lock.Templates["aws/ec2"])
+	}
+
+	// 锁定模式安装锁文件中的版本，不修改锁文件
+	if err := PinTemplate(lockFile, "aws/ec2", entry); err != nil {
+		t.Fatal(err)
+	}
+	opts.Locked = true
+	if err := PullLocked(ctx, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, v, _ := CheckLocalImage("aws/ec2"); v != "1.0.0" {
+		t.Fatalf("locked pull installed v%s", v)
+	}
+
+	// 本地修改后锁定拉取会重新安装
+	main := filepath.Join(tmplDir, "aws", "ec2", "main.tf")
+	os.WriteFile(main, []byte("# edited\n"), 0644)
+	if err := Pull(ctx, "aws/ec2", opts); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(main); string(data) != "# v1.0.0\n" {
+		t.Errorf("locally edited template was not reinstalled: %q", data)
+	}
+
+	if err := Pull(ctx, "aws/ec2:1.1.0", opts); err == nil || !strings.Contains(err.Error(), "pinned to 1.0.0") {
+		t.Errorf("pulling another tag with --locked: err = %v", err)
+	}
+	if err := Pull(ctx, "aws/vpc", opts); err == nil || !strings.Contains(err.Error(), "not pinned") {
+		t.Errorf("pulling an unpinned template with --locked: err = %v", err)
+	}
+	entry.ArchiveSHA256 = strings.Repeat("0", 64)
+	PinTemplate(lockFile, "aws/ec2", entry)
+	if err := Pull(ctx, "aws/ec2", opts); err == nil || !strings.Contains(err.Error(), "changed since it was locked") {
+		t.Errorf("registry archive change: err = %v", err)
+	}
+}
+
+func TestCase_TemplateChanged(t *testing.T) {
+	tmplDir := useTemplateDir(t)
+	dir := filepath.Join(tmplDir, "aws", "ec2")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, TmplCaseFile), []byte(`{"version": "1.0.0"}`), 0644)
+	hash, _ := TemplateHash(dir)
+
+	c := &Case{Type: "aws/ec2", TemplateVersion: "1.0.0", TemplateSHA256: hash}
+	if changed, _ := c.TemplateChanged(); changed {
+		t.Fatal("unchanged template reported as changed")
+	}
+	os.WriteFile(filepath.Join(dir, TmplCaseFile), []byte(`{"version": "1.0.1"}`), 0644)
+	if changed, v := c.TemplateChanged(); !changed || v != "1.0.1" {
+		t.Errorf("TemplateChanged() = %v, %q", changed, v)
+	}
+	if changed, _ := (&Case{Type: "aws/ec2"}).TemplateChanged(); changed {
+		t.Error("case without a recorded hash reported as changed")
+	}
+}
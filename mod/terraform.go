@@ -202,6 +202,11 @@ func (te *TerraformExecutor) Plan(ctx context.Context, opts ...tfexec.PlanOption
 	return err
 }
 
+// PlanHasChanges runs terraform plan and reports whether it contains changes
+func (te *TerraformExecutor) PlanHasChanges(ctx context.Context, opts ...tfexec.PlanOption) (bool, error) {
+	return te.tf.Plan(ctx, opts...)
+}
+
 // Validate runs terraform validate and returns structured output
 func (te *TerraformExecutor) Validate(ctx context.Context) (*tfjson.ValidateOutput, error) {
 	return te.tf.Validate(ctx)
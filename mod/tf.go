@@ -105,6 +105,14 @@ func TfApply(Path string, opts ...string) error {
 	return nil
 }
 
+// TfApplyPlan 只 apply 已保存的 plan 文件，用于应用用户确认过的变更，plan 文件不存在时报错
+func TfApplyPlan(Path string) error {
+	if _, err := os.Stat(filepath.Join(Path, RedcPlanPath)); err != nil {
+		return fmt.Errorf("saved plan %s: %w", RedcPlanPath, err)
+	}
+	return TfApply(Path)
+}
+
 func TfStatus(Path string) (*tfjson.State, error) {
 	ctx, cancel := createContextWithTimeout()
 	defer cancel()
@@ -153,6 +161,20 @@ func TfValidate(Path string) (*tfjson.ValidateOutput, error) {
 	return result, nil
 }
 
+// TfDriftCheck 执行 refresh-only plan，返回云上资源是否被 redc 之外的操作修改
+// 不写入 plan 文件，避免覆盖待执行的计划
+func TfDriftCheck(Path string, opts ...string) (bool, error) {
+	ctx, cancel := createContextWithTimeout()
+	defer cancel()
+	gologger.Debug().Msgf("Checking terraform drift in %s\n", Path)
+	te, err := NewTerraformExecutor(Path)
+	if err != nil {
+		return false, fmt.Errorf("%s", i18n.Tf("tf_exec_failed", err.Error()))
+	}
+	o := append(ToPlan(opts), tfexec.RefreshOnly(true))
+	return te.PlanHasChanges(ctx, o...)
+}
+
 func TfDestroy(Path string, opts []string) error {
 	ctx, cancel := createContextWithTimeout()
 	defer cancel()
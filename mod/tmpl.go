@@ -6,16 +6,18 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
 
+	"red-cloud/i18n"
 	"red-cloud/mod/gologger"
 	"red-cloud/utils"
 
@@ -53,14 +55,27 @@ type RedcTmpl struct {
 	RedcPlugins   string       `json:"redc_plugins"`
 	TemplateType  TemplateType `json:"template"`
 	Tags          []string     `json:"tags,omitempty"`
-	Path          string       `json:"-"`
+	// LivenessProbe 抢占式实例监控使用的存活探测，未配置时探测 SSH 22 端口
+	LivenessProbe *ProbeConfig `json:"liveness_probe,omitempty"`
+	// DisableResourceTags 不为资源注入 redc_* 标准标签（见 CaseTagsFile）
+	DisableResourceTags bool   `json:"disable_resource_tags,omitempty"`
+	Path                string `json:"-"`
 }
 
 // PullOptions 配置项
 type PullOptions struct {
+	// RegistryURL 指定仓库地址，为空时按顺序使用配置的仓库（见 Registry）
 	RegistryURL string
-	Force       bool
-	Timeout     time.Duration
+	// Registry 只使用该名称的仓库，镜像引用中的 @registry 会覆盖它
+	Registry string
+	// Registries 可用的仓库，为空时读取配置
+	Registries []Registry
+	Force      bool
+	Timeout    time.Duration
+	// LockFile 项目的 redc.lock，拉取后记录模板版本与摘要，为空时不记录
+	LockFile string
+	// Locked 只安装 LockFile 中锁定的版本，并校验 ZIP 包与模板内容摘要
+	Locked bool
 }
 
 // =============================================================================
@@ -108,6 +123,7 @@ type SearchResult struct {
 	Description string
 	Author      string
 	Provider    string
+	Registry    string // 模板所在仓库的名称
 	Score       int
 }
 
@@ -117,13 +133,7 @@ type SearchResult struct {
 
 // GetRemoteIndex 获取并解析远程索引 (独立函数，便于复用)
 func GetRemoteIndex(ctx context.Context, registryURL string) (*RemoteIndex, error) {
-	var idx RemoteIndex
-	// 添加时间戳防止 CDN 缓存
-	indexURL := fmt.Sprintf("%s/index.json?t=%d", registryURL, time.Now().Unix())
-	if err := fetchJSON(ctx, indexURL, &idx); err != nil {
-		return nil, fmt.Errorf("fetch index failed: %w", err)
-	}
-	return &idx, nil
+	return Registry{URL: registryURL}.Index(ctx)
 }
 
 // =============================================================================
@@ -202,8 +212,13 @@ func SearchFromIndex(idx *RemoteIndex, query string) []SearchResult {
 		}
 	}
 
-	// 结果排序：分数高 > 名字短 > 字母序
-	sort.Slice(results, func(i, j int) bool {
+	sortSearchResults(results)
+	return results
+}
+
+// sortSearchResults 结果排序：分数高 > 名字短 > 字母序，多个仓库的结果保持仓库顺序
+func sortSearchResults(results []SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
 		// 优先级 1: 分数
 		if results[i].Score != results[j].Score {
 			return results[i].Score > results[j].Score
@@ -215,19 +230,59 @@ func SearchFromIndex(idx *RemoteIndex, query string) []SearchResult {
 		// 优先级 3: 字母序
 		return results[i].Key < results[j].Key
 	})
-
-	return results
 }
 
-// Search 对外暴露的完整搜索接口 (网络 + 计算)
+// Search 对外暴露的完整搜索接口 (网络 + 计算)，在所有仓库中搜索并标注来源。
+// 部分仓库不可用时给出警告，全部不可用时返回错误
 func Search(ctx context.Context, query string, opts PullOptions) ([]SearchResult, error) {
-	// 1. 获取远程索引
-	idx, err := GetRemoteIndex(ctx, opts.RegistryURL)
+	// 1. 获取各仓库的远程索引
+	indexes, err := RegistryIndexes(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
-	// 2. 内存搜索
-	return SearchFromIndex(idx, query), nil
+	var results []SearchResult
+	for _, ri := range indexes {
+		// 2. 内存搜索
+		for _, r := range SearchFromIndex(ri.Index, query) {
+			r.Registry = ri.Registry.Name
+			results = append(results, r)
+		}
+	}
+	sortSearchResults(results)
+	return results, nil
+}
+
+// RegistryIndex 仓库及其 index.json
+type RegistryIndex struct {
+	Registry Registry
+	Index    *RemoteIndex
+}
+
+// RegistryIndexes 按查找顺序读取本次操作使用的各仓库索引（带认证）。
+// 不可用的仓库给出警告并跳过，只有一个仓库或全部不可用时返回错误
+func RegistryIndexes(ctx context.Context, opts PullOptions) ([]RegistryIndex, error) {
+	regs, err := opts.registries()
+	if err != nil {
+		return nil, err
+	}
+	var indexes []RegistryIndex
+	var errs []error
+	for _, reg := range regs {
+		idx, err := reg.Index(ctx)
+		if err != nil {
+			if len(regs) == 1 {
+				return nil, err
+			}
+			gologger.Warning().Msgf("%s", i18n.Tf("registry_unavailable", reg.Name, err))
+			errs = append(errs, fmt.Errorf("%s: %w", reg.Name, err))
+			continue
+		}
+		indexes = append(indexes, RegistryIndex{Registry: reg, Index: idx})
+	}
+	if len(errs) == len(regs) {
+		return nil, errors.Join(errs...)
+	}
+	return indexes, nil
 }
 
 // =============================================================================
@@ -238,10 +293,37 @@ func Search(ctx context.Context, query string, opts PullOptions) ([]SearchResult
 func Pull(ctx context.Context, imageRef string, opts PullOptions) error {
 	startTime := time.Now()
 
-	// 1. 解析参数 (name:tag)
-	imageName, tag, found := strings.Cut(imageRef, ":")
-	if !found || tag == "" {
-		tag = "latest"
+	// 1. 解析参数 (name:tag@registry)
+	imageName, tag, registry := ParseImageRef(imageRef)
+	if registry != "" {
+		opts.Registry, opts.RegistryURL = registry, ""
+	}
+
+	// 锁定模式下使用 redc.lock 中的版本
+	var pin *TemplateLockEntry
+	if opts.Locked {
+		if opts.LockFile == "" {
+			return fmt.Errorf("locked pull requires a lockfile")
+		}
+		lock, err := LoadTemplateLock(opts.LockFile)
+		if err != nil {
+			return err
+		}
+		entry, ok := lock.Templates[imageName]
+		if !ok {
+			return fmt.Errorf("template '%s' is not pinned in %s", imageName, opts.LockFile)
+		}
+		if tag != "latest" && tag != entry.Version {
+			return fmt.Errorf("template '%s' is pinned to %s in %s", imageName, entry.Version, opts.LockFile)
+		}
+		tag = entry.Version
+		pin = &entry
+		// 从锁定时的仓库拉取（仓库已删除时按顺序查找，ZIP 摘要仍会校验）
+		if opts.Registry == "" && opts.RegistryURL == "" && entry.Registry != "" {
+			if regs, err := opts.registries(); err == nil && slices.ContainsFunc(regs, func(r Registry) bool { return r.Name == entry.Registry }) {
+				opts.Registry = entry.Registry
+			}
+		}
 	}
 
 	// 2. 检查本地
@@ -262,11 +344,18 @@ func Pull(ctx context.Context, imageRef string, opts PullOptions) error {
 	}
 
 	// 4. 执行核心下载逻辑
-	downloaded, err := pullCore(ctx, imageName, tag, localVer, exists, opts)
+	installed, downloaded, err := pullCore(ctx, imageName, tag, localVer, exists, opts, pin)
 	if err != nil {
 		return err
 	}
 
+	// 记录到项目锁文件（锁定模式不修改锁文件）
+	if opts.LockFile != "" && !opts.Locked {
+		if err := PinTemplate(opts.LockFile, imageName, installed); err != nil {
+			gologger.Warning().Msgf("%s", i18n.Tf("template_lock_write_failed", opts.LockFile, err))
+		}
+	}
+
 	// 5. 结果反馈
 	duration := time.Since(startTime).Round(time.Millisecond)
 	if downloaded {
@@ -279,58 +368,71 @@ func Pull(ctx context.Context, imageRef string, opts PullOptions) error {
 	return nil
 }
 
-// pullCore 处理网络请求和决策
-func pullCore(ctx context.Context, imageName, tag, localVer string, exists bool, opts PullOptions) (bool, error) {
-	gologger.Info().Msgf("🔍 Connecting to registry %s...", opts.RegistryURL)
+// pullCore 处理网络请求和决策，返回安装后的版本信息以及是否重新下载。
+// pin 不为空时校验 registry 的 ZIP 包摘要和安装后的内容摘要与锁文件一致
+func pullCore(ctx context.Context, imageName, tag, localVer string, exists bool, opts PullOptions, pin *TemplateLockEntry) (TemplateLockEntry, bool, error) {
+	var installed TemplateLockEntry
 
-	// 1. 获取远程索引
-	idx, err := GetRemoteIndex(ctx, opts.RegistryURL)
+	// 1-2. 按顺序在仓库中查找模版
+	reg, tmpl, err := findTemplate(ctx, imageName, opts)
 	if err != nil {
-		return false, err
-	}
-
-	// 2. 查找模版
-	tmpl, ok := idx.Templates[imageName]
-	if !ok {
-		return false, fmt.Errorf("template '%s' not found in registry", imageName)
+		return installed, false, err
 	}
 
 	// 3. 解析版本
 	targetTag := tag
 	if targetTag == "latest" || targetTag == "" {
 		if tmpl.Latest == "" {
-			return false, fmt.Errorf("remote latest version is missing")
+			return installed, false, fmt.Errorf("remote latest version is missing")
 		}
 		targetTag = tmpl.Latest
 	}
 
 	verData, ok := tmpl.Versions[targetTag]
 	if !ok {
-		return false, fmt.Errorf("version '%s' not found", targetTag)
+		return installed, false, fmt.Errorf("version '%s' not found", targetTag)
+	}
+	if pin != nil && pin.ArchiveSHA256 != "" && !strings.EqualFold(pin.ArchiveSHA256, verData.SHA256) {
+		return installed, false, fmt.Errorf("registry archive of %s:%s changed since it was locked (locked %s, registry %s)", imageName, targetTag, pin.ArchiveSHA256, verData.SHA256)
+	}
+	installed = TemplateLockEntry{Version: targetTag, ArchiveSHA256: verData.SHA256, Registry: reg.Name}
+
+	targetDir, err := resolveSafePath(imageName)
+	if err != nil {
+		return installed, false, fmt.Errorf("invalid install path: %w", err)
 	}
 
 	// 4. 决策
 	if exists && !opts.Force {
 		if localVer == targetTag {
-			gologger.Info().Msgf("✅ %s:%s is already up to date.", imageName, targetTag)
-			return false, nil
+			hash, _ := TemplateHash(targetDir)
+			if pin == nil || pin.SHA256 == "" || pin.SHA256 == hash {
+				gologger.Info().Msgf("✅ %s:%s is already up to date.", imageName, targetTag)
+				installed.SHA256 = hash
+				return installed, false, nil
+			}
+			gologger.Info().Msgf("🔄 Local %s:%s differs from %s, reinstalling...", imageName, targetTag, TemplateLockFile)
+		} else {
+			gologger.Info().Msgf("🔄 Updating %s (v%s -> v%s)...", imageName, localVer, targetTag)
 		}
-		gologger.Info().Msgf("🔄 Updating %s (v%s -> v%s)...", imageName, localVer, targetTag)
 	} else if exists {
 		gologger.Info().Msgf("⚠️  Force pulling %s:%s...", imageName, targetTag)
 	}
 
 	// 5. 下载并原子安装
-	targetDir, err := resolveSafePath(imageName)
-	if err != nil {
-		return false, fmt.Errorf("invalid install path: %w", err)
+	if err := downloadAndInstall(ctx, reg, verData, targetDir); err != nil {
+		return installed, false, err
 	}
 
-	if err := downloadAndInstall(ctx, verData, targetDir); err != nil {
-		return false, err
+	hash, err := TemplateHash(targetDir)
+	if err != nil {
+		return installed, true, fmt.Errorf("hash template failed: %w", err)
 	}
-
-	return true, nil
+	if pin != nil && pin.SHA256 != "" && pin.SHA256 != hash {
+		return installed, true, fmt.Errorf("content of %s:%s does not match %s", imageName, targetTag, TemplateLockFile)
+	}
+	installed.SHA256 = hash
+	return installed, true, nil
 }
 
 // =============================================================================
@@ -761,25 +863,27 @@ func readTemplateMeta(dirPath string) (*RedcTmpl, error) {
 	return tmpl, nil
 }
 
-// fetchJSON 通用 GET 请求
-func fetchJSON(ctx context.Context, url string, v interface{}) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// TemplatePlugins 返回模板 case.json 中 redc_plugins 声明的插件，模板不存在时返回 nil
+func TemplatePlugins(templateName string) []string {
+	tpPath, err := GetTemplatePath(templateName)
 	if err != nil {
-		return err
+		return nil
 	}
-	resp, err := NewProxyHTTPClient(30 * time.Second).Do(req)
-	if err != nil {
-		return err
+	meta, err := readTemplateMeta(tpPath)
+	if err != nil || meta == nil {
+		return nil
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("http status %d", resp.StatusCode)
+	var plugins []string
+	for _, p := range strings.Split(meta.RedcPlugins, ",") {
+		if name := strings.TrimSpace(p); name != "" {
+			plugins = append(plugins, name)
+		}
 	}
-	return json.NewDecoder(resp.Body).Decode(v)
+	return plugins
 }
 
-// downloadAndInstall 下载并解压 (适配新的 TemplateVersion 结构)
-func downloadAndInstall(ctx context.Context, verData TemplateVersion, finalDest string) error {
+// downloadAndInstall 从仓库下载并解压 (适配新的 TemplateVersion 结构)
+func downloadAndInstall(ctx context.Context, reg Registry, verData TemplateVersion, finalDest string) error {
 	// 1. 创建临时 ZIP 文件
 	tmpZip, err := os.CreateTemp("", "redc-dl-*.zip")
 	if err != nil {
@@ -791,24 +895,17 @@ func downloadAndInstall(ctx context.Context, verData TemplateVersion, finalDest
 	}()
 
 	// 2. 下载
-	req, err := http.NewRequestWithContext(ctx, "GET", verData.URL, nil)
+	body, size, err := reg.open(ctx, verData.URL, 0)
 	if err != nil {
 		return err
 	}
-	resp, err := NewProxyHTTPClient(0).Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("http status: %d", resp.StatusCode)
-	}
+	defer body.Close()
 
 	// 3. 进度条 + Hash
-	bar := progressbar.DefaultBytes(resp.ContentLength, "⬇️  Downloading")
+	bar := progressbar.DefaultBytes(size, "⬇️  Downloading")
 	hasher := sha256.New()
 	writer := io.MultiWriter(tmpZip, hasher, bar)
-	if _, err := io.Copy(writer, resp.Body); err != nil {
+	if _, err := io.Copy(writer, body); err != nil {
 		return fmt.Errorf("write failed: %w", err)
 	}
 	tmpZip.Close()
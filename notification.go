@@ -143,3 +143,21 @@ func (nm *NotificationManager) SendSpotRecoverFailed(sceneName string) {
 		nm.webhookMgr.Send(title, message, "#ff0000")
 	}
 }
+
+func (nm *NotificationManager) SendCaseDegraded(sceneName, detail string) {
+	title := i18n.T("notify_case_degraded")
+	message := i18n.Tf("notify_case_degraded_msg", sceneName, detail)
+	nm.Send(title, message)
+	if nm.webhookMgr != nil {
+		nm.webhookMgr.Send(title, message, "#ff8c00")
+	}
+}
+
+func (nm *NotificationManager) SendCaseHealthy(sceneName string) {
+	title := i18n.T("notify_case_healthy")
+	message := i18n.Tf("notify_case_healthy_msg", sceneName)
+	nm.Send(title, message)
+	if nm.webhookMgr != nil {
+		nm.webhookMgr.Send(title, message, "#36a64f")
+	}
+}
@@ -22,19 +22,21 @@ const (
 )
 
 type Case struct {
-	state      protoimpl.MessageState `protogen:"open.v1"`
-	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name       string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Type       string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
-	Module     string                 `protobuf:"bytes,4,opt,name=module,proto3" json:"module,omitempty"`
-	Operator   string                 `protobuf:"bytes,5,opt,name=operator,proto3" json:"operator,omitempty"`
-	Path       string                 `protobuf:"bytes,6,opt,name=path,proto3" json:"path,omitempty"`
-	Node       int32                  `protobuf:"varint,7,opt,name=node,proto3" json:"node,omitempty"`
-	CreateTime string                 `protobuf:"bytes,8,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
-	StateTime  string                 `protobuf:"bytes,9,opt,name=state_time,json=stateTime,proto3" json:"state_time,omitempty"`
-	Parameter  []string               `protobuf:"bytes,10,rep,name=parameter,proto3" json:"parameter,omitempty"`
-	State      string                 `protobuf:"bytes,11,opt,name=state,proto3" json:"state,omitempty"`
-	Plugins    string                 `protobuf:"bytes,13,opt,name=plugins,proto3" json:"plugins,omitempty"`
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type            string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Module          string                 `protobuf:"bytes,4,opt,name=module,proto3" json:"module,omitempty"`
+	Operator        string                 `protobuf:"bytes,5,opt,name=operator,proto3" json:"operator,omitempty"`
+	Path            string                 `protobuf:"bytes,6,opt,name=path,proto3" json:"path,omitempty"`
+	Node            int32                  `protobuf:"varint,7,opt,name=node,proto3" json:"node,omitempty"`
+	CreateTime      string                 `protobuf:"bytes,8,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	StateTime       string                 `protobuf:"bytes,9,opt,name=state_time,json=stateTime,proto3" json:"state_time,omitempty"`
+	Parameter       []string               `protobuf:"bytes,10,rep,name=parameter,proto3" json:"parameter,omitempty"`
+	State           string                 `protobuf:"bytes,11,opt,name=state,proto3" json:"state,omitempty"`
+	Plugins         string                 `protobuf:"bytes,13,opt,name=plugins,proto3" json:"plugins,omitempty"`
+	TemplateVersion string                 `protobuf:"bytes,14,opt,name=template_version,json=templateVersion,proto3" json:"template_version,omitempty"`
+	TemplateSha256  string                 `protobuf:"bytes,15,opt,name=template_sha256,json=templateSha256,proto3" json:"template_sha256,omitempty"`
 	// Output 是 map[string]OutputMeta，这里简化存储为 JSON 字符串
 	// 或者你可以定义一个 message OutputMeta 然后用 map<string, OutputMeta>
 	OutputJson    string `protobuf:"bytes,12,opt,name=output_json,json=outputJson,proto3" json:"output_json,omitempty"`
@@ -156,6 +158,20 @@ func (x *Case) GetPlugins() string {
 	return ""
 }
 
+func (x *Case) GetTemplateVersion() string {
+	if x != nil {
+		return x.TemplateVersion
+	}
+	return ""
+}
+
+func (x *Case) GetTemplateSha256() string {
+	if x != nil {
+		return x.TemplateSha256
+	}
+	return ""
+}
+
 func (x *Case) GetOutputJson() string {
 	if x != nil {
 		return x.OutputJson
@@ -451,7 +467,7 @@ var File_proto_redc_proto protoreflect.FileDescriptor
 
 const file_proto_redc_proto_rawDesc = "" +
 	"\n" +
-	"\x10proto/redc.proto\x12\x04redc\"\xc9\x02\n" +
+	"\x10proto/redc.proto\x12\x04redc\"\x9d\x03\n" +
 	"\x04Case\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
@@ -467,7 +483,9 @@ const file_proto_redc_proto_rawDesc = "" +
 	"\tparameter\x18\n" +
 	" \x03(\tR\tparameter\x12\x14\n" +
 	"\x05state\x18\v \x01(\tR\x05state\x12\x18\n" +
-	"\aplugins\x18\r \x01(\tR\aplugins\x12\x1f\n" +
+	"\aplugins\x18\r \x01(\tR\aplugins\x12)\n" +
+	"\x10template_version\x18\x0e \x01(\tR\x0ftemplateVersion\x12'\n" +
+	"\x0ftemplate_sha256\x18\x0f \x01(\tR\x0etemplateSha256\x12\x1f\n" +
 	"\voutput_json\x18\f \x01(\tR\n" +
 	"outputJson\"\x84\x01\n" +
 	"\aProject\x12!\n" +